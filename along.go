@@ -1,7 +1,9 @@
 package goodgeo
 
 // Along takes a [LineString] and returns a [Point] at a specified distance along the line.
-func Along(line *LineString, distance Meters) *Point {
+// Distances are measured with [Haversine] unless another [DistanceModel] is given.
+func Along(line *LineString, distance Meters, model ...DistanceModel) *Point {
+	m := distanceModel(model)
 	coords := line.Coords()
 	travelled := Meters(0.)
 
@@ -11,21 +13,16 @@ func Along(line *LineString, distance Meters) *Point {
 		}
 
 		if travelled >= distance {
-			overshot := Meters(distance - travelled)
-			if overshot == 0 {
+			overshot := Meters(travelled - distance)
+			if overshot == 0 || i == 0 {
 				return NewPointFlat(line.layout, coords[i])
 			}
 
-			direction := Bearing(coords[i], coords[i-1]) - 180
-			interpolated := Destination(
-				NewPointFlat(line.layout, coords[i]),
-				overshot,
-				direction,
-			)
-			return interpolated
+			interpolated := m.Destination(coords[i], overshot, m.Bearing(coords[i], coords[i-1]))
+			return NewPointFlat(line.layout, interpolated)
 		}
 
-		travelled += Distance(coords[i], coords[i+1])
+		travelled += m.Distance(coords[i], coords[i+1])
 	}
 
 	return NewPointFlat(line.layout, coords[len(coords)-1])
@@ -0,0 +1,217 @@
+package goodgeo
+
+import "math"
+
+// SplitAtAntimeridian cuts the line strings and polygons of g that cross the
+// antimeridian into multi-line strings and multi-polygons whose parts lie
+// on either side of it, as recommended by RFC 7946 section 3.1.9. The first
+// ordinate of g is taken to be longitude in degrees.
+//
+// A segment crosses the antimeridian if its longitudes differ by more than
+// 180°, that is if the shorter way between its ends is across it. The cut
+// points are interpolated linearly. Polygons that go around a pole are closed
+// along that pole. Geometries that do not cross the antimeridian are returned
+// as they are, members of geometry collections are cut one by one.
+func SplitAtAntimeridian(g T) T {
+	switch g := g.(type) {
+	case *LineString:
+		lines := splitLineAtAntimeridian(g.Coords())
+		if len(lines) < 2 {
+			return g
+		}
+		return NewMultiLineString(g.layout).MustSetCoords(lines).SetSRID(g.srid)
+	case *MultiLineString:
+		var lines [][]Coord
+		split := false
+		for _, line := range g.Coords() {
+			parts := splitLineAtAntimeridian(line)
+			split = split || len(parts) > 1
+			lines = append(lines, parts...)
+		}
+		if !split {
+			return g
+		}
+		return NewMultiLineString(g.layout).MustSetCoords(lines).SetSRID(g.srid)
+	case *Polygon:
+		polygons, split := splitPolygonAtAntimeridian(g.Coords())
+		if !split {
+			return g
+		}
+		return NewMultiPolygon(g.layout).MustSetCoords(polygons).SetSRID(g.srid)
+	case *MultiPolygon:
+		var polygons [][][]Coord
+		split := false
+		for _, polygon := range g.Coords() {
+			parts, ok := splitPolygonAtAntimeridian(polygon)
+			if !ok {
+				parts = [][][]Coord{polygon}
+			}
+			split = split || ok
+			polygons = append(polygons, parts...)
+		}
+		if !split {
+			return g
+		}
+		return NewMultiPolygon(g.layout).MustSetCoords(polygons).SetSRID(g.srid)
+	case *GeometryCollection:
+		gc := NewGeometryCollection().SetSRID(g.srid)
+		for _, g := range g.geoms {
+			gc.MustPush(SplitAtAntimeridian(g))
+		}
+		return gc
+	default:
+		return g
+	}
+}
+
+// splitLineAtAntimeridian returns the parts of line between the points
+// where it crosses the antimeridian.
+func splitLineAtAntimeridian(line []Coord) [][]Coord {
+	if len(line) == 0 {
+		return nil
+	}
+	var lines [][]Coord
+	part := []Coord{line[0]}
+	for i := 1; i < len(line); i++ {
+		a, b := line[i-1], line[i]
+		if math.Abs(b[0]-a[0]) > 180 {
+			// Cross at 180° if a is to the east of the antimeridian, at
+			// -180° otherwise.
+			side := math.Copysign(180, a[0])
+			unwrapped := b[0] + 2*side
+			end := interpolate(a, b, (side-a[0])/(unwrapped-a[0]))
+			end[0] = side
+			start := append(Coord(nil), end...)
+			start[0] = -side
+			part = appendDistinct(part, end)
+			if len(part) > 1 {
+				lines = append(lines, part)
+			}
+			part = []Coord{start}
+		}
+		part = appendDistinct(part, b)
+	}
+	if len(part) > 1 || len(lines) == 0 {
+		lines = append(lines, part)
+	}
+	return lines
+}
+
+// appendDistinct appends c to coords unless it is equal to the last one.
+func appendDistinct(coords []Coord, c Coord) []Coord {
+	if n := len(coords); n > 0 && coords[n-1][0] == c[0] && coords[n-1][1] == c[1] {
+		return coords
+	}
+	return append(coords, c)
+}
+
+// splitPolygonAtAntimeridian returns the parts of polygon on either side of
+// the antimeridian, and false if it does not cross it.
+//
+// The longitudes of the rings are unwrapped so that they have no jumps, and
+// the holes are shifted by whole turns to lie with the exterior ring. The
+// rings are then clipped to each 360° wide strip between two antimeridians
+// that they overlap, which may leave them running back and forth along the
+// sides of the strip, and rebuilt into polygons from there.
+func splitPolygonAtAntimeridian(polygon [][]Coord) ([][][]Coord, bool) {
+	if len(polygon) == 0 || len(polygon[0]) == 0 {
+		return nil, false
+	}
+	rings := make([][]Coord, len(polygon))
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	for i, ring := range polygon {
+		rings[i] = unwrapRing(ring)
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, c := range rings[i] {
+			lo, hi = min(lo, c[0]), max(hi, c[0])
+		}
+		if i > 0 {
+			shift := 360 * math.Round(((minX+maxX)-(lo+hi))/720)
+			for _, c := range rings[i] {
+				c[0] += shift
+			}
+			lo, hi = lo+shift, hi+shift
+		}
+		minX, maxX = min(minX, lo), max(maxX, hi)
+	}
+	first, last := math.Floor((minX+180)/360), math.Ceil((maxX+180)/360)-1
+	if first >= last {
+		return nil, false
+	}
+
+	var polygons [][][]Coord
+	for k := first; k <= last; k++ {
+		lo, hi := 360*k-180, 360*k+180
+		var clipped [][]Coord
+		for _, ring := range rings {
+			ring = clipRingX(ring[:len(ring)-1], lo, hi)
+			if len(ring) < 3 {
+				continue
+			}
+			for _, c := range ring {
+				c[0] -= 360 * k
+			}
+			clipped = append(clipped, append(ring, ring[0]))
+		}
+		polygons = append(polygons, polygonize(cleanRings(clipped), true)...)
+	}
+	return polygons, true
+}
+
+// unwrapRing returns a copy of the closed ring with longitudes shifted by
+// whole turns so that consecutive longitudes differ by at most 180°. A ring
+// that goes around a pole is closed along the pole that is on its left.
+func unwrapRing(ring []Coord) []Coord {
+	unwrapped := make([]Coord, len(ring))
+	shift := 0.0
+	for i, c := range ring {
+		if i > 0 {
+			if d := c[0] - ring[i-1][0]; d > 180 {
+				shift -= 360
+			} else if d < -180 {
+				shift += 360
+			}
+		}
+		unwrapped[i] = append(Coord(nil), c...)
+		unwrapped[i][0] += shift
+	}
+	if shift == 0 {
+		return unwrapped
+	}
+	// Going east, the north pole is on the left.
+	first, last := unwrapped[0], unwrapped[len(unwrapped)-1]
+	pole := math.Copysign(90, shift)
+	a, b := append(Coord(nil), last...), append(Coord(nil), first...)
+	a[1], b[1] = pole, pole
+	return append(unwrapped, a, b, append(Coord(nil), first...))
+}
+
+// clipRingX clips the open ring to the longitudes from lo to hi with the
+// Sutherland–Hodgman algorithm.
+func clipRingX(ring []Coord, lo, hi float64) []Coord {
+	for _, edge := range []struct {
+		x     float64
+		upper bool
+	}{{lo, false}, {hi, true}} {
+		inside := func(c Coord) bool {
+			if edge.upper {
+				return c[0] <= edge.x
+			}
+			return c[0] >= edge.x
+		}
+		var clipped []Coord
+		for i, c := range ring {
+			prev := ring[(i+len(ring)-1)%len(ring)]
+			if inside(c) != inside(prev) {
+				p := interpolate(prev, c, (edge.x-prev[0])/(c[0]-prev[0]))
+				p[0] = edge.x
+				clipped = append(clipped, p)
+			}
+			if inside(c) {
+				clipped = append(clipped, append(Coord(nil), c...))
+			}
+		}
+		ring = clipped
+	}
+	return ring
+}
@@ -0,0 +1,103 @@
+package goodgeo
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestSplitAtAntimeridian(t *testing.T) {
+	for i, tc := range []struct {
+		g    T
+		want T
+	}{
+		{
+			g:    NewLineString(XY).MustSetCoords([]Coord{{10, 0}, {20, 10}}),
+			want: NewLineString(XY).MustSetCoords([]Coord{{10, 0}, {20, 10}}),
+		},
+		{
+			g: NewLineString(XY).MustSetCoords([]Coord{{170, 0}, {-170, 10}, {-160, 10}, {170, 20}}).SetSRID(4326),
+			want: NewMultiLineString(XY).MustSetCoords([][]Coord{
+				{{170, 0}, {180, 5}},
+				{{-180, 5}, {-170, 10}, {-160, 10}, {-180, 16.666666666666664}},
+				{{180, 16.666666666666664}, {170, 20}},
+			}).SetSRID(4326),
+		},
+		{
+			// A point on the antimeridian is not repeated.
+			g: NewLineString(XYZ).MustSetCoords([]Coord{{170, 0, 0}, {180, 0, 1}, {-170, 0, 3}}),
+			want: NewMultiLineString(XYZ).MustSetCoords([][]Coord{
+				{{170, 0, 0}, {180, 0, 1}},
+				{{-180, 0, 1}, {-170, 0, 3}},
+			}),
+		},
+		{
+			g: NewMultiLineString(XY).MustSetCoords([][]Coord{{{0, 0}, {1, 1}}, {{-175, 0}, {175, 10}}}),
+			want: NewMultiLineString(XY).MustSetCoords([][]Coord{
+				{{0, 0}, {1, 1}},
+				{{-175, 0}, {-180, 5}},
+				{{180, 5}, {175, 10}},
+			}),
+		},
+		{
+			g: NewPolygon(XY).MustSetCoords([][]Coord{
+				{{170, 0}, {-170, 0}, {-170, 10}, {170, 10}, {170, 0}},
+				{{-178, 2}, {-178, 4}, {-172, 4}, {-172, 2}, {-178, 2}},
+			}),
+			want: NewMultiPolygon(XY).MustSetCoords([][][]Coord{
+				{{{170, 0}, {180, 0}, {180, 10}, {170, 10}, {170, 0}}},
+				{
+					{{-180, 0}, {-170, 0}, {-170, 10}, {-180, 10}, {-180, 0}},
+					{{-178, 2}, {-178, 4}, {-172, 4}, {-172, 2}, {-178, 2}},
+				},
+			}),
+		},
+		{
+			// The part west of the antimeridian falls apart in two.
+			g: NewPolygon(XY).MustSetCoords([][]Coord{
+				{{170, 0}, {-170, 0}, {-170, 10}, {175, 5}, {-170, 20}, {170, 20}, {170, 0}},
+			}),
+			want: NewMultiPolygon(XY).MustSetCoords([][][]Coord{
+				{{{170, 0}, {180, 0}, {180, 20.0 / 3}, {175, 5}, {180, 10}, {180, 20}, {170, 20}, {170, 0}}},
+				{{{-180, 0}, {-170, 0}, {-170, 10}, {-180, 20.0 / 3}, {-180, 0}}},
+				{{{-180, 10}, {-170, 20}, {-180, 20}, {-180, 10}}},
+			}),
+		},
+		{
+			// A ring around the south pole.
+			g: NewPolygon(XY).MustSetCoords([][]Coord{
+				{{0, -80}, {-120, -80}, {120, -80}, {0, -80}},
+			}),
+			want: NewMultiPolygon(XY).MustSetCoords([][][]Coord{
+				{{{0, -90}, {180, -90}, {180, -80}, {120, -80}, {0, -80}, {0, -90}}},
+				{{{-180, -90}, {0, -90}, {0, -80}, {-120, -80}, {-180, -80}, {-180, -90}}},
+			}),
+		},
+		{
+			g: NewMultiPolygon(XY).MustSetCoords([][][]Coord{
+				{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+				{{{175, 0}, {-175, 0}, {-175, 10}, {175, 0}}},
+			}),
+			want: NewMultiPolygon(XY).MustSetCoords([][][]Coord{
+				{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+				{{{175, 0}, {180, 0}, {180, 5}, {175, 0}}},
+				{{{-180, 0}, {-175, 0}, {-175, 10}, {-180, 5}, {-180, 0}}},
+			}),
+		},
+		{
+			g: NewGeometryCollection().MustPush(
+				NewPoint(XY).MustSetCoords(Coord{180, 0}),
+				NewLineString(XY).MustSetCoords([]Coord{{-175, 0}, {175, 0}}),
+			),
+			want: NewGeometryCollection().MustPush(
+				NewPoint(XY).MustSetCoords(Coord{180, 0}),
+				NewMultiLineString(XY).MustSetCoords([][]Coord{{{-175, 0}, {-180, 0}}, {{180, 0}, {175, 0}}}),
+			),
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tc.want, SplitAtAntimeridian(tc.g))
+		})
+	}
+}
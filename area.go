@@ -0,0 +1,120 @@
+package goodgeo
+
+import (
+	"math"
+
+	"github.com/matoous/goodgeo/internal/geographiclib"
+)
+
+// AreaModel selects the model of the Earth used by [Area].
+type AreaModel int
+
+const (
+	// Spherical measures area on a sphere with radius [EarthRadius], like Turf's area.
+	Spherical AreaModel = iota
+	// Ellipsoidal measures area on the WGS84 ellipsoid with edges along
+	// geodesics, using the method from "Algorithms for geodesics" by Karney,
+	// J. Geodesy 87, 43–55 (2013). It is accurate to within about 0.1 square
+	// meters.
+	Ellipsoidal
+)
+
+// wgs84Geodesic solves geodesic problems on the WGS84 ellipsoid.
+var wgs84Geodesic = geographiclib.New(WGS84SemiMajorAxis, WGS84Flattening)
+
+// Area returns the area of g in square meters. Holes are subtracted from the
+// area of their polygon, lines and points have no area. The [Spherical] model
+// is used unless another model is given.
+func Area(g T, model ...AreaModel) SquareMeters {
+	area := sphericalRingArea
+	if len(model) > 0 && model[0] == Ellipsoidal {
+		area = wgs84Geodesic.PolygonArea
+	}
+	return SquareMeters(geodesicArea(g, area))
+}
+
+// A ringAreaFunc returns the signed area of the ring in flatCoords, which may,
+// but does not need to, be closed.
+type ringAreaFunc func(flatCoords []float64, stride int) float64
+
+func geodesicArea(g T, ringArea ringAreaFunc) float64 {
+	switch g := g.(type) {
+	case *LinearRing:
+		return math.Abs(ringArea(g.flatCoords, g.stride))
+	case *Polygon:
+		return polygonArea(g.flatCoords, 0, g.ends, g.stride, ringArea)
+	case *MultiPolygon:
+		var area float64
+		offset := 0
+		for _, ends := range g.endss {
+			area += polygonArea(g.flatCoords, offset, ends, g.stride, ringArea)
+			if len(ends) > 0 {
+				offset = ends[len(ends)-1]
+			}
+		}
+		return area
+	case *GeometryCollection:
+		var area float64
+		for _, g := range g.geoms {
+			area += geodesicArea(g, ringArea)
+		}
+		return area
+	default:
+		return 0
+	}
+}
+
+// polygonArea returns the area of the exterior ring minus the area of the
+// holes.
+func polygonArea(flatCoords []float64, offset int, ends []int, stride int, ringArea ringAreaFunc) float64 {
+	var area float64
+	for i, end := range ends {
+		ring := math.Abs(ringArea(flatCoords[offset:end], stride))
+		if i == 0 {
+			area += ring
+		} else {
+			area -= ring
+		}
+		offset = end
+	}
+	return area
+}
+
+// sphericalRingArea returns the signed area of a ring on a sphere with radius
+// [EarthRadius] using the method from "Some Algorithms for Polygons on a
+// Sphere" by Chamberlain and Duquette, JPL Publication 07-03. The area is
+// positive for counter-clockwise rings.
+func sphericalRingArea(flatCoords []float64, stride int) float64 {
+	end := len(flatCoords)
+	if end >= 2*stride && flatCoords[0] == flatCoords[end-stride] && flatCoords[1] == flatCoords[end-stride+1] {
+		end -= stride
+	}
+	n := end / stride
+	if n < 3 {
+		return 0
+	}
+
+	var total float64
+	for i := range n {
+		lower := i * stride
+		middle := ((i + 1) % n) * stride
+		upper := ((i + 2) % n) * stride
+		dLon := normalizeLongitude(flatCoords[upper] - flatCoords[lower])
+		total += float64(DegreesToRadians(Degrees(dLon))) *
+			math.Sin(float64(DegreesToRadians(Degrees(flatCoords[middle+1]))))
+	}
+
+	return -total * EarthRadius * EarthRadius / 2
+}
+
+// normalizeLongitude wraps a longitude difference into the range [-180, 180].
+func normalizeLongitude(lon float64) float64 {
+	switch {
+	case lon > 180:
+		return lon - 360
+	case lon < -180:
+		return lon + 360
+	default:
+		return lon
+	}
+}
@@ -0,0 +1,111 @@
+package goodgeo
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestGeodesicArea(t *testing.T) {
+	// Area of the band between the equator and 1°N spanning 1° of longitude
+	// on a sphere.
+	unitCell := EarthRadius * EarthRadius * math.Pi / 180 * math.Sin(math.Pi/180)
+
+	for i, tc := range []struct {
+		g     T
+		model []AreaModel
+		want  float64
+		tol   float64
+	}{
+		{
+			g:    NewPoint(XY).MustSetCoords(Coord{1, 2}),
+			want: 0,
+		},
+		{
+			g:    NewLineString(XY).MustSetCoords([]Coord{{0, 0}, {1, 1}}),
+			want: 0,
+		},
+		{
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}),
+			want: unitCell,
+			tol:  1e-3,
+		},
+		{
+			// Clockwise rings have the same area.
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {0, 0}}}),
+			want: unitCell,
+			tol:  1e-3,
+		},
+		{
+			// Unclosed rings are implicitly closed.
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}),
+			want: unitCell,
+			tol:  1e-3,
+		},
+		{
+			// Rings crossing the antimeridian.
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{{{179.5, 0}, {-179.5, 0}, {-179.5, 1}, {179.5, 1}, {179.5, 0}}}),
+			want: unitCell,
+			tol:  1e-3,
+		},
+		{
+			g: NewPolygon(XY).MustSetCoords([][]Coord{
+				{{0, 0}, {2, 0}, {2, 1}, {0, 1}, {0, 0}},
+				{{0.25, 0.25}, {0.25, 0.75}, {0.75, 0.75}, {0.75, 0.25}, {0.25, 0.25}},
+			}),
+			want: 1.75 * unitCell,
+			tol:  1e-3,
+		},
+		{
+			g: NewMultiPolygon(XY).MustSetCoords([][][]Coord{
+				{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}},
+				{{{10, 0}, {11, 0}, {11, 1}, {10, 1}, {10, 0}}},
+			}),
+			want: 2 * unitCell,
+			tol:  1e-3,
+		},
+		{
+			// 1° by 1° cell at the equator on the WGS84 ellipsoid.
+			g:     NewPolygon(XY).MustSetCoords([][]Coord{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}),
+			model: []AreaModel{Ellipsoidal},
+			want:  12308778361.469,
+			tol:   1e-9,
+		},
+		{
+			// The same cell across the antimeridian, clockwise.
+			g:     NewPolygon(XY).MustSetCoords([][]Coord{{{179.5, 0}, {179.5, 1}, {-179.5, 1}, {-179.5, 0}, {179.5, 0}}}),
+			model: []AreaModel{Ellipsoidal},
+			want:  12308778361.469,
+			tol:   1e-9,
+		},
+		{
+			// A ring around the north pole at 89°N, from GeographicLib's
+			// tests.
+			g:     NewPolygon(XY).MustSetCoords([][]Coord{{{0, 89}, {90, 89}, {180, 89}, {270, 89}, {0, 89}}}),
+			model: []AreaModel{Ellipsoidal},
+			want:  24952305678.0,
+			tol:   1e-10,
+		},
+		{
+			// An octant of the ellipsoid with a 1° by 1° hole.
+			g: NewPolygon(XY).MustSetCoords([][]Coord{
+				{{0, 90}, {0, 0}, {90, 0}, {0, 90}},
+				{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {0, 0}},
+			}),
+			model: []AreaModel{Ellipsoidal},
+			want:  63758202715511.0 - 12308778361.469,
+			tol:   1e-12,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := float64(Area(tc.g, tc.model...))
+			if tc.want == 0 {
+				assert.Equal(t, 0, got)
+				return
+			}
+			assert.True(t, math.Abs(got/tc.want-1) < tc.tol, "got %f, want %f", got, tc.want)
+		})
+	}
+}
@@ -0,0 +1,639 @@
+package goodgeo
+
+import (
+	"math"
+	"slices"
+)
+
+// BooleanIntersects returns true if a and b share at least one point.
+func BooleanIntersects(a, b T) bool {
+	return intersects(newPlanar(a), newPlanar(b))
+}
+
+// BooleanDisjoint returns true if a and b share no point.
+func BooleanDisjoint(a, b T) bool {
+	return !BooleanIntersects(a, b)
+}
+
+// BooleanContains returns true if no point of b lies in the exterior of a and
+// at least one point of the interior of b lies in the interior of a.
+func BooleanContains(a, b T) bool {
+	return contains(newPlanar(a), newPlanar(b))
+}
+
+// BooleanWithin returns true if a lies within b, i.e. b contains a.
+func BooleanWithin(a, b T) bool {
+	return BooleanContains(b, a)
+}
+
+// BooleanTouches returns true if a and b share at least one point but their
+// interiors do not intersect.
+func BooleanTouches(a, b T) bool {
+	return touches(newPlanar(a), newPlanar(b))
+}
+
+// BooleanCrosses returns true if the interiors of a and b intersect in a
+// geometry of lower dimension than the higher dimensional of a and b, and
+// the intersection is neither a nor b. It is defined for point/line,
+// point/polygon, line/line and line/polygon pairs in either order.
+func BooleanCrosses(a, b T) bool {
+	pa, pb := newPlanar(a), newPlanar(b)
+	if pa.empty() || pb.empty() {
+		return false
+	}
+	if pa.dimension() > pb.dimension() {
+		pa, pb = pb, pa
+	}
+	switch {
+	case pa.dimension() == 0 && pb.dimension() > 0,
+		pa.dimension() == 1 && pb.dimension() == 2:
+		var in, out bool
+		for _, s := range pa.samples(pb) {
+			switch pb.locate(s.coord) {
+			case interior:
+				in = true
+			case exterior:
+				out = true
+			case boundary:
+			}
+		}
+		return in && out
+	case pa.dimension() == 1 && pb.dimension() == 1:
+		if linesOverlap(pa, pb) {
+			return false
+		}
+		for _, s := range pa.samples(pb) {
+			if pa.locate(s.coord) == interior && pb.locate(s.coord) == interior {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// BooleanOverlaps returns true if a and b have the same dimension, their
+// interiors intersect in a geometry of that dimension, and neither contains
+// the other.
+func BooleanOverlaps(a, b T) bool {
+	pa, pb := newPlanar(a), newPlanar(b)
+	if pa.empty() || pb.empty() || pa.dimension() != pb.dimension() ||
+		!pa.bounds.Overlaps(XY, pb.bounds) {
+		return false
+	}
+	switch pa.dimension() {
+	case 0:
+		var shared, onlyA, onlyB bool
+		for _, c := range pa.points {
+			if pb.locate(c) == exterior {
+				onlyA = true
+			} else {
+				shared = true
+			}
+		}
+		for _, c := range pb.points {
+			if pa.locate(c) == exterior {
+				onlyB = true
+			}
+		}
+		return shared && onlyA && onlyB
+	case 1:
+		return linesOverlap(pa, pb) && !pa.contains(pb) && !pb.contains(pa)
+	default:
+		return interiorsIntersect(pa, pb) && !pa.contains(pb) && !pb.contains(pa)
+	}
+}
+
+// A Relation is the relation of a geometry to a [PreparedGeometry].
+type Relation int
+
+const (
+	// RelationDisjoint means that the geometries share no point.
+	RelationDisjoint Relation = iota
+	// RelationTouches means that the geometries share a point but their
+	// interiors do not intersect.
+	RelationTouches
+	// RelationContained means that the geometry lies within the prepared
+	// one.
+	RelationContained
+	// RelationPartial means that the interiors intersect but the geometry
+	// does not lie within the prepared one.
+	RelationPartial
+)
+
+// A PreparedGeometry is a geometry whose segments are indexed once, for
+// testing many geometries against it.
+type PreparedGeometry struct {
+	p *planar
+}
+
+// Prepare returns g prepared for [PreparedGeometry.Relate].
+func Prepare(g T) *PreparedGeometry {
+	return &PreparedGeometry{p: newPlanar(g)}
+}
+
+// Relate returns the relation of g to p, as given by [BooleanIntersects],
+// [BooleanTouches] and [BooleanContains].
+func (p *PreparedGeometry) Relate(g T) Relation {
+	other := newPlanar(g)
+	switch {
+	case !intersects(p.p, other):
+		return RelationDisjoint
+	case (p.p.dimension() > 0 || other.dimension() > 0) && !interiorsIntersect(p.p, other):
+		return RelationTouches
+	case contains(p.p, other):
+		return RelationContained
+	default:
+		return RelationPartial
+	}
+}
+
+// A location is the position of a point relative to a geometry.
+type location int
+
+const (
+	exterior location = iota
+	boundary
+	interior
+)
+
+// A sample is a point on a geometry. mid is true for samples between the
+// vertices and intersection points of lines and rings.
+type sample struct {
+	coord Coord
+	mid   bool
+}
+
+// planar is a geometry decomposed into its points, lines and polygons, which
+// are treated as planar for the boolean predicates. The segments of the
+// lines and rings and the points are indexed in packed R-trees, so that
+// locating a point takes logarithmic time.
+type planar struct {
+	points    []Coord
+	lines     [][]Coord
+	polygons  [][][]Coord
+	bounds    *Bounds
+	edges     []planarEdge
+	edgeTree  *boxTree
+	pointTree *boxTree
+}
+
+// A planarEdge is a segment of a line or of a ring of a polygon. part is the
+// index of the line or polygon and ring the index of the ring, or -1 for
+// lines.
+type planarEdge struct {
+	a, b       Coord
+	part, ring int
+}
+
+func newPlanar(g T) *planar {
+	p := &planar{bounds: NewBounds(XY)}
+	p.add(g)
+	p.index()
+	return p
+}
+
+func (p *planar) add(g T) {
+	switch g := g.(type) {
+	case *Point:
+		if !g.Empty() {
+			p.points = append(p.points, g.Coords())
+		}
+	case *MultiPoint:
+		for _, c := range g.Coords() {
+			if c != nil {
+				p.points = append(p.points, c)
+			}
+		}
+	case *LineString:
+		p.lines = append(p.lines, g.Coords())
+	case *LinearRing:
+		p.lines = append(p.lines, g.Coords())
+	case *MultiLineString:
+		p.lines = append(p.lines, g.Coords()...)
+	case *Polygon:
+		p.addPolygon(g.Coords())
+	case *MultiPolygon:
+		for _, coords := range g.Coords() {
+			p.addPolygon(coords)
+		}
+	case *GeometryCollection:
+		for _, g := range g.geoms {
+			p.add(g)
+		}
+		return
+	}
+	if !g.Empty() {
+		p.bounds.extendFlatCoords(g.FlatCoords(), 0, len(g.FlatCoords()), g.Stride())
+	}
+}
+
+func (p *planar) addPolygon(rings [][]Coord) {
+	if len(rings) == 0 || len(rings[0]) == 0 {
+		return
+	}
+	closed := make([][]Coord, len(rings))
+	for i, ring := range rings {
+		if len(ring) > 0 && !equals(ring[0], ring[len(ring)-1]) {
+			ring = append(ring, ring[0])
+		}
+		closed[i] = ring
+	}
+	p.polygons = append(p.polygons, closed)
+}
+
+// index collects the edges of p and builds the trees.
+func (p *planar) index() {
+	for i, line := range p.lines {
+		for j := 1; j < len(line); j++ {
+			p.edges = append(p.edges, planarEdge{a: line[j-1], b: line[j], part: i, ring: -1})
+		}
+	}
+	for i, rings := range p.polygons {
+		for j, ring := range rings {
+			// Like pointInRing, close rings whose ends are only equal within
+			// the tolerance, so that no ray passes through the gap.
+			for k := range ring {
+				prev := ring[(k+len(ring)-1)%len(ring)]
+				if k == 0 && prev[0] == ring[0][0] && prev[1] == ring[0][1] {
+					continue
+				}
+				p.edges = append(p.edges, planarEdge{a: prev, b: ring[k], part: i, ring: j})
+			}
+		}
+	}
+	boxes := make([]box, len(p.edges))
+	for i, e := range p.edges {
+		boxes[i] = segmentBox(e.a, e.b)
+	}
+	p.edgeTree = newBoxTree(boxes)
+	boxes = make([]box, len(p.points))
+	for i, c := range p.points {
+		boxes[i] = segmentBox(c, c)
+	}
+	p.pointTree = newBoxTree(boxes)
+}
+
+func (p *planar) empty() bool {
+	return len(p.points) == 0 && len(p.lines) == 0 && len(p.polygons) == 0
+}
+
+// dimension returns the highest dimension of the components of p.
+func (p *planar) dimension() int {
+	switch {
+	case len(p.polygons) > 0:
+		return 2
+	case len(p.lines) > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// locate returns the location of c relative to p. A ray cast from c to the
+// right finds the edges that c lies on and the edges of each ring that it
+// crosses, which give the location of c in each polygon by the even-odd
+// rule.
+func (p *planar) locate(c Coord) location {
+	type ringKey struct{ part, ring int }
+	var (
+		crossings    map[ringKey]int
+		onRing       map[int]bool
+		onLine       map[int]bool
+		lineInterior bool
+	)
+	x, y := c[0], c[1]
+	ray := box{minX: x - onSegmentTolerance, minY: y - onSegmentTolerance, maxX: math.Inf(1), maxY: y + onSegmentTolerance}
+	p.edgeTree.search(ray, func(i int) {
+		e := &p.edges[i]
+		if onSegment(x, y, e.a[0], e.a[1], e.b[0], e.b[1]) {
+			if e.ring >= 0 {
+				if onRing == nil {
+					onRing = make(map[int]bool)
+				}
+				onRing[e.part] = true
+				return
+			}
+			if !isEndpoint(c, p.lines[e.part]) {
+				lineInterior = true
+				return
+			}
+			if onLine == nil {
+				onLine = make(map[int]bool)
+			}
+			onLine[e.part] = true
+			return
+		}
+		// The same test as pointInRing.
+		if e.ring >= 0 && (e.b[1] > y) != (e.a[1] > y) && x < (e.a[0]-e.b[0])*(y-e.b[1])/(e.a[1]-e.b[1])+e.b[0] {
+			if crossings == nil {
+				crossings = make(map[ringKey]int)
+			}
+			crossings[ringKey{e.part, e.ring}]++
+		}
+	})
+
+	loc := exterior
+	for part, rings := range p.polygons {
+		switch {
+		case onRing[part]:
+			loc = boundary
+			continue
+		case crossings[ringKey{part, 0}]%2 == 0:
+			continue
+		}
+		inHole := false
+		for ring := 1; ring < len(rings); ring++ {
+			if crossings[ringKey{part, ring}]%2 == 1 {
+				inHole = true
+				break
+			}
+		}
+		if !inHole {
+			return interior
+		}
+	}
+	if lineInterior {
+		return interior
+	}
+	if endpoints := len(onLine); endpoints > 0 {
+		if endpoints%2 == 0 {
+			return interior
+		}
+		loc = max(loc, boundary)
+	}
+	found := false
+	p.pointTree.search(segmentBox(c, c).grow(onSegmentTolerance), func(i int) {
+		found = found || equals(c, p.points[i])
+	})
+	if found {
+		return interior
+	}
+	return loc
+}
+
+// isEndpoint returns true if c is an end of the open line.
+func isEndpoint(c Coord, line []Coord) bool {
+	first, last := line[0], line[len(line)-1]
+	if equals(first, last) {
+		return false
+	}
+	return equals(c, first) || equals(c, last)
+}
+
+// samples returns the points of p and, for lines and rings, their vertices,
+// their intersection points with other, the vertices of other that lie on
+// them, and the midpoints between those. Between two consecutive samples
+// each segment lies entirely in either the interior, boundary or exterior of
+// other. Segments outside the bounds of other lie in its exterior and are
+// sampled by their first vertex only.
+func (p *planar) samples(other *planar) []sample {
+	var samples []sample
+	for _, c := range p.points {
+		samples = append(samples, sample{coord: c})
+	}
+	ob := other.bounds
+	bounds := box{minX: ob.Min(0), minY: ob.Min(1), maxX: ob.Max(0), maxY: ob.Max(1)}.grow(onSegmentTolerance)
+	for _, e := range p.edges {
+		a, b := e.a, e.b
+		q := segmentBox(a, b).grow(onSegmentTolerance)
+		if !q.intersects(bounds) {
+			samples = append(samples, sample{coord: a})
+			continue
+		}
+		ts := []float64{0, 1}
+		other.edgeTree.search(q, func(i int) {
+			o := &other.edges[i]
+			for _, x := range segmentIntersection(a, b, o.a, o.b) {
+				ts = append(ts, segmentParameter(a, b, x))
+			}
+		})
+		other.pointTree.search(q, func(i int) {
+			if c := other.points[i]; onSegment(c[0], c[1], a[0], a[1], b[0], b[1]) {
+				ts = append(ts, segmentParameter(a, b, c))
+			}
+		})
+		slices.Sort(ts)
+		ts = slices.Compact(ts)
+		for i, t := range ts {
+			samples = append(samples, sample{coord: interpolateSegment(a, b, t)})
+			if i > 0 {
+				samples = append(samples, sample{coord: interpolateSegment(a, b, (ts[i-1]+t)/2), mid: true})
+			}
+		}
+	}
+	return samples
+}
+
+// contains returns true if p contains other.
+func (p *planar) contains(other *planar) bool {
+	hasInterior := false
+	for _, s := range other.samples(p) {
+		switch p.locate(s.coord) {
+		case exterior:
+			return false
+		case interior:
+			if !hasInterior && other.locate(s.coord) == interior {
+				hasInterior = true
+			}
+		case boundary:
+		}
+	}
+	if other.dimension() < 2 {
+		return hasInterior
+	}
+	// The boundary of p must not enter the interior of other.
+	for _, s := range p.samples(other) {
+		if other.locate(s.coord) == interior {
+			return false
+		}
+	}
+	if hasInterior {
+		return true
+	}
+	for _, s := range other.samples(p) {
+		if p.locate(s.coord) == interior {
+			return true
+		}
+	}
+	c, ok := interiorPoint(other.polygons[0])
+	return ok && p.locate(c) == interior
+}
+
+// intersects returns true if a and b share at least one point.
+func intersects(a, b *planar) bool {
+	if a.empty() || b.empty() || !a.bounds.Overlaps(XY, b.bounds) {
+		return false
+	}
+	for _, s := range a.samples(b) {
+		if b.locate(s.coord) != exterior {
+			return true
+		}
+	}
+	for _, s := range b.samples(a) {
+		if a.locate(s.coord) != exterior {
+			return true
+		}
+	}
+	return false
+}
+
+// contains returns true if no point of b lies in the exterior of a and at
+// least one point of the interior of b lies in the interior of a.
+func contains(a, b *planar) bool {
+	if a.empty() || b.empty() || b.dimension() > a.dimension() {
+		return false
+	}
+	return a.contains(b)
+}
+
+// touches returns true if a and b share at least one point but their
+// interiors do not intersect.
+func touches(a, b *planar) bool {
+	if !intersects(a, b) || a.dimension() == 0 && b.dimension() == 0 {
+		return false
+	}
+	return !interiorsIntersect(a, b)
+}
+
+// interiorsIntersect returns true if the interiors of a and b intersect.
+func interiorsIntersect(a, b *planar) bool {
+	for _, pair := range [][2]*planar{{a, b}, {b, a}} {
+		p, other := pair[0], pair[1]
+		for _, s := range p.samples(other) {
+			if other.locate(s.coord) != interior {
+				continue
+			}
+			if other.dimension() == 2 || p.locate(s.coord) == interior {
+				return true
+			}
+		}
+	}
+	if a.dimension() == 2 && b.dimension() == 2 {
+		c, ok := interiorPoint(a.polygons[0])
+		return ok && b.locate(c) == interior
+	}
+	return false
+}
+
+// linesOverlap returns true if the lines of a and b share a segment of
+// non-zero length.
+func linesOverlap(a, b *planar) bool {
+	for _, s := range a.samples(b) {
+		if s.mid && b.locate(s.coord) != exterior {
+			return true
+		}
+	}
+	return false
+}
+
+// segmentIntersection returns the intersection of the segments ab and cd:
+// nothing, a single point, or the two ends of a collinear overlap.
+func segmentIntersection(a, b, c, d Coord) []Coord {
+	if math.Max(a[0], b[0]) < math.Min(c[0], d[0])-onSegmentTolerance ||
+		math.Min(a[0], b[0]) > math.Max(c[0], d[0])+onSegmentTolerance ||
+		math.Max(a[1], b[1]) < math.Min(c[1], d[1])-onSegmentTolerance ||
+		math.Min(a[1], b[1]) > math.Max(c[1], d[1])+onSegmentTolerance {
+		return nil
+	}
+
+	rx, ry := b[0]-a[0], b[1]-a[1]
+	sx, sy := d[0]-c[0], d[1]-c[1]
+	denom := rx*sy - ry*sx
+	if math.Abs(denom) <= 1e-12*math.Hypot(rx, ry)*math.Hypot(sx, sy) {
+		// Parallel segments intersect only if they are collinear.
+		var overlap []Coord
+		for _, x := range []Coord{a, b, c, d} {
+			if onSegment(x[0], x[1], a[0], a[1], b[0], b[1]) && onSegment(x[0], x[1], c[0], c[1], d[0], d[1]) &&
+				!slices.ContainsFunc(overlap, func(y Coord) bool { return equals(x, y) }) {
+				overlap = append(overlap, x)
+			}
+		}
+		return overlap
+	}
+
+	// Prefer exact vertices over computed intersections.
+	for _, x := range []Coord{a, b} {
+		if onSegment(x[0], x[1], c[0], c[1], d[0], d[1]) {
+			return []Coord{x}
+		}
+	}
+	for _, x := range []Coord{c, d} {
+		if onSegment(x[0], x[1], a[0], a[1], b[0], b[1]) {
+			return []Coord{x}
+		}
+	}
+
+	qx, qy := c[0]-a[0], c[1]-a[1]
+	t := (qx*sy - qy*sx) / denom
+	u := (qx*ry - qy*rx) / denom
+	if t < 0 || t > 1 || u < 0 || u > 1 {
+		return nil
+	}
+	return []Coord{{a[0] + t*rx, a[1] + t*ry}}
+}
+
+// segmentParameter returns the position of x, which lies on the segment ab,
+// as a fraction of the length of ab.
+func segmentParameter(a, b, x Coord) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	lengthSq := dx*dx + dy*dy
+	if lengthSq == 0 {
+		return 0
+	}
+	return math.Max(0, math.Min(1, ((x[0]-a[0])*dx+(x[1]-a[1])*dy)/lengthSq))
+}
+
+// interpolateSegment returns the point at fraction t of the segment ab.
+func interpolateSegment(a, b Coord, t float64) Coord {
+	switch t {
+	case 0:
+		return a
+	case 1:
+		return b
+	default:
+		return Coord{a[0] + t*(b[0]-a[0]), a[1] + t*(b[1]-a[1])}
+	}
+}
+
+// interiorPoint returns a point in the interior of the polygon given by its
+// closed rings. It intersects the polygon with a horizontal line between two
+// distinct vertex latitudes close to the middle of the polygon and returns
+// the middle of the widest interior interval.
+func interiorPoint(rings [][]Coord) (Coord, bool) {
+	var ys []float64
+	for _, ring := range rings {
+		for _, c := range ring {
+			ys = append(ys, c[1])
+		}
+	}
+	slices.Sort(ys)
+	ys = slices.Compact(ys)
+	if len(ys) < 2 {
+		return nil, false
+	}
+	mid := len(ys) / 2
+	y := (ys[mid-1] + ys[mid]) / 2
+
+	var xs []float64
+	for _, ring := range rings {
+		for i := 1; i < len(ring); i++ {
+			a, b := ring[i-1], ring[i]
+			if (a[1] > y) != (b[1] > y) {
+				xs = append(xs, a[0]+(y-a[1])*(b[0]-a[0])/(b[1]-a[1]))
+			}
+		}
+	}
+	slices.Sort(xs)
+	best, width := -1, 0.
+	for i := 0; i+1 < len(xs); i += 2 {
+		if w := xs[i+1] - xs[i]; w > width {
+			best, width = i, w
+		}
+	}
+	if best < 0 {
+		return nil, false
+	}
+	return Coord{(xs[best] + xs[best+1]) / 2, y}, true
+}
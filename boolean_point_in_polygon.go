@@ -0,0 +1,110 @@
+package goodgeo
+
+import "math"
+
+// PointInPolygonOptions configures [BooleanPointInPolygon].
+type PointInPolygonOptions struct {
+	// IgnoreBoundary excludes points that lie on the edges of the polygon.
+	IgnoreBoundary bool
+	// IgnoreHoles treats holes as part of the polygon.
+	IgnoreHoles bool
+}
+
+// BooleanPointInPolygon returns true if pt lies inside g, which must be a
+// [Polygon] or a [MultiPolygon]. Points on the edges of g are inside unless
+// opts.IgnoreBoundary is true, points inside holes are outside unless
+// opts.IgnoreHoles is true. The coordinates are treated as planar, like
+// Turf's booleanPointInPolygon. opts may be nil.
+func BooleanPointInPolygon(pt Coord, g T, opts *PointInPolygonOptions) bool {
+	if opts == nil {
+		opts = &PointInPolygonOptions{}
+	}
+	switch g := g.(type) {
+	case *Polygon:
+		return pointInPolygon(pt, g.flatCoords, 0, g.ends, g.stride, opts)
+	case *MultiPolygon:
+		offset := 0
+		for _, ends := range g.endss {
+			if len(ends) == 0 {
+				continue
+			}
+			if pointInPolygon(pt, g.flatCoords, offset, ends, g.stride, opts) {
+				return true
+			}
+			offset = ends[len(ends)-1]
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+func pointInPolygon(pt Coord, flatCoords []float64, offset int, ends []int, stride int, opts *PointInPolygonOptions) bool {
+	if len(ends) == 0 {
+		return false
+	}
+	inside, boundary := pointInRing(pt, flatCoords, offset, ends[0], stride)
+	if boundary {
+		return !opts.IgnoreBoundary
+	}
+	if !inside {
+		return false
+	}
+	if opts.IgnoreHoles {
+		return true
+	}
+	offset = ends[0]
+	for _, end := range ends[1:] {
+		inside, boundary := pointInRing(pt, flatCoords, offset, end, stride)
+		if boundary {
+			return !opts.IgnoreBoundary
+		}
+		if inside {
+			return false
+		}
+		offset = end
+	}
+	return true
+}
+
+// pointInRing returns whether pt lies inside the ring, and whether it lies on
+// its boundary, using the even-odd rule. The ring may, but does not need to,
+// be closed.
+func pointInRing(pt Coord, flatCoords []float64, offset, end, stride int) (bool, bool) {
+	n := (end - offset) / stride
+	if n == 0 {
+		return false, false
+	}
+	x, y := pt[0], pt[1]
+	inside := false
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		xi, yi := flatCoords[offset+i*stride], flatCoords[offset+i*stride+1]
+		xj, yj := flatCoords[offset+j*stride], flatCoords[offset+j*stride+1]
+		if onSegment(x, y, xj, yj, xi, yi) {
+			return false, true
+		}
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside, false
+}
+
+// onSegmentTolerance is the distance, in degrees, within which a point is
+// considered to lie on a segment. It corresponds to roughly 10 µm.
+const onSegmentTolerance = 1e-10
+
+// onSegment returns true if (x, y) lies on the segment between (x1, y1) and
+// (x2, y2).
+func onSegment(x, y, x1, y1, x2, y2 float64) bool {
+	if x < math.Min(x1, x2)-onSegmentTolerance || x > math.Max(x1, x2)+onSegmentTolerance ||
+		y < math.Min(y1, y2)-onSegmentTolerance || y > math.Max(y1, y2)+onSegmentTolerance {
+		return false
+	}
+	dx, dy := x2-x1, y2-y1
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return math.Hypot(x-x1, y-y1) <= onSegmentTolerance
+	}
+	return math.Abs((x-x1)*dy-(y-y1)*dx)/length <= onSegmentTolerance
+}
@@ -0,0 +1,173 @@
+package goodgeo
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+var (
+	squareWithHole = NewPolygon(XY).MustSetCoords([][]Coord{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{4, 4}, {4, 6}, {6, 6}, {6, 4}, {4, 4}},
+	})
+	square      = NewPolygon(XY).MustSetCoords([][]Coord{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}})
+	innerSquare = NewPolygon(XY).MustSetCoords([][]Coord{{{2, 2}, {3, 2}, {3, 3}, {2, 3}, {2, 2}}})
+	shiftedSq   = NewPolygon(XY).MustSetCoords([][]Coord{{{5, 5}, {15, 5}, {15, 15}, {5, 15}, {5, 5}}})
+	adjacentSq  = NewPolygon(XY).MustSetCoords([][]Coord{{{10, 0}, {20, 0}, {20, 10}, {10, 10}, {10, 0}}})
+	farSquare   = NewPolygon(XY).MustSetCoords([][]Coord{{{20, 20}, {30, 20}, {30, 30}, {20, 30}, {20, 20}}})
+)
+
+func TestBooleanPointInPolygon(t *testing.T) {
+	multi := NewMultiPolygon(XY).MustSetCoords([][][]Coord{square.Coords(), farSquare.Coords()})
+	for i, tc := range []struct {
+		pt   Coord
+		g    T
+		opts *PointInPolygonOptions
+		want bool
+	}{
+		{pt: Coord{1, 1}, g: squareWithHole, want: true},
+		{pt: Coord{5, 5}, g: squareWithHole, want: false},
+		{pt: Coord{5, 5}, g: squareWithHole, opts: &PointInPolygonOptions{IgnoreHoles: true}, want: true},
+		{pt: Coord{0, 5}, g: squareWithHole, want: true},
+		{pt: Coord{0, 5}, g: squareWithHole, opts: &PointInPolygonOptions{IgnoreBoundary: true}, want: false},
+		{pt: Coord{4, 5}, g: squareWithHole, want: true},
+		{pt: Coord{4, 5}, g: squareWithHole, opts: &PointInPolygonOptions{IgnoreBoundary: true}, want: false},
+		{pt: Coord{11, 5}, g: squareWithHole, want: false},
+		{pt: Coord{25, 25}, g: multi, want: true},
+		{pt: Coord{15, 15}, g: multi, want: false},
+		{pt: Coord{1, 1}, g: NewLineString(XY).MustSetCoords([]Coord{{0, 0}, {2, 2}}), want: false},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tc.want, BooleanPointInPolygon(tc.pt, tc.g, tc.opts))
+		})
+	}
+}
+
+func TestBooleanPredicates(t *testing.T) {
+	crossingLine := NewLineString(XY).MustSetCoords([]Coord{{-5, 5}, {5, 5}})
+	innerLine := NewLineString(XY).MustSetCoords([]Coord{{1, 1}, {2, 2}, {3, 1}})
+	touchingLine := NewLineString(XY).MustSetCoords([]Coord{{-5, 5}, {0, 5}})
+	diagonal := NewLineString(XY).MustSetCoords([]Coord{{0, 0}, {10, 10}})
+	antiDiagonal := NewLineString(XY).MustSetCoords([]Coord{{0, 10}, {10, 0}})
+	overlappingLine := NewLineString(XY).MustSetCoords([]Coord{{5, 5}, {15, 15}})
+	points := NewMultiPoint(XY).MustSetCoords([]Coord{{1, 1}, {11, 11}})
+	insidePoints := NewMultiPoint(XY).MustSetCoords([]Coord{{1, 1}, {2, 2}})
+
+	for i, tc := range []struct {
+		a, b                                                     T
+		intersects, contains, within, crosses, overlaps, touches bool
+	}{
+		{a: square, b: innerSquare, intersects: true, contains: true},
+		{a: innerSquare, b: square, intersects: true, within: true},
+		{a: square, b: square, intersects: true, contains: true, within: true},
+		{a: square, b: shiftedSq, intersects: true, overlaps: true},
+		{a: square, b: adjacentSq, intersects: true, touches: true},
+		{a: square, b: farSquare},
+		{a: squareWithHole, b: NewPolygon(XY).MustSetCoords([][]Coord{{{3, 3}, {7, 3}, {7, 7}, {3, 7}, {3, 3}}}), intersects: true, overlaps: true},
+		{a: square, b: crossingLine, intersects: true, crosses: true},
+		{a: square, b: innerLine, intersects: true, contains: true},
+		{a: square, b: touchingLine, intersects: true, touches: true},
+		{a: diagonal, b: antiDiagonal, intersects: true, crosses: true},
+		{a: diagonal, b: overlappingLine, intersects: true, overlaps: true},
+		{a: diagonal, b: NewLineString(XY).MustSetCoords([]Coord{{2, 2}, {4, 4}}), intersects: true, contains: true},
+		{a: diagonal, b: NewLineString(XY).MustSetCoords([]Coord{{10, 10}, {10, 20}}), intersects: true, touches: true},
+		{a: square, b: points, intersects: true, crosses: true},
+		{a: square, b: insidePoints, intersects: true, contains: true},
+		{a: NewPoint(XY).MustSetCoords(Coord{5, 5}), b: diagonal, intersects: true, within: true},
+		{a: NewPoint(XY).MustSetCoords(Coord{0, 0}), b: diagonal, intersects: true, touches: true},
+		{a: NewPoint(XY).MustSetCoords(Coord{0, 0}), b: NewPoint(XY).MustSetCoords(Coord{0, 0}), intersects: true, contains: true, within: true},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tc.intersects, BooleanIntersects(tc.a, tc.b), "intersects")
+			assert.Equal(t, !tc.intersects, BooleanDisjoint(tc.a, tc.b), "disjoint")
+			assert.Equal(t, tc.contains, BooleanContains(tc.a, tc.b), "contains")
+			assert.Equal(t, tc.within, BooleanWithin(tc.a, tc.b), "within")
+			assert.Equal(t, tc.crosses, BooleanCrosses(tc.a, tc.b), "crosses")
+			assert.Equal(t, tc.overlaps, BooleanOverlaps(tc.a, tc.b), "overlaps")
+			assert.Equal(t, tc.touches, BooleanTouches(tc.a, tc.b), "touches")
+		})
+	}
+}
+
+func TestPreparedGeometryRelate(t *testing.T) {
+	p := Prepare(NewPolygon(XY).MustSetCoords([][]Coord{arc(0, 0, 1, 0, 360, 1000)}))
+	square := func(minX, minY, maxX, maxY float64) *Polygon {
+		return NewBounds(XY).Set(minX, minY, maxX, maxY).Polygon()
+	}
+	for i, tc := range []struct {
+		g    T
+		want Relation
+	}{
+		{g: square(2, 2, 3, 3), want: RelationDisjoint},
+		{g: square(1, -1, 2, 1), want: RelationTouches},
+		{g: square(-0.5, -0.5, 0.5, 0.5), want: RelationContained},
+		{g: square(0.5, 0.5, 1.5, 1.5), want: RelationPartial},
+		{g: square(-2, -2, 2, 2), want: RelationPartial},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tc.want, p.Relate(tc.g))
+		})
+	}
+}
+
+// arc returns n+1 points on the circle around (x, y) with radius r from
+// angle from to angle to, in degrees.
+func arc(x, y, r, from, to float64, n int) []Coord {
+	coords := make([]Coord, 0, n+1)
+	for i := range n + 1 {
+		a := (from + (to-from)*float64(i)/float64(n)) * math.Pi / 180
+		coords = append(coords, Coord{x + r*math.Cos(a), y + r*math.Sin(a)})
+	}
+	return coords
+}
+
+func TestBooleanPredicatesManyVertices(t *testing.T) {
+	const n = 5000
+	disc := func(x, y, r float64) *Polygon {
+		return NewPolygon(XY).MustSetCoords([][]Coord{arc(x, y, r, 0, 360, n)})
+	}
+	a := disc(0, 0, 1)
+	donut := NewPolygon(XY).MustSetCoords([][]Coord{arc(0, 0, 1, 0, 360, n), arc(0, 0, 0.5, 360, 0, n)})
+	// Halves of a with the diameter along the X axis as shared edge, their
+	// vertices are those of a.
+	upper := NewPolygon(XY).MustSetCoords([][]Coord{append(arc(0, 0, 1, 0, 180, n/2), Coord{1, 0})})
+	lower := NewPolygon(XY).MustSetCoords([][]Coord{append(arc(0, 0, 1, 180, 360, n/2), Coord{-1, 0})})
+	wave := make([]Coord, 0, 2*n+1)
+	for i := range 2*n + 1 {
+		x := -2 + 4*float64(i)/float64(2*n)
+		wave = append(wave, Coord{x, 0.1 * math.Sin(20*x)})
+	}
+
+	for i, tc := range []struct {
+		a, b                                                     T
+		intersects, contains, within, crosses, overlaps, touches bool
+	}{
+		{a: a, b: disc(0.5, 0.3, 1), intersects: true, overlaps: true},
+		{a: a, b: disc(0.1, 0, 0.5), intersects: true, contains: true},
+		{a: disc(0.1, 0, 0.5), b: a, intersects: true, within: true},
+		// Discs touching at (1, 0).
+		{a: a, b: disc(2, 0, 1), intersects: true, touches: true},
+		{a: a, b: disc(2.001, 0, 1)},
+		{a: upper, b: lower, intersects: true, touches: true},
+		{a: a, b: upper, intersects: true, contains: true},
+		{a: donut, b: disc(0, 0, 0.4)},
+		// A disc in the hole touching it at (0.5, 0).
+		{a: donut, b: disc(0.25, 0, 0.25), intersects: true, touches: true},
+		{a: donut, b: disc(0, 0, 0.75), intersects: true, overlaps: true},
+		{a: a, b: NewLineString(XY).MustSetCoords(wave), intersects: true, crosses: true},
+		{a: upper, b: NewLineString(XY).MustSetCoords(arc(0, 0, 1, 0, 180, n/2)), intersects: true, touches: true},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tc.intersects, BooleanIntersects(tc.a, tc.b), "intersects")
+			assert.Equal(t, !tc.intersects, BooleanDisjoint(tc.a, tc.b), "disjoint")
+			assert.Equal(t, tc.contains, BooleanContains(tc.a, tc.b), "contains")
+			assert.Equal(t, tc.within, BooleanWithin(tc.a, tc.b), "within")
+			assert.Equal(t, tc.crosses, BooleanCrosses(tc.a, tc.b), "crosses")
+			assert.Equal(t, tc.overlaps, BooleanOverlaps(tc.a, tc.b), "overlaps")
+			assert.Equal(t, tc.touches, BooleanTouches(tc.a, tc.b), "touches")
+		})
+	}
+}
@@ -77,6 +77,11 @@ func TestBoundsIsEmpty(t *testing.T) {
 			b:        &Bounds{layout: XY, min: Coord{-100, -100}, max: Coord{100, 100}},
 			expected: false,
 		},
+		{
+			// Longitudes are plain numbers, see GeographicBounds.
+			b:        &Bounds{layout: XY, min: Coord{170, 0}, max: Coord{-170, 10}},
+			expected: true,
+		},
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			assert.Equal(t, tc.expected, tc.b.IsEmpty())
@@ -120,6 +125,11 @@ func TestBoundsOverlaps(t *testing.T) {
 			b2:       &Bounds{layout: XY, min: Coord{-10, -10}, max: Coord{-0.000000000000000000000000000001, 0}},
 			expected: false,
 		},
+		{
+			b1:       &Bounds{layout: XY, min: Coord{170, 0}, max: Coord{-170, 10}},
+			b2:       &Bounds{layout: XY, min: Coord{-175, 5}, max: Coord{-160, 15}},
+			expected: false,
+		},
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			assert.Equal(t, tc.expected, tc.b1.Overlaps(tc.b1.layout, tc.b2))
@@ -158,6 +168,11 @@ func TestBoundsOverlapsPoint(t *testing.T) {
 			p:        Coord{-0.000000000000000000000000000001, 0},
 			expected: false,
 		},
+		{
+			b:        &Bounds{layout: XY, min: Coord{170, 0}, max: Coord{-170, 10}},
+			p:        Coord{-175, 5},
+			expected: false,
+		},
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			assert.Equal(t, tc.expected, tc.b.OverlapsPoint(tc.b.layout, tc.p))
@@ -198,6 +213,10 @@ func TestBoundsPolygon(t *testing.T) {
 			b:        NewBounds(XYZ).Set(1, 2, 3, 4, 5, 6),
 			expected: NewPolygon(XY).MustSetCoords([][]Coord{{{1, 2}, {1, 5}, {4, 5}, {4, 2}, {1, 2}}}),
 		},
+		{
+			b:        NewBounds(XY).Set(170, 0, -170, 10),
+			expected: NewPolygon(XY),
+		},
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
 			assert.Equal(t, tc.expected, tc.b.Polygon())
@@ -0,0 +1,113 @@
+package goodgeo
+
+import (
+	"math"
+	"slices"
+)
+
+// boxTreeNodeSize is the number of children of the nodes of a boxTree.
+const boxTreeNodeSize = 16
+
+// A box is a two-dimensional bounding box.
+type box struct {
+	minX, minY, maxX, maxY float64
+}
+
+// segmentBox returns the bounding box of the segment ab.
+func segmentBox(a, b Coord) box {
+	return box{minX: min(a[0], b[0]), minY: min(a[1], b[1]), maxX: max(a[0], b[0]), maxY: max(a[1], b[1])}
+}
+
+// grow returns b grown by d on every side.
+func (b box) grow(d float64) box {
+	return box{minX: b.minX - d, minY: b.minY - d, maxX: b.maxX + d, maxY: b.maxY + d}
+}
+
+func (b box) intersects(o box) bool {
+	return o.minX <= b.maxX && o.minY <= b.maxY && o.maxX >= b.minX && o.maxY >= b.minY
+}
+
+// A boxTree is a static R-tree of boxes packed with Sort-Tile-Recursive. Its
+// nodes are stored level by level, leaves first and the root last.
+type boxTree struct {
+	boxes []box
+	// indices holds the index of the box of each leaf, and the position of
+	// the first child of each other node.
+	indices []int
+	// levels holds the end of each level in boxes.
+	levels []int
+}
+
+func newBoxTree(boxes []box) *boxTree {
+	order := make([]int, len(boxes))
+	for i := range order {
+		order[i] = i
+	}
+	centerX := func(i int) float64 { return boxes[i].minX + boxes[i].maxX }
+	centerY := func(i int) float64 { return boxes[i].minY + boxes[i].maxY }
+	byCenter := func(center func(int) float64) func(i, j int) int {
+		return func(i, j int) int {
+			if ci, cj := center(i), center(j); ci != cj {
+				if ci < cj {
+					return -1
+				}
+				return 1
+			}
+			return i - j
+		}
+	}
+	// Cut the boxes sorted by X into vertical slices of whole leaves, and
+	// sort each slice by Y.
+	slices.SortFunc(order, byCenter(centerX))
+	leaves := (len(boxes) + boxTreeNodeSize - 1) / boxTreeNodeSize
+	sliceSize := boxTreeNodeSize * int(math.Ceil(math.Sqrt(float64(leaves))))
+	for i := 0; i < len(order); i += sliceSize {
+		slices.SortFunc(order[i:min(i+sliceSize, len(order))], byCenter(centerY))
+	}
+
+	t := &boxTree{
+		boxes:   make([]box, 0, len(boxes)+len(boxes)/(boxTreeNodeSize-1)+1),
+		indices: make([]int, 0, len(boxes)+len(boxes)/(boxTreeNodeSize-1)+1),
+	}
+	for _, i := range order {
+		t.boxes = append(t.boxes, boxes[i])
+		t.indices = append(t.indices, i)
+	}
+	t.levels = append(t.levels, len(t.boxes))
+	for start, end := 0, len(t.boxes); end-start > 1; start, end = end, len(t.boxes) {
+		for i := start; i < end; i += boxTreeNodeSize {
+			b := t.boxes[i]
+			for _, c := range t.boxes[i+1 : min(i+boxTreeNodeSize, end)] {
+				b.minX, b.minY = min(b.minX, c.minX), min(b.minY, c.minY)
+				b.maxX, b.maxY = max(b.maxX, c.maxX), max(b.maxY, c.maxY)
+			}
+			t.boxes = append(t.boxes, b)
+			t.indices = append(t.indices, i)
+		}
+		t.levels = append(t.levels, len(t.boxes))
+	}
+	return t
+}
+
+// search calls f with the index of each box that intersects q.
+func (t *boxTree) search(q box, f func(i int)) {
+	if len(t.boxes) == 0 || !t.boxes[len(t.boxes)-1].intersects(q) {
+		return
+	}
+	type entry struct{ pos, level int }
+	stack := []entry{{pos: len(t.boxes) - 1, level: len(t.levels) - 1}}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if e.level == 0 {
+			f(t.indices[e.pos])
+			continue
+		}
+		first := t.indices[e.pos]
+		for c := first; c < min(first+boxTreeNodeSize, t.levels[e.level-1]); c++ {
+			if t.boxes[c].intersects(q) {
+				stack = append(stack, entry{pos: c, level: e.level - 1})
+			}
+		}
+	}
+}
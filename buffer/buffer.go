@@ -0,0 +1,214 @@
+// Package buffer computes geodesic buffers of geometries, similar to Turf's
+// buffer.
+//
+// A buffer is built from circles around points and capsules around the
+// segments of lines and rings, which are constructed on the sphere with
+// [goodgeo.Destination] and [goodgeo.Bearing] and then merged with the
+// overlay package. Longitudes of the result are not wrapped, so buffers of
+// geometries close to the antimeridian may extend beyond ±180°. Buffers that
+// contain a pole are not supported.
+package buffer
+
+import (
+	"math"
+
+	"github.com/matoous/goodgeo"
+	"github.com/matoous/goodgeo/overlay"
+)
+
+// DefaultSteps is the number of steps per quarter circle used when steps is
+// not positive.
+const DefaultSteps = 8
+
+// Buffer returns the area within radius of g. Circles are approximated with
+// steps segments per quarter circle. A negative radius shrinks polygons and
+// yields an empty result for points and lines.
+//
+// The result is a [goodgeo.Polygon] if the buffer consists of a single
+// polygon and a [goodgeo.MultiPolygon] otherwise. It has the XY layout and
+// the SRID of g.
+func Buffer(g goodgeo.T, radius goodgeo.Meters, steps int) (goodgeo.T, error) {
+	if steps <= 0 {
+		steps = DefaultSteps
+	}
+	b := &builder{radius: goodgeo.Meters(math.Abs(float64(radius))), steps: steps}
+	mp, err := b.buffer(g, radius < 0)
+	if err != nil {
+		return nil, err
+	}
+	mp.SetSRID(g.SRID())
+	if mp.NumPolygons() == 1 {
+		return mp.Polygon(0).SetSRID(g.SRID()), nil
+	}
+	return mp, nil
+}
+
+type builder struct {
+	radius goodgeo.Meters
+	steps  int
+}
+
+func (b *builder) buffer(g goodgeo.T, shrink bool) (*goodgeo.MultiPolygon, error) {
+	switch g := g.(type) {
+	case *goodgeo.Point:
+		if g.Empty() || shrink || b.radius == 0 {
+			return empty(), nil
+		}
+		return b.circle(g.Coords()), nil
+	case *goodgeo.MultiPoint:
+		if shrink || b.radius == 0 {
+			return empty(), nil
+		}
+		var parts []*goodgeo.MultiPolygon
+		for _, c := range g.Coords() {
+			if c != nil {
+				parts = append(parts, b.circle(c))
+			}
+		}
+		return unionAll(parts)
+	case *goodgeo.LineString:
+		if shrink || b.radius == 0 {
+			return empty(), nil
+		}
+		return unionAll(b.capsules(g.Coords()))
+	case *goodgeo.MultiLineString:
+		if shrink || b.radius == 0 {
+			return empty(), nil
+		}
+		var parts []*goodgeo.MultiPolygon
+		for _, line := range g.Coords() {
+			parts = append(parts, b.capsules(line)...)
+		}
+		return unionAll(parts)
+	case *goodgeo.Polygon:
+		return b.polygon(g.Coords(), shrink)
+	case *goodgeo.MultiPolygon:
+		// Shrunk polygons are merged one by one, as the boundary of one
+		// polygon must not cut into another.
+		var parts []*goodgeo.MultiPolygon
+		for _, rings := range g.Coords() {
+			mp, err := b.polygon(rings, shrink)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, mp)
+		}
+		return unionAll(parts)
+	case *goodgeo.GeometryCollection:
+		var parts []*goodgeo.MultiPolygon
+		for _, g := range g.Geoms() {
+			mp, err := b.buffer(g, shrink)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, mp)
+		}
+		return unionAll(parts)
+	default:
+		return nil, goodgeo.UnsupportedTypeError{Value: g}
+	}
+}
+
+// polygon returns the polygon given by its rings grown, or shrunk, by the
+// radius.
+func (b *builder) polygon(rings [][]goodgeo.Coord, shrink bool) (*goodgeo.MultiPolygon, error) {
+	xy := make([][]goodgeo.Coord, len(rings))
+	var parts []*goodgeo.MultiPolygon
+	for i, ring := range rings {
+		xy[i] = make([]goodgeo.Coord, len(ring))
+		for j, c := range ring {
+			xy[i][j] = goodgeo.Coord{c[0], c[1]}
+		}
+		if b.radius > 0 {
+			parts = append(parts, b.capsules(ring)...)
+		}
+	}
+	p, err := goodgeo.NewPolygon(goodgeo.XY).SetCoords(xy)
+	if err != nil {
+		return nil, err
+	}
+	boundary, err := unionAll(parts)
+	if err != nil {
+		return nil, err
+	}
+	if shrink {
+		return overlay.Difference(p, boundary)
+	}
+	return overlay.Union(p, boundary)
+}
+
+// circle returns a circle around c.
+func (b *builder) circle(c goodgeo.Coord) *goodgeo.MultiPolygon {
+	ring := b.arc(nil, c, 0, 360)
+	ring[len(ring)-1] = ring[0]
+	return polygon(ring)
+}
+
+// capsules returns, for each segment of the line, the area within the
+// radius of the segment. A line with a single distinct coordinate yields a
+// circle.
+func (b *builder) capsules(line []goodgeo.Coord) []*goodgeo.MultiPolygon {
+	var capsules []*goodgeo.MultiPolygon
+	for i := 1; i < len(line); i++ {
+		from, to := line[i-1], line[i]
+		if from[0] == to[0] && from[1] == to[1] {
+			continue
+		}
+		forward := goodgeo.Bearing(from, to)
+		back := goodgeo.Bearing(to, from)
+		ring := b.arc(nil, from, forward+90, forward+270)
+		ring = b.arc(ring, to, back+90, back+270)
+		capsules = append(capsules, polygon(append(ring, ring[0])))
+	}
+	if len(capsules) == 0 && len(line) > 0 {
+		capsules = append(capsules, b.circle(line[0]))
+	}
+	return capsules
+}
+
+// arc appends to ring the points at the radius from center with bearings
+// from start to end, clockwise. Bearings in between are multiples of the
+// step angle, so that arcs around the same center share their vertices.
+func (b *builder) arc(ring []goodgeo.Coord, center goodgeo.Coord, start, end goodgeo.Degrees) []goodgeo.Coord {
+	step := 90 / float64(b.steps)
+	ring = append(ring, b.destination(center, start))
+	for k := math.Floor(float64(start)/step) + 1; k*step < float64(end); k++ {
+		if k*step-float64(start) < 1e-9 || float64(end)-k*step < 1e-9 {
+			continue
+		}
+		ring = append(ring, b.destination(center, goodgeo.Degrees(k*step)))
+	}
+	return append(ring, b.destination(center, end))
+}
+
+func (b *builder) destination(center goodgeo.Coord, bearing goodgeo.Degrees) goodgeo.Coord {
+	return goodgeo.Haversine{}.Destination(goodgeo.Coord{center[0], center[1]}, b.radius, bearing)
+}
+
+func polygon(ring []goodgeo.Coord) *goodgeo.MultiPolygon {
+	return goodgeo.NewMultiPolygon(goodgeo.XY).MustSetCoords([][][]goodgeo.Coord{{ring}})
+}
+
+func empty() *goodgeo.MultiPolygon {
+	return goodgeo.NewMultiPolygon(goodgeo.XY)
+}
+
+// unionAll returns the union of parts, merging them pairwise so that each
+// overlay works on results of similar size.
+func unionAll(parts []*goodgeo.MultiPolygon) (*goodgeo.MultiPolygon, error) {
+	switch len(parts) {
+	case 0:
+		return empty(), nil
+	case 1:
+		return overlay.Union(parts[0], empty())
+	}
+	a, err := unionAll(parts[:len(parts)/2])
+	if err != nil {
+		return nil, err
+	}
+	b, err := unionAll(parts[len(parts)/2:])
+	if err != nil {
+		return nil, err
+	}
+	return overlay.Union(a, b)
+}
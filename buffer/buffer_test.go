@@ -0,0 +1,79 @@
+package buffer
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/matoous/goodgeo"
+)
+
+func TestBuffer(t *testing.T) {
+	const radius = 1000
+	point := goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{14.42, 50.08})
+	// A line of roughly 11.1 km along the equator.
+	line := goodgeo.NewLineString(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{0, 0}, {0.05, 0}, {0.1, 0}})
+	// A square with sides of roughly 11.1 km.
+	square := goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{
+		{{0, 0}, {0.1, 0}, {0.1, 0.1}, {0, 0.1}, {0, 0}},
+	})
+	side := float64(goodgeo.Distance(goodgeo.Coord{0, 0}, goodgeo.Coord{0.1, 0}))
+
+	for i, tc := range []struct {
+		g           goodgeo.T
+		radius      goodgeo.Meters
+		area        float64
+		numPolygons int
+	}{
+		{g: point, radius: radius, area: math.Pi * radius * radius, numPolygons: 1},
+		{g: point, radius: -radius, numPolygons: 0},
+		{g: line, radius: radius, area: 2*radius*side + math.Pi*radius*radius, numPolygons: 1},
+		{g: square, radius: radius, area: side*side + 4*side*radius + math.Pi*radius*radius, numPolygons: 1},
+		{g: square, radius: -radius, area: (side - 2*radius) * (side - 2*radius), numPolygons: 1},
+		{g: square, radius: goodgeo.Meters(-side), numPolygons: 0},
+		{
+			g:      goodgeo.NewMultiPoint(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{0, 0}, {1, 1}}),
+			radius: radius, area: 2 * math.Pi * radius * radius, numPolygons: 2,
+		},
+		{
+			g:      goodgeo.NewMultiPoint(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{0, 0}, {0.001, 0}}),
+			radius: radius, numPolygons: 1,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := Buffer(tc.g, tc.radius, 64)
+			assert.NoError(t, err)
+			switch got := got.(type) {
+			case *goodgeo.Polygon:
+				assert.Equal(t, 1, tc.numPolygons)
+			case *goodgeo.MultiPolygon:
+				assert.Equal(t, tc.numPolygons, got.NumPolygons())
+			default:
+				t.Fatalf("unexpected type %T", got)
+			}
+			if tc.area != 0 {
+				area := float64(goodgeo.Area(got))
+				assert.True(t, math.Abs(area-tc.area) < 0.01*tc.area, "area %f, want %f", area, tc.area)
+			}
+		})
+	}
+}
+
+func TestBufferRadius(t *testing.T) {
+	center := goodgeo.Coord{-122.42, 37.77}
+	got, err := Buffer(goodgeo.NewPoint(goodgeo.XY).MustSetCoords(center).SetSRID(4326), 500, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 4326, got.SRID())
+	p := got.(*goodgeo.Polygon)
+	assert.Equal(t, 4*DefaultSteps+1, p.NumCoords())
+	for _, c := range p.LinearRing(0).Coords() {
+		assert.True(t, math.Abs(float64(goodgeo.Distance(center, c))-500) < 1e-6)
+	}
+}
+
+func TestBufferErrors(t *testing.T) {
+	_, err := Buffer(goodgeo.NewLinearRing(goodgeo.XY), 1, 8)
+	assert.Error(t, err)
+}
@@ -0,0 +1,166 @@
+package goodgeo
+
+import "math"
+
+// Centroid returns the mean of the vertices of g, like Turf's centroid. The
+// closing vertices of rings are not counted twice. The result has the XY
+// layout and the SRID of g, and is empty if g has no vertices.
+func Centroid(g T) *Point {
+	var sumX, sumY float64
+	var n int
+	forEachVertex(g, func(x, y float64) {
+		sumX += x
+		sumY += y
+		n++
+	})
+	if n == 0 {
+		return NewPointEmpty(XY).SetSRID(g.SRID())
+	}
+	return NewPoint(XY).MustSetCoords(Coord{sumX / float64(n), sumY / float64(n)}).SetSRID(g.SRID())
+}
+
+// forEachVertex calls f with the X and Y ordinates of each vertex of g,
+// skipping empty points and the closing vertices of rings.
+func forEachVertex(g T, f func(x, y float64)) {
+	each := func(flatCoords []float64, offset, end, stride int, ring bool) {
+		if ring && end-offset >= 2*stride &&
+			flatCoords[offset] == flatCoords[end-stride] && flatCoords[offset+1] == flatCoords[end-stride+1] {
+			end -= stride
+		}
+		for i := offset; i < end; i += stride {
+			if !math.IsNaN(flatCoords[i]) {
+				f(flatCoords[i], flatCoords[i+1])
+			}
+		}
+	}
+	switch g := g.(type) {
+	case *GeometryCollection:
+		for _, g := range g.geoms {
+			forEachVertex(g, f)
+		}
+	case *LinearRing:
+		each(g.flatCoords, 0, len(g.flatCoords), g.stride, true)
+	case *Polygon:
+		offset := 0
+		for _, end := range g.ends {
+			each(g.flatCoords, offset, end, g.stride, true)
+			offset = end
+		}
+	case *MultiPolygon:
+		offset := 0
+		for _, ends := range g.endss {
+			for _, end := range ends {
+				each(g.flatCoords, offset, end, g.stride, true)
+				offset = end
+			}
+		}
+	default:
+		each(g.FlatCoords(), 0, len(g.FlatCoords()), g.Stride(), false)
+	}
+}
+
+// CenterOfMass returns the center of mass of g, treating its coordinates as
+// planar. Polygons are weighted by area, with holes subtracted, lines by
+// length and points equally. Only the components of the highest dimension
+// that have a non-zero weight are taken into account, so the center of mass
+// of a polygon and a point is that of the polygon. The result has the XY
+// layout and the SRID of g, and is empty if g has no vertices.
+func CenterOfMass(g T) *Point {
+	var c centerOfMass
+	c.add(g)
+	p := NewPoint(XY).SetSRID(g.SRID())
+	switch {
+	case c.area != 0:
+		return p.MustSetCoords(Coord{c.areaX / c.area, c.areaY / c.area})
+	case c.length != 0:
+		return p.MustSetCoords(Coord{c.lengthX / c.length, c.lengthY / c.length})
+	case c.points != 0:
+		return p.MustSetCoords(Coord{c.pointX / c.points, c.pointY / c.points})
+	default:
+		return NewPointEmpty(XY).SetSRID(g.SRID())
+	}
+}
+
+// A centerOfMass accumulates the weighted coordinates of the components of
+// a geometry by dimension.
+type centerOfMass struct {
+	area, areaX, areaY       float64
+	length, lengthX, lengthY float64
+	points, pointX, pointY   float64
+}
+
+func (c *centerOfMass) add(g T) {
+	switch g := g.(type) {
+	case *GeometryCollection:
+		for _, g := range g.geoms {
+			c.add(g)
+		}
+	case *LineString:
+		c.addLine(g.flatCoords, 0, len(g.flatCoords), g.stride)
+	case *MultiLineString:
+		offset := 0
+		for _, end := range g.ends {
+			c.addLine(g.flatCoords, offset, end, g.stride)
+			offset = end
+		}
+	case *LinearRing:
+		c.addLine(g.flatCoords, 0, len(g.flatCoords), g.stride)
+	case *Polygon:
+		c.addPolygon(g.flatCoords, 0, g.ends, g.stride)
+	case *MultiPolygon:
+		offset := 0
+		for _, ends := range g.endss {
+			c.addPolygon(g.flatCoords, offset, ends, g.stride)
+			if len(ends) > 0 {
+				offset = ends[len(ends)-1]
+			}
+		}
+	}
+	forEachVertex(g, func(x, y float64) {
+		c.points++
+		c.pointX += x
+		c.pointY += y
+	})
+}
+
+// addPolygon adds the rings of a polygon as areas, holes with a negative
+// weight, and as lines.
+func (c *centerOfMass) addPolygon(flatCoords []float64, offset int, ends []int, stride int) {
+	for i, end := range ends {
+		if end-offset >= 3*stride {
+			// Sum the triangles from the first vertex, which also reduces
+			// rounding errors. The edge that closes an unclosed ring ends at
+			// the first vertex and adds nothing.
+			x0, y0 := flatCoords[offset], flatCoords[offset+1]
+			var area, x, y float64
+			for j := offset + stride; j < end; j += stride {
+				ax, ay := flatCoords[j-stride]-x0, flatCoords[j-stride+1]-y0
+				bx, by := flatCoords[j]-x0, flatCoords[j+1]-y0
+				a := ax*by - bx*ay
+				area += a
+				x += (ax + bx) * a
+				y += (ay + by) * a
+			}
+			if (area < 0) != (i > 0) {
+				area, x, y = -area, -x, -y
+			}
+			c.area += area / 2
+			c.areaX += x/6 + x0*area/2
+			c.areaY += y/6 + y0*area/2
+		}
+		c.addLine(flatCoords, offset, end, stride)
+		offset = end
+	}
+}
+
+// addLine adds the segments of a line weighted by their lengths.
+func (c *centerOfMass) addLine(flatCoords []float64, offset, end, stride int) {
+	for i := offset + stride; i < end; i += stride {
+		ax, ay := flatCoords[i-stride], flatCoords[i-stride+1]
+		bx, by := flatCoords[i], flatCoords[i+1]
+		length := math.Hypot(bx-ax, by-ay)
+		c.length += length
+		c.lengthX += length * (ax + bx) / 2
+		c.lengthY += length * (ay + by) / 2
+	}
+}
@@ -0,0 +1,183 @@
+package goodgeo
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestCentroid(t *testing.T) {
+	for i, tc := range []struct {
+		g    T
+		want Coord
+	}{
+		{
+			g: NewMultiPoint(XY),
+		},
+		{
+			g:    NewPoint(XYZ).MustSetCoords(Coord{1, 2, 3}),
+			want: Coord{1, 2},
+		},
+		{
+			g:    NewMultiPoint(XY).MustSetCoords([]Coord{{0, 0}, nil, {2, 4}}),
+			want: Coord{1, 2},
+		},
+		{
+			// The closing vertex is not counted twice.
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}),
+			want: Coord{2, 2},
+		},
+		{
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{{{0, 0}, {3, 0}, {0, 3}}}),
+			want: Coord{1, 1},
+		},
+		{
+			g:    NewLineString(XY).MustSetCoords([]Coord{{0, 0}, {1, 0}, {5, 0}}),
+			want: Coord{2, 0},
+		},
+		{
+			g: NewGeometryCollection().MustPush(
+				NewPoint(XY).MustSetCoords(Coord{0, 0}),
+				NewMultiPolygon(XY).MustSetCoords([][][]Coord{{{{2, 0}, {4, 0}, {4, 3}, {2, 0}}}}),
+			),
+			want: Coord{2.5, 0.75},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := Centroid(tc.g)
+			assert.Equal(t, XY, got.Layout())
+			if tc.want == nil {
+				assert.True(t, got.Empty())
+				return
+			}
+			assert.Equal(t, tc.want, got.Coords())
+		})
+	}
+
+	p := NewPoint(XY).MustSetCoords(Coord{1, 2}).SetSRID(4326)
+	assert.Equal(t, 4326, Centroid(p).SRID())
+}
+
+func TestCenterOfMass(t *testing.T) {
+	for i, tc := range []struct {
+		g    T
+		want Coord
+	}{
+		{
+			g: NewPolygon(XY),
+		},
+		{
+			g:    NewMultiPoint(XY).MustSetCoords([]Coord{{0, 0}, {2, 4}}),
+			want: Coord{1, 2},
+		},
+		{
+			// Segments are weighted by their lengths.
+			g:    NewLineString(XY).MustSetCoords([]Coord{{0, 0}, {1, 0}, {5, 0}}),
+			want: Coord{2.5, 0},
+		},
+		{
+			g:    NewMultiLineString(XY).MustSetCoords([][]Coord{{{0, 0}, {2, 0}}, {{0, 2}, {0, 4}}}),
+			want: Coord{0.5, 1.5},
+		},
+		{
+			// A zero length line is a point.
+			g:    NewLineString(XY).MustSetCoords([]Coord{{1, 1}, {1, 1}}),
+			want: Coord{1, 1},
+		},
+		{
+			// Triangles are weighted by their areas, unlike vertices.
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{{{0, 0}, {1, 0}, {3, 0}, {3, 3}, {0, 3}, {0, 0}}}),
+			want: Coord{1.5, 1.5},
+		},
+		{
+			// The orientation of the rings does not matter and unclosed
+			// rings are closed.
+			g: NewPolygon(XY).MustSetCoords([][]Coord{
+				{{10, 10}, {10, 14}, {14, 14}, {14, 10}},
+				{{10, 10}, {12, 10}, {12, 12}, {10, 12}, {10, 10}},
+			}),
+			want: Coord{12 + 1.0/3, 12 + 1.0/3},
+		},
+		{
+			// The polygon outweighs the point.
+			g: NewGeometryCollection().MustPush(
+				NewPoint(XY).MustSetCoords(Coord{100, 100}),
+				NewMultiPolygon(XY).MustSetCoords([][][]Coord{
+					{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}},
+					{{{4, 0}, {6, 0}, {6, 2}, {4, 2}, {4, 0}}},
+				}),
+			),
+			want: Coord{3, 1},
+		},
+		{
+			// A polygon without area is a line.
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{{{0, 0}, {4, 0}, {0, 0}}}),
+			want: Coord{2, 0},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := CenterOfMass(tc.g)
+			assert.Equal(t, XY, got.Layout())
+			if tc.want == nil {
+				assert.True(t, got.Empty())
+				return
+			}
+			assert.True(t, math.Abs(tc.want[0]-got.X()) < 1e-12 && math.Abs(tc.want[1]-got.Y()) < 1e-12, "got %v", got.Coords())
+		})
+	}
+}
+
+func TestPolyLabel(t *testing.T) {
+	for i, tc := range []struct {
+		coords    [][]Coord
+		precision Meters
+		want      Coord
+		tol       float64
+	}{
+		{
+			coords: nil,
+		},
+		{
+			coords:    [][]Coord{{{0, 0}, {0.02, 0}, {0.02, 0.01}, {0, 0.01}, {0, 0}}},
+			precision: 1,
+			want:      Coord{0.01, 0.005},
+			tol:       1e-5,
+		},
+		{
+			// A U shape, whose centroid is outside it. The largest circle
+			// inside it touches the outer edges and an inner corner.
+			coords:    [][]Coord{{{0, 0}, {0.03, 0}, {0.03, 0.03}, {0.02, 0.03}, {0.02, 0.01}, {0.01, 0.01}, {0.01, 0.03}, {0, 0.03}, {0, 0}}},
+			precision: 1,
+			want:      Coord{0.01 * math.Sqrt2 / (1 + math.Sqrt2), 0.01 * math.Sqrt2 / (1 + math.Sqrt2)},
+			tol:       1e-4,
+		},
+		{
+			// The hole pushes the label to the wide part.
+			coords: [][]Coord{
+				{{0, 0}, {0.035, 0}, {0.035, 0.02}, {0, 0.02}, {0, 0}},
+				{{0.005, 0.005}, {0.005, 0.015}, {0.015, 0.015}, {0.015, 0.005}, {0.005, 0.005}},
+			},
+			precision: 1,
+			want:      Coord{0.025, 0.01},
+			tol:       1e-4,
+		},
+		{
+			coords: [][]Coord{{{1, 1}, {2, 1}, {1, 1}}},
+			want:   Coord{1, 1},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			p := NewPolygon(XY).MustSetCoords(tc.coords).SetSRID(4326)
+			got := PolyLabel(p, tc.precision)
+			assert.Equal(t, 4326, got.SRID())
+			if tc.want == nil {
+				assert.True(t, got.Empty())
+				return
+			}
+			assert.True(t, math.Abs(tc.want[0]-got.X()) <= tc.tol && math.Abs(tc.want[1]-got.Y()) <= tc.tol, "got %v", got.Coords())
+			assert.True(t, BooleanPointInPolygon(got.Coords(), p, nil))
+		})
+	}
+}
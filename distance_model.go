@@ -0,0 +1,46 @@
+package goodgeo
+
+// A DistanceModel measures distances and bearings on the surface of the Earth.
+// Functions that accept an optional DistanceModel use [Haversine] unless
+// another model is given, see the geodesic package for an ellipsoidal model.
+type DistanceModel interface {
+	// Distance returns the distance between two coordinates.
+	Distance(from, to Coord) Meters
+	// Bearing returns the initial bearing from one coordinate to another,
+	// between -180 and 180 degrees (positive clockwise).
+	Bearing(from, to Coord) Degrees
+	// Destination returns the coordinate reached by travelling the given
+	// distance from origin along the given initial bearing. Ordinates other
+	// than X and Y are copied from origin.
+	Destination(origin Coord, distance Meters, bearing Degrees) Coord
+}
+
+// Haversine is the spherical [DistanceModel] implemented by [Distance],
+// [Bearing] and [Destination].
+type Haversine struct{}
+
+// Distance implements [DistanceModel].
+func (Haversine) Distance(from, to Coord) Meters {
+	return Distance(from, to)
+}
+
+// Bearing implements [DistanceModel].
+func (Haversine) Bearing(from, to Coord) Degrees {
+	return Bearing(from, to)
+}
+
+// Destination implements [DistanceModel].
+func (Haversine) Destination(origin Coord, distance Meters, bearing Degrees) Coord {
+	dest := Destination(NewPointFlat(XY, origin[:2]), distance, bearing)
+	c := origin.Clone()
+	c[0], c[1] = dest.X(), dest.Y()
+	return c
+}
+
+// distanceModel returns the first model, or [Haversine] if there is none.
+func distanceModel(model []DistanceModel) DistanceModel {
+	if len(model) > 0 && model[0] != nil {
+		return model[0]
+	}
+	return Haversine{}
+}
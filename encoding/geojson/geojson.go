@@ -228,6 +228,9 @@ func (g *Geometry) Decode() (goodgeo.T, error) {
 type EncodeGeometryOption struct {
 	onGeometryHandler func(*Geometry, goodgeo.T, ...EncodeGeometryOption) error
 	onFloat64Handler  func(interface{}) interface{}
+	// transformGeometry returns the geometry to encode in place of the given
+	// one.
+	transformGeometry func(goodgeo.T) goodgeo.T
 }
 
 // nestedFloat64WithMaxDecimalDigits is a wrapper around any nested array
@@ -329,11 +332,27 @@ func EncodeGeometryWithMaxDecimalDigits(maxDecimalDigits int) EncodeGeometryOpti
 	}
 }
 
+// EncodeGeometryWithRFC7946Winding rewinds the rings of polygons while
+// encoding, so that exterior rings are counter-clockwise and holes clockwise
+// as required by RFC 7946. The geometry itself is not modified.
+func EncodeGeometryWithRFC7946Winding() EncodeGeometryOption {
+	return EncodeGeometryOption{
+		transformGeometry: func(g goodgeo.T) goodgeo.T {
+			return goodgeo.Rewind(g, true)
+		},
+	}
+}
+
 // Encode encodes g as a GeoJSON geometry.
 func Encode(g goodgeo.T, opts ...EncodeGeometryOption) (*Geometry, error) {
 	if g == nil {
 		return nil, nil //nolint:nilnil
 	}
+	for _, opt := range opts {
+		if opt.transformGeometry != nil {
+			g = opt.transformGeometry(g)
+		}
+	}
 	ret, err := encode(g, opts...)
 	if err != nil {
 		return nil, err
@@ -538,7 +557,12 @@ func encodeBBox(b *goodgeo.Bounds) ([]float64, error) {
 
 // MarshalJSON implements json.Marshaler.MarshalJSON.
 func (f *Feature) MarshalJSON() ([]byte, error) {
-	geometry, err := Encode(f.Geometry)
+	return f.marshalJSON()
+}
+
+// marshalJSON marshals f, encoding its geometry with opts.
+func (f *Feature) marshalJSON(opts ...EncodeGeometryOption) ([]byte, error) {
+	geometry, err := Encode(f.Geometry, opts...)
 	if err != nil {
 		return nil, err
 	}
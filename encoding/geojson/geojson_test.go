@@ -259,6 +259,20 @@ func TestGeometry(t *testing.T) {
 			s:             `{"type":"GeometryCollection","bbox":[100.12,0.46,102.12,1.57],"geometries":[{"type":"Point","coordinates":[100.12,0.46]},{"type":"LineString","coordinates":[[101.57,0.9],[102.12,1.57]]}]}`,
 			skipUnmarshal: true,
 		},
+		{
+			g:             goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{{{0, 0}, {0, 4}, {4, 4}, {4, 0}, {0, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 1}}}),
+			opts:          []EncodeGeometryOption{EncodeGeometryWithRFC7946Winding()},
+			s:             `{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[1,1],[2,2],[2,1],[1,1]]]}`,
+			skipUnmarshal: true,
+		},
+		{
+			g: goodgeo.NewGeometryCollection().MustPush(
+				goodgeo.NewMultiPolygon(goodgeo.XY).MustSetCoords([][][]goodgeo.Coord{{{{0, 0}, {0, 1}, {1, 0}, {0, 0}}}}),
+			),
+			opts:          []EncodeGeometryOption{EncodeGeometryWithRFC7946Winding(), EncodeGeometryWithBBox()},
+			s:             `{"type":"GeometryCollection","bbox":[0,0,1,1],"geometries":[{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[0,1],[0,0]]]]}]}`,
+			skipUnmarshal: true,
+		},
 	} {
 		t.Run(tc.s, func(t *testing.T) {
 			got, err := Marshal(tc.g, tc.opts...)
@@ -0,0 +1,143 @@
+package geojson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// recordSeparator starts each record of a GeoJSON text sequence.
+const recordSeparator = 0x1e
+
+// A SeqError is an error in a record of a sequence of features. Record is
+// the number of the record in the stream, counting from 1 and including
+// empty records, which for newline-delimited GeoJSON is the line number.
+type SeqError struct {
+	Record int
+	Err    error
+}
+
+func (e *SeqError) Error() string {
+	return fmt.Sprintf("geojson: record %d: %v", e.Record, e.Err)
+}
+
+func (e *SeqError) Unwrap() error {
+	return e.Err
+}
+
+// A SeqDecoder reads features from a GeoJSON text sequence, see RFC 8142,
+// or from newline-delimited GeoJSON, one feature per record. The format is
+// detected from the first byte of the stream that is not whitespace: a
+// record separator starts a GeoJSON text sequence, whose records may span
+// several lines.
+type SeqDecoder struct {
+	r      *bufio.Reader
+	sep    byte
+	record int
+	err    error
+}
+
+// NewSeqDecoder returns a SeqDecoder that reads from r.
+func NewSeqDecoder(r io.Reader) *SeqDecoder {
+	return &SeqDecoder{r: bufio.NewReader(r)}
+}
+
+// Decode returns the feature in the next record, or io.EOF at the end of
+// the stream. Empty records are skipped. A record that is not a feature is
+// reported as a [*SeqError], after which Decode carries on with the next
+// record. Errors reading the stream are returned on every later call.
+func (d *SeqDecoder) Decode() (*Feature, error) {
+	for {
+		if d.err != nil {
+			return nil, d.err
+		}
+		data, err := d.next()
+		if err != nil {
+			d.err = err
+			return nil, err
+		}
+		d.record++
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+		f := &Feature{}
+		if err := json.Unmarshal(data, f); err != nil {
+			return nil, &SeqError{Record: d.record, Err: err}
+		}
+		return f, nil
+	}
+}
+
+// next returns the next record without its separator. The blank lines
+// before the first line of newline-delimited GeoJSON are counted as
+// records.
+func (d *SeqDecoder) next() ([]byte, error) {
+	if d.sep == 0 {
+		lines := 0
+		for {
+			b, err := d.r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			switch b {
+			case '\n':
+				lines++
+				continue
+			case ' ', '\t', '\r':
+				continue
+			case recordSeparator:
+				d.sep = recordSeparator
+			default:
+				d.sep = '\n'
+				d.record += lines
+				if err := d.r.UnreadByte(); err != nil {
+					return nil, err
+				}
+			}
+			break
+		}
+	}
+	data, err := d.r.ReadBytes(d.sep)
+	switch {
+	case err == io.EOF && len(data) > 0:
+		return data, nil
+	case err != nil:
+		return nil, err
+	default:
+		return data[:len(data)-1], nil
+	}
+}
+
+// A SeqEncoder writes features to a stream one record at a time.
+type SeqEncoder struct {
+	w      io.Writer
+	prefix []byte
+	opts   []EncodeGeometryOption
+}
+
+// NewSeqEncoder returns a SeqEncoder that writes a GeoJSON text sequence,
+// see RFC 8142, to w, encoding the geometries of the features with opts.
+func NewSeqEncoder(w io.Writer, opts ...EncodeGeometryOption) *SeqEncoder {
+	return &SeqEncoder{w: w, prefix: []byte{recordSeparator}, opts: opts}
+}
+
+// NewNDJSONEncoder returns a SeqEncoder that writes newline-delimited
+// GeoJSON to w, encoding the geometries of the features with opts.
+func NewNDJSONEncoder(w io.Writer, opts ...EncodeGeometryOption) *SeqEncoder {
+	return &SeqEncoder{w: w, opts: opts}
+}
+
+// Encode writes f as the next record.
+func (e *SeqEncoder) Encode(f *Feature) error {
+	data, err := f.marshalJSON(e.opts...)
+	if err != nil {
+		return err
+	}
+	record := make([]byte, 0, len(e.prefix)+len(data)+1)
+	record = append(append(append(record, e.prefix...), data...), '\n')
+	_, err = e.w.Write(record)
+	return err
+}
@@ -0,0 +1,94 @@
+package geojson
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/matoous/goodgeo"
+)
+
+func TestSeqDecoder(t *testing.T) {
+	point := &Feature{
+		Geometry:   goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{1, 2}),
+		Properties: map[string]interface{}{"a": "b"},
+	}
+	const feature = `{"type": "Feature", "geometry": {"type": "Point", "coordinates": [1, 2]}, "properties": {"a": "b"}}`
+
+	for _, tc := range []struct {
+		name string
+		s    string
+		// bad are the numbers of the records that are not features.
+		bad []int
+	}{
+		{
+			// A truncated record is followed by one that is not a feature,
+			// empty records are skipped but counted.
+			name: "GeoJSONSeq",
+			s:    "\x1e" + feature + "\n\x1e{\"type\": \"Feature\",\n \"geometry\": {\"type\": \"Point\"\x1e[1, 2]\n\x1e\x1e \n\x1e" + feature + "\n",
+			bad:  []int{2, 3},
+		},
+		{
+			name: "NDJSON",
+			s:    "\n \n" + feature + "\n\n{\"type\": \"Point\", \"coordinates\": [1, 2]}\r\n\n" + feature,
+			bad:  []int{5},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewSeqDecoder(strings.NewReader(tc.s))
+
+			f, err := d.Decode()
+			assert.NoError(t, err)
+			assert.Equal(t, point, f)
+
+			for _, record := range tc.bad {
+				_, err = d.Decode()
+				var seqErr *SeqError
+				assert.True(t, errors.As(err, &seqErr), "%v", err)
+				assert.Equal(t, record, seqErr.Record)
+			}
+
+			f, err = d.Decode()
+			assert.NoError(t, err)
+			assert.Equal(t, point, f)
+
+			_, err = d.Decode()
+			assert.IsError(t, err, io.EOF)
+		})
+	}
+}
+
+func TestSeqEncoder(t *testing.T) {
+	features := []*Feature{
+		{Geometry: goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{1.23456, 2})},
+		{Properties: map[string]interface{}{"text": "two\nlines"}},
+	}
+	const want = `{"type":"Feature","geometry":{"type":"Point","coordinates":[1.23,2]},"properties":null}` + "\n" +
+		`{"type":"Feature","geometry":null,"properties":{"text":"two\nlines"}}` + "\n"
+
+	for _, tc := range []struct {
+		newEncoder func(io.Writer, ...EncodeGeometryOption) *SeqEncoder
+		want       string
+	}{
+		{newEncoder: NewNDJSONEncoder, want: want},
+		{newEncoder: NewSeqEncoder, want: "\x1e" + strings.Replace(want, "\n{", "\n\x1e{", 1)},
+	} {
+		var sb strings.Builder
+		e := tc.newEncoder(&sb, EncodeGeometryWithMaxDecimalDigits(2))
+		for _, f := range features {
+			assert.NoError(t, e.Encode(f))
+		}
+		assert.Equal(t, tc.want, sb.String())
+
+		d := NewSeqDecoder(strings.NewReader(sb.String()))
+		for range features {
+			_, err := d.Decode()
+			assert.NoError(t, err)
+		}
+		_, err := d.Decode()
+		assert.IsError(t, err, io.EOF)
+	}
+}
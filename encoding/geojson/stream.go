@@ -0,0 +1,216 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/matoous/goodgeo"
+)
+
+// Errors returned by [Decoder] and [Encoder].
+var (
+	// ErrInvalidFeatureCollection is returned when the input is not a JSON
+	// object with an array of features.
+	ErrInvalidFeatureCollection = errors.New("geojson: invalid FeatureCollection")
+	// ErrEncoderClosed is returned when a feature is encoded after Close.
+	ErrEncoderClosed = errors.New("geojson: encoder closed")
+)
+
+// A Decoder reads the features of a FeatureCollection from a stream one at a
+// time, holding only the feature being read in memory.
+type Decoder struct {
+	dec        *json.Decoder
+	started    bool
+	inFeatures bool
+	typ        string
+	bbox       *goodgeo.Bounds
+	err        error
+}
+
+// NewDecoder returns a Decoder that reads a FeatureCollection from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: json.NewDecoder(r)}
+}
+
+// Decode returns the next feature of the FeatureCollection, or io.EOF once
+// all of it has been read. A wrong type is reported as soon as it is read,
+// a missing one at the end. Members of the FeatureCollection other than
+// its type, bbox and features are skipped. Once Decode returns an error, it
+// returns the same error on every call.
+func (d *Decoder) Decode() (*Feature, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	f, err := d.decode()
+	if err != nil {
+		d.err = err
+	}
+	return f, err
+}
+
+// BBox returns the bbox of the FeatureCollection, or nil if it has none or
+// it comes after the features that have not been read yet.
+func (d *Decoder) BBox() *goodgeo.Bounds {
+	return d.bbox
+}
+
+func (d *Decoder) decode() (*Feature, error) {
+	if !d.started {
+		if err := d.delim('{'); err != nil {
+			return nil, err
+		}
+		d.started = true
+	}
+	for {
+		if d.inFeatures {
+			if d.dec.More() {
+				f := &Feature{}
+				if err := d.dec.Decode(f); err != nil {
+					return nil, err
+				}
+				return f, nil
+			}
+			if err := d.delim(']'); err != nil {
+				return nil, err
+			}
+			d.inFeatures = false
+			continue
+		}
+
+		if !d.dec.More() {
+			if err := d.delim('}'); err != nil {
+				return nil, err
+			}
+			if d.typ != "FeatureCollection" {
+				return nil, ErrUnsupportedType(d.typ)
+			}
+			return nil, io.EOF
+		}
+		token, err := d.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch token {
+		case "type":
+			if err := d.dec.Decode(&d.typ); err != nil {
+				return nil, err
+			}
+			if d.typ != "FeatureCollection" {
+				return nil, ErrUnsupportedType(d.typ)
+			}
+		case "bbox":
+			var bbox []float64
+			if err := d.dec.Decode(&bbox); err != nil {
+				return nil, err
+			}
+			if bbox != nil {
+				if d.bbox, err = decodeBBox(bbox); err != nil {
+					return nil, err
+				}
+			}
+		case "features":
+			token, err := d.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch token {
+			case json.Delim('['):
+				d.inFeatures = true
+			case nil:
+			default:
+				return nil, ErrInvalidFeatureCollection
+			}
+		default:
+			var skip json.RawMessage
+			if err := d.dec.Decode(&skip); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// delim reads the delimiter want.
+func (d *Decoder) delim(want json.Delim) error {
+	token, err := d.dec.Token()
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	if token != want {
+		return ErrInvalidFeatureCollection
+	}
+	return nil
+}
+
+// An Encoder writes features to a stream as a FeatureCollection, one at a
+// time.
+type Encoder struct {
+	w      io.Writer
+	opts   []EncodeGeometryOption
+	bbox   *goodgeo.Bounds
+	n      int
+	closed bool
+}
+
+// NewEncoder returns an Encoder that writes a FeatureCollection to w,
+// encoding the geometries of its features with opts.
+func NewEncoder(w io.Writer, opts ...EncodeGeometryOption) *Encoder {
+	return &Encoder{w: w, opts: opts}
+}
+
+// SetBBox sets the bbox written after the features by Close.
+func (e *Encoder) SetBBox(bbox *goodgeo.Bounds) {
+	e.bbox = bbox
+}
+
+// Encode writes f as the next feature of the FeatureCollection.
+func (e *Encoder) Encode(f *Feature) error {
+	if e.closed {
+		return ErrEncoderClosed
+	}
+	data, err := f.marshalJSON(e.opts...)
+	if err != nil {
+		return err
+	}
+	prefix := `,`
+	if e.n == 0 {
+		prefix = `{"type":"FeatureCollection","features":[`
+	}
+	if _, err := io.WriteString(e.w, prefix); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(data); err != nil {
+		return err
+	}
+	e.n++
+	return nil
+}
+
+// Close ends the FeatureCollection. It does not close the underlying
+// writer.
+func (e *Encoder) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	end := `]`
+	if e.n == 0 {
+		end = `{"type":"FeatureCollection","features":[]`
+	}
+	if e.bbox != nil {
+		bbox, err := encodeBBox(e.bbox)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(bbox)
+		if err != nil {
+			return err
+		}
+		end += `,"bbox":` + string(data)
+	}
+	_, err := io.WriteString(e.w, end+"}")
+	return err
+}
@@ -0,0 +1,107 @@
+package geojson
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/matoous/goodgeo"
+)
+
+func TestDecoder(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`{
+		"type": "FeatureCollection",
+		"name": {"skipped": [1, 2, 3]},
+		"features": [
+			{"type": "Feature", "id": 1, "geometry": {"type": "Point", "coordinates": [1, 2]}, "properties": {"a": "b"}},
+			{"type": "Feature", "geometry": null, "properties": null}
+		],
+		"bbox": [1, 2, 1, 2]
+	}`))
+
+	f, err := d.Decode()
+	assert.NoError(t, err)
+	assert.Equal(t, &Feature{
+		ID:         "1",
+		Geometry:   goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{1, 2}),
+		Properties: map[string]interface{}{"a": "b"},
+	}, f)
+	assert.Zero(t, d.BBox())
+
+	f, err = d.Decode()
+	assert.NoError(t, err)
+	assert.Equal(t, &Feature{}, f)
+
+	_, err = d.Decode()
+	assert.IsError(t, err, io.EOF)
+	_, err = d.Decode()
+	assert.IsError(t, err, io.EOF)
+	assert.Equal(t, goodgeo.NewBounds(goodgeo.XY).Set(1, 2, 1, 2), d.BBox())
+}
+
+func TestDecoderErrors(t *testing.T) {
+	for _, tc := range []struct {
+		s        string
+		features int
+		err      error
+	}{
+		{s: `{"type": "Feature", "features": []}`, err: ErrUnsupportedType("Feature")},
+		{s: `{"features": []}`, err: ErrUnsupportedType("")},
+		{s: `{"features": [{"type": "Feature", "geometry": null, "properties": null}], "type": "Topology"}`, features: 1, err: ErrUnsupportedType("Topology")},
+		{s: `[]`, err: ErrInvalidFeatureCollection},
+		{s: `{"type": "FeatureCollection", "features": {}}`, err: ErrInvalidFeatureCollection},
+		{s: `{"type": "FeatureCollection", "features": [{"type": "Feature", "geometry": null, "properties": null}`, features: 1},
+		{s: `{"type": "FeatureCollection", "features": [{"type": "Feature", "geometry": nu`, err: io.ErrUnexpectedEOF},
+		{s: ``, err: io.ErrUnexpectedEOF},
+	} {
+		t.Run(tc.s, func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(tc.s))
+			for range tc.features {
+				_, err := d.Decode()
+				assert.NoError(t, err)
+			}
+			_, err := d.Decode()
+			if tc.err == nil {
+				assert.Error(t, err)
+			} else {
+				assert.IsError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	features := []*Feature{
+		{
+			ID:         "0",
+			Geometry:   goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{1.23456, 2}),
+			Properties: map[string]interface{}{"a": "b"},
+		},
+		{},
+	}
+
+	var sb strings.Builder
+	e := NewEncoder(&sb, EncodeGeometryWithMaxDecimalDigits(2))
+	for _, f := range features {
+		assert.NoError(t, e.Encode(f))
+	}
+	e.SetBBox(goodgeo.NewBounds(goodgeo.XY).Set(1, 2, 1, 2))
+	assert.NoError(t, e.Close())
+	assert.IsError(t, e.Encode(features[0]), ErrEncoderClosed)
+	assert.Equal(t, `{"type":"FeatureCollection","features":[`+
+		`{"type":"Feature","id":"0","geometry":{"type":"Point","coordinates":[1.23,2]},"properties":{"a":"b"}},`+
+		`{"type":"Feature","geometry":null,"properties":null}],"bbox":[1,2,1,2]}`, sb.String())
+
+	var fc FeatureCollection
+	assert.NoError(t, json.Unmarshal([]byte(sb.String()), &fc))
+	assert.Equal(t, 2, len(fc.Features))
+
+	sb.Reset()
+	assert.NoError(t, NewEncoder(&sb).Close())
+	want, err := json.Marshal(&FeatureCollection{})
+	assert.NoError(t, err)
+	assert.Equal(t, string(want), sb.String())
+}
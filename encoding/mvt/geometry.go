@@ -0,0 +1,517 @@
+package mvt
+
+import (
+	"math"
+
+	"github.com/matoous/goodgeo"
+)
+
+// Geometry types.
+const (
+	geomUnknown    = 0
+	geomPoint      = 1
+	geomLineString = 2
+	geomPolygon    = 3
+)
+
+// Geometry commands.
+const (
+	cmdMoveTo    = 1
+	cmdLineTo    = 2
+	cmdClosePath = 7
+)
+
+// maxLatitude is the latitude at which Web Mercator tiles end.
+const maxLatitude = 85.05112877980659
+
+// A projection maps longitudes and latitudes to the coordinates of a tile
+// and back. Tile coordinates grow to the east and to the south, from 0 to
+// extent.
+type projection struct {
+	scale, x, y, extent float64
+}
+
+func newProjection(tile TileID, extent uint32) projection {
+	return projection{
+		scale:  math.Exp2(float64(tile.Z)),
+		x:      float64(tile.X),
+		y:      float64(tile.Y),
+		extent: float64(extent),
+	}
+}
+
+func (p projection) project(c goodgeo.Coord) point {
+	lat := max(-maxLatitude, min(maxLatitude, c[1])) * math.Pi / 180
+	x := (c[0] + 180) / 360
+	y := (1 - math.Log(math.Tan(lat)+1/math.Cos(lat))/math.Pi) / 2
+	return point{(x*p.scale - p.x) * p.extent, (y*p.scale - p.y) * p.extent}
+}
+
+func (p projection) unproject(x, y int64) goodgeo.Coord {
+	lng := (p.x+float64(x)/p.extent)/p.scale*360 - 180
+	lat := math.Atan(math.Sinh(math.Pi*(1-2*(p.y+float64(y)/p.extent)/p.scale))) * 180 / math.Pi
+	return goodgeo.Coord{lng, lat}
+}
+
+// A point is a position in tile coordinates.
+type point [2]float64
+
+// A shape is a geometry in tile coordinates: points, lines, or polygons
+// made of rings. Rings are not closed.
+type shape struct {
+	geomType int
+	points   []point
+	lines    [][]point
+	polygons [][][]point
+}
+
+// newShape projects g into tile coordinates.
+func newShape(g goodgeo.T, p projection) (*shape, error) {
+	projectAll := func(coords []goodgeo.Coord) []point {
+		pts := make([]point, len(coords))
+		for i, c := range coords {
+			pts[i] = p.project(c)
+		}
+		return pts
+	}
+	projectRings := func(rings [][]goodgeo.Coord) [][]point {
+		polygon := make([][]point, len(rings))
+		for i, ring := range rings {
+			if n := len(ring); n > 1 && ring[0][0] == ring[n-1][0] && ring[0][1] == ring[n-1][1] {
+				ring = ring[:n-1]
+			}
+			polygon[i] = projectAll(ring)
+		}
+		return polygon
+	}
+
+	switch g := g.(type) {
+	case *goodgeo.Point:
+		if g.Empty() {
+			return &shape{geomType: geomPoint}, nil
+		}
+		return &shape{geomType: geomPoint, points: projectAll([]goodgeo.Coord{g.Coords()})}, nil
+	case *goodgeo.MultiPoint:
+		s := &shape{geomType: geomPoint}
+		for _, c := range g.Coords() {
+			if c != nil {
+				s.points = append(s.points, p.project(c))
+			}
+		}
+		return s, nil
+	case *goodgeo.LineString:
+		return &shape{geomType: geomLineString, lines: [][]point{projectAll(g.Coords())}}, nil
+	case *goodgeo.MultiLineString:
+		s := &shape{geomType: geomLineString}
+		for _, line := range g.Coords() {
+			s.lines = append(s.lines, projectAll(line))
+		}
+		return s, nil
+	case *goodgeo.Polygon:
+		return &shape{geomType: geomPolygon, polygons: [][][]point{projectRings(g.Coords())}}, nil
+	case *goodgeo.MultiPolygon:
+		s := &shape{geomType: geomPolygon}
+		for _, polygon := range g.Coords() {
+			s.polygons = append(s.polygons, projectRings(polygon))
+		}
+		return s, nil
+	default:
+		return nil, goodgeo.UnsupportedTypeError{Value: g}
+	}
+}
+
+// clip clips s to the square from lo to hi.
+func (s *shape) clip(lo, hi float64) {
+	points := s.points[:0]
+	for _, pt := range s.points {
+		if lo <= pt[0] && pt[0] <= hi && lo <= pt[1] && pt[1] <= hi {
+			points = append(points, pt)
+		}
+	}
+	s.points = points
+
+	var lines [][]point
+	for _, line := range s.lines {
+		lines = append(lines, clipLine(line, lo, hi)...)
+	}
+	s.lines = lines
+
+	var polygons [][][]point
+	for _, polygon := range s.polygons {
+		var rings [][]point
+		for i, ring := range polygon {
+			ring = clipRing(ring, lo, hi)
+			if len(ring) < 3 {
+				if i == 0 {
+					break
+				}
+				continue
+			}
+			rings = append(rings, ring)
+		}
+		if len(rings) > 0 {
+			polygons = append(polygons, rings)
+		}
+	}
+	s.polygons = polygons
+}
+
+// clipLine returns the parts of line inside the square from lo to hi.
+func clipLine(line []point, lo, hi float64) [][]point {
+	var parts [][]point
+	var part []point
+	for i := 1; i < len(line); i++ {
+		a, b, ok := clipSegment(line[i-1], line[i], lo, hi)
+		if !ok {
+			continue
+		}
+		if len(part) == 0 || part[len(part)-1] != a {
+			if len(part) > 1 {
+				parts = append(parts, part)
+			}
+			part = []point{a}
+		}
+		part = append(part, b)
+		if b != line[i] {
+			// The segment leaves the square.
+			parts = append(parts, part)
+			part = nil
+		}
+	}
+	if len(part) > 1 {
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// clipSegment returns the part of the segment ab inside the square from lo
+// to hi with the Liang–Barsky algorithm, or false if there is none.
+func clipSegment(a, b point, lo, hi float64) (point, point, bool) {
+	t0, t1 := 0.0, 1.0
+	d := point{b[0] - a[0], b[1] - a[1]}
+	for dim := range 2 {
+		for _, edge := range [2]struct{ p, q float64 }{
+			{-d[dim], a[dim] - lo},
+			{d[dim], hi - a[dim]},
+		} {
+			switch {
+			case edge.p == 0:
+				if edge.q < 0 {
+					return point{}, point{}, false
+				}
+			case edge.p < 0:
+				t0 = max(t0, edge.q/edge.p)
+			default:
+				t1 = min(t1, edge.q/edge.p)
+			}
+		}
+	}
+	if t0 > t1 {
+		return point{}, point{}, false
+	}
+	ca, cb := a, b
+	if t0 > 0 {
+		ca = point{a[0] + t0*d[0], a[1] + t0*d[1]}
+	}
+	if t1 < 1 {
+		cb = point{a[0] + t1*d[0], a[1] + t1*d[1]}
+	}
+	return ca, cb, true
+}
+
+// clipRing clips ring to the square from lo to hi with the
+// Sutherland–Hodgman algorithm.
+func clipRing(ring []point, lo, hi float64) []point {
+	for dim := range 2 {
+		ring = clipRingEdge(ring, dim, lo, false)
+		ring = clipRingEdge(ring, dim, hi, true)
+	}
+	return ring
+}
+
+// clipRingEdge clips ring to the half-plane where the coordinate dim is at
+// least v, or at most v if upper is true.
+func clipRingEdge(ring []point, dim int, v float64, upper bool) []point {
+	inside := func(p point) bool {
+		if upper {
+			return p[dim] <= v
+		}
+		return p[dim] >= v
+	}
+	var clipped []point
+	for i, b := range ring {
+		a := ring[(i+len(ring)-1)%len(ring)]
+		if inside(b) {
+			if !inside(a) {
+				clipped = append(clipped, intersect(a, b, dim, v))
+			}
+			clipped = append(clipped, b)
+		} else if inside(a) {
+			clipped = append(clipped, intersect(a, b, dim, v))
+		}
+	}
+	return clipped
+}
+
+// intersect returns the point of the segment ab where the coordinate dim
+// is v.
+func intersect(a, b point, dim int, v float64) point {
+	t := (v - a[dim]) / (b[dim] - a[dim])
+	p := point{a[0] + t*(b[0]-a[0]), a[1] + t*(b[1]-a[1])}
+	p[dim] = v
+	return p
+}
+
+// simplify simplifies the lines and rings of s with the Ramer–Douglas–Peucker
+// algorithm.
+func (s *shape) simplify(tolerance float64) {
+	if tolerance <= 0 {
+		return
+	}
+	for i, line := range s.lines {
+		s.lines[i] = simplifyLine(line, tolerance)
+	}
+	for _, polygon := range s.polygons {
+		for i, ring := range polygon {
+			// Simplify the ring as a line that starts and ends at its
+			// first vertex.
+			closed := simplifyLine(append(ring, ring[0]), tolerance)
+			polygon[i] = closed[:len(closed)-1]
+		}
+	}
+}
+
+func simplifyLine(line []point, tolerance float64) []point {
+	if len(line) < 3 {
+		return line
+	}
+	keep := make([]bool, len(line))
+	keep[0], keep[len(line)-1] = true, true
+	simplifyRange(line, keep, 0, len(line)-1, tolerance*tolerance)
+	simplified := line[:0:0]
+	for i, pt := range line {
+		if keep[i] {
+			simplified = append(simplified, pt)
+		}
+	}
+	return simplified
+}
+
+func simplifyRange(line []point, keep []bool, start, end int, sqTolerance float64) {
+	index, maxSqDist := -1, sqTolerance
+	for i := start + 1; i < end; i++ {
+		if d := sqSegmentDistance(line[i], line[start], line[end]); d > maxSqDist {
+			index, maxSqDist = i, d
+		}
+	}
+	if index == -1 {
+		return
+	}
+	keep[index] = true
+	simplifyRange(line, keep, start, index, sqTolerance)
+	simplifyRange(line, keep, index, end, sqTolerance)
+}
+
+// sqSegmentDistance returns the squared distance from p to the segment ab.
+func sqSegmentDistance(p, a, b point) float64 {
+	x, y := a[0], a[1]
+	dx, dy := b[0]-x, b[1]-y
+	if dx != 0 || dy != 0 {
+		t := ((p[0]-x)*dx + (p[1]-y)*dy) / (dx*dx + dy*dy)
+		if t > 1 {
+			x, y = b[0], b[1]
+		} else if t > 0 {
+			x, y = x+dx*t, y+dy*t
+		}
+	}
+	dx, dy = p[0]-x, p[1]-y
+	return dx*dx + dy*dy
+}
+
+// An ipoint is a position in tile coordinates rounded to integers.
+type ipoint [2]int64
+
+// quantize rounds pts to integers and removes consecutive duplicates.
+func quantize(pts []point) []ipoint {
+	q := make([]ipoint, 0, len(pts))
+	for _, pt := range pts {
+		ip := ipoint{int64(math.Round(pt[0])), int64(math.Round(pt[1]))}
+		if len(q) > 0 && q[len(q)-1] == ip {
+			continue
+		}
+		q = append(q, ip)
+	}
+	return q
+}
+
+// area returns twice the signed area of ring, positive if the ring is
+// clockwise in tile coordinates.
+func area(ring []ipoint) int64 {
+	var a int64
+	for i, p := range ring {
+		q := ring[(i+1)%len(ring)]
+		a += p[0]*q[1] - q[0]*p[1]
+	}
+	return a
+}
+
+func reverse[S ~[]E, E any](s S) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// commands returns the geometry commands of s, or nil if nothing of s is
+// left after rounding.
+func (s *shape) commands() []uint32 {
+	var e commandEncoder
+	switch s.geomType {
+	case geomPoint:
+		pts := make([]ipoint, len(s.points))
+		for i, pt := range s.points {
+			pts[i] = ipoint{int64(math.Round(pt[0])), int64(math.Round(pt[1]))}
+		}
+		if len(pts) > 0 {
+			e.command(cmdMoveTo, pts)
+		}
+	case geomLineString:
+		for _, line := range s.lines {
+			if q := quantize(line); len(q) > 1 {
+				e.command(cmdMoveTo, q[:1])
+				e.command(cmdLineTo, q[1:])
+			}
+		}
+	case geomPolygon:
+		for _, polygon := range s.polygons {
+			for i, ring := range polygon {
+				q := quantize(ring)
+				if len(q) > 1 && q[0] == q[len(q)-1] {
+					q = q[:len(q)-1]
+				}
+				a := area(q)
+				if len(q) < 3 || a == 0 {
+					if i == 0 {
+						break
+					}
+					continue
+				}
+				// Exterior rings are clockwise and holes are
+				// counter-clockwise.
+				if (i == 0) != (a > 0) {
+					reverse(q)
+				}
+				e.command(cmdMoveTo, q[:1])
+				e.command(cmdLineTo, q[1:])
+				e.cmds = append(e.cmds, command(cmdClosePath, 1))
+			}
+		}
+	}
+	return e.cmds
+}
+
+func command(id, count int) uint32 {
+	return uint32(id&7 | count<<3)
+}
+
+// A commandEncoder encodes geometry commands with coordinates relative to
+// the previous position.
+type commandEncoder struct {
+	cmds []uint32
+	pos  ipoint
+}
+
+func (e *commandEncoder) command(id int, pts []ipoint) {
+	e.cmds = append(e.cmds, command(id, len(pts)))
+	for _, p := range pts {
+		e.cmds = append(e.cmds, uint32(zigzag(p[0]-e.pos[0])), uint32(zigzag(p[1]-e.pos[1])))
+		e.pos = p
+	}
+}
+
+// decodeGeometry decodes geometry commands into a geometry.
+func decodeGeometry(geomType int, cmds []uint32, p projection) (goodgeo.T, error) {
+	var parts [][]goodgeo.Coord
+	var rings [][]ipoint
+	var pos ipoint
+	for i := 0; i < len(cmds); {
+		id, count := int(cmds[i]&7), int(cmds[i]>>3)
+		i++
+		switch id {
+		case cmdMoveTo, cmdLineTo:
+			if len(cmds)-i < 2*count {
+				return nil, ErrInvalidTile
+			}
+			for range count {
+				pos[0] += unzigzag(uint64(cmds[i]))
+				pos[1] += unzigzag(uint64(cmds[i+1]))
+				i += 2
+				switch {
+				case id == cmdLineTo && len(rings) == 0:
+					return nil, ErrInvalidTile
+				case id == cmdMoveTo && geomType != geomPoint, len(rings) == 0:
+					rings = append(rings, []ipoint{pos})
+				default:
+					rings[len(rings)-1] = append(rings[len(rings)-1], pos)
+				}
+			}
+		case cmdClosePath:
+			if len(rings) == 0 {
+				return nil, ErrInvalidTile
+			}
+		default:
+			return nil, ErrInvalidTile
+		}
+	}
+	toCoords := func(ring []ipoint) []goodgeo.Coord {
+		coords := make([]goodgeo.Coord, len(ring))
+		for i, q := range ring {
+			coords[i] = p.unproject(q[0], q[1])
+		}
+		return coords
+	}
+
+	switch geomType {
+	case geomPoint:
+		if len(rings) == 0 {
+			return goodgeo.NewPoint(goodgeo.XY), nil
+		}
+		pts := toCoords(rings[0])
+		if len(pts) == 1 {
+			return goodgeo.NewPoint(goodgeo.XY).SetCoords(pts[0])
+		}
+		return goodgeo.NewMultiPoint(goodgeo.XY).SetCoords(pts)
+	case geomLineString:
+		for _, ring := range rings {
+			parts = append(parts, toCoords(ring))
+		}
+		if len(parts) == 1 {
+			return goodgeo.NewLineString(goodgeo.XY).SetCoords(parts[0])
+		}
+		return goodgeo.NewMultiLineString(goodgeo.XY).SetCoords(parts)
+	case geomPolygon:
+		// Clockwise rings start new polygons and counter-clockwise rings
+		// are holes in the preceding one. As the y axis of tiles points to
+		// the south, rings are reversed to keep exterior rings
+		// counter-clockwise in longitudes and latitudes.
+		var polygons [][][]goodgeo.Coord
+		for _, ring := range rings {
+			if len(ring) < 3 {
+				continue
+			}
+			a := area(ring)
+			reverse(ring)
+			coords := toCoords(append(ring, ring[0]))
+			if a > 0 || len(polygons) == 0 {
+				polygons = append(polygons, [][]goodgeo.Coord{coords})
+			} else {
+				polygons[len(polygons)-1] = append(polygons[len(polygons)-1], coords)
+			}
+		}
+		if len(polygons) == 1 {
+			return goodgeo.NewPolygon(goodgeo.XY).SetCoords(polygons[0])
+		}
+		return goodgeo.NewMultiPolygon(goodgeo.XY).SetCoords(polygons)
+	default:
+		return nil, ErrInvalidTile
+	}
+}
@@ -0,0 +1,517 @@
+// Package mvt implements Mapbox Vector Tile encoding and decoding, see
+// https://github.com/mapbox/vector-tile-spec.
+//
+// A vector tile holds named layers of features in the coordinates of a
+// single tile of the Web Mercator grid. Encoding projects the features into
+// the tile, clips them to the tile and a buffer around it, simplifies them
+// and packs them into a protocol buffer. Decoding projects the features
+// back to longitudes and latitudes.
+package mvt
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/matoous/goodgeo"
+	"github.com/matoous/goodgeo/encoding/geojson"
+)
+
+// Defaults of the encoding options, as used by geojson-vt.
+const (
+	DefaultExtent    = 4096
+	DefaultBuffer    = 64
+	DefaultTolerance = 3
+)
+
+// Errors returned by the functions of this package.
+var (
+	ErrInvalidExtent = errors.New("mvt: invalid extent")
+	ErrInvalidTile   = errors.New("mvt: invalid tile")
+	ErrInvalidTileID = errors.New("mvt: invalid tile id")
+)
+
+// A TileID identifies a tile by its zoom level and its column and row,
+// counted from the north-west corner.
+type TileID struct {
+	Z, X, Y int
+}
+
+func (t TileID) valid() bool {
+	return 0 <= t.Z && t.Z < 32 && 0 <= t.X && t.X < 1<<t.Z && 0 <= t.Y && t.Y < 1<<t.Z
+}
+
+// A Layer is a named collection of features.
+type Layer struct {
+	Name     string
+	Features []*geojson.Feature
+}
+
+// An encoder holds the options of [Marshal].
+type encoder struct {
+	extent    uint32
+	buffer    float64
+	tolerance float64
+}
+
+// An EncodeOption sets an option of [Marshal].
+type EncodeOption func(*encoder)
+
+// WithExtent sets the number of units across a tile, [DefaultExtent] by
+// default. An extent of 0 makes [Marshal] return [ErrInvalidExtent].
+func WithExtent(extent uint32) EncodeOption {
+	return func(e *encoder) {
+		e.extent = extent
+	}
+}
+
+// WithBuffer sets the width, in tile units, of the buffer around the tile
+// that features are clipped to, [DefaultBuffer] by default. The buffer
+// hides the seams between tiles when lines and polygons are rendered.
+func WithBuffer(buffer int) EncodeOption {
+	return func(e *encoder) {
+		e.buffer = float64(buffer)
+	}
+}
+
+// WithTolerance sets the tolerance, in tile units, of the simplification of
+// lines and polygons, [DefaultTolerance] by default. As a tile unit covers
+// half the distance at each zoom level, so does the simplification. A
+// tolerance of 0 disables simplification.
+func WithTolerance(tolerance float64) EncodeOption {
+	return func(e *encoder) {
+		e.tolerance = tolerance
+	}
+}
+
+// Marshal encodes layers as the tile at id. The geometries of the features
+// are in longitudes and latitudes. Features with IDs that are unsigned
+// integers keep them. Properties that are not strings, numbers or booleans
+// are encoded as JSON strings and nil properties are omitted. Features and
+// layers with nothing left inside the tile are omitted.
+func Marshal(id TileID, layers []*Layer, opts ...EncodeOption) ([]byte, error) {
+	if !id.valid() {
+		return nil, ErrInvalidTileID
+	}
+	e := &encoder{extent: DefaultExtent, buffer: DefaultBuffer, tolerance: DefaultTolerance}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.extent == 0 {
+		return nil, ErrInvalidExtent
+	}
+	p := newProjection(id, e.extent)
+
+	var tile []byte
+	for _, layer := range layers {
+		b, err := e.encodeLayer(layer, p)
+		if err != nil {
+			return nil, err
+		}
+		if b != nil {
+			tile = appendBytesField(tile, 3, b)
+		}
+	}
+	return tile, nil
+}
+
+// A value is a property value of a feature, as encoded in a tile. Floats
+// are held as their bits in u, so that values can be compared.
+type value struct {
+	field int
+	s     string
+	u     uint64
+	b     bool
+}
+
+// newValue returns the value of v, or false if v is nil.
+func newValue(v any) (value, bool, error) {
+	switch v := v.(type) {
+	case nil:
+		return value{}, false, nil
+	case string:
+		return value{field: 1, s: v}, true, nil
+	case float32:
+		if math.IsNaN(float64(v)) {
+			v = float32(math.NaN())
+		}
+		return value{field: 2, u: uint64(math.Float32bits(v))}, true, nil
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<63 {
+			return newInt(int64(v)), true, nil
+		}
+		if math.IsNaN(v) {
+			v = math.NaN()
+		}
+		return value{field: 3, u: math.Float64bits(v)}, true, nil
+	case int:
+		return newInt(int64(v)), true, nil
+	case int8:
+		return newInt(int64(v)), true, nil
+	case int16:
+		return newInt(int64(v)), true, nil
+	case int32:
+		return newInt(int64(v)), true, nil
+	case int64:
+		return newInt(v), true, nil
+	case uint:
+		return value{field: 5, u: uint64(v)}, true, nil
+	case uint8:
+		return value{field: 5, u: uint64(v)}, true, nil
+	case uint16:
+		return value{field: 5, u: uint64(v)}, true, nil
+	case uint32:
+		return value{field: 5, u: uint64(v)}, true, nil
+	case uint64:
+		return value{field: 5, u: v}, true, nil
+	case bool:
+		return value{field: 7, b: v}, true, nil
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return value{}, false, err
+		}
+		return value{field: 1, s: string(b)}, true, nil
+	}
+}
+
+// newInt returns the value of v, as an unsigned integer if it is not
+// negative and as a zigzag encoded integer otherwise.
+func newInt(v int64) value {
+	if v < 0 {
+		return value{field: 6, u: zigzag(v)}
+	}
+	return value{field: 5, u: uint64(v)}
+}
+
+func (v value) marshal() []byte {
+	switch v.field {
+	case 1:
+		return appendBytesField(nil, 1, []byte(v.s))
+	case 2:
+		b := appendTag(nil, 2, wireFixed32)
+		return append(b, le32(uint32(v.u))...)
+	case 3:
+		b := appendTag(nil, 3, wireFixed64)
+		return append(b, le64(v.u)...)
+	case 7:
+		var u uint64
+		if v.b {
+			u = 1
+		}
+		return appendVarintField(nil, 7, u)
+	default:
+		return appendVarintField(nil, v.field, v.u)
+	}
+}
+
+func le32(v uint32) []byte {
+	return []byte{byte(v), byte(v >> 8), byte(v >> 16), byte(v >> 24)}
+}
+
+func le64(v uint64) []byte {
+	return append(le32(uint32(v)), le32(uint32(v>>32))...)
+}
+
+// encodeLayer returns the encoded layer, or nil if none of its features are
+// inside the tile.
+func (e *encoder) encodeLayer(layer *Layer, p projection) ([]byte, error) {
+	var features [][]byte
+	var keys []string
+	var values []value
+	keyIndex := make(map[string]uint32)
+	valueIndex := make(map[value]uint32)
+	for _, f := range layer.Features {
+		if f.Geometry == nil {
+			continue
+		}
+		var geoms []goodgeo.T
+		if gc, ok := f.Geometry.(*goodgeo.GeometryCollection); ok {
+			geoms = gc.Geoms()
+		} else {
+			geoms = []goodgeo.T{f.Geometry}
+		}
+		for _, g := range geoms {
+			s, err := newShape(g, p)
+			if err != nil {
+				return nil, err
+			}
+			s.clip(-e.buffer, float64(e.extent)+e.buffer)
+			s.simplify(e.tolerance)
+			cmds := s.commands()
+			if len(cmds) == 0 {
+				continue
+			}
+
+			var b []byte
+			if id, err := strconv.ParseUint(f.ID, 10, 64); err == nil {
+				b = appendVarintField(b, 1, id)
+			}
+			var tags []uint32
+			for _, key := range slices.Sorted(maps.Keys(f.Properties)) {
+				val, ok, err := newValue(f.Properties[key])
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue
+				}
+				ki, ok := keyIndex[key]
+				if !ok {
+					ki = uint32(len(keys))
+					keyIndex[key] = ki
+					keys = append(keys, key)
+				}
+				vi, ok := valueIndex[val]
+				if !ok {
+					vi = uint32(len(values))
+					valueIndex[val] = vi
+					values = append(values, val)
+				}
+				tags = append(tags, ki, vi)
+			}
+			if len(tags) > 0 {
+				b = appendPackedField(b, 2, tags)
+			}
+			b = appendVarintField(b, 3, uint64(s.geomType))
+			b = appendPackedField(b, 4, cmds)
+			features = append(features, b)
+		}
+	}
+	if len(features) == 0 {
+		return nil, nil
+	}
+
+	b := appendVarintField(nil, 15, 2)
+	b = appendBytesField(b, 1, []byte(layer.Name))
+	for _, f := range features {
+		b = appendBytesField(b, 2, f)
+	}
+	for _, key := range keys {
+		b = appendBytesField(b, 3, []byte(key))
+	}
+	for _, v := range values {
+		b = appendBytesField(b, 4, v.marshal())
+	}
+	return appendVarintField(b, 5, uint64(e.extent)), nil
+}
+
+// Unmarshal decodes the tile at id. The geometries of the features are
+// projected to longitudes and latitudes. Integer properties are decoded as
+// int64 or uint64, floating point properties as float64.
+func Unmarshal(data []byte, id TileID) ([]*Layer, error) {
+	if !id.valid() {
+		return nil, ErrInvalidTileID
+	}
+	var layers []*Layer
+	r := reader{b: data}
+	for len(r.b) > 0 {
+		field, wireType, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		if field != 3 || wireType != wireBytes {
+			if err := r.skip(wireType); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		b, err := r.bytes()
+		if err != nil {
+			return nil, err
+		}
+		layer, err := decodeLayer(b, id)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
+
+func decodeLayer(data []byte, id TileID) (*Layer, error) {
+	layer := &Layer{}
+	var features [][]byte
+	var keys []string
+	var values []any
+	extent := uint64(DefaultExtent)
+	r := reader{b: data}
+	for len(r.b) > 0 {
+		field, wireType, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case field == 1 && wireType == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			layer.Name = string(b)
+		case field == 2 && wireType == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			features = append(features, b)
+		case field == 3 && wireType == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, string(b))
+		case field == 4 && wireType == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeValue(b)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		case field == 5 && wireType == wireVarint:
+			if extent, err = r.varint(); err != nil {
+				return nil, err
+			}
+			if extent == 0 {
+				return nil, ErrInvalidTile
+			}
+		default:
+			if err := r.skip(wireType); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	p := newProjection(id, uint32(extent))
+	for _, b := range features {
+		f, err := decodeFeature(b, keys, values, p)
+		if err != nil {
+			return nil, err
+		}
+		if f != nil {
+			layer.Features = append(layer.Features, f)
+		}
+	}
+	return layer, nil
+}
+
+func decodeValue(data []byte) (any, error) {
+	var v any
+	r := reader{b: data}
+	for len(r.b) > 0 {
+		field, wireType, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case field == 1 && wireType == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			v = string(b)
+		case field == 2 && wireType == wireFixed32:
+			u, err := r.fixed32()
+			if err != nil {
+				return nil, err
+			}
+			v = float64(math.Float32frombits(u))
+		case field == 3 && wireType == wireFixed64:
+			u, err := r.fixed64()
+			if err != nil {
+				return nil, err
+			}
+			v = math.Float64frombits(u)
+		case field >= 4 && field <= 7 && wireType == wireVarint:
+			u, err := r.varint()
+			if err != nil {
+				return nil, err
+			}
+			switch field {
+			case 4:
+				v = int64(u)
+			case 5:
+				v = u
+			case 6:
+				v = unzigzag(u)
+			default:
+				v = u != 0
+			}
+		default:
+			if err := r.skip(wireType); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
+
+// decodeFeature decodes a feature, or returns nil if it has an unknown
+// geometry type.
+func decodeFeature(data []byte, keys []string, values []any, p projection) (*geojson.Feature, error) {
+	f := &geojson.Feature{}
+	var tags, cmds []uint32
+	geomType := geomUnknown
+	r := reader{b: data}
+	for len(r.b) > 0 {
+		field, wireType, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case field == 1 && wireType == wireVarint:
+			id, err := r.varint()
+			if err != nil {
+				return nil, err
+			}
+			f.ID = strconv.FormatUint(id, 10)
+		case field == 2:
+			vs, err := r.packed(wireType)
+			if err != nil {
+				return nil, err
+			}
+			tags = append(tags, vs...)
+		case field == 3 && wireType == wireVarint:
+			t, err := r.varint()
+			if err != nil {
+				return nil, err
+			}
+			geomType = int(t)
+		case field == 4:
+			vs, err := r.packed(wireType)
+			if err != nil {
+				return nil, err
+			}
+			cmds = append(cmds, vs...)
+		default:
+			if err := r.skip(wireType); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if geomType < geomPoint || geomType > geomPolygon {
+		return nil, nil
+	}
+
+	if len(tags)%2 != 0 {
+		return nil, ErrInvalidTile
+	}
+	f.Properties = make(map[string]any, len(tags)/2)
+	for i := 0; i < len(tags); i += 2 {
+		if int(tags[i]) >= len(keys) || int(tags[i+1]) >= len(values) {
+			return nil, ErrInvalidTile
+		}
+		f.Properties[keys[tags[i]]] = values[tags[i+1]]
+	}
+	g, err := decodeGeometry(geomType, cmds, p)
+	if err != nil {
+		return nil, err
+	}
+	f.Geometry = g
+	return f, nil
+}
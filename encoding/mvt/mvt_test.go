@@ -0,0 +1,294 @@
+package mvt
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/matoous/goodgeo"
+	"github.com/matoous/goodgeo/encoding/geojson"
+)
+
+func TestCommands(t *testing.T) {
+	// Examples from the vector tile specification.
+	for i, tc := range []struct {
+		s    *shape
+		want []uint32
+	}{
+		{
+			s:    &shape{geomType: geomPoint, points: []point{{25, 17}}},
+			want: []uint32{9, 50, 34},
+		},
+		{
+			s:    &shape{geomType: geomPoint, points: []point{{5, 7}, {3, 2}}},
+			want: []uint32{17, 10, 14, 3, 9},
+		},
+		{
+			s:    &shape{geomType: geomLineString, lines: [][]point{{{2, 2}, {2, 10}, {10, 10}}}},
+			want: []uint32{9, 4, 4, 18, 0, 16, 16, 0},
+		},
+		{
+			s:    &shape{geomType: geomLineString, lines: [][]point{{{2, 2}, {2, 10}, {10, 10}}, {{1, 1}, {3, 5}}}},
+			want: []uint32{9, 4, 4, 18, 0, 16, 16, 0, 9, 17, 17, 10, 4, 8},
+		},
+		{
+			s:    &shape{geomType: geomPolygon, polygons: [][][]point{{{{3, 6}, {8, 12}, {20, 34}}}}},
+			want: []uint32{9, 6, 12, 18, 10, 12, 24, 44, 15},
+		},
+		{
+			// Rings are reoriented and degenerate rings are dropped.
+			s:    &shape{geomType: geomPolygon, polygons: [][][]point{{{{20, 34}, {8, 12}, {3, 6}}, {{1, 1}, {1.2, 1.2}, {0.9, 1}}}}},
+			want: []uint32{9, 6, 12, 18, 10, 12, 24, 44, 15},
+		},
+		{
+			s: &shape{geomType: geomPolygon, polygons: [][][]point{{{{20, 34}, {8, 12}, {3, 6}}, {{20, 34}, {8, 12}, {3, 6}}}}},
+			want: []uint32{
+				9, 6, 12, 18, 10, 12, 24, 44, 15,
+				9, 0, 0, 18, 23, 43, 9, 11, 15,
+			},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			cmds := tc.s.commands()
+			assert.Equal(t, tc.want, cmds)
+			p := projection{scale: 1, extent: 4096}
+			g, err := decodeGeometry(tc.s.geomType, cmds, p)
+			assert.NoError(t, err)
+			s, err := newShape(g, p)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, s.commands())
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	id := TileID{Z: 9, X: 275, Y: 172}
+	layers := []*Layer{
+		{
+			Name: "places",
+			Features: []*geojson.Feature{
+				{
+					ID:         "42",
+					Geometry:   goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{13.8, 50.5}),
+					Properties: map[string]interface{}{"name": "Teplice", "population": 49731.0},
+				},
+				{
+					Geometry: goodgeo.NewMultiPoint(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{13.75, 50.45}, {13.85, 50.55}}),
+				},
+			},
+		},
+		{
+			Name: "roads",
+			Features: []*geojson.Feature{
+				{
+					Geometry: goodgeo.NewLineString(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{13.7, 50.4}, {13.8, 50.5}, {13.9, 50.5}}),
+				},
+			},
+		},
+		{
+			Name: "areas",
+			Features: []*geojson.Feature{
+				{
+					Geometry: goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{
+						{{13.7, 50.4}, {13.9, 50.4}, {13.9, 50.6}, {13.7, 50.6}, {13.7, 50.4}},
+						{{13.75, 50.45}, {13.75, 50.55}, {13.85, 50.55}, {13.85, 50.45}, {13.75, 50.45}},
+					}),
+				},
+			},
+		},
+	}
+	data, err := Marshal(id, layers, WithTolerance(0))
+	assert.NoError(t, err)
+	got, err := Unmarshal(data, id)
+	assert.NoError(t, err)
+
+	assert.Equal(t, len(layers), len(got))
+	// A tile unit at zoom 9 and the default extent is about 1.7e-4 degrees
+	// of longitude.
+	const tolerance = 2e-4
+	for i, layer := range layers {
+		assert.Equal(t, layer.Name, got[i].Name)
+		assert.Equal(t, len(layer.Features), len(got[i].Features))
+		for j, f := range layer.Features {
+			g := got[i].Features[j].Geometry
+			assert.Equal(t, f.Geometry.Layout(), g.Layout())
+			assert.Equal(t, f.Geometry.Ends(), g.Ends())
+			assert.Equal(t, f.Geometry.Endss(), g.Endss())
+			assert.Equal(t, len(f.Geometry.FlatCoords()), len(g.FlatCoords()))
+			for k, v := range f.Geometry.FlatCoords() {
+				assert.True(t, math.Abs(v-g.FlatCoords()[k]) < tolerance, "%d %d %d: %v != %v", i, j, k, v, g.FlatCoords()[k])
+			}
+		}
+	}
+	assert.Equal(t, "42", got[0].Features[0].ID)
+	assert.Equal(t, map[string]interface{}{"name": "Teplice", "population": uint64(49731)}, got[0].Features[0].Properties)
+	assert.Equal(t, "", got[0].Features[1].ID)
+}
+
+func TestProperties(t *testing.T) {
+	id := TileID{}
+	pt := goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{0, 0})
+	props := map[string]interface{}{
+		"highway": "primary",
+		"lanes":   2,
+		"offset":  -3,
+		"speed":   12.5,
+		"width":   float32(2.5),
+		"oneway":  true,
+		"tags":    []string{"a", "b"},
+		"ref":     nil,
+	}
+	data, err := Marshal(id, []*Layer{
+		{
+			Name: "roads",
+			Features: []*geojson.Feature{
+				{Geometry: pt, Properties: props},
+				{Geometry: pt, Properties: props},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	// Keys and values are shared by the features.
+	assert.Equal(t, 1, bytes.Count(data, []byte("highway")))
+	assert.Equal(t, 1, bytes.Count(data, []byte("primary")))
+
+	layers, err := Unmarshal(data, id)
+	assert.NoError(t, err)
+	want := map[string]interface{}{
+		"highway": "primary",
+		"lanes":   uint64(2),
+		"offset":  int64(-3),
+		"speed":   12.5,
+		"width":   2.5,
+		"oneway":  true,
+		"tags":    `["a","b"]`,
+	}
+	for _, f := range layers[0].Features {
+		assert.Equal(t, want, f.Properties)
+	}
+}
+
+func TestMarshalDeterministic(t *testing.T) {
+	id := TileID{}
+	props := map[string]interface{}{"nan": math.NaN(), "other": math.NaN(), "float": float32(math.NaN())}
+	for i := range 20 {
+		props["k"+strconv.Itoa(i)] = i
+	}
+	layers := []*Layer{
+		{
+			Name: "points",
+			Features: []*geojson.Feature{
+				{Geometry: goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{0, 0}), Properties: props},
+				{Geometry: goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{1, 1}), Properties: props},
+			},
+		},
+	}
+	want, err := Marshal(id, layers)
+	assert.NoError(t, err)
+	for range 10 {
+		data, err := Marshal(id, layers)
+		assert.NoError(t, err)
+		assert.Equal(t, want, data)
+	}
+	// All NaNs of the same type share a value.
+	assert.Equal(t, 1, bytes.Count(want, le64(math.Float64bits(math.NaN()))))
+	assert.Equal(t, 1, bytes.Count(want, le32(math.Float32bits(float32(math.NaN())))))
+}
+
+func TestClip(t *testing.T) {
+	// The north-western tile of zoom 1 spans longitudes from -180 to 0.
+	id := TileID{Z: 1, X: 0, Y: 0}
+	line := goodgeo.NewLineString(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{-90, 45}, {90, 45}})
+	outside := goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{90, 45})
+	for i, tc := range []struct {
+		opts    []EncodeOption
+		wantLng float64
+	}{
+		{wantLng: 64.0 / 4096 * 180},
+		{opts: []EncodeOption{WithBuffer(0)}, wantLng: 0},
+		{opts: []EncodeOption{WithExtent(256), WithBuffer(64)}, wantLng: 45},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			data, err := Marshal(id, []*Layer{
+				{Name: "a", Features: []*geojson.Feature{{Geometry: line}, {Geometry: outside}}},
+				{Name: "b", Features: []*geojson.Feature{{Geometry: outside}}},
+			}, tc.opts...)
+			assert.NoError(t, err)
+			layers, err := Unmarshal(data, id)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, len(layers))
+			assert.Equal(t, 1, len(layers[0].Features))
+			coords := layers[0].Features[0].Geometry.(*goodgeo.LineString).Coords()
+			assert.Equal(t, 2, len(coords))
+			assert.True(t, math.Abs(coords[0][0]+90) < 1e-9)
+			assert.True(t, math.Abs(coords[1][0]-tc.wantLng) < 1e-9, "%v", coords[1][0])
+		})
+	}
+
+	// Polygons are clipped to the buffer around the tile.
+	polygon := goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{
+		{{-90, -45}, {90, -45}, {90, 45}, {-90, 45}, {-90, -45}},
+	})
+	data, err := Marshal(id, []*Layer{{Name: "a", Features: []*geojson.Feature{{Geometry: polygon}}}}, WithBuffer(0))
+	assert.NoError(t, err)
+	layers, err := Unmarshal(data, id)
+	assert.NoError(t, err)
+	b := layers[0].Features[0].Geometry.Bounds()
+	assert.True(t, math.Abs(b.Min(0)+90) < 1e-9 && math.Abs(b.Max(0)) < 1e-9)
+	assert.True(t, math.Abs(b.Min(1)) < 1e-9 && math.Abs(b.Max(1)-45) < 1e-2)
+}
+
+func TestSimplify(t *testing.T) {
+	// The middle vertex is about 1.5 tile units off the line at zoom 8 and
+	// about 6 tile units off at zoom 10.
+	line := goodgeo.NewLineString(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{0.001, 0.001}, {0.005, 0.0015}, {0.009, 0.001}})
+	layers := []*Layer{{Name: "a", Features: []*geojson.Feature{{Geometry: line}}}}
+	for i, tc := range []struct {
+		id   TileID
+		opts []EncodeOption
+		want int
+	}{
+		{id: TileID{Z: 8, X: 128, Y: 127}, want: 2},
+		{id: TileID{Z: 8, X: 128, Y: 127}, opts: []EncodeOption{WithTolerance(1)}, want: 3},
+		{id: TileID{Z: 10, X: 512, Y: 511}, want: 3},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			data, err := Marshal(tc.id, layers, tc.opts...)
+			assert.NoError(t, err)
+			got, err := Unmarshal(data, tc.id)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, len(got[0].Features[0].Geometry.FlatCoords())/2)
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, id := range []TileID{{Z: -1}, {Z: 1, X: 2}, {Z: 2, Y: -1}} {
+		_, err := Marshal(id, nil)
+		assert.IsError(t, err, ErrInvalidTileID)
+		_, err = Unmarshal(nil, id)
+		assert.IsError(t, err, ErrInvalidTileID)
+	}
+
+	_, err := Marshal(TileID{}, nil, WithExtent(0))
+	assert.IsError(t, err, ErrInvalidExtent)
+
+	ring := goodgeo.NewLinearRing(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{0, 0}, {1, 0}, {1, 1}, {0, 0}})
+	_, err = Marshal(TileID{}, []*Layer{{Name: "a", Features: []*geojson.Feature{{Geometry: ring}}}})
+	assert.IsError(t, err, goodgeo.UnsupportedTypeError{Value: ring})
+
+	pt := goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{0, 0})
+	data, err := Marshal(TileID{}, []*Layer{{Name: "a", Features: []*geojson.Feature{{Geometry: pt}}}})
+	assert.NoError(t, err)
+	for i := 1; i < len(data); i++ {
+		_, err := Unmarshal(data[:i], TileID{})
+		assert.IsError(t, err, ErrInvalidTile, "%d", i)
+	}
+	for _, cmds := range [][]uint32{{9, 1}, {18, 1, 1, 2, 2}, {15}, {11, 1, 1}} {
+		_, err := decodeGeometry(geomLineString, cmds, projection{scale: 1, extent: 4096})
+		assert.IsError(t, err, ErrInvalidTile)
+	}
+}
@@ -0,0 +1,139 @@
+package mvt
+
+import "encoding/binary"
+
+// Protocol buffer wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+func appendVarint(b []byte, v uint64) []byte {
+	return binary.AppendUvarint(b, v)
+}
+
+func appendTag(b []byte, field, wireType int) []byte {
+	return appendVarint(b, uint64(field<<3|wireType))
+}
+
+func appendVarintField(b []byte, field int, v uint64) []byte {
+	return appendVarint(appendTag(b, field, wireVarint), v)
+}
+
+func appendBytesField(b []byte, field int, v []byte) []byte {
+	b = appendVarint(appendTag(b, field, wireBytes), uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendPackedField(b []byte, field int, vs []uint32) []byte {
+	var packed []byte
+	for _, v := range vs {
+		packed = appendVarint(packed, uint64(v))
+	}
+	return appendBytesField(b, field, packed)
+}
+
+// A reader reads the fields of a protocol buffer message.
+type reader struct {
+	b []byte
+}
+
+// next returns the field number and wire type of the next field.
+func (r *reader) next() (field, wireType int, err error) {
+	v, err := r.varint()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(v >> 3), int(v & 7), nil
+}
+
+func (r *reader) varint() (uint64, error) {
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		return 0, ErrInvalidTile
+	}
+	r.b = r.b[n:]
+	return v, nil
+}
+
+func (r *reader) bytes() ([]byte, error) {
+	n, err := r.varint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(r.b)) {
+		return nil, ErrInvalidTile
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v, nil
+}
+
+func (r *reader) fixed32() (uint32, error) {
+	if len(r.b) < 4 {
+		return 0, ErrInvalidTile
+	}
+	v := binary.LittleEndian.Uint32(r.b)
+	r.b = r.b[4:]
+	return v, nil
+}
+
+func (r *reader) fixed64() (uint64, error) {
+	if len(r.b) < 8 {
+		return 0, ErrInvalidTile
+	}
+	v := binary.LittleEndian.Uint64(r.b)
+	r.b = r.b[8:]
+	return v, nil
+}
+
+// packed returns the values of a packed repeated field, or of a single
+// value if the field is not packed.
+func (r *reader) packed(wireType int) ([]uint32, error) {
+	if wireType == wireVarint {
+		v, err := r.varint()
+		return []uint32{uint32(v)}, err
+	}
+	b, err := r.bytes()
+	if err != nil {
+		return nil, err
+	}
+	var vs []uint32
+	pr := reader{b: b}
+	for len(pr.b) > 0 {
+		v, err := pr.varint()
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, uint32(v))
+	}
+	return vs, nil
+}
+
+// skip skips the value of a field of wireType.
+func (r *reader) skip(wireType int) error {
+	var err error
+	switch wireType {
+	case wireVarint:
+		_, err = r.varint()
+	case wireFixed64:
+		_, err = r.fixed64()
+	case wireBytes:
+		_, err = r.bytes()
+	case wireFixed32:
+		_, err = r.fixed32()
+	default:
+		err = ErrInvalidTile
+	}
+	return err
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1 ^ v>>63)
+}
+
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
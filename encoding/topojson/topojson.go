@@ -0,0 +1,623 @@
+// Package topojson implements TopoJSON encoding and decoding, see
+// https://github.com/topojson/topojson-specification.
+//
+// A topology stores the lines and rings of its geometries as arcs that are
+// shared between the geometries that have them in common, such as the
+// borders of neighbouring countries. Encoding quantizes the coordinates to
+// a grid, cuts the lines and rings into arcs where they part ways, stores
+// each arc once and delta-encodes it. Decoding stitches the arcs back into
+// geometries.
+package topojson
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/matoous/goodgeo"
+	"github.com/matoous/goodgeo/encoding/geojson"
+	"github.com/matoous/goodgeo/internal/arcs"
+)
+
+// DefaultQuantization is the number of distinct values that coordinates are
+// quantized to along each axis unless another one is given.
+const DefaultQuantization = 100000
+
+// ErrInvalidTopology is returned when a topology refers to arcs that it
+// does not have.
+var ErrInvalidTopology = errors.New("topojson: invalid topology")
+
+// ErrUnsupportedType is returned when the type is unsupported.
+type ErrUnsupportedType string
+
+func (e ErrUnsupportedType) Error() string {
+	return "topojson: unsupported type: " + string(e)
+}
+
+// A Topology is a TopoJSON topology.
+type Topology struct {
+	Type      string               `json:"type"`
+	BBox      []float64            `json:"bbox,omitempty"`
+	Transform *Transform           `json:"transform,omitempty"`
+	Objects   map[string]*Geometry `json:"objects"`
+	Arcs      [][][]float64        `json:"arcs"`
+}
+
+// A Transform maps the quantized positions of a topology back to
+// coordinates.
+type Transform struct {
+	Scale     [2]float64 `json:"scale"`
+	Translate [2]float64 `json:"translate"`
+}
+
+// A Geometry is a TopoJSON geometry object. Points and multipoints have
+// coordinates, lines and polygons refer to arcs of their topology and
+// geometry collections have geometries. A Geometry without a type is a null
+// geometry.
+type Geometry struct {
+	Type        string          `json:"type"`
+	ID          any             `json:"id,omitempty"`
+	Properties  map[string]any  `json:"properties,omitempty"`
+	BBox        []float64       `json:"bbox,omitempty"`
+	Coordinates json.RawMessage `json:"coordinates,omitempty"`
+	Arcs        json.RawMessage `json:"arcs,omitempty"`
+	Geometries  []*Geometry     `json:"geometries,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON.
+func (g *Geometry) MarshalJSON() ([]byte, error) {
+	type geometry Geometry
+	var geometryType any
+	if g.Type != "" {
+		geometryType = g.Type
+	}
+	return json.Marshal(struct {
+		Type any `json:"type"`
+		*geometry
+	}{
+		Type:     geometryType,
+		geometry: (*geometry)(g),
+	})
+}
+
+// An encoder holds the options of [Encode].
+type encoder struct {
+	quantization int
+}
+
+// An EncodeOption sets an option of [Encode].
+type EncodeOption func(*encoder)
+
+// WithQuantization sets the number of distinct values that coordinates are
+// quantized to along each axis, [DefaultQuantization] by default. A
+// quantization of less than 2 disables quantization and delta encoding, the
+// arcs then hold the coordinates as they are.
+func WithQuantization(quantization int) EncodeOption {
+	return func(e *encoder) {
+		e.quantization = quantization
+	}
+}
+
+// Encode encodes the feature collections of objects as the objects of a
+// topology, each a geometry collection of their features. Only x and y are
+// encoded. Rings that are not cut into several arcs start at their lowest
+// vertex, and consecutive vertices that quantize to the same position are
+// merged.
+func Encode(objects map[string]*geojson.FeatureCollection, opts ...EncodeOption) (*Topology, error) {
+	e := &encoder{quantization: DefaultQuantization}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	names := make([]string, 0, len(objects))
+	for name := range objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bounds := goodgeo.NewBounds(goodgeo.XY)
+	for _, name := range names {
+		for _, f := range objects[name].Features {
+			extendBounds(bounds, f.Geometry)
+		}
+	}
+
+	t := &Topology{
+		Type:    "Topology",
+		Objects: make(map[string]*Geometry, len(objects)),
+		Arcs:    [][][]float64{},
+	}
+	b := &builder{q: identity}
+	if !bounds.IsEmpty() {
+		t.BBox = []float64{bounds.Min(0), bounds.Min(1), bounds.Max(0), bounds.Max(1)}
+		if e.quantization > 1 {
+			q := newQuantizer(bounds, e.quantization)
+			b.q = q.quantize
+			t.Transform = &Transform{Scale: q.scale, Translate: q.translate}
+		}
+	}
+
+	for _, name := range names {
+		collection := &Geometry{Type: "GeometryCollection", Geometries: []*Geometry{}}
+		for _, f := range objects[name].Features {
+			g, err := b.geometry(f.Geometry)
+			if err != nil {
+				return nil, err
+			}
+			if f.ID != "" {
+				g.ID = f.ID
+			}
+			g.Properties = f.Properties
+			collection.Geometries = append(collection.Geometries, g)
+		}
+		t.Objects[name] = collection
+	}
+
+	arcs, err := b.finish()
+	if err != nil {
+		return nil, err
+	}
+	for _, arc := range arcs {
+		if t.Transform != nil {
+			arc = deltaEncode(arc)
+		}
+		positions := make([][]float64, len(arc))
+		for i, p := range arc {
+			positions[i] = []float64{p[0], p[1]}
+		}
+		t.Arcs = append(t.Arcs, positions)
+	}
+	return t, nil
+}
+
+// Marshal encodes objects as a topology, see [Encode], and marshals it.
+func Marshal(objects map[string]*geojson.FeatureCollection, opts ...EncodeOption) ([]byte, error) {
+	t, err := Encode(objects, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(t)
+}
+
+// Unmarshal unmarshals a topology and decodes it, see [Topology.Decode].
+func Unmarshal(data []byte) (map[string]*geojson.FeatureCollection, error) {
+	var t Topology
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	return t.Decode()
+}
+
+// Decode decodes the objects of t to feature collections. The geometries of
+// an object that is a geometry collection become its features, any other
+// object becomes a single feature. Geometries are decoded with the XY
+// layout, null geometries to features without a geometry.
+func (t *Topology) Decode() (map[string]*geojson.FeatureCollection, error) {
+	if t.Type != "Topology" {
+		return nil, ErrUnsupportedType(t.Type)
+	}
+	d := &decoder{t: t, arcs: make([][]goodgeo.Coord, len(t.Arcs))}
+	for i, arc := range t.Arcs {
+		var x, y float64
+		d.arcs[i] = make([]goodgeo.Coord, len(arc))
+		for j, position := range arc {
+			if len(position) < 2 {
+				return nil, geojson.ErrDimensionalityTooLow(len(position))
+			}
+			if t.Transform == nil {
+				x, y = position[0], position[1]
+			} else {
+				x, y = x+position[0], y+position[1]
+			}
+			d.arcs[i][j] = d.transform(x, y)
+		}
+	}
+
+	objects := make(map[string]*geojson.FeatureCollection, len(t.Objects))
+	for name, object := range t.Objects {
+		var members []*Geometry
+		switch {
+		case object == nil:
+		case object.Type == "GeometryCollection":
+			members = object.Geometries
+		default:
+			members = []*Geometry{object}
+		}
+		fc := &geojson.FeatureCollection{Features: make([]*geojson.Feature, 0, len(members))}
+		for _, member := range members {
+			f, err := d.feature(member)
+			if err != nil {
+				return nil, err
+			}
+			fc.Features = append(fc.Features, f)
+		}
+		objects[name] = fc
+	}
+	return objects, nil
+}
+
+// extendBounds extends bounds with g and, if it is a geometry collection,
+// its members.
+func extendBounds(bounds *goodgeo.Bounds, g goodgeo.T) {
+	switch g := g.(type) {
+	case nil:
+	case *goodgeo.GeometryCollection:
+		for _, g := range g.Geoms() {
+			extendBounds(bounds, g)
+		}
+	default:
+		if !g.Empty() {
+			bounds.Extend(g)
+		}
+	}
+}
+
+// A point is a position in a topology, quantized or not.
+type point = arcs.Point
+
+// A quantizer maps coordinates within bounds to a grid of integers.
+type quantizer struct {
+	scale, translate [2]float64
+}
+
+func newQuantizer(bounds *goodgeo.Bounds, quantization int) quantizer {
+	q := quantizer{translate: [2]float64{bounds.Min(0), bounds.Min(1)}}
+	for i := range 2 {
+		q.scale[i] = 1
+		if size := bounds.Max(i) - bounds.Min(i); size > 0 {
+			q.scale[i] = size / float64(quantization-1)
+		}
+	}
+	return q
+}
+
+func (q quantizer) quantize(c goodgeo.Coord) point {
+	return point{
+		math.Round((c[0] - q.translate[0]) / q.scale[0]),
+		math.Round((c[1] - q.translate[1]) / q.scale[1]),
+	}
+}
+
+func identity(c goodgeo.Coord) point {
+	return point{c[0], c[1]}
+}
+
+// deltaEncode returns the first point of arc followed by the differences
+// between consecutive points.
+func deltaEncode(arc []point) []point {
+	encoded := make([]point, len(arc))
+	var prev point
+	for i, p := range arc {
+		encoded[i] = point{p[0] - prev[0], p[1] - prev[1]}
+		prev = p
+	}
+	return encoded
+}
+
+// A builder collects the lines and rings of geometries and cuts them into
+// arcs.
+type builder struct {
+	q     func(goodgeo.Coord) point
+	paths []arcs.Path
+	// fills set the arcs of the geometries once the arcs are known.
+	fills []func(arcs [][]int) error
+}
+
+// geometry returns g as a topology geometry whose arcs are set by finish.
+func (b *builder) geometry(g goodgeo.T) (*Geometry, error) {
+	switch g := g.(type) {
+	case nil:
+		return &Geometry{}, nil
+	case *goodgeo.Point:
+		if g.Empty() {
+			return &Geometry{}, nil
+		}
+		coordinates, err := json.Marshal(b.q(g.Coords()))
+		if err != nil {
+			return nil, err
+		}
+		return &Geometry{Type: "Point", Coordinates: coordinates}, nil
+	case *goodgeo.MultiPoint:
+		points := []point{}
+		for _, c := range g.Coords() {
+			if len(c) > 0 {
+				points = append(points, b.q(c))
+			}
+		}
+		coordinates, err := json.Marshal(points)
+		if err != nil {
+			return nil, err
+		}
+		return &Geometry{Type: "MultiPoint", Coordinates: coordinates}, nil
+	case *goodgeo.LineString:
+		result := &Geometry{Type: "LineString"}
+		line := b.path(g.Coords(), false)
+		b.fill(result, func(arcs [][]int) any { return arcs[line] })
+		return result, nil
+	case *goodgeo.MultiLineString:
+		result := &Geometry{Type: "MultiLineString"}
+		lines := b.paths2(g.Coords(), false)
+		b.fill(result, func(arcs [][]int) any { return arcs2(arcs, lines) })
+		return result, nil
+	case *goodgeo.Polygon:
+		result := &Geometry{Type: "Polygon"}
+		rings := b.paths2(g.Coords(), true)
+		b.fill(result, func(arcs [][]int) any { return arcs2(arcs, rings) })
+		return result, nil
+	case *goodgeo.MultiPolygon:
+		result := &Geometry{Type: "MultiPolygon"}
+		polygons := make([][]int, 0, g.NumPolygons())
+		for _, polygon := range g.Coords() {
+			polygons = append(polygons, b.paths2(polygon, true))
+		}
+		b.fill(result, func(arcs [][]int) any {
+			polygonArcs := make([][][]int, len(polygons))
+			for i, rings := range polygons {
+				polygonArcs[i] = arcs2(arcs, rings)
+			}
+			return polygonArcs
+		})
+		return result, nil
+	case *goodgeo.GeometryCollection:
+		result := &Geometry{Type: "GeometryCollection", Geometries: []*Geometry{}}
+		for _, g := range g.Geoms() {
+			member, err := b.geometry(g)
+			if err != nil {
+				return nil, err
+			}
+			result.Geometries = append(result.Geometries, member)
+		}
+		return result, nil
+	default:
+		return nil, goodgeo.UnsupportedTypeError{Value: g}
+	}
+}
+
+// fill registers the function that returns the arcs of g.
+func (b *builder) fill(g *Geometry, arcsOf func(arcs [][]int) any) {
+	b.fills = append(b.fills, func(arcs [][]int) error {
+		data, err := json.Marshal(arcsOf(arcs))
+		if err != nil {
+			return err
+		}
+		g.Arcs = data
+		return nil
+	})
+}
+
+// path adds the quantized coords as a path and returns its index. Lines keep
+// at least two points.
+func (b *builder) path(coords []goodgeo.Coord, closed bool) int {
+	var points []point
+	for _, c := range coords {
+		p := b.q(c)
+		if len(points) == 0 || p != points[len(points)-1] {
+			points = append(points, p)
+		}
+	}
+	switch {
+	case closed && len(points) > 0 && points[0] != points[len(points)-1]:
+		points = append(points, points[0])
+	case !closed && len(points) == 1:
+		points = append(points, points[0])
+	}
+	b.paths = append(b.paths, arcs.Path{Points: points, Closed: closed})
+	return len(b.paths) - 1
+}
+
+func (b *builder) paths2(coords [][]goodgeo.Coord, closed bool) []int {
+	paths := make([]int, len(coords))
+	for i, c := range coords {
+		paths[i] = b.path(c, closed)
+	}
+	return paths
+}
+
+func arcs2(arcs [][]int, paths []int) [][]int {
+	result := make([][]int, 0, len(paths))
+	for _, p := range paths {
+		if len(arcs[p]) > 0 {
+			result = append(result, arcs[p])
+		}
+	}
+	return result
+}
+
+// finish cuts the paths into arcs, sets the arcs of the geometries and
+// returns the distinct arcs.
+func (b *builder) finish() ([][]point, error) {
+	var (
+		junctions = arcs.Junctions(b.paths)
+		set       = arcs.NewSet()
+		distinct  [][]point
+		pathArcs  = make([][]int, len(b.paths))
+	)
+	for i, p := range b.paths {
+		pathArcs[i] = []int{}
+		for _, indices := range arcs.Cut(p, junctions) {
+			arc := make([]point, len(indices))
+			for j, k := range indices {
+				arc[j] = p.Points[k]
+			}
+			index, added := set.Add(arc)
+			if added {
+				distinct = append(distinct, arc)
+			}
+			pathArcs[i] = append(pathArcs[i], index)
+		}
+	}
+	for _, fill := range b.fills {
+		if err := fill(pathArcs); err != nil {
+			return nil, err
+		}
+	}
+	return distinct, nil
+}
+
+// A decoder decodes the objects of a topology with its decoded arcs.
+type decoder struct {
+	t    *Topology
+	arcs [][]goodgeo.Coord
+}
+
+func (d *decoder) transform(x, y float64) goodgeo.Coord {
+	if tr := d.t.Transform; tr != nil {
+		return goodgeo.Coord{x*tr.Scale[0] + tr.Translate[0], y*tr.Scale[1] + tr.Translate[1]}
+	}
+	return goodgeo.Coord{x, y}
+}
+
+func (d *decoder) position(position []float64) (goodgeo.Coord, error) {
+	if len(position) < 2 {
+		return nil, geojson.ErrDimensionalityTooLow(len(position))
+	}
+	return d.transform(position[0], position[1]), nil
+}
+
+func (d *decoder) feature(g *Geometry) (*geojson.Feature, error) {
+	if g == nil {
+		return &geojson.Feature{}, nil
+	}
+	f := &geojson.Feature{Properties: g.Properties}
+	switch id := g.ID.(type) {
+	case nil:
+	case string:
+		f.ID = id
+	case float64:
+		f.ID = strconv.FormatFloat(id, 'f', -1, 64)
+	default:
+		return nil, &json.InvalidUnmarshalError{Type: reflect.TypeOf(g.ID)}
+	}
+	var err error
+	f.Geometry, err = d.geometry(g)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (d *decoder) geometry(g *Geometry) (goodgeo.T, error) {
+	if g == nil {
+		return nil, nil //nolint:nilnil
+	}
+	switch g.Type {
+	case "":
+		return nil, nil //nolint:nilnil
+	case "Point":
+		var position []float64
+		if err := json.Unmarshal(g.Coordinates, &position); err != nil {
+			return nil, err
+		}
+		c, err := d.position(position)
+		if err != nil {
+			return nil, err
+		}
+		return goodgeo.NewPoint(goodgeo.XY).SetCoords(c)
+	case "MultiPoint":
+		var positions [][]float64
+		if err := json.Unmarshal(g.Coordinates, &positions); err != nil {
+			return nil, err
+		}
+		coords := make([]goodgeo.Coord, len(positions))
+		for i, position := range positions {
+			var err error
+			if coords[i], err = d.position(position); err != nil {
+				return nil, err
+			}
+		}
+		return goodgeo.NewMultiPoint(goodgeo.XY).SetCoords(coords)
+	case "LineString":
+		var arcs []int
+		if err := json.Unmarshal(g.Arcs, &arcs); err != nil {
+			return nil, err
+		}
+		coords, err := d.stitch(arcs)
+		if err != nil {
+			return nil, err
+		}
+		return goodgeo.NewLineString(goodgeo.XY).SetCoords(coords)
+	case "MultiLineString", "Polygon":
+		var arcs [][]int
+		if err := json.Unmarshal(g.Arcs, &arcs); err != nil {
+			return nil, err
+		}
+		coords, err := d.stitch2(arcs)
+		if err != nil {
+			return nil, err
+		}
+		if g.Type == "Polygon" {
+			return goodgeo.NewPolygon(goodgeo.XY).SetCoords(coords)
+		}
+		return goodgeo.NewMultiLineString(goodgeo.XY).SetCoords(coords)
+	case "MultiPolygon":
+		var arcs [][][]int
+		if err := json.Unmarshal(g.Arcs, &arcs); err != nil {
+			return nil, err
+		}
+		coords := make([][][]goodgeo.Coord, len(arcs))
+		for i, polygon := range arcs {
+			var err error
+			if coords[i], err = d.stitch2(polygon); err != nil {
+				return nil, err
+			}
+		}
+		return goodgeo.NewMultiPolygon(goodgeo.XY).SetCoords(coords)
+	case "GeometryCollection":
+		gc := goodgeo.NewGeometryCollection()
+		for _, member := range g.Geometries {
+			m, err := d.geometry(member)
+			if err != nil {
+				return nil, err
+			}
+			if m == nil {
+				continue
+			}
+			if err := gc.Push(m); err != nil {
+				return nil, err
+			}
+		}
+		return gc, nil
+	default:
+		return nil, ErrUnsupportedType(g.Type)
+	}
+}
+
+// stitch joins the arcs into a line, dropping the first point of each arc
+// after the first.
+func (d *decoder) stitch(arcs []int) ([]goodgeo.Coord, error) {
+	var coords []goodgeo.Coord
+	for i, index := range arcs {
+		reversed := index < 0
+		if reversed {
+			index = ^index
+		}
+		if index >= len(d.arcs) {
+			return nil, ErrInvalidTopology
+		}
+		arc := d.arcs[index]
+		if reversed {
+			arc = slices.Clone(arc)
+			slices.Reverse(arc)
+		}
+		if i > 0 && len(arc) > 0 {
+			arc = arc[1:]
+		}
+		coords = append(coords, arc...)
+	}
+	return coords, nil
+}
+
+func (d *decoder) stitch2(arcs [][]int) ([][]goodgeo.Coord, error) {
+	coords := make([][]goodgeo.Coord, len(arcs))
+	for i, line := range arcs {
+		var err error
+		if coords[i], err = d.stitch(line); err != nil {
+			return nil, err
+		}
+	}
+	return coords, nil
+}
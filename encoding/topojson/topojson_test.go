@@ -0,0 +1,128 @@
+package topojson
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/matoous/goodgeo"
+	"github.com/matoous/goodgeo/encoding/geojson"
+)
+
+func TestMarshal(t *testing.T) {
+	// The squares share their border, which is stored once.
+	objects := map[string]*geojson.FeatureCollection{
+		"districts": {
+			Features: []*geojson.Feature{
+				{
+					ID: "left",
+					Geometry: goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{
+						{{1, 0}, {1, 1}, {0, 1}, {0, 0}, {1, 0}},
+					}),
+					Properties: map[string]any{"name": "Left"},
+				},
+				{
+					Geometry: goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{
+						{{1, 1}, {1, 0}, {2, 0}, {2, 1}, {1, 1}},
+					}),
+				},
+			},
+		},
+	}
+	data, err := Marshal(objects, WithQuantization(3))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"Topology","bbox":[0,0,2,1],"transform":{"scale":[1,0.5],"translate":[0,0]},`+
+		`"objects":{"districts":{"type":"GeometryCollection","geometries":[`+
+		`{"type":"Polygon","id":"left","properties":{"name":"Left"},"arcs":[[0,1]]},`+
+		`{"type":"Polygon","arcs":[[-1,2]]}]}},`+
+		`"arcs":[[[1,0],[0,2]],[[1,2],[-1,0],[0,-2],[1,0]],[[1,0],[1,0],[0,2],[-1,0]]]}`, string(data))
+
+	got, err := Unmarshal(data)
+	assert.NoError(t, err)
+	assert.Equal(t, objects, got)
+}
+
+func TestRoundTrip(t *testing.T) {
+	objects := map[string]*geojson.FeatureCollection{
+		"roads": {
+			Features: []*geojson.Feature{
+				{Geometry: goodgeo.NewLineString(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{0, 0}, {1, 0}, {2, 0}, {3, 1}})},
+				{Geometry: goodgeo.NewMultiLineString(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{
+					{{1, 1}, {1, 0}, {2, 0}, {2, -1}},
+					{{0.5, 0.5}, {0.75, 0.25}},
+				})},
+			},
+		},
+		"places": {
+			Features: []*geojson.Feature{
+				{
+					ID:         "7",
+					Geometry:   goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{0.25, 0.75}),
+					Properties: map[string]any{"population": 1200.0},
+				},
+				{Geometry: goodgeo.NewMultiPoint(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{0.1, 0.2}, {0.3, 0.4}})},
+				{Properties: map[string]any{"name": "nowhere"}},
+			},
+		},
+		"areas": {
+			Features: []*geojson.Feature{
+				{Geometry: goodgeo.NewMultiPolygon(goodgeo.XY).MustSetCoords([][][]goodgeo.Coord{
+					{
+						{{0, 0}, {3, 0}, {3, 3}, {0, 3}, {0, 0}},
+						{{1, 1}, {1, 2}, {2, 2}, {2, 1}, {1, 1}},
+					},
+					{{{1, 1}, {2, 1}, {2, 2}, {1, 2}, {1, 1}}},
+				})},
+				{Geometry: goodgeo.NewGeometryCollection().MustPush(
+					goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{5, 5}),
+					goodgeo.NewLineString(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{5, 5}, {6, 6}}),
+				)},
+			},
+		},
+	}
+
+	topology, err := Encode(objects, WithQuantization(0))
+	assert.NoError(t, err)
+	assert.Zero(t, topology.Transform)
+	// The roads share a stretch, the hole and the island share their ring.
+	assert.Equal(t, 5+1+2+1, len(topology.Arcs))
+	got, err := topology.Decode()
+	assert.NoError(t, err)
+	assert.Equal(t, objects, got)
+}
+
+func TestDecode(t *testing.T) {
+	got, err := Unmarshal([]byte(`{
+		"type": "Topology",
+		"transform": {"scale": [0.5, 0.25], "translate": [10, 20]},
+		"objects": {
+			"border": {"type": "LineString", "id": 3, "arcs": [0, -2]},
+			"empty": {"type": "GeometryCollection", "geometries": [{"type": null}]}
+		},
+		"arcs": [[[0, 0], [2, 4]], [[4, 4], [-2, 0]]]
+	}`))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*geojson.FeatureCollection{
+		"border": {Features: []*geojson.Feature{{
+			ID:       "3",
+			Geometry: goodgeo.NewLineString(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{10, 20}, {11, 21}, {12, 21}}),
+		}}},
+		"empty": {Features: []*geojson.Feature{{}}},
+	}, got)
+}
+
+func TestErrors(t *testing.T) {
+	ring := goodgeo.NewLinearRing(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{0, 0}, {1, 0}, {1, 1}, {0, 0}})
+	_, err := Encode(map[string]*geojson.FeatureCollection{"a": {Features: []*geojson.Feature{{Geometry: ring}}}})
+	assert.IsError(t, err, goodgeo.UnsupportedTypeError{Value: ring})
+
+	for data, want := range map[string]error{
+		`{"type": "FeatureCollection"}`:                                                                            ErrUnsupportedType("FeatureCollection"),
+		`{"type": "Topology", "objects": {"a": {"type": "Circle"}}, "arcs": []}`:                                   ErrUnsupportedType("Circle"),
+		`{"type": "Topology", "objects": {"a": {"type": "LineString", "arcs": [1]}}, "arcs": [[[0, 0], [1, 1]]]}`:  ErrInvalidTopology,
+		`{"type": "Topology", "objects": {"a": {"type": "LineString", "arcs": [-3]}}, "arcs": [[[0, 0], [1, 1]]]}`: ErrInvalidTopology,
+	} {
+		_, err := Unmarshal([]byte(data))
+		assert.IsError(t, err, want, data)
+	}
+}
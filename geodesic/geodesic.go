@@ -0,0 +1,196 @@
+// Package geodesic solves the direct and inverse geodesic problems on an
+// ellipsoid of revolution. The direct problem is solved with [Vincenty's
+// formulae] and the inverse problem with the method from "Algorithms for
+// geodesics" by Karney, J. Geodesy 87, 43–55 (2013), which, unlike
+// Vincenty's, converges for nearly antipodal points.
+//
+// The solutions are accurate to within a millimeter on the Earth.
+//
+// [Vincenty's formulae]: https://en.wikipedia.org/wiki/Vincenty%27s_formulae
+package geodesic
+
+import (
+	"math"
+	"sync"
+
+	"github.com/matoous/goodgeo"
+	"github.com/matoous/goodgeo/internal/geographiclib"
+)
+
+const (
+	// tolerance is the change in arc length, in radians, at which the
+	// iterations stop. It corresponds to roughly 0.06 mm.
+	tolerance     = 1e-12
+	maxIterations = 200
+)
+
+// An Ellipsoid is an ellipsoid of revolution.
+type Ellipsoid struct {
+	// A is the equatorial radius (semi-major axis) in meters.
+	A float64
+	// F is the flattening.
+	F float64
+}
+
+// WGS84 is the [WGS84](https://apps.dtic.mil/sti/citations/ADA280358)
+// reference ellipsoid.
+var WGS84 = NewEllipsoid(goodgeo.WGS84SemiMajorAxis, goodgeo.WGS84Flattening)
+
+// solvers caches the solvers of the inverse problem by ellipsoid, as their
+// series coefficients are costly to compute for every pair of points.
+var (
+	wgs84Solver = geographiclib.New(WGS84.A, WGS84.F)
+	solvers     sync.Map
+)
+
+// Ellipsoid implements goodgeo.DistanceModel.
+var _ goodgeo.DistanceModel = Ellipsoid{}
+
+// NewEllipsoid returns a new Ellipsoid with equatorial radius a in meters and
+// flattening f.
+func NewEllipsoid(a, f float64) Ellipsoid {
+	return Ellipsoid{A: a, F: f}
+}
+
+// solver returns the solver of the inverse problem on e.
+func (e Ellipsoid) solver() *geographiclib.Geodesic {
+	if e == WGS84 {
+		return wgs84Solver
+	}
+	if g, ok := solvers.Load(e); ok {
+		return g.(*geographiclib.Geodesic)
+	}
+	g, _ := solvers.LoadOrStore(e, geographiclib.New(e.A, e.F))
+	return g.(*geographiclib.Geodesic)
+}
+
+// B returns the polar radius (semi-minor axis) of e in meters.
+func (e Ellipsoid) B() float64 {
+	return e.A * (1 - e.F)
+}
+
+// InverseResult is the solution of the inverse geodesic problem.
+type InverseResult struct {
+	// Distance is the length of the geodesic.
+	Distance goodgeo.Meters
+	// InitialBearing is the bearing at the start of the geodesic.
+	InitialBearing goodgeo.Degrees
+	// FinalBearing is the bearing at the end of the geodesic.
+	FinalBearing goodgeo.Degrees
+}
+
+// Inverse returns the shortest geodesic between from and to. Bearings are
+// between -180 and 180 degrees (positive clockwise) and zero for coincident
+// points.
+func (e Ellipsoid) Inverse(from, to goodgeo.Coord) InverseResult {
+	s12, azi1, azi2, _ := e.solver().Inverse(from[1], from[0], to[1], to[0])
+	if s12 == 0 {
+		return InverseResult{}
+	}
+	return InverseResult{
+		Distance:       goodgeo.Meters(s12),
+		InitialBearing: goodgeo.Degrees(azi1),
+		FinalBearing:   goodgeo.Degrees(azi2),
+	}
+}
+
+// DirectResult is the solution of the direct geodesic problem.
+type DirectResult struct {
+	// Coord is the end of the geodesic. Ordinates other than X and Y are
+	// copied from the origin.
+	Coord goodgeo.Coord
+	// FinalBearing is the bearing at the end of the geodesic.
+	FinalBearing goodgeo.Degrees
+}
+
+// Direct returns the end of the geodesic that starts at origin with the given
+// initial bearing and length.
+func (e Ellipsoid) Direct(origin goodgeo.Coord, distance goodgeo.Meters, bearing goodgeo.Degrees) DirectResult {
+	b := e.B()
+	s := float64(distance)
+	sinAlpha1, cosAlpha1 := math.Sincos(radians(float64(bearing)))
+	tanU1 := (1 - e.F) * math.Tan(radians(origin[1]))
+	cosU1 := 1 / math.Sqrt(1+tanU1*tanU1)
+	sinU1 := tanU1 * cosU1
+	sigma1 := math.Atan2(tanU1, cosAlpha1)
+	sinAlpha := cosU1 * sinAlpha1
+	cosSqAlpha := 1 - sinAlpha*sinAlpha
+	uSq := cosSqAlpha * (e.A*e.A - b*b) / (b * b)
+	bigA, bigB := seriesCoefficients(uSq)
+
+	var (
+		sigma                          = s / (b * bigA)
+		sinSigma, cosSigma, cos2SigmaM float64
+	)
+	for range maxIterations {
+		cos2SigmaM = math.Cos(2*sigma1 + sigma)
+		sinSigma, cosSigma = math.Sincos(sigma)
+		prev := sigma
+		sigma = s/(b*bigA) + sigmaCorrection(bigB, sinSigma, cosSigma, cos2SigmaM)
+		if math.Abs(sigma-prev) < tolerance {
+			break
+		}
+	}
+	cos2SigmaM = math.Cos(2*sigma1 + sigma)
+	sinSigma, cosSigma = math.Sincos(sigma)
+
+	x := sinU1*sinSigma - cosU1*cosSigma*cosAlpha1
+	lat := math.Atan2(sinU1*cosSigma+cosU1*sinSigma*cosAlpha1, (1-e.F)*math.Sqrt(sinAlpha*sinAlpha+x*x))
+	lambda := math.Atan2(sinSigma*sinAlpha1, cosU1*cosSigma-sinU1*sinSigma*cosAlpha1)
+	c := e.F / 16 * cosSqAlpha * (4 + e.F*(4-3*cosSqAlpha))
+	l := lambda - (1-c)*e.F*sinAlpha*
+		(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+
+	coord := origin.Clone()
+	coord[0] = normalizeLongitude(origin[0] + float64(degrees(l)))
+	coord[1] = float64(degrees(lat))
+	return DirectResult{
+		Coord:        coord,
+		FinalBearing: degrees(math.Atan2(sinAlpha, -x)),
+	}
+}
+
+// Distance implements goodgeo.DistanceModel.
+func (e Ellipsoid) Distance(from, to goodgeo.Coord) goodgeo.Meters {
+	return e.Inverse(from, to).Distance
+}
+
+// Bearing implements goodgeo.DistanceModel.
+func (e Ellipsoid) Bearing(from, to goodgeo.Coord) goodgeo.Degrees {
+	return e.Inverse(from, to).InitialBearing
+}
+
+// Destination implements goodgeo.DistanceModel.
+func (e Ellipsoid) Destination(origin goodgeo.Coord, distance goodgeo.Meters, bearing goodgeo.Degrees) goodgeo.Coord {
+	return e.Direct(origin, distance, bearing).Coord
+}
+
+// seriesCoefficients returns Vincenty's A and B coefficients for u².
+func seriesCoefficients(uSq float64) (float64, float64) {
+	a := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+	b := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+	return a, b
+}
+
+// sigmaCorrection returns Δσ.
+func sigmaCorrection(b, sinSigma, cosSigma, cos2SigmaM float64) float64 {
+	return b * sinSigma * (cos2SigmaM + b/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+		b/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) goodgeo.Degrees {
+	return goodgeo.Degrees(rad * 180 / math.Pi)
+}
+
+// normalizeLongitude wraps lon into the range [-180, 180].
+func normalizeLongitude(lon float64) float64 {
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return lon - 180
+}
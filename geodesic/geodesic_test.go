@@ -0,0 +1,101 @@
+package geodesic
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/matoous/goodgeo"
+)
+
+func dms(d, m, s float64) float64 {
+	return math.Copysign(math.Abs(d)+m/60+s/3600, d)
+}
+
+var (
+	// Flinders Peak and Buninyong, the worked example of the Geoscience
+	// Australia geodetic calculations.
+	flindersPeak = goodgeo.Coord{dms(144, 25, 29.52440), dms(-37, 57, 3.72030)}
+	buninyong    = goodgeo.Coord{dms(143, 55, 35.38390), dms(-37, 39, 10.15610)}
+)
+
+func TestInverse(t *testing.T) {
+	got := WGS84.Inverse(flindersPeak, buninyong)
+	assert.True(t, math.Abs(float64(got.Distance)-54972.271) < 1e-3, "distance %v", got.Distance)
+	assert.True(t, math.Abs(float64(got.InitialBearing)-(dms(306, 52, 5.37)-360)) < 1e-5, "initial bearing %v", got.InitialBearing)
+	assert.True(t, math.Abs(float64(got.FinalBearing)-(dms(307, 10, 25.07)-360)) < 1e-5, "final bearing %v", got.FinalBearing)
+}
+
+func TestInverseCoincident(t *testing.T) {
+	got := WGS84.Inverse(flindersPeak, flindersPeak)
+	assert.Equal(t, InverseResult{}, got)
+}
+
+func TestInverseAntipodal(t *testing.T) {
+	// Wellington to Salamanca, Vincenty's inverse method does not converge.
+	wellington, salamanca := goodgeo.Coord{174.81, -41.32}, goodgeo.Coord{-5.50, 40.96}
+	got := WGS84.Inverse(wellington, salamanca)
+	assert.True(t, math.Abs(float64(got.Distance)-19959679.267) < 1e-3, "distance %v", got.Distance)
+	assert.True(t, math.Abs(float64(got.InitialBearing)-161.067669986) < 1e-8, "initial bearing %v", got.InitialBearing)
+	assert.True(t, math.Abs(float64(got.FinalBearing)-18.825195123) < 1e-8, "final bearing %v", got.FinalBearing)
+
+	for _, to := range []goodgeo.Coord{{179.7, 0.5}, {179.99, 0}, {180, 0}, {0, -89.99}} {
+		from := goodgeo.Coord{0, 0}
+		if to[1] < -89 {
+			from = goodgeo.Coord{0, 89.99}
+		}
+		inverse := WGS84.Inverse(from, to)
+		assert.Equal(t, inverse.Distance, WGS84.Distance(to, from))
+		direct := WGS84.Direct(from, inverse.Distance, inverse.InitialBearing)
+		assert.True(t, WGS84.Distance(direct.Coord, to) < 1e-3, "%v to %v", from, to)
+	}
+}
+
+func TestDirect(t *testing.T) {
+	got := WGS84.Direct(flindersPeak, 54972.271, goodgeo.Degrees(dms(306, 52, 5.37)))
+	assert.True(t, math.Abs(got.Coord[0]-buninyong[0]) < 1e-7, "longitude %v", got.Coord[0])
+	assert.True(t, math.Abs(got.Coord[1]-buninyong[1]) < 1e-7, "latitude %v", got.Coord[1])
+	assert.True(t, math.Abs(float64(got.FinalBearing)-(dms(307, 10, 25.07)-360)) < 1e-5, "final bearing %v", got.FinalBearing)
+}
+
+func TestDirectKeepsOrdinates(t *testing.T) {
+	got := WGS84.Destination(goodgeo.Coord{0, 0, 123}, 1000, 90)
+	assert.Equal(t, 3, len(got))
+	assert.Equal(t, 123, got[2])
+}
+
+func TestDistanceModel(t *testing.T) {
+	ls := goodgeo.NewLineString(goodgeo.XY).MustSetCoords([]goodgeo.Coord{flindersPeak, buninyong})
+
+	length := goodgeo.Length(ls, WGS84)
+	assert.True(t, math.Abs(float64(length)-54972.271) < 1e-3, "length %v", length)
+
+	mid := goodgeo.Along(ls, length/2, WGS84)
+	assert.True(t, math.Abs(float64(WGS84.Distance(flindersPeak, mid.Coords()))-float64(length/2)) < 1e-3)
+	assert.True(t, math.Abs(float64(WGS84.Distance(mid.Coords(), buninyong))-float64(length/2)) < 1e-3)
+
+	nearest := goodgeo.NearestPointOnLine(ls, mid.Coords(), WGS84)
+	assert.True(t, float64(nearest.Distance) < 1, "distance %v", nearest.Distance)
+}
+
+func TestInverseOtherEllipsoid(t *testing.T) {
+	// On a sphere the solver is cached like the one for WGS84.
+	sphere := NewEllipsoid(6371000, 0)
+	for range 2 {
+		got := sphere.Distance(goodgeo.Coord{0, 0}, goodgeo.Coord{90, 0})
+		assert.True(t, math.Abs(float64(got)-6371000*math.Pi/2) < 1e-6, "distance %v", got)
+	}
+}
+
+func BenchmarkLength(b *testing.B) {
+	coords := make([]goodgeo.Coord, 1000)
+	for i := range coords {
+		coords[i] = goodgeo.Coord{float64(i) / 100, math.Sin(float64(i) / 50)}
+	}
+	ls := goodgeo.NewLineString(goodgeo.XY).MustSetCoords(coords)
+	b.ResetTimer()
+	for range b.N {
+		goodgeo.Length(ls, WGS84)
+	}
+}
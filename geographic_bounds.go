@@ -0,0 +1,192 @@
+package goodgeo
+
+import (
+	"math"
+	"slices"
+)
+
+// A GeographicBounds is a bounding box of geographic coordinates whose first
+// dimension is longitude in degrees. Unlike a [Bounds], its longitudes may
+// cross the antimeridian, in which case the minimum longitude is greater
+// than the maximum longitude and the box covers the longitudes from its
+// minimum to 180° and from -180° to its maximum.
+type GeographicBounds struct {
+	bounds *Bounds
+}
+
+// NewGeographicBounds creates a new GeographicBounds.
+func NewGeographicBounds(layout Layout) *GeographicBounds {
+	return &GeographicBounds{bounds: NewBounds(layout)}
+}
+
+// GeographicBoundsOf returns the geographic bounds of g. A route from Fiji
+// to Samoa gets a box that crosses the antimeridian, where g.Bounds() covers
+// the whole globe.
+func GeographicBoundsOf(g T) *GeographicBounds {
+	return NewGeographicBounds(NoLayout).Extend(g)
+}
+
+// Clone returns a deep copy of b.
+func (b *GeographicBounds) Clone() *GeographicBounds {
+	return &GeographicBounds{bounds: b.bounds.Clone()}
+}
+
+// Bounds returns the parts of b on either side of the antimeridian if it
+// crosses it, otherwise a copy of b, as planar bounds.
+func (b *GeographicBounds) Bounds() []*Bounds {
+	if !b.CrossesAntimeridian() {
+		return []*Bounds{b.bounds.Clone()}
+	}
+	east, west := b.bounds.Clone(), b.bounds.Clone()
+	east.max[0], west.min[0] = 180, -180
+	return []*Bounds{east, west}
+}
+
+// Extend extends b to include geometry g. The longitudes are covered by the
+// shortest range, which crosses the antimeridian if that is shorter. Other
+// dimensions are extended as by [Bounds.Extend].
+func (b *GeographicBounds) Extend(g T) *GeographicBounds {
+	if gc, ok := g.(*GeometryCollection); ok {
+		for _, g := range gc.geoms {
+			b.Extend(g)
+		}
+		return b
+	}
+	var lons []float64
+	if bb := b.bounds; bb.layout != NoLayout && !math.IsInf(bb.min[0], 0) && !math.IsInf(bb.max[0], 0) {
+		lons = append(lons, bb.min[0], bb.max[0])
+	}
+	covered := slices.Clone(lons)
+	b.bounds.Extend(g)
+	if b.bounds.layout == NoLayout {
+		return b
+	}
+	flatCoords, stride := g.FlatCoords(), g.Stride()
+	for i := 0; i < len(flatCoords); i += stride {
+		lons = append(lons, normalizeLongitude(flatCoords[i]))
+	}
+	if len(lons) == 0 {
+		return b
+	}
+	b.bounds.min[0], b.bounds.max[0] = longitudeRange(lons, covered)
+	return b
+}
+
+// CrossesAntimeridian returns true if the minimum longitude of b is greater
+// than its maximum longitude.
+func (b *GeographicBounds) CrossesAntimeridian() bool {
+	bb := b.bounds
+	return bb.layout != NoLayout && bb.min[0] > bb.max[0] && !math.IsInf(bb.min[0], 0) && !math.IsInf(bb.max[0], 0)
+}
+
+// IsEmpty returns true if b is empty.
+func (b *GeographicBounds) IsEmpty() bool {
+	for _, part := range b.Bounds() {
+		if part.IsEmpty() {
+			return true
+		}
+	}
+	return false
+}
+
+// Layout returns b's layout.
+func (b *GeographicBounds) Layout() Layout {
+	return b.bounds.layout
+}
+
+// Max returns the maximum value in dimension dim.
+func (b *GeographicBounds) Max(dim int) float64 {
+	return b.bounds.max[dim]
+}
+
+// Min returns the minimum value in dimension dim.
+func (b *GeographicBounds) Min(dim int) float64 {
+	return b.bounds.min[dim]
+}
+
+// Overlaps returns true if b overlaps b2 in layout.
+func (b *GeographicBounds) Overlaps(layout Layout, b2 *GeographicBounds) bool {
+	for _, part := range b.Bounds() {
+		for _, part2 := range b2.Bounds() {
+			if part.Overlaps(layout, part2) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// OverlapsPoint returns true if point is within or on the border of b.
+func (b *GeographicBounds) OverlapsPoint(layout Layout, point Coord) bool {
+	for _, part := range b.Bounds() {
+		if part.OverlapsPoint(layout, point) {
+			return true
+		}
+	}
+	return false
+}
+
+// Polygon returns b as a two-dimensional Polygon. If b crosses the
+// antimeridian, its maximum longitude is shifted by 360° so that the polygon
+// is continuous; [SplitAtAntimeridian] cuts it in two.
+func (b *GeographicBounds) Polygon() *Polygon {
+	if !b.CrossesAntimeridian() {
+		return b.bounds.Polygon()
+	}
+	if b.IsEmpty() {
+		return NewPolygonFlat(XY, nil, nil)
+	}
+	x1, y1 := b.bounds.min[0], b.bounds.min[1]
+	x2, y2 := b.bounds.max[0]+360, b.bounds.max[1]
+	flatCoords := []float64{
+		x1, y1,
+		x1, y2,
+		x2, y2,
+		x2, y1,
+		x1, y1,
+	}
+	return NewPolygonFlat(XY, flatCoords, []int{len(flatCoords)})
+}
+
+// Set sets the minimum and maximum values as [Bounds.Set] does. The minimum
+// longitude may be greater than the maximum longitude.
+func (b *GeographicBounds) Set(args ...float64) *GeographicBounds {
+	b.bounds.Set(args...)
+	return b
+}
+
+// longitudeRange returns the minimum and maximum of the shortest range of
+// longitudes that contains lons and the range from covered[0] eastwards to
+// covered[1], if any. The range is the rest of the circle after the largest
+// gap between the longitudes that is not covered.
+func longitudeRange(lons, covered []float64) (float64, float64) {
+	inCovered := func(lon float64) bool {
+		switch {
+		case covered == nil:
+			return false
+		case covered[0] <= covered[1]:
+			return covered[0] <= lon && lon <= covered[1]
+		default:
+			return lon >= covered[0] || lon <= covered[1]
+		}
+	}
+	slices.Sort(lons)
+	lons = slices.Compact(lons)
+	// Without a gap, the range is either covered or all points are the same.
+	minLon, maxLon, gap := lons[0], lons[len(lons)-1], -1.0
+	if covered != nil {
+		minLon, maxLon = covered[0], covered[1]
+	}
+	for i, lon := range lons {
+		next := lons[(i+1)%len(lons)]
+		width := next - lon
+		if width <= 0 {
+			width += 360
+		}
+		mid := normalizeLongitude(lon + width/2)
+		if width > gap && !inCovered(mid) {
+			minLon, maxLon, gap = next, lon, width
+		}
+	}
+	return minLon, maxLon
+}
@@ -0,0 +1,159 @@
+package goodgeo
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestGeographicBoundsExtend(t *testing.T) {
+	for i, tc := range []struct {
+		b        *GeographicBounds
+		g        T
+		expected *GeographicBounds
+	}{
+		{
+			b:        NewGeographicBounds(XY),
+			g:        NewLineString(XY).MustSetCoords([]Coord{{10, 0}, {20, 5}}),
+			expected: NewGeographicBounds(XY).Set(10, 0, 20, 5),
+		},
+		{
+			b:        NewGeographicBounds(XY),
+			g:        NewLineString(XY).MustSetCoords([]Coord{{178, -18}, {-172, -14}}),
+			expected: NewGeographicBounds(XY).Set(178, -18, -172, -14),
+		},
+		{
+			b:        NewGeographicBounds(XY),
+			g:        NewPoint(XY).MustSetCoords(Coord{-170, 10}),
+			expected: NewGeographicBounds(XY).Set(-170, 10, -170, 10),
+		},
+		{
+			b:        NewGeographicBounds(XY).Set(178, -18, -172, -14),
+			g:        NewPoint(XY).MustSetCoords(Coord{179, -20}),
+			expected: NewGeographicBounds(XY).Set(178, -20, -172, -14),
+		},
+		{
+			b:        NewGeographicBounds(XY).Set(178, -18, -172, -14),
+			g:        NewPoint(XY).MustSetCoords(Coord{0, 0}),
+			expected: NewGeographicBounds(XY).Set(178, -18, 0, 0),
+		},
+		{
+			b:        NewGeographicBounds(XY).Set(-10, 0, 10, 0),
+			g:        NewMultiPoint(XYZ).MustSetCoords([]Coord{{150, 0, 1}, {-170, 0, 2}}),
+			expected: NewGeographicBounds(XYZ).Set(-10, 0, 1, -170, 0, 2),
+		},
+		{
+			b: NewGeographicBounds(XY),
+			g: NewGeometryCollection().MustPush(
+				NewPoint(XY).MustSetCoords(Coord{-179, 0}),
+				NewPoint(XY).MustSetCoords(Coord{179, 1}),
+			),
+			expected: NewGeographicBounds(XY).Set(179, 0, -179, 1),
+		},
+		{
+			b:        NewGeographicBounds(XY).Set(178, -18, -172, -14),
+			g:        NewLineString(XY),
+			expected: NewGeographicBounds(XY).Set(178, -18, -172, -14),
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.b.Clone().Extend(tc.g))
+		})
+	}
+}
+
+func TestGeographicBoundsOf(t *testing.T) {
+	// A route from Fiji to Samoa.
+	route := NewLineString(XY).MustSetCoords([]Coord{{178.4, -18.1}, {-171.8, -13.8}})
+	assert.Equal(t, NewGeographicBounds(XY).Set(178.4, -18.1, -171.8, -13.8), GeographicBoundsOf(route))
+	assert.Equal(t, NewBounds(XY).Set(-171.8, -18.1, 178.4, -13.8), route.Bounds())
+}
+
+func TestGeographicBoundsIsEmpty(t *testing.T) {
+	for i, tc := range []struct {
+		b        *GeographicBounds
+		expected bool
+	}{
+		{
+			b:        NewGeographicBounds(XY),
+			expected: true,
+		},
+		{
+			b:        NewGeographicBounds(XY).Set(0, 0, 0, 0),
+			expected: false,
+		},
+		{
+			b:        NewGeographicBounds(XY).Set(170, 0, -170, 10),
+			expected: false,
+		},
+		{
+			b:        NewGeographicBounds(XY).Set(170, 10, -170, 0),
+			expected: true,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.b.IsEmpty())
+		})
+	}
+}
+
+func TestGeographicBoundsOverlaps(t *testing.T) {
+	for i, tc := range []struct {
+		b1, b2   *GeographicBounds
+		expected bool
+	}{
+		{
+			b1:       NewGeographicBounds(XY).Set(0, 0, 10, 10),
+			b2:       NewGeographicBounds(XY).Set(5, 5, 15, 15),
+			expected: true,
+		},
+		{
+			b1:       NewGeographicBounds(XY).Set(170, 0, -170, 10),
+			b2:       NewGeographicBounds(XY).Set(-175, 5, -160, 15),
+			expected: true,
+		},
+		{
+			b1:       NewGeographicBounds(XY).Set(170, 0, -170, 10),
+			b2:       NewGeographicBounds(XY).Set(-100, 0, 100, 10),
+			expected: false,
+		},
+		{
+			b1:       NewGeographicBounds(XY).Set(-100, 0, 100, 10),
+			b2:       NewGeographicBounds(XY).Set(170, 0, -170, 10),
+			expected: false,
+		},
+		{
+			b1:       NewGeographicBounds(XY).Set(170, 0, -170, 10),
+			b2:       NewGeographicBounds(XY).Set(175, 20, -175, 30),
+			expected: false,
+		},
+		{
+			b1:       NewGeographicBounds(XY).Set(170, 0, -170, 10),
+			b2:       NewGeographicBounds(XY).Set(175, 5, -175, 15),
+			expected: true,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.b1.Overlaps(XY, tc.b2))
+		})
+	}
+}
+
+func TestGeographicBoundsOverlapsPoint(t *testing.T) {
+	b := NewGeographicBounds(XY).Set(170, 0, -170, 10)
+	assert.True(t, b.OverlapsPoint(XY, Coord{-175, 5}))
+	assert.True(t, b.OverlapsPoint(XY, Coord{175, 5}))
+	assert.False(t, b.OverlapsPoint(XY, Coord{0, 5}))
+	assert.False(t, b.OverlapsPoint(XY, Coord{175, 15}))
+}
+
+func TestGeographicBoundsPolygon(t *testing.T) {
+	assert.Equal(t,
+		NewPolygon(XY).MustSetCoords([][]Coord{{{1, 2}, {1, 4}, {3, 4}, {3, 2}, {1, 2}}}),
+		NewGeographicBounds(XY).Set(1, 2, 3, 4).Polygon())
+	assert.Equal(t,
+		NewPolygon(XY).MustSetCoords([][]Coord{{{170, 0}, {170, 10}, {190, 10}, {190, 0}, {170, 0}}}),
+		NewGeographicBounds(XY).Set(170, 0, -170, 10).Polygon())
+	assert.Equal(t, NewPolygon(XY), NewGeographicBounds(XY).Set(170, 10, -170, 0).Polygon())
+}
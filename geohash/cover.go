@@ -0,0 +1,101 @@
+package geohash
+
+import (
+	"github.com/matoous/goodgeo"
+)
+
+// Cover returns a minimal set of geohashes, of at most precision
+// characters, whose cells together cover g, which must be a
+// [goodgeo.Polygon] or a [goodgeo.MultiPolygon]. Cells that lie completely
+// inside g are returned at the lowest precision possible.
+func Cover(g goodgeo.T, precision int) ([]string, error) {
+	switch g.(type) {
+	case *goodgeo.Polygon, *goodgeo.MultiPolygon:
+	default:
+		return nil, goodgeo.UnsupportedTypeError{Value: g}
+	}
+	if g.Empty() {
+		return nil, nil
+	}
+	bounds := g.Bounds()
+	prepared := goodgeo.Prepare(g)
+	hashes, _ := cover("", max(1, min(MaxPrecision, precision)), func(cell *goodgeo.Bounds) relation {
+		if !cell.Overlaps(goodgeo.XY, bounds) {
+			return disjoint
+		}
+		switch prepared.Relate(cell.Polygon()) {
+		case goodgeo.RelationDisjoint, goodgeo.RelationTouches:
+			return disjoint
+		case goodgeo.RelationContained:
+			return within
+		default:
+			return partial
+		}
+	})
+	return hashes, nil
+}
+
+// CoverBounds returns a minimal set of geohashes, of at most precision
+// characters, whose cells together cover b. If the minimum longitude of b
+// is greater than its maximum longitude, b is taken to cross the
+// antimeridian.
+func CoverBounds(b *goodgeo.Bounds, precision int) []string {
+	precision = max(1, min(MaxPrecision, precision))
+	if b.Min(0) > b.Max(0) {
+		east := goodgeo.NewBounds(goodgeo.XY).Set(b.Min(0), b.Min(1), 180, b.Max(1))
+		west := goodgeo.NewBounds(goodgeo.XY).Set(-180, b.Min(1), b.Max(0), b.Max(1))
+		return append(CoverBounds(east, precision), CoverBounds(west, precision)...)
+	}
+	hashes, _ := cover("", precision, func(cell *goodgeo.Bounds) relation {
+		for dim := range 2 {
+			lo, hi := max(cell.Min(dim), b.Min(dim)), min(cell.Max(dim), b.Max(dim))
+			// Cells that only touch b are not needed, unless b is
+			// degenerate in this dimension.
+			if lo > hi || lo == hi && b.Min(dim) != b.Max(dim) {
+				return disjoint
+			}
+		}
+		if b.Min(0) <= cell.Min(0) && cell.Max(0) <= b.Max(0) && b.Min(1) <= cell.Min(1) && cell.Max(1) <= b.Max(1) {
+			return within
+		}
+		return partial
+	})
+	return hashes
+}
+
+// A relation is the relation of a cell to the covered region.
+type relation int
+
+const (
+	disjoint relation = iota
+	partial
+	within
+)
+
+// cover returns the geohashes covering the region within the cell hash.
+// full is true if the result is the cell itself.
+func cover(hash string, precision int, relate func(cell *goodgeo.Bounds) relation) (hashes []string, full bool) {
+	if hash != "" {
+		cell, _ := DecodeBounds(hash)
+		switch relate(cell) {
+		case disjoint:
+			return nil, false
+		case within:
+			return []string{hash}, true
+		case partial:
+			if len(hash) == precision {
+				return []string{hash}, true
+			}
+		}
+	}
+	full = true
+	for i := range len(alphabet) {
+		child, childFull := cover(hash+alphabet[i:i+1], precision, relate)
+		hashes = append(hashes, child...)
+		full = full && childFull
+	}
+	if full && hash != "" {
+		return []string{hash}, true
+	}
+	return hashes, false
+}
@@ -0,0 +1,124 @@
+// Package geohash encodes coordinates as geohashes, see
+// https://en.wikipedia.org/wiki/Geohash.
+//
+// A geohash identifies a cell of a grid of longitudes and latitudes. Each
+// character adds five bits that alternately halve the cell by longitude and
+// latitude, starting with longitude, so that a hash of precision 12 locates
+// a point within a few centimeters.
+package geohash
+
+import (
+	"strings"
+
+	"github.com/matoous/goodgeo"
+)
+
+// MaxPrecision is the maximum supported number of characters of a geohash.
+const MaxPrecision = 12
+
+const alphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// decodeMap maps characters of the alphabet to their values.
+var decodeMap [256]int8
+
+func init() {
+	for i := range decodeMap {
+		decodeMap[i] = -1
+	}
+	for i := range len(alphabet) {
+		decodeMap[alphabet[i]] = int8(i)
+	}
+}
+
+// InvalidHashError is returned when a geohash is empty, too long or contains
+// characters outside of the geohash alphabet.
+type InvalidHashError string
+
+func (e InvalidHashError) Error() string {
+	return "geohash: invalid hash: " + string(e)
+}
+
+// Encode returns the geohash of c with the given number of characters,
+// which is clamped to between 1 and [MaxPrecision].
+func Encode(c goodgeo.Coord, precision int) string {
+	precision = max(1, min(MaxPrecision, precision))
+	minLon, maxLon := -180.0, 180.0
+	minLat, maxLat := -90.0, 90.0
+	lon, lat := c[0], c[1]
+
+	var sb strings.Builder
+	sb.Grow(precision)
+	even := true
+	for range precision {
+		var ch byte
+		for range 5 {
+			ch <<= 1
+			if even {
+				if mid := (minLon + maxLon) / 2; lon >= mid {
+					ch |= 1
+					minLon = mid
+				} else {
+					maxLon = mid
+				}
+			} else {
+				if mid := (minLat + maxLat) / 2; lat >= mid {
+					ch |= 1
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			even = !even
+		}
+		sb.WriteByte(alphabet[ch])
+	}
+	return sb.String()
+}
+
+// EncodePoint returns the geohash of p with the given number of characters.
+func EncodePoint(p *goodgeo.Point, precision int) string {
+	return Encode(p.Coords(), precision)
+}
+
+// DecodeBounds returns the cell identified by hash.
+func DecodeBounds(hash string) (*goodgeo.Bounds, error) {
+	if len(hash) == 0 || len(hash) > MaxPrecision {
+		return nil, InvalidHashError(hash)
+	}
+	minLon, maxLon := -180.0, 180.0
+	minLat, maxLat := -90.0, 90.0
+	even := true
+	for i := range len(hash) {
+		v := decodeMap[hash[i]]
+		if v < 0 {
+			return nil, InvalidHashError(hash)
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := v>>bit&1 == 1
+			if even {
+				if mid := (minLon + maxLon) / 2; set {
+					minLon = mid
+				} else {
+					maxLon = mid
+				}
+			} else {
+				if mid := (minLat + maxLat) / 2; set {
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return goodgeo.NewBounds(goodgeo.XY).Set(minLon, minLat, maxLon, maxLat), nil
+}
+
+// Decode returns the centre of the cell identified by hash.
+func Decode(hash string) (*goodgeo.Point, error) {
+	b, err := DecodeBounds(hash)
+	if err != nil {
+		return nil, err
+	}
+	return goodgeo.NewPointFlat(goodgeo.XY, []float64{(b.Min(0) + b.Max(0)) / 2, (b.Min(1) + b.Max(1)) / 2}), nil
+}
@@ -0,0 +1,132 @@
+package geohash
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/matoous/goodgeo"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	for i, tc := range []struct {
+		c         goodgeo.Coord
+		precision int
+		hash      string
+	}{
+		{c: goodgeo.Coord{-5.6, 42.6}, precision: 5, hash: "ezs42"},
+		{c: goodgeo.Coord{10.40744, 57.64911}, precision: 11, hash: "u4pruydqqvj"},
+		{c: goodgeo.Coord{0, 0}, precision: 1, hash: "s"},
+		{c: goodgeo.Coord{-180, -90}, precision: 4, hash: "0000"},
+		{c: goodgeo.Coord{180, 90}, precision: 4, hash: "zzzz"},
+		{c: goodgeo.Coord{0, 0}, precision: 20, hash: "s00000000000"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			hash := Encode(tc.c, tc.precision)
+			assert.Equal(t, tc.hash, hash)
+			assert.Equal(t, hash, EncodePoint(goodgeo.NewPoint(goodgeo.XY).MustSetCoords(tc.c), tc.precision))
+
+			b, err := DecodeBounds(hash)
+			assert.NoError(t, err)
+			assert.True(t, b.OverlapsPoint(goodgeo.XY, tc.c))
+			center, err := Decode(hash)
+			assert.NoError(t, err)
+			assert.Equal(t, hash, EncodePoint(center, len(hash)))
+		})
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, hash := range []string{"", "ezs4a", "0123456789bcd"} {
+		_, err := DecodeBounds(hash)
+		assert.IsError(t, err, InvalidHashError(hash))
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	neighbors, err := Neighbors("ezs42")
+	assert.NoError(t, err)
+	assert.Equal(t, [8]string{"ezs48", "ezs49", "ezs43", "ezs41", "ezs40", "ezefp", "ezefr", "ezefx"}, neighbors)
+
+	// Longitudes wrap around the antimeridian, there is nothing beyond the
+	// poles.
+	neighbors, err = Neighbors("0")
+	assert.NoError(t, err)
+	assert.Equal(t, [8]string{"2", "3", "1", "", "", "", "p", "r"}, neighbors)
+
+	_, err = Neighbor("a", North)
+	assert.Error(t, err)
+}
+
+func TestCoverBounds(t *testing.T) {
+	cell, err := DecodeBounds("ezs4")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ezs4"}, CoverBounds(cell, 6))
+
+	b := goodgeo.NewBounds(goodgeo.XY).Set(-5.6, 42.6, -5.5, 42.7)
+	hashes := CoverBounds(b, 5)
+	assert.Equal(t, 9, len(hashes))
+	for _, hash := range hashes {
+		assert.Equal(t, 5, len(hashes[0]))
+		cell, err := DecodeBounds(hash)
+		assert.NoError(t, err)
+		assert.True(t, cell.Overlaps(goodgeo.XY, b))
+	}
+
+	// A bounding box across the antimeridian.
+	hashes = CoverBounds(goodgeo.NewBounds(goodgeo.XY).Set(170, -10, -170, 10), 2)
+	for _, hash := range []string{"xb", "80"} {
+		assert.True(t, slices.Contains(hashes, hash), hash)
+	}
+}
+
+func TestCover(t *testing.T) {
+	polygon := goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{
+		{{14.2, 49.9}, {14.7, 49.95}, {14.6, 50.2}, {14.3, 50.15}, {14.2, 49.9}},
+	})
+	hashes, err := Cover(polygon, 5)
+	assert.NoError(t, err)
+
+	r := rand.New(rand.NewSource(1))
+	var cells []*goodgeo.Bounds
+	for _, hash := range hashes {
+		assert.True(t, len(hash) <= 5)
+		cell, err := DecodeBounds(hash)
+		assert.NoError(t, err)
+		assert.True(t, goodgeo.BooleanIntersects(polygon, cell.Polygon()), hash)
+		cells = append(cells, cell)
+		// No cell contains another one.
+		for _, other := range hashes {
+			assert.True(t, other == hash || !strings.HasPrefix(other, hash))
+		}
+	}
+	for range 1000 {
+		pt := goodgeo.Coord{14.2 + r.Float64()*0.5, 49.9 + r.Float64()*0.3}
+		if !goodgeo.BooleanPointInPolygon(pt, polygon, nil) {
+			continue
+		}
+		assert.True(t, slices.ContainsFunc(cells, func(cell *goodgeo.Bounds) bool {
+			return cell.OverlapsPoint(goodgeo.XY, pt)
+		}), "%v", pt)
+	}
+
+	_, err = Cover(goodgeo.NewPoint(goodgeo.XY), 5)
+	assert.Error(t, err)
+}
+
+func BenchmarkCover(b *testing.B) {
+	coords := make([]goodgeo.Coord, 0, 2001)
+	for i := range 2001 {
+		a := 2 * math.Pi * float64(i%2000) / 2000
+		coords = append(coords, goodgeo.Coord{14.45 + 0.3*math.Cos(a), 50.05 + 0.2*math.Sin(a)})
+	}
+	polygon := goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{coords})
+	for range b.N {
+		_, _ = Cover(polygon, 5)
+	}
+}
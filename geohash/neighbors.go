@@ -0,0 +1,67 @@
+package geohash
+
+import "math"
+
+// A Direction is the direction of a neighbouring cell.
+type Direction int
+
+// Directions, clockwise from north.
+const (
+	North Direction = iota
+	NorthEast
+	East
+	SouthEast
+	South
+	SouthWest
+	West
+	NorthWest
+)
+
+// offsets holds the longitude and latitude offsets, in cells, of the
+// neighbour in each direction.
+var offsets = [8][2]float64{
+	North:     {0, 1},
+	NorthEast: {1, 1},
+	East:      {1, 0},
+	SouthEast: {1, -1},
+	South:     {0, -1},
+	SouthWest: {-1, -1},
+	West:      {-1, 0},
+	NorthWest: {-1, 1},
+}
+
+// Neighbor returns the geohash of the same precision next to hash in
+// direction dir. Longitudes wrap around the antimeridian; cells at the
+// poles have no neighbours further north or south, for which Neighbor
+// returns an empty string.
+func Neighbor(hash string, dir Direction) (string, error) {
+	b, err := DecodeBounds(hash)
+	if err != nil {
+		return "", err
+	}
+	width, height := b.Max(0)-b.Min(0), b.Max(1)-b.Min(1)
+	lon := (b.Min(0)+b.Max(0))/2 + offsets[dir][0]*width
+	lat := (b.Min(1)+b.Max(1))/2 + offsets[dir][1]*height
+	if lat < -90 || lat > 90 {
+		return "", nil
+	}
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return Encode([]float64{lon - 180, lat}, len(hash)), nil
+}
+
+// Neighbors returns the geohashes of the 8 cells around hash, indexed by
+// [Direction].
+func Neighbors(hash string) ([8]string, error) {
+	var neighbors [8]string
+	for dir := range neighbors {
+		n, err := Neighbor(hash, Direction(dir))
+		if err != nil {
+			return neighbors, err
+		}
+		neighbors[dir] = n
+	}
+	return neighbors, nil
+}
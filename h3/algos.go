@@ -0,0 +1,293 @@
+package h3
+
+import "errors"
+
+// errPentagon is returned by the traversal functions when they run into the
+// distortion of a pentagon.
+var errPentagon = errors.New("h3: pentagon distortion")
+
+// ringDirections holds the directions of the sides of a ring, starting from
+// the cell in nextRingDirection of the center.
+var ringDirections = [6]direction{jAxesDigit, jkAxesDigit, kAxesDigit, ikAxesDigit, iAxesDigit, ijAxesDigit}
+
+const nextRingDirection = iAxesDigit
+
+// newDigitII and newDigitIII hold the digit of the neighbour of a cell, by
+// the digit of the cell and the direction of the neighbour, at Class II and
+// Class III resolutions respectively. newAdjustmentII and newAdjustmentIII
+// hold the direction in which the parents must be adjusted, or centerDigit.
+var (
+	newDigitII = [7][7]direction{
+		{centerDigit, kAxesDigit, jAxesDigit, jkAxesDigit, iAxesDigit, ikAxesDigit, ijAxesDigit},
+		{kAxesDigit, iAxesDigit, jkAxesDigit, ijAxesDigit, ikAxesDigit, jAxesDigit, centerDigit},
+		{jAxesDigit, jkAxesDigit, kAxesDigit, iAxesDigit, ijAxesDigit, centerDigit, ikAxesDigit},
+		{jkAxesDigit, ijAxesDigit, iAxesDigit, ikAxesDigit, centerDigit, kAxesDigit, jAxesDigit},
+		{iAxesDigit, ikAxesDigit, ijAxesDigit, centerDigit, jAxesDigit, jkAxesDigit, kAxesDigit},
+		{ikAxesDigit, jAxesDigit, centerDigit, kAxesDigit, jkAxesDigit, ijAxesDigit, iAxesDigit},
+		{ijAxesDigit, centerDigit, ikAxesDigit, jAxesDigit, kAxesDigit, iAxesDigit, jkAxesDigit},
+	}
+	newAdjustmentII = [7][7]direction{
+		{centerDigit, centerDigit, centerDigit, centerDigit, centerDigit, centerDigit, centerDigit},
+		{centerDigit, kAxesDigit, centerDigit, kAxesDigit, centerDigit, ikAxesDigit, centerDigit},
+		{centerDigit, centerDigit, jAxesDigit, jkAxesDigit, centerDigit, centerDigit, jAxesDigit},
+		{centerDigit, kAxesDigit, jkAxesDigit, jkAxesDigit, centerDigit, centerDigit, centerDigit},
+		{centerDigit, centerDigit, centerDigit, centerDigit, iAxesDigit, iAxesDigit, ijAxesDigit},
+		{centerDigit, ikAxesDigit, centerDigit, centerDigit, iAxesDigit, ikAxesDigit, centerDigit},
+		{centerDigit, centerDigit, jAxesDigit, centerDigit, ijAxesDigit, centerDigit, ijAxesDigit},
+	}
+	newDigitIII = [7][7]direction{
+		{centerDigit, kAxesDigit, jAxesDigit, jkAxesDigit, iAxesDigit, ikAxesDigit, ijAxesDigit},
+		{kAxesDigit, jAxesDigit, jkAxesDigit, iAxesDigit, ikAxesDigit, ijAxesDigit, centerDigit},
+		{jAxesDigit, jkAxesDigit, iAxesDigit, ikAxesDigit, ijAxesDigit, centerDigit, kAxesDigit},
+		{jkAxesDigit, iAxesDigit, ikAxesDigit, ijAxesDigit, centerDigit, kAxesDigit, jAxesDigit},
+		{iAxesDigit, ikAxesDigit, ijAxesDigit, centerDigit, kAxesDigit, jAxesDigit, jkAxesDigit},
+		{ikAxesDigit, ijAxesDigit, centerDigit, kAxesDigit, jAxesDigit, jkAxesDigit, iAxesDigit},
+		{ijAxesDigit, centerDigit, kAxesDigit, jAxesDigit, jkAxesDigit, iAxesDigit, ikAxesDigit},
+	}
+	newAdjustmentIII = [7][7]direction{
+		{centerDigit, centerDigit, centerDigit, centerDigit, centerDigit, centerDigit, centerDigit},
+		{centerDigit, kAxesDigit, centerDigit, jkAxesDigit, centerDigit, kAxesDigit, centerDigit},
+		{centerDigit, centerDigit, jAxesDigit, jAxesDigit, centerDigit, centerDigit, ijAxesDigit},
+		{centerDigit, jkAxesDigit, jAxesDigit, jkAxesDigit, centerDigit, centerDigit, centerDigit},
+		{centerDigit, centerDigit, centerDigit, centerDigit, iAxesDigit, ikAxesDigit, iAxesDigit},
+		{centerDigit, kAxesDigit, centerDigit, centerDigit, ikAxesDigit, ikAxesDigit, centerDigit},
+		{centerDigit, centerDigit, ijAxesDigit, centerDigit, iAxesDigit, centerDigit, ijAxesDigit},
+	}
+)
+
+// neighbor returns the neighbour of c in direction d, after rotating d by
+// rotations 60 degree counter-clockwise steps. It also returns the number of
+// rotations to apply to directions taken from the neighbour, which differs
+// from rotations when the step crosses into a differently oriented base
+// cell.
+func (c Cell) neighbor(d direction, rotations int) (Cell, int, error) {
+	rotations %= 6
+	for range rotations {
+		d = d.rotate60ccw()
+	}
+
+	current := c
+	newRotations := 0
+	oldBaseCell := c.BaseCell()
+	oldLeadingDigit := c.leadingNonZeroDigit()
+
+	// Adjust the digits and, if needed, the base cell.
+	for r := c.Resolution() - 1; ; r-- {
+		if r == -1 {
+			current = current.setBaseCell(baseCellNeighbors[oldBaseCell][d])
+			newRotations = baseCellNeighbor60CCWRots[oldBaseCell][d]
+			if current.BaseCell() == invalidBaseCell {
+				// The edge borders the neighbour beyond the deleted k
+				// vertex of the pentagon.
+				current = current.setBaseCell(baseCellNeighbors[oldBaseCell][ikAxesDigit])
+				newRotations = baseCellNeighbor60CCWRots[oldBaseCell][ikAxesDigit]
+				current = current.rotate60ccw()
+				rotations++
+			}
+			break
+		}
+		oldDigit := current.digit(r + 1)
+		if oldDigit == invalidDigit {
+			return 0, 0, ErrInvalidCell
+		}
+		var next direction
+		if isClassIII(r + 1) {
+			current = current.setDigit(r+1, newDigitII[oldDigit][d])
+			next = newAdjustmentII[oldDigit][d]
+		} else {
+			current = current.setDigit(r+1, newDigitIII[oldDigit][d])
+			next = newAdjustmentIII[oldDigit][d]
+		}
+		if next == centerDigit {
+			break
+		}
+		d = next
+	}
+
+	newBaseCell := current.BaseCell()
+	if !isBaseCellPentagon(newBaseCell) {
+		for range newRotations {
+			current = current.rotate60ccw()
+		}
+		return current, (rotations + newRotations) % 6, nil
+	}
+
+	// Force the rotation out of the missing k-axes sequence.
+	alreadyAdjustedKSubsequence := false
+	if current.leadingNonZeroDigit() == kAxesDigit {
+		if oldBaseCell != newBaseCell {
+			// We traversed into the deleted k sequence of the pentagon
+			// from another base cell.
+			if baseCellIsCwOffset(newBaseCell, baseCellData[oldBaseCell].homeFIJK.face) {
+				current = current.rotate60cw()
+			} else {
+				current = current.rotate60ccw()
+			}
+			alreadyAdjustedKSubsequence = true
+		} else {
+			// We traversed into the deleted k sequence from within the
+			// pentagon.
+			switch oldLeadingDigit {
+			case centerDigit:
+				return 0, 0, errPentagon
+			case jkAxesDigit:
+				current = current.rotate60ccw()
+				rotations++
+			case ikAxesDigit:
+				current = current.rotate60cw()
+				rotations += 5
+			default:
+				return 0, 0, ErrInvalidCell
+			}
+		}
+	}
+	for range newRotations {
+		current = current.rotatePent60ccw()
+	}
+
+	// Account for the differing orientation of the base cells.
+	if oldBaseCell != newBaseCell {
+		if isBaseCellPolarPentagon(newBaseCell) {
+			// The polar pentagons have all i neighbours.
+			if oldBaseCell != 118 && oldBaseCell != 8 && current.leadingNonZeroDigit() != jkAxesDigit {
+				rotations++
+			}
+		} else if current.leadingNonZeroDigit() == ikAxesDigit && !alreadyAdjustedKSubsequence {
+			// Account for the distortion introduced to the 5 neighbour by
+			// the deleted k sequence.
+			rotations++
+		}
+	}
+	return current, (rotations + newRotations) % 6, nil
+}
+
+// GridDisk returns the cells within grid distance k of origin, including
+// origin itself, in the same order as the reference implementation.
+func GridDisk(origin Cell, k int) ([]Cell, error) {
+	cells, _, err := GridDiskDistances(origin, k)
+	return cells, err
+}
+
+// GridDiskDistances returns the cells within grid distance k of origin and
+// the grid distance of each of them.
+func GridDiskDistances(origin Cell, k int) ([]Cell, []int, error) {
+	if !origin.IsValid() {
+		return nil, nil, ErrInvalidCell
+	}
+	if k < 0 {
+		return nil, nil, ErrInvalidDistance
+	}
+	size := 3*k*(k+1) + 1
+	cells, distances, err := gridDiskUnsafe(origin, k, size)
+	if err == nil {
+		return cells, distances, nil
+	}
+
+	// The spiral ran into a pentagon; fall back to a flood fill, using the
+	// output as an open addressing hash set.
+	cells, distances = make([]Cell, size), make([]int, size)
+	if err := gridDiskFill(origin, k, 0, cells, distances); err != nil {
+		return nil, nil, err
+	}
+	n := 0
+	for i, c := range cells {
+		if c != 0 {
+			cells[n], distances[n] = c, distances[i]
+			n++
+		}
+	}
+	return cells[:n], distances[:n], nil
+}
+
+// gridDiskUnsafe returns the cells within grid distance k of origin by
+// spiraling outwards. It fails if it encounters a pentagon.
+func gridDiskUnsafe(origin Cell, k, size int) ([]Cell, []int, error) {
+	cells, distances := make([]Cell, 0, size), make([]int, 0, size)
+	cells, distances = append(cells, origin), append(distances, 0)
+	if origin.IsPentagon() {
+		return nil, nil, errPentagon
+	}
+
+	// ring is the current ring, side the current side of the ring and i
+	// the position on the side.
+	ring, side, i, rotations := 1, 0, 0, 0
+	for ring <= k {
+		var err error
+		if side == 0 && i == 0 {
+			// Step out to the next ring; the cell is added at the end of
+			// the ring.
+			if origin, rotations, err = origin.neighbor(nextRingDirection, rotations); err != nil {
+				return nil, nil, err
+			}
+			if origin.IsPentagon() {
+				return nil, nil, errPentagon
+			}
+		}
+		if origin, rotations, err = origin.neighbor(ringDirections[side], rotations); err != nil {
+			return nil, nil, err
+		}
+		cells, distances = append(cells, origin), append(distances, ring)
+
+		i++
+		if i == ring {
+			i = 0
+			side++
+			if side == 6 {
+				side = 0
+				ring++
+			}
+		}
+		if origin.IsPentagon() {
+			return nil, nil, errPentagon
+		}
+	}
+	return cells, distances, nil
+}
+
+// gridDiskFill adds origin, at grid distance curK, and the cells within k
+// of it to the hash set cells.
+func gridDiskFill(origin Cell, k, curK int, cells []Cell, distances []int) error {
+	size := uint64(len(cells))
+	off := uint64(origin) % size
+	for cells[off] != 0 && cells[off] != origin {
+		off = (off + 1) % size
+	}
+	// A cell reached before on a shorter path is done.
+	if cells[off] == origin && distances[off] <= curK {
+		return nil
+	}
+	cells[off], distances[off] = origin, curK
+	if curK >= k {
+		return nil
+	}
+
+	for _, d := range ringDirections {
+		next, _, err := origin.neighbor(d, 0)
+		if errors.Is(err, errPentagon) {
+			// Pentagons have no neighbour in the deleted direction.
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := gridDiskFill(next, k, curK+1, cells, distances); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GridRing returns the cells at exactly grid distance k of origin.
+func GridRing(origin Cell, k int) ([]Cell, error) {
+	cells, distances, err := GridDiskDistances(origin, k)
+	if err != nil {
+		return nil, err
+	}
+	ring := cells[:0]
+	for i, c := range cells {
+		if distances[i] == k {
+			ring = append(ring, c)
+		}
+	}
+	return ring, nil
+}
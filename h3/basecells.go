@@ -0,0 +1,53 @@
+package h3
+
+const (
+	numBaseCells    = 122
+	numIcosaFaces   = 20
+	invalidBaseCell = 127
+	// maxFaceCoord is the maximum ijk coordinate of a resolution 0 cell on
+	// a face.
+	maxFaceCoord = 2
+)
+
+// A baseCellRotation is a base cell and the number of 60 degree
+// counter-clockwise rotations from a face into its orientation.
+type baseCellRotation struct {
+	baseCell int
+	ccwRot60 int
+}
+
+// A baseCellInfo describes a base cell.
+type baseCellInfo struct {
+	// homeFIJK is the position of the base cell on its home face.
+	homeFIJK   faceIJK
+	isPentagon bool
+	// cwOffsetPent holds, for pentagons, the two faces on which the
+	// base cell is rotated clockwise instead of counter-clockwise.
+	cwOffsetPent [2]int
+}
+
+func isBaseCellPentagon(baseCell int) bool {
+	if baseCell < 0 || baseCell >= numBaseCells {
+		return false
+	}
+	return baseCellData[baseCell].isPentagon
+}
+
+// isBaseCellPolarPentagon returns true if baseCell is one of the two
+// pentagons centered on the poles of the icosahedron.
+func isBaseCellPolarPentagon(baseCell int) bool {
+	return baseCell == 4 || baseCell == 117
+}
+
+// baseCellAt returns the base cell at the resolution 0 position h, and the
+// number of 60 degree counter-clockwise rotations into its orientation.
+func baseCellAt(h faceIJK) baseCellRotation {
+	return faceIJKBaseCells[h.face][h.coord.i][h.coord.j][h.coord.k]
+}
+
+// baseCellIsCwOffset returns true if baseCell, a pentagon, is rotated
+// clockwise on face.
+func baseCellIsCwOffset(baseCell, face int) bool {
+	offset := baseCellData[baseCell].cwOffsetPent
+	return offset[0] == face || offset[1] == face
+}
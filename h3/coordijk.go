@@ -0,0 +1,295 @@
+package h3
+
+import "math"
+
+// A coordIJK is a position in a hexagonal grid in ijk coordinates, in which
+// the axes are 120 degrees apart. Normalized coordinates have no negative
+// component and at least one zero component.
+type coordIJK struct {
+	i, j, k int
+}
+
+// A direction is a digit of a cell index, naming one of the seven cells
+// of an aperture 7 group or the direction of a neighbour.
+type direction int
+
+const (
+	centerDigit direction = 0
+	kAxesDigit  direction = 1
+	jAxesDigit  direction = 2
+	jkAxesDigit direction = jAxesDigit | kAxesDigit
+	iAxesDigit  direction = 4
+	ikAxesDigit direction = iAxesDigit | kAxesDigit
+	ijAxesDigit direction = iAxesDigit | jAxesDigit
+	// invalidDigit is also the number of valid digits.
+	invalidDigit direction = 7
+)
+
+// unitVecs holds the unit vector of each direction.
+var unitVecs = [7]coordIJK{
+	{0, 0, 0},
+	{0, 0, 1},
+	{0, 1, 0},
+	{0, 1, 1},
+	{1, 0, 0},
+	{1, 0, 1},
+	{1, 1, 0},
+}
+
+// A vec2d is a position in a two-dimensional plane.
+type vec2d struct {
+	x, y float64
+}
+
+func (v vec2d) mag() float64 {
+	return math.Sqrt(v.x*v.x + v.y*v.y)
+}
+
+// intersect returns the intersection of the lines through p0, p1 and
+// through p2, p3.
+func intersect(p0, p1, p2, p3 vec2d) vec2d {
+	s1 := vec2d{p1.x - p0.x, p1.y - p0.y}
+	s2 := vec2d{p3.x - p2.x, p3.y - p2.y}
+	t := (s2.x*(p0.y-p2.y) - s2.y*(p0.x-p2.x)) / (-s2.x*s1.y + s1.x*s2.y)
+	return vec2d{p0.x + t*s1.x, p0.y + t*s1.y}
+}
+
+// almostEquals returns true if v and o are equal within the precision of
+// a float32.
+func (v vec2d) almostEquals(o vec2d) bool {
+	const epsilon = 1.1920929e-07
+	return math.Abs(v.x-o.x) < epsilon && math.Abs(v.y-o.y) < epsilon
+}
+
+// hex2dToCoordIJK returns the coordinates of the hexagon containing v.
+func hex2dToCoordIJK(v vec2d) coordIJK {
+	var h coordIJK
+	a1, a2 := math.Abs(v.x), math.Abs(v.y)
+
+	// Reverse the conversion from ijk to the plane.
+	x2 := a2 / sin60
+	x1 := a1 + x2/2
+	m1, m2 := int(x1), int(x2)
+	r1, r2 := x1-float64(m1), x2-float64(m2)
+
+	if r1 < 0.5 {
+		if r1 < 1.0/3.0 {
+			if r2 < (1+r1)/2 {
+				h.i, h.j = m1, m2
+			} else {
+				h.i, h.j = m1, m2+1
+			}
+		} else {
+			if r2 < 1-r1 {
+				h.j = m2
+			} else {
+				h.j = m2 + 1
+			}
+			if 1-r1 <= r2 && r2 < 2*r1 {
+				h.i = m1 + 1
+			} else {
+				h.i = m1
+			}
+		}
+	} else {
+		if r1 < 2.0/3.0 {
+			if r2 < 1-r1 {
+				h.j = m2
+			} else {
+				h.j = m2 + 1
+			}
+			if 2*r1-1 < r2 && r2 < 1-r1 {
+				h.i = m1
+			} else {
+				h.i = m1 + 1
+			}
+		} else {
+			if r2 < r1/2 {
+				h.i, h.j = m1+1, m2
+			} else {
+				h.i, h.j = m1+1, m2+1
+			}
+		}
+	}
+
+	// Fold across the axes if necessary.
+	if v.x < 0 {
+		if h.j%2 == 0 {
+			axisi := h.j / 2
+			diff := h.i - axisi
+			h.i -= 2 * diff
+		} else {
+			axisi := (h.j + 1) / 2
+			diff := h.i - axisi
+			h.i -= 2*diff + 1
+		}
+	}
+	if v.y < 0 {
+		h.i -= (2*h.j + 1) / 2
+		h.j = -h.j
+	}
+	h.normalize()
+	return h
+}
+
+// toHex2d returns the center of the hexagon at h in the plane.
+func (h coordIJK) toHex2d() vec2d {
+	i, j := h.i-h.k, h.j-h.k
+	return vec2d{float64(i) - 0.5*float64(j), float64(j) * sqrt3_2}
+}
+
+func (h coordIJK) add(o coordIJK) coordIJK {
+	return coordIJK{h.i + o.i, h.j + o.j, h.k + o.k}
+}
+
+func (h coordIJK) sub(o coordIJK) coordIJK {
+	return coordIJK{h.i - o.i, h.j - o.j, h.k - o.k}
+}
+
+func (h coordIJK) scale(factor int) coordIJK {
+	return coordIJK{h.i * factor, h.j * factor, h.k * factor}
+}
+
+// normalize normalizes h in place.
+func (h *coordIJK) normalize() {
+	if h.i < 0 {
+		h.j -= h.i
+		h.k -= h.i
+		h.i = 0
+	}
+	if h.j < 0 {
+		h.i -= h.j
+		h.k -= h.j
+		h.j = 0
+	}
+	if h.k < 0 {
+		h.i -= h.k
+		h.j -= h.k
+		h.k = 0
+	}
+	if m := min(h.i, h.j, h.k); m > 0 {
+		h.i -= m
+		h.j -= m
+		h.k -= m
+	}
+}
+
+func (h coordIJK) normalized() coordIJK {
+	h.normalize()
+	return h
+}
+
+// toDigit returns the direction of the unit vector h, or invalidDigit.
+func (h coordIJK) toDigit() direction {
+	h.normalize()
+	for d := centerDigit; d < invalidDigit; d++ {
+		if h == unitVecs[d] {
+			return d
+		}
+	}
+	return invalidDigit
+}
+
+// combine returns the normalized sum of the vectors vi, vj and vk scaled by
+// the components of h.
+func (h coordIJK) combine(vi, vj, vk coordIJK) coordIJK {
+	return vi.scale(h.i).add(vj.scale(h.j)).add(vk.scale(h.k)).normalized()
+}
+
+// upAp7 returns the coordinates of the parent of h in the counter-clockwise
+// aperture 7 grid.
+func (h coordIJK) upAp7() coordIJK {
+	i, j := h.i-h.k, h.j-h.k
+	return coordIJK{
+		i: int(math.Round(float64(3*i-j) / 7)),
+		j: int(math.Round(float64(i+2*j) / 7)),
+	}.normalized()
+}
+
+// upAp7r returns the coordinates of the parent of h in the clockwise
+// aperture 7 grid.
+func (h coordIJK) upAp7r() coordIJK {
+	i, j := h.i-h.k, h.j-h.k
+	return coordIJK{
+		i: int(math.Round(float64(2*i+j) / 7)),
+		j: int(math.Round(float64(3*j-i) / 7)),
+	}.normalized()
+}
+
+// downAp7 returns the coordinates of the center child of h in the
+// counter-clockwise aperture 7 grid.
+func (h coordIJK) downAp7() coordIJK {
+	return h.combine(coordIJK{3, 0, 1}, coordIJK{1, 3, 0}, coordIJK{0, 1, 3})
+}
+
+// downAp7r returns the coordinates of the center child of h in the
+// clockwise aperture 7 grid.
+func (h coordIJK) downAp7r() coordIJK {
+	return h.combine(coordIJK{3, 1, 0}, coordIJK{0, 3, 1}, coordIJK{1, 0, 3})
+}
+
+// downAp3 returns the coordinates of the center of h in the
+// counter-clockwise aperture 3 grid.
+func (h coordIJK) downAp3() coordIJK {
+	return h.combine(coordIJK{2, 0, 1}, coordIJK{1, 2, 0}, coordIJK{0, 1, 2})
+}
+
+// downAp3r returns the coordinates of the center of h in the clockwise
+// aperture 3 grid.
+func (h coordIJK) downAp3r() coordIJK {
+	return h.combine(coordIJK{2, 1, 0}, coordIJK{0, 2, 1}, coordIJK{1, 0, 2})
+}
+
+// neighbor returns the coordinates of the neighbour of h in direction d.
+func (h coordIJK) neighbor(d direction) coordIJK {
+	if d > centerDigit && d < invalidDigit {
+		return h.add(unitVecs[d]).normalized()
+	}
+	return h
+}
+
+func (h coordIJK) rotate60ccw() coordIJK {
+	return h.combine(coordIJK{1, 1, 0}, coordIJK{0, 1, 1}, coordIJK{1, 0, 1})
+}
+
+func (h coordIJK) rotate60cw() coordIJK {
+	return h.combine(coordIJK{1, 0, 1}, coordIJK{1, 1, 0}, coordIJK{0, 1, 1})
+}
+
+func (d direction) rotate60ccw() direction {
+	switch d {
+	case kAxesDigit:
+		return ikAxesDigit
+	case ikAxesDigit:
+		return iAxesDigit
+	case iAxesDigit:
+		return ijAxesDigit
+	case ijAxesDigit:
+		return jAxesDigit
+	case jAxesDigit:
+		return jkAxesDigit
+	case jkAxesDigit:
+		return kAxesDigit
+	default:
+		return d
+	}
+}
+
+func (d direction) rotate60cw() direction {
+	switch d {
+	case kAxesDigit:
+		return jkAxesDigit
+	case jkAxesDigit:
+		return jAxesDigit
+	case jAxesDigit:
+		return ijAxesDigit
+	case ijAxesDigit:
+		return iAxesDigit
+	case iAxesDigit:
+		return ikAxesDigit
+	case ikAxesDigit:
+		return kAxesDigit
+	default:
+		return d
+	}
+}
@@ -0,0 +1,543 @@
+package h3
+
+import "math"
+
+const (
+	epsilon = 1e-16
+	sqrt3_2 = 0.8660254037844386467637231707529361834714
+	sin60   = sqrt3_2
+	sqrt7   = 2.6457513110645905905016157536392604257102
+	// ap7RotRads is the rotation angle between Class II and Class III
+	// resolution axes, asin(sqrt(3/28)).
+	ap7RotRads = 0.333473172251832115336090755351601070065900389
+	// res0UGnomonic is the scaling factor from the hex2d resolution 0 unit
+	// length to the gnomonic unit length.
+	res0UGnomonic = 0.38196601125010500003
+	numHexVerts   = 6
+	numPentVerts  = 5
+)
+
+// A latLng is a position on the sphere in radians.
+type latLng struct {
+	lat, lng float64
+}
+
+// A faceIJK is a position on one of the faces of the icosahedron.
+type faceIJK struct {
+	face  int
+	coord coordIJK
+}
+
+// A faceOrientIJK describes the orientation of a neighbouring face relative
+// to a face.
+type faceOrientIJK struct {
+	face      int
+	translate coordIJK
+	ccwRot60  int
+}
+
+// The quadrants of a face, in the order of faceNeighbors. Index 0 is the
+// face itself.
+const (
+	ij = 1
+	ki = 2
+	jk = 3
+)
+
+// An overage describes how a position relates to the face it is expressed
+// on.
+type overage int
+
+const (
+	noOverage overage = iota
+	// faceEdge means the position is on the edge of the face.
+	faceEdge
+	// newFace means the position was moved to a neighbouring face.
+	newFace
+)
+
+// faceCenterGeo holds the center of each icosahedron face.
+var faceCenterGeo = [numIcosaFaces]latLng{
+	{0.803582649718989942, 1.248397419617396099},
+	{1.307747883455638156, 2.536945009877921159},
+	{1.054751253523952054, -1.347517358900396623},
+	{0.600191595538186799, -0.450603909469755746},
+	{0.491715428198773866, 0.401988202911306943},
+	{0.172745327415618701, 1.678146885280433686},
+	{0.605929321571350690, 2.953923329812411617},
+	{0.427370518328979641, -1.888876200336285401},
+	{-0.079066118549212831, -0.733429513380867741},
+	{-0.230961644455383637, 0.506495587332349035},
+	{0.079066118549212831, 2.408163140208925497},
+	{0.230961644455383637, -2.635097066257444203},
+	{-0.172745327415618701, -1.463445768309359553},
+	{-0.605929321571350690, -0.187669323777381622},
+	{-0.427370518328979641, 1.252716453253507838},
+	{-0.600191595538186799, 2.690988744120037492},
+	{-0.491715428198773866, -2.739604450678486295},
+	{-0.803582649718989942, -1.893195233972397139},
+	{-1.307747883455638156, -0.604647643711872080},
+	{-1.054751253523952054, 1.794075294689396615},
+}
+
+// faceCenterPoint holds the center of each icosahedron face as a point on
+// the unit sphere.
+var faceCenterPoint = [numIcosaFaces][3]float64{
+	{0.2199307791404606, 0.6583691780274996, 0.7198475378926182},
+	{-0.2139234834501421, 0.1478171829550703, 0.9656017935214205},
+	{0.1092625278784797, -0.4811951572873210, 0.8697775121287253},
+	{0.7428567301586791, -0.3593941678278028, 0.5648005936517033},
+	{0.8112534709140969, 0.3448953237639384, 0.4721387736413930},
+	{-0.1055498149613921, 0.9794457296411413, 0.1718874610009365},
+	{-0.8075407579970092, 0.1533552485898818, 0.5695261994882688},
+	{-0.2846148069787907, -0.8644080972654206, 0.4144792552473539},
+	{0.7405621473854482, -0.6673299564565524, -0.0789837646326737},
+	{0.8512303986474293, 0.4722343788582681, -0.2289137388687808},
+	{-0.7405621473854481, 0.6673299564565524, 0.0789837646326737},
+	{-0.8512303986474292, -0.4722343788582682, 0.2289137388687808},
+	{0.1055498149613919, -0.9794457296411413, -0.1718874610009365},
+	{0.8075407579970092, -0.1533552485898819, -0.5695261994882688},
+	{0.2846148069787908, 0.8644080972654204, -0.4144792552473539},
+	{-0.7428567301586791, 0.3593941678278027, -0.5648005936517033},
+	{-0.8112534709140971, -0.3448953237639382, -0.4721387736413930},
+	{-0.2199307791404607, -0.6583691780274996, -0.7198475378926182},
+	{0.2139234834501420, -0.1478171829550704, -0.9656017935214205},
+	{-0.1092625278784796, 0.4811951572873210, -0.8697775121287253},
+}
+
+// faceAxesAzRadsCII holds the azimuths of the Class II i, j and k axes of
+// each face.
+var faceAxesAzRadsCII = [numIcosaFaces][3]float64{
+	{5.619958268523939882, 3.525563166130744542, 1.431168063737548730},
+	{5.760339081714187279, 3.665943979320991689, 1.571548876927796127},
+	{0.780213654393430055, 4.969003859179821079, 2.874608756786625655},
+	{0.430469363979999913, 4.619259568766391033, 2.524864466373195467},
+	{6.130269123335111400, 4.035874020941915804, 1.941478918548720291},
+	{2.692877706530642877, 0.598482604137447119, 4.787272808923838195},
+	{2.982963003477243874, 0.888567901084048369, 5.077358105870439581},
+	{3.532912002790141181, 1.438516900396945656, 5.627307105183336758},
+	{3.494305004259568154, 1.399909901866372864, 5.588700106652763840},
+	{3.003214169499538391, 0.908819067106342928, 5.097609271892733906},
+	{5.930472956509811562, 3.836077854116615875, 1.741682751723420374},
+	{0.138378484090254847, 4.327168688876645809, 2.232773586483450311},
+	{0.448714947059150361, 4.637505151845541521, 2.543110049452346120},
+	{0.158629650112549365, 4.347419854898940135, 2.253024752505744869},
+	{5.891865957979238535, 3.797470855586042958, 1.703075753192847583},
+	{2.711123289609793325, 0.616728187216597771, 4.805518392002988683},
+	{3.294508837434268316, 1.200113735041072948, 5.388903939827463911},
+	{3.804819692245439833, 1.710424589852244509, 5.899214794638635174},
+	{3.664438879055192436, 1.570043776661997111, 5.758833981448388027},
+	{2.361378999196363184, 0.266983896803167583, 4.455774101589558636},
+}
+
+// faceNeighbors holds, for each face, the face itself followed by its
+// neighbours across the ij, ki and jk quadrants.
+var faceNeighbors = [numIcosaFaces][4]faceOrientIJK{
+	{{0, coordIJK{0, 0, 0}, 0}, {4, coordIJK{2, 0, 2}, 1}, {1, coordIJK{2, 2, 0}, 5}, {5, coordIJK{0, 2, 2}, 3}},
+	{{1, coordIJK{0, 0, 0}, 0}, {0, coordIJK{2, 0, 2}, 1}, {2, coordIJK{2, 2, 0}, 5}, {6, coordIJK{0, 2, 2}, 3}},
+	{{2, coordIJK{0, 0, 0}, 0}, {1, coordIJK{2, 0, 2}, 1}, {3, coordIJK{2, 2, 0}, 5}, {7, coordIJK{0, 2, 2}, 3}},
+	{{3, coordIJK{0, 0, 0}, 0}, {2, coordIJK{2, 0, 2}, 1}, {4, coordIJK{2, 2, 0}, 5}, {8, coordIJK{0, 2, 2}, 3}},
+	{{4, coordIJK{0, 0, 0}, 0}, {3, coordIJK{2, 0, 2}, 1}, {0, coordIJK{2, 2, 0}, 5}, {9, coordIJK{0, 2, 2}, 3}},
+	{{5, coordIJK{0, 0, 0}, 0}, {10, coordIJK{2, 2, 0}, 3}, {14, coordIJK{2, 0, 2}, 3}, {0, coordIJK{0, 2, 2}, 3}},
+	{{6, coordIJK{0, 0, 0}, 0}, {11, coordIJK{2, 2, 0}, 3}, {10, coordIJK{2, 0, 2}, 3}, {1, coordIJK{0, 2, 2}, 3}},
+	{{7, coordIJK{0, 0, 0}, 0}, {12, coordIJK{2, 2, 0}, 3}, {11, coordIJK{2, 0, 2}, 3}, {2, coordIJK{0, 2, 2}, 3}},
+	{{8, coordIJK{0, 0, 0}, 0}, {13, coordIJK{2, 2, 0}, 3}, {12, coordIJK{2, 0, 2}, 3}, {3, coordIJK{0, 2, 2}, 3}},
+	{{9, coordIJK{0, 0, 0}, 0}, {14, coordIJK{2, 2, 0}, 3}, {13, coordIJK{2, 0, 2}, 3}, {4, coordIJK{0, 2, 2}, 3}},
+	{{10, coordIJK{0, 0, 0}, 0}, {5, coordIJK{2, 2, 0}, 3}, {6, coordIJK{2, 0, 2}, 3}, {15, coordIJK{0, 2, 2}, 3}},
+	{{11, coordIJK{0, 0, 0}, 0}, {6, coordIJK{2, 2, 0}, 3}, {7, coordIJK{2, 0, 2}, 3}, {16, coordIJK{0, 2, 2}, 3}},
+	{{12, coordIJK{0, 0, 0}, 0}, {7, coordIJK{2, 2, 0}, 3}, {8, coordIJK{2, 0, 2}, 3}, {17, coordIJK{0, 2, 2}, 3}},
+	{{13, coordIJK{0, 0, 0}, 0}, {8, coordIJK{2, 2, 0}, 3}, {9, coordIJK{2, 0, 2}, 3}, {18, coordIJK{0, 2, 2}, 3}},
+	{{14, coordIJK{0, 0, 0}, 0}, {9, coordIJK{2, 2, 0}, 3}, {5, coordIJK{2, 0, 2}, 3}, {19, coordIJK{0, 2, 2}, 3}},
+	{{15, coordIJK{0, 0, 0}, 0}, {16, coordIJK{2, 0, 2}, 1}, {19, coordIJK{2, 2, 0}, 5}, {10, coordIJK{0, 2, 2}, 3}},
+	{{16, coordIJK{0, 0, 0}, 0}, {17, coordIJK{2, 0, 2}, 1}, {15, coordIJK{2, 2, 0}, 5}, {11, coordIJK{0, 2, 2}, 3}},
+	{{17, coordIJK{0, 0, 0}, 0}, {18, coordIJK{2, 0, 2}, 1}, {16, coordIJK{2, 2, 0}, 5}, {12, coordIJK{0, 2, 2}, 3}},
+	{{18, coordIJK{0, 0, 0}, 0}, {19, coordIJK{2, 0, 2}, 1}, {17, coordIJK{2, 2, 0}, 5}, {13, coordIJK{0, 2, 2}, 3}},
+	{{19, coordIJK{0, 0, 0}, 0}, {15, coordIJK{2, 0, 2}, 1}, {18, coordIJK{2, 2, 0}, 5}, {14, coordIJK{0, 2, 2}, 3}},
+}
+
+// adjacentFaceDir holds the quadrant of each face in which another face
+// lies, 0 for the face itself and -1 for faces that are not adjacent.
+var adjacentFaceDir = [numIcosaFaces][numIcosaFaces]int{
+	{0, ki, -1, -1, ij, jk, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+	{ij, 0, ki, -1, -1, -1, jk, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+	{-1, ij, 0, ki, -1, -1, -1, jk, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+	{-1, -1, ij, 0, ki, -1, -1, -1, jk, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+	{ki, -1, -1, ij, 0, -1, -1, -1, -1, jk, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+	{jk, -1, -1, -1, -1, 0, -1, -1, -1, -1, ij, -1, -1, -1, ki, -1, -1, -1, -1, -1},
+	{-1, jk, -1, -1, -1, -1, 0, -1, -1, -1, ki, ij, -1, -1, -1, -1, -1, -1, -1, -1},
+	{-1, -1, jk, -1, -1, -1, -1, 0, -1, -1, -1, ki, ij, -1, -1, -1, -1, -1, -1, -1},
+	{-1, -1, -1, jk, -1, -1, -1, -1, 0, -1, -1, -1, ki, ij, -1, -1, -1, -1, -1, -1},
+	{-1, -1, -1, -1, jk, -1, -1, -1, -1, 0, -1, -1, -1, ki, ij, -1, -1, -1, -1, -1},
+	{-1, -1, -1, -1, -1, ij, ki, -1, -1, -1, 0, -1, -1, -1, -1, jk, -1, -1, -1, -1},
+	{-1, -1, -1, -1, -1, -1, ij, ki, -1, -1, -1, 0, -1, -1, -1, -1, jk, -1, -1, -1},
+	{-1, -1, -1, -1, -1, -1, -1, ij, ki, -1, -1, -1, 0, -1, -1, -1, -1, jk, -1, -1},
+	{-1, -1, -1, -1, -1, -1, -1, -1, ij, ki, -1, -1, -1, 0, -1, -1, -1, -1, jk, -1},
+	{-1, -1, -1, -1, -1, ki, -1, -1, -1, ij, -1, -1, -1, -1, 0, -1, -1, -1, -1, jk},
+	{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, jk, -1, -1, -1, -1, 0, ij, -1, -1, ki},
+	{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, jk, -1, -1, -1, ki, 0, ij, -1, -1},
+	{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, jk, -1, -1, -1, ki, 0, ij, -1},
+	{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, jk, -1, -1, -1, ki, 0, ij},
+	{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, jk, ij, -1, -1, ki, 0},
+}
+
+// maxDimByCIIres holds the maximum ijk+ coordinate on a face at each Class
+// II resolution.
+var maxDimByCIIres = [...]int{2, -1, 14, -1, 98, -1, 686, -1, 4802, -1, 33614, -1, 235298, -1, 1647086, -1, 11529602}
+
+// unitScaleByCIIres holds the length of a resolution 0 unit vector in units
+// of each Class II resolution.
+var unitScaleByCIIres = [...]int{1, -1, 7, -1, 49, -1, 343, -1, 2401, -1, 16807, -1, 117649, -1, 823543, -1, 5764801}
+
+// isClassIII returns true if res is a Class III resolution, whose axes are
+// rotated relative to the icosahedron.
+func isClassIII(res int) bool {
+	return res%2 == 1
+}
+
+// posAngle returns rads normalized to [0, 2π).
+func posAngle(rads float64) float64 {
+	tmp := rads
+	if rads < 0 {
+		tmp = rads + 2*math.Pi
+	}
+	if rads >= 2*math.Pi {
+		tmp -= 2 * math.Pi
+	}
+	return tmp
+}
+
+// constrainLng returns lng normalized to [-π, π].
+func constrainLng(lng float64) float64 {
+	for lng > math.Pi {
+		lng -= 2 * math.Pi
+	}
+	for lng < -math.Pi {
+		lng += 2 * math.Pi
+	}
+	return lng
+}
+
+// azimuth returns the azimuth from p1 to p2.
+func azimuth(p1, p2 latLng) float64 {
+	return math.Atan2(
+		math.Cos(p2.lat)*math.Sin(p2.lng-p1.lng),
+		math.Cos(p1.lat)*math.Sin(p2.lat)-math.Sin(p1.lat)*math.Cos(p2.lat)*math.Cos(p2.lng-p1.lng),
+	)
+}
+
+// azDistance returns the point at the given azimuth and angular distance
+// from p1.
+func azDistance(p1 latLng, az, distance float64) latLng {
+	if distance < epsilon {
+		return p1
+	}
+	var p2 latLng
+	az = posAngle(az)
+	if az < epsilon || math.Abs(az-math.Pi) < epsilon {
+		// Due north or south.
+		if az < epsilon {
+			p2.lat = p1.lat + distance
+		} else {
+			p2.lat = p1.lat - distance
+		}
+		switch {
+		case math.Abs(p2.lat-math.Pi/2) < epsilon:
+			p2 = latLng{math.Pi / 2, 0}
+		case math.Abs(p2.lat+math.Pi/2) < epsilon:
+			p2 = latLng{-math.Pi / 2, 0}
+		default:
+			p2.lng = constrainLng(p1.lng)
+		}
+		return p2
+	}
+	sinlat := math.Sin(p1.lat)*math.Cos(distance) + math.Cos(p1.lat)*math.Sin(distance)*math.Cos(az)
+	sinlat = math.Max(-1, math.Min(1, sinlat))
+	p2.lat = math.Asin(sinlat)
+	switch {
+	case math.Abs(p2.lat-math.Pi/2) < epsilon:
+		p2 = latLng{math.Pi / 2, 0}
+	case math.Abs(p2.lat+math.Pi/2) < epsilon:
+		p2 = latLng{-math.Pi / 2, 0}
+	default:
+		sinlng := math.Sin(az) * math.Sin(distance) / math.Cos(p2.lat)
+		coslng := (math.Cos(distance) - math.Sin(p1.lat)*math.Sin(p2.lat)) / math.Cos(p1.lat) / math.Cos(p2.lat)
+		sinlng = math.Max(-1, math.Min(1, sinlng))
+		coslng = math.Max(-1, math.Min(1, coslng))
+		p2.lng = constrainLng(p1.lng + math.Atan2(sinlng, coslng))
+	}
+	return p2
+}
+
+// closestFace returns the face whose center is closest to g, and the square
+// of the euclidean distance between them on the unit sphere.
+func closestFace(g latLng) (int, float64) {
+	r := math.Cos(g.lat)
+	v := [3]float64{math.Cos(g.lng) * r, math.Sin(g.lng) * r, math.Sin(g.lat)}
+	face, sqd := 0, 5.0
+	for f, c := range faceCenterPoint {
+		dx, dy, dz := c[0]-v[0], c[1]-v[1], c[2]-v[2]
+		if d := dx*dx + dy*dy + dz*dz; d < sqd {
+			face, sqd = f, d
+		}
+	}
+	return face, sqd
+}
+
+// geoToFaceIJK returns the position of the cell containing g at res.
+func geoToFaceIJK(g latLng, res int) faceIJK {
+	face, v := geoToHex2d(g, res)
+	return faceIJK{face, hex2dToCoordIJK(v)}
+}
+
+// geoToHex2d returns the face closest to g and the position of g in the
+// hex2d plane of that face at res.
+func geoToHex2d(g latLng, res int) (int, vec2d) {
+	face, sqd := closestFace(g)
+
+	// cos(r) = 1 - 2 * sin^2(r/2) = 1 - 2 * (sqd / 4) = 1 - sqd/2
+	r := math.Acos(1 - sqd/2)
+	if r < epsilon {
+		return face, vec2d{}
+	}
+
+	// The angle counter-clockwise from the Class II i-axis.
+	theta := posAngle(faceAxesAzRadsCII[face][0] - posAngle(azimuth(faceCenterGeo[face], g)))
+	if isClassIII(res) {
+		theta = posAngle(theta - ap7RotRads)
+	}
+
+	// Gnomonic scaling of r, then scaling for the resolution.
+	r = math.Tan(r) / res0UGnomonic
+	for range res {
+		r *= sqrt7
+	}
+	return face, vec2d{r * math.Cos(theta), r * math.Sin(theta)}
+}
+
+// hex2dToGeo returns the position on the sphere of v in the hex2d plane of
+// face at res. substrate is true if v is in the aperture 33r substrate grid
+// used for cell vertices.
+func hex2dToGeo(v vec2d, face, res int, substrate bool) latLng {
+	r := v.mag()
+	if r < epsilon {
+		return faceCenterGeo[face]
+	}
+	theta := math.Atan2(v.y, v.x)
+
+	for range res {
+		r /= sqrt7
+	}
+	if substrate {
+		r /= 3
+		if isClassIII(res) {
+			r /= sqrt7
+		}
+	}
+	r = math.Atan(r * res0UGnomonic)
+
+	// Substrate grids are already adjusted for Class III.
+	if !substrate && isClassIII(res) {
+		theta = posAngle(theta + ap7RotRads)
+	}
+	theta = posAngle(faceAxesAzRadsCII[face][0] - theta)
+	return azDistance(faceCenterGeo[face], theta, r)
+}
+
+// toGeo returns the center of the cell at h.
+func (h faceIJK) toGeo(res int) latLng {
+	return hex2dToGeo(h.coord.toHex2d(), h.face, res, false)
+}
+
+var (
+	// hexVertsCII holds the vertices of an origin-centered cell at a Class
+	// II resolution in the aperture 33r substrate grid, counter-clockwise
+	// from the i-axis.
+	hexVertsCII = [numHexVerts]coordIJK{{2, 1, 0}, {1, 2, 0}, {0, 2, 1}, {0, 1, 2}, {1, 0, 2}, {2, 0, 1}}
+	// hexVertsCIII holds the vertices of an origin-centered cell at a Class
+	// III resolution in the aperture 33r7r substrate grid.
+	hexVertsCIII = [numHexVerts]coordIJK{{5, 4, 0}, {1, 5, 0}, {0, 5, 4}, {0, 1, 5}, {4, 0, 5}, {5, 0, 1}}
+)
+
+// verts returns the vertices of the cell at h in the substrate grid, and
+// the Class II resolution of that grid. Pentagons use the first five.
+func (h faceIJK) verts(res int) ([numHexVerts]faceIJK, int) {
+	verts := &hexVertsCII
+	if isClassIII(res) {
+		verts = &hexVertsCIII
+	}
+
+	// Move the center into the aperture 33r substrate grid, and for Class
+	// III add a clockwise aperture 7 to get to Class II.
+	center := h.coord.downAp3().downAp3r()
+	if isClassIII(res) {
+		center = center.downAp7r()
+		res++
+	}
+
+	var out [numHexVerts]faceIJK
+	for v := range out {
+		out[v] = faceIJK{h.face, center.add(verts[v]).normalized()}
+	}
+	return out, res
+}
+
+// faceEdge returns the endpoints of the edge of a face, at the Class II
+// resolution res, that lies in the given quadrant.
+func faceEdgeVerts(quadrant, res int) (vec2d, vec2d) {
+	maxDim := float64(maxDimByCIIres[res])
+	v0 := vec2d{3 * maxDim, 0}
+	v1 := vec2d{-1.5 * maxDim, 3 * sqrt3_2 * maxDim}
+	v2 := vec2d{-1.5 * maxDim, -3 * sqrt3_2 * maxDim}
+	switch quadrant {
+	case ij:
+		return v0, v1
+	case jk:
+		return v1, v2
+	default:
+		return v2, v0
+	}
+}
+
+// boundary returns the vertices of the hexagon at h. Class III hexagons
+// get extra vertices where their edges cross the edges of the icosahedron.
+func (h faceIJK) boundary(res int) []latLng {
+	verts, adjRes := h.verts(res)
+	out := make([]latLng, 0, 2*numHexVerts)
+
+	// Loop once more to check for a distortion vertex on the last edge.
+	lastFace, lastOverage := -1, noOverage
+	for vert := 0; vert < numHexVerts+1; vert++ {
+		v := vert % numHexVerts
+		fijk := verts[v]
+		overage := fijk.adjustOverageClassII(adjRes, false, true)
+
+		// Each face is a different projection plane, so an edge that
+		// crosses an icosahedron edge gets a vertex at the crossing. Class
+		// II cells have their vertices on face edges instead.
+		if isClassIII(res) && vert > 0 && fijk.face != lastFace && lastOverage != faceEdge {
+			lastV := (v + 5) % numHexVerts
+			orig0 := verts[lastV].coord.toHex2d()
+			orig1 := verts[v].coord.toHex2d()
+
+			face2 := lastFace
+			if lastFace == h.face {
+				face2 = fijk.face
+			}
+			edge0, edge1 := faceEdgeVerts(adjacentFaceDir[h.face][face2], adjRes)
+			inter := intersect(orig0, orig1, edge0, edge1)
+
+			// An intersection at a vertex means both edges lie on a
+			// single face.
+			if !orig0.almostEquals(inter) && !orig1.almostEquals(inter) {
+				out = append(out, hex2dToGeo(inter, h.face, adjRes, true))
+			}
+		}
+
+		if vert < numHexVerts {
+			out = append(out, hex2dToGeo(fijk.coord.toHex2d(), fijk.face, adjRes, true))
+		}
+		lastFace, lastOverage = fijk.face, overage
+	}
+	return out
+}
+
+// pentBoundary returns the vertices of the pentagon at h. Class III
+// pentagons get extra vertices where their edges cross the edges of the
+// icosahedron.
+func (h faceIJK) pentBoundary(res int) []latLng {
+	verts, adjRes := h.verts(res)
+	out := make([]latLng, 0, 2*numPentVerts)
+
+	var lastFijk faceIJK
+	for vert := 0; vert < numPentVerts+1; vert++ {
+		v := vert % numPentVerts
+		fijk := verts[v]
+		fijk.adjustPentVertOverage(adjRes)
+
+		// All Class III pentagon edges cross icosahedron edges.
+		if isClassIII(res) && vert > 0 {
+			orig0 := lastFijk.coord.toHex2d()
+
+			// Express the current vertex on the face of the last one.
+			orient := faceNeighbors[fijk.face][adjacentFaceDir[fijk.face][lastFijk.face]]
+			tmp := faceIJK{face: orient.face, coord: fijk.coord}
+			for range orient.ccwRot60 {
+				tmp.coord = tmp.coord.rotate60ccw()
+			}
+			tmp.coord = tmp.coord.add(orient.translate.scale(unitScaleByCIIres[adjRes] * 3)).normalized()
+			orig1 := tmp.coord.toHex2d()
+
+			edge0, edge1 := faceEdgeVerts(adjacentFaceDir[tmp.face][fijk.face], adjRes)
+			inter := intersect(orig0, orig1, edge0, edge1)
+			out = append(out, hex2dToGeo(inter, tmp.face, adjRes, true))
+		}
+
+		if vert < numPentVerts {
+			out = append(out, hex2dToGeo(fijk.coord.toHex2d(), fijk.face, adjRes, true))
+		}
+		lastFijk = fijk
+	}
+	return out
+}
+
+// adjustOverageClassII moves h, at the Class II resolution res, to the
+// neighbouring face if it lies beyond the edge of its face. pentLeading4
+// adjusts for the missing sequence of a pentagon with a leading 4 digit.
+// substrate is true if h is in the aperture 33r substrate grid.
+func (h *faceIJK) adjustOverageClassII(res int, pentLeading4, substrate bool) overage {
+	ijk := &h.coord
+	maxDim := maxDimByCIIres[res]
+	if substrate {
+		maxDim *= 3
+	}
+
+	sum := ijk.i + ijk.j + ijk.k
+	if substrate && sum == maxDim {
+		return faceEdge
+	}
+	if sum <= maxDim {
+		return noOverage
+	}
+
+	var orient faceOrientIJK
+	switch {
+	case ijk.k > 0 && ijk.j > 0:
+		orient = faceNeighbors[h.face][jk]
+	case ijk.k > 0:
+		orient = faceNeighbors[h.face][ki]
+		if pentLeading4 {
+			// Rotate around the center of the pentagon to adjust for
+			// the missing sequence.
+			origin := coordIJK{maxDim, 0, 0}
+			*ijk = ijk.sub(origin).rotate60cw().add(origin)
+		}
+	default:
+		orient = faceNeighbors[h.face][ij]
+	}
+
+	h.face = orient.face
+	for range orient.ccwRot60 {
+		*ijk = ijk.rotate60ccw()
+	}
+	unitScale := unitScaleByCIIres[res]
+	if substrate {
+		unitScale *= 3
+	}
+	*ijk = ijk.add(orient.translate.scale(unitScale)).normalized()
+
+	// Overage points on pentagon boundaries can end up on edges.
+	if substrate && ijk.i+ijk.j+ijk.k == maxDim {
+		return faceEdge
+	}
+	return newFace
+}
+
+// adjustPentVertOverage moves the pentagon vertex h, in the substrate grid,
+// onto the face it lies on.
+func (h *faceIJK) adjustPentVertOverage(res int) overage {
+	for {
+		if o := h.adjustOverageClassII(res, false, true); o != newFace {
+			return o
+		}
+	}
+}
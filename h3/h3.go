@@ -0,0 +1,452 @@
+// Package h3 implements the H3 hierarchical hexagonal grid, see
+// https://h3geo.org.
+//
+// H3 projects the sphere onto the faces of an icosahedron and tiles each
+// face with hexagons, plus twelve pentagons at the vertices of the
+// icosahedron. Every cell has seven children at the next finer resolution,
+// so that a cell is identified by one of 122 base cells followed by up to 15
+// digits. This package is a port of the reference implementation and
+// returns identical cell indexes.
+//
+// Coordinates are longitudes and latitudes in degrees, in that order.
+package h3
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/matoous/goodgeo"
+)
+
+// MaxResolution is the finest resolution of the grid.
+const MaxResolution = 15
+
+// Errors returned by the functions of this package.
+var (
+	ErrInvalidCell       = errors.New("h3: invalid cell")
+	ErrInvalidDistance   = errors.New("h3: negative grid distance")
+	ErrInvalidLatLng     = errors.New("h3: latitude or longitude is not finite")
+	ErrInvalidResolution = errors.New("h3: invalid resolution")
+)
+
+// A Cell is the 64-bit index of a cell of the grid.
+type Cell uint64
+
+// The layout of a Cell, from the most significant bit: a reserved bit, four
+// bits of mode, three reserved bits, four bits of resolution, seven bits of
+// base cell and 15 digits of three bits each. Digits finer than the
+// resolution of the cell are set to 7.
+const (
+	modeOffset     = 59
+	resOffset      = 52
+	baseCellOffset = 45
+	digitBits      = 3
+
+	cellMode = 1
+	// initCell has mode and resolution 0, base cell 0 and all digits set
+	// to 7.
+	initCell Cell = 1<<baseCellOffset - 1
+
+	highBitMask  Cell = 1 << 63
+	modeMask     Cell = 15 << modeOffset
+	reservedMask Cell = 7 << 56
+	resMask      Cell = 15 << resOffset
+	baseCellMask Cell = 127 << baseCellOffset
+	digitMask    Cell = 7
+)
+
+// newCell returns the cell at res in baseCell with all digits set to d.
+func newCell(res, baseCell int, d direction) Cell {
+	h := initCell
+	h = h&^modeMask | cellMode<<modeOffset
+	h = h.setResolution(res)
+	h = h.setBaseCell(baseCell)
+	for r := 1; r <= res; r++ {
+		h = h.setDigit(r, d)
+	}
+	return h
+}
+
+// ParseCell parses the hexadecimal representation of a cell.
+func ParseCell(s string) (Cell, error) {
+	v, err := strconv.ParseUint(s, 16, 64)
+	if err != nil || !Cell(v).IsValid() {
+		return 0, ErrInvalidCell
+	}
+	return Cell(v), nil
+}
+
+// String returns the hexadecimal representation of c.
+func (c Cell) String() string {
+	return strconv.FormatUint(uint64(c), 16)
+}
+
+// Resolution returns the resolution of c.
+func (c Cell) Resolution() int {
+	return int(c & resMask >> resOffset)
+}
+
+// BaseCell returns the number of the resolution 0 cell that contains c.
+func (c Cell) BaseCell() int {
+	return int(c & baseCellMask >> baseCellOffset)
+}
+
+// IsValid returns true if c is a valid cell index.
+func (c Cell) IsValid() bool {
+	if c&highBitMask != 0 || c&modeMask>>modeOffset != cellMode || c&reservedMask != 0 {
+		return false
+	}
+	baseCell := c.BaseCell()
+	if baseCell >= numBaseCells {
+		return false
+	}
+	res := c.Resolution()
+	foundFirstNonZeroDigit := false
+	for r := 1; r <= res; r++ {
+		d := c.digit(r)
+		if !foundFirstNonZeroDigit && d != centerDigit {
+			foundFirstNonZeroDigit = true
+			// Pentagons have no children in the k-axes direction.
+			if isBaseCellPentagon(baseCell) && d == kAxesDigit {
+				return false
+			}
+		}
+		if d >= invalidDigit {
+			return false
+		}
+	}
+	for r := res + 1; r <= MaxResolution; r++ {
+		if c.digit(r) != invalidDigit {
+			return false
+		}
+	}
+	return true
+}
+
+// IsPentagon returns true if c is one of the twelve pentagons of its
+// resolution.
+func (c Cell) IsPentagon() bool {
+	return isBaseCellPentagon(c.BaseCell()) && c.leadingNonZeroDigit() == centerDigit
+}
+
+func (c Cell) setResolution(res int) Cell {
+	return c&^resMask | Cell(res)<<resOffset
+}
+
+func (c Cell) setBaseCell(baseCell int) Cell {
+	return c&^baseCellMask | Cell(baseCell)<<baseCellOffset
+}
+
+// digit returns the digit of c at resolution r.
+func (c Cell) digit(r int) direction {
+	return direction(c >> ((MaxResolution - r) * digitBits) & digitMask)
+}
+
+func (c Cell) setDigit(r int, d direction) Cell {
+	shift := (MaxResolution - r) * digitBits
+	return c&^(digitMask<<shift) | Cell(d)<<shift
+}
+
+// leadingNonZeroDigit returns the coarsest non-zero digit of c, or
+// centerDigit if there is none.
+func (c Cell) leadingNonZeroDigit() direction {
+	for r := 1; r <= c.Resolution(); r++ {
+		if d := c.digit(r); d != centerDigit {
+			return d
+		}
+	}
+	return centerDigit
+}
+
+// rotate60ccw rotates the digits of c 60 degrees counter-clockwise.
+func (c Cell) rotate60ccw() Cell {
+	for r := 1; r <= c.Resolution(); r++ {
+		c = c.setDigit(r, c.digit(r).rotate60ccw())
+	}
+	return c
+}
+
+// rotate60cw rotates the digits of c 60 degrees clockwise.
+func (c Cell) rotate60cw() Cell {
+	for r := 1; r <= c.Resolution(); r++ {
+		c = c.setDigit(r, c.digit(r).rotate60cw())
+	}
+	return c
+}
+
+// rotatePent60ccw rotates the digits of c 60 degrees counter-clockwise
+// around a pentagon, skipping the deleted k-axes sequence.
+func (c Cell) rotatePent60ccw() Cell {
+	foundFirstNonZeroDigit := false
+	for r := 1; r <= c.Resolution(); r++ {
+		c = c.setDigit(r, c.digit(r).rotate60ccw())
+		if !foundFirstNonZeroDigit && c.digit(r) != centerDigit {
+			foundFirstNonZeroDigit = true
+			if c.leadingNonZeroDigit() == kAxesDigit {
+				c = c.rotate60ccw()
+			}
+		}
+	}
+	return c
+}
+
+// rotatePent60cw rotates the digits of c 60 degrees clockwise around a
+// pentagon, skipping the deleted k-axes sequence.
+func (c Cell) rotatePent60cw() Cell {
+	foundFirstNonZeroDigit := false
+	for r := 1; r <= c.Resolution(); r++ {
+		c = c.setDigit(r, c.digit(r).rotate60cw())
+		if !foundFirstNonZeroDigit && c.digit(r) != centerDigit {
+			foundFirstNonZeroDigit = true
+			if c.leadingNonZeroDigit() == kAxesDigit {
+				c = c.rotate60cw()
+			}
+		}
+	}
+	return c
+}
+
+// LatLngToCell returns the cell at res that contains c.
+func LatLngToCell(c goodgeo.Coord, res int) (Cell, error) {
+	if res < 0 || res > MaxResolution {
+		return 0, ErrInvalidResolution
+	}
+	if len(c) < 2 || math.IsNaN(c[0]) || math.IsInf(c[0], 0) || math.IsNaN(c[1]) || math.IsInf(c[1], 0) {
+		return 0, ErrInvalidLatLng
+	}
+	cell := faceIJKToCell(geoToFaceIJK(toLatLng(c), res), res)
+	if cell == 0 {
+		return 0, ErrInvalidLatLng
+	}
+	return cell, nil
+}
+
+// CellToLatLng returns the center of c.
+func CellToLatLng(c Cell) (goodgeo.Coord, error) {
+	if !c.IsValid() {
+		return nil, ErrInvalidCell
+	}
+	return fromLatLng(c.faceIJK().toGeo(c.Resolution())), nil
+}
+
+// CellToBoundary returns the boundary of c. The longitudes of the ring are
+// continuous, so that a cell that crosses the antimeridian has longitudes
+// beyond ±180.
+func CellToBoundary(c Cell) (*goodgeo.Polygon, error) {
+	if !c.IsValid() {
+		return nil, ErrInvalidCell
+	}
+	verts := c.boundary()
+	ring := make([]float64, 0, 2*(len(verts)+1))
+	var prev float64
+	for i, v := range verts {
+		lng := v.lng * 180 / math.Pi
+		if i > 0 {
+			// Unwrap the longitude relative to the previous vertex.
+			for lng-prev > 180 {
+				lng -= 360
+			}
+			for lng-prev < -180 {
+				lng += 360
+			}
+		}
+		ring = append(ring, lng, v.lat*180/math.Pi)
+		prev = lng
+	}
+	ring = append(ring, ring[0], ring[1])
+	return goodgeo.NewPolygonFlat(goodgeo.XY, ring, []int{len(ring)}), nil
+}
+
+// boundary returns the vertices of c counter-clockwise.
+func (c Cell) boundary() []latLng {
+	fijk := c.faceIJK()
+	if c.IsPentagon() {
+		return fijk.pentBoundary(c.Resolution())
+	}
+	return fijk.boundary(c.Resolution())
+}
+
+// toLatLng converts c, in degrees, to radians.
+func toLatLng(c goodgeo.Coord) latLng {
+	return latLng{c[1] * math.Pi / 180, c[0] * math.Pi / 180}
+}
+
+// fromLatLng converts g to a coordinate in degrees.
+func fromLatLng(g latLng) goodgeo.Coord {
+	return goodgeo.Coord{g.lng * 180 / math.Pi, g.lat * 180 / math.Pi}
+}
+
+// faceIJKToCell returns the cell at res at the position h, or 0 if h is
+// outside the range of the faces.
+func faceIJKToCell(h faceIJK, res int) Cell {
+	cell := newCell(res, 0, invalidDigit)
+	if res == 0 {
+		if h.coord.i > maxFaceCoord || h.coord.j > maxFaceCoord || h.coord.k > maxFaceCoord {
+			return 0
+		}
+		return cell.setBaseCell(baseCellAt(h).baseCell)
+	}
+
+	// Build the digits from the finest resolution up, leaving h at the
+	// position of the base cell on the face.
+	for r := res - 1; r >= 0; r-- {
+		last := h.coord
+		var center coordIJK
+		if isClassIII(r + 1) {
+			h.coord = h.coord.upAp7()
+			center = h.coord.downAp7()
+		} else {
+			h.coord = h.coord.upAp7r()
+			center = h.coord.downAp7r()
+		}
+		cell = cell.setDigit(r+1, last.sub(center).normalized().toDigit())
+	}
+	if h.coord.i > maxFaceCoord || h.coord.j > maxFaceCoord || h.coord.k > maxFaceCoord {
+		return 0
+	}
+
+	// Rotate into the canonical orientation of the base cell.
+	bc := baseCellAt(h)
+	cell = cell.setBaseCell(bc.baseCell)
+	if !isBaseCellPentagon(bc.baseCell) {
+		for range bc.ccwRot60 {
+			cell = cell.rotate60ccw()
+		}
+		return cell
+	}
+	// Force the rotation out of the missing k-axes sequence.
+	if cell.leadingNonZeroDigit() == kAxesDigit {
+		if baseCellIsCwOffset(bc.baseCell, h.face) {
+			cell = cell.rotate60cw()
+		} else {
+			cell = cell.rotate60ccw()
+		}
+	}
+	for range bc.ccwRot60 {
+		cell = cell.rotatePent60ccw()
+	}
+	return cell
+}
+
+// faceIJK returns the position of c on the face it lies on.
+func (c Cell) faceIJK() faceIJK {
+	baseCell := c.BaseCell()
+	// All of sub-sequence 5 of a pentagon needs to be adjusted for the
+	// missing sequence, and some of sub-sequence 4 below.
+	if isBaseCellPentagon(baseCell) && c.leadingNonZeroDigit() == ikAxesDigit {
+		c = c.rotate60cw()
+	}
+
+	fijk := baseCellData[baseCell].homeFIJK
+	if !c.descend(&fijk) {
+		return fijk
+	}
+
+	// c may lie on an adjacent face; if it is Class III, drop into the
+	// next finer Class II grid to check.
+	orig := fijk.coord
+	res := c.Resolution()
+	if isClassIII(res) {
+		fijk.coord = fijk.coord.downAp7r()
+		res++
+	}
+	pentLeading4 := isBaseCellPentagon(baseCell) && c.leadingNonZeroDigit() == iAxesDigit
+	switch {
+	case fijk.adjustOverageClassII(res, pentLeading4, false) != noOverage:
+		// Pentagons may have secondary overages.
+		if isBaseCellPentagon(baseCell) {
+			for fijk.adjustOverageClassII(res, false, false) != noOverage {
+			}
+		}
+		if res != c.Resolution() {
+			fijk.coord = fijk.coord.upAp7r()
+		}
+	case res != c.Resolution():
+		fijk.coord = orig
+	}
+	return fijk
+}
+
+// descend moves fijk, initialized with the position of the base cell of c,
+// down the digits of c. It returns true if c may lie on another face.
+func (c Cell) descend(fijk *faceIJK) bool {
+	res := c.Resolution()
+	possibleOverage := isBaseCellPentagon(c.BaseCell()) || (res != 0 && fijk.coord != coordIJK{})
+	for r := 1; r <= res; r++ {
+		if isClassIII(r) {
+			fijk.coord = fijk.coord.downAp7()
+		} else {
+			fijk.coord = fijk.coord.downAp7r()
+		}
+		fijk.coord = fijk.coord.neighbor(c.digit(r))
+	}
+	return possibleOverage
+}
+
+// CellToParent returns the ancestor of c at res, which must not be finer
+// than the resolution of c.
+func CellToParent(c Cell, res int) (Cell, error) {
+	if !c.IsValid() {
+		return 0, ErrInvalidCell
+	}
+	if res < 0 || res > c.Resolution() {
+		return 0, ErrInvalidResolution
+	}
+	parent := c.setResolution(res)
+	for r := res + 1; r <= c.Resolution(); r++ {
+		parent = parent.setDigit(r, invalidDigit)
+	}
+	return parent, nil
+}
+
+// CellToCenterChild returns the child of c at res that has the same center
+// as c.
+func CellToCenterChild(c Cell, res int) (Cell, error) {
+	if !c.IsValid() {
+		return 0, ErrInvalidCell
+	}
+	if res < c.Resolution() || res > MaxResolution {
+		return 0, ErrInvalidResolution
+	}
+	child := c.setResolution(res)
+	for r := c.Resolution() + 1; r <= res; r++ {
+		child = child.setDigit(r, centerDigit)
+	}
+	return child, nil
+}
+
+// CellToChildren returns the descendants of c at res, in ascending order.
+func CellToChildren(c Cell, res int) ([]Cell, error) {
+	if !c.IsValid() {
+		return nil, ErrInvalidCell
+	}
+	if res < c.Resolution() || res > MaxResolution {
+		return nil, ErrInvalidResolution
+	}
+	n := 1
+	for range res - c.Resolution() {
+		n *= 7
+	}
+	if c.IsPentagon() {
+		n = 1 + 5*(n-1)/6
+	}
+	children := make([]Cell, 0, n)
+	var walk func(Cell, bool)
+	walk = func(c Cell, pentagon bool) {
+		if c.Resolution() == res {
+			children = append(children, c)
+			return
+		}
+		child := c.setResolution(c.Resolution() + 1)
+		for d := centerDigit; d < invalidDigit; d++ {
+			// Pentagons have no children in the k-axes direction.
+			if pentagon && d == kAxesDigit {
+				continue
+			}
+			walk(child.setDigit(child.Resolution(), d), pentagon && d == centerDigit)
+		}
+	}
+	walk(c, c.IsPentagon())
+	return children, nil
+}
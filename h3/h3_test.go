@@ -0,0 +1,299 @@
+package h3
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/matoous/goodgeo"
+)
+
+// The expected values in this file were produced by the H3 reference
+// implementation, version 4.1.0.
+
+func mustParseCell(t *testing.T, s string) Cell {
+	t.Helper()
+	c, err := ParseCell(s)
+	assert.NoError(t, err)
+	return c
+}
+
+func cellStrings(cells []Cell) []string {
+	s := make([]string, len(cells))
+	for i, c := range cells {
+		s[i] = c.String()
+	}
+	return s
+}
+
+func assertCoordsClose(t *testing.T, want, got []goodgeo.Coord) {
+	t.Helper()
+	assert.Equal(t, len(want), len(got))
+	for i := range want {
+		for j := range 2 {
+			assert.True(t, math.Abs(want[i][j]-got[i][j]) < 1e-9, "coordinate %d: want %v, got %v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestLatLngToCell(t *testing.T) {
+	for i, tc := range []struct {
+		coord goodgeo.Coord
+		res   int
+		want  string
+	}{
+		{coord: goodgeo.Coord{-122.0553238, 37.3615593}, res: 7, want: "87283472bffffff"},
+		{coord: goodgeo.Coord{-0.1246254, 51.5007292}, res: 0, want: "8019fffffffffff"},
+		{coord: goodgeo.Coord{-0.1246254, 51.5007292}, res: 9, want: "89194ad14c3ffff"},
+		{coord: goodgeo.Coord{-0.1246254, 51.5007292}, res: 15, want: "8f194ad14c25499"},
+		{coord: goodgeo.Coord{151.2152967, -33.8567844}, res: 5, want: "85be0e37fffffff"},
+		{coord: goodgeo.Coord{10.536, 64.7}, res: 1, want: "81083ffffffffff"},
+		{coord: goodgeo.Coord{180, 0}, res: 3, want: "837eb5fffffffff"},
+		{coord: goodgeo.Coord{-179.99, -16.1}, res: 8, want: "889b5d7343fffff"},
+		{coord: goodgeo.Coord{45, 89.999}, res: 4, want: "8403263ffffffff"},
+		{coord: goodgeo.Coord{0, -90}, res: 2, want: "82f297fffffffff"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c, err := LatLngToCell(tc.coord, tc.res)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, c.String())
+			assert.True(t, c.IsValid())
+			assert.Equal(t, tc.res, c.Resolution())
+
+			// The center of a cell is in the cell.
+			center, err := CellToLatLng(c)
+			assert.NoError(t, err)
+			got, err := LatLngToCell(center, tc.res)
+			assert.NoError(t, err)
+			assert.Equal(t, c, got)
+		})
+	}
+}
+
+func TestCellToBoundary(t *testing.T) {
+	for i, tc := range []struct {
+		cell     string
+		pentagon bool
+		center   goodgeo.Coord
+		boundary []goodgeo.Coord
+	}{
+		{
+			cell:   "87283472bffffff",
+			center: goodgeo.Coord{-122.05032565263944, 37.35171820183272},
+			boundary: []goodgeo.Coord{
+				{-122.04156135164335, 37.34109909323569}, {-122.03403171908789, 37.352896581102904},
+				{-122.04279666094904, 37.36351522362578}, {-122.05909124330346, 37.362335222443996},
+				{-122.06661754027382, 37.3505376991078}, {-122.05785259137618, 37.33992021232257},
+			},
+		},
+		{
+			cell:     "8009fffffffffff",
+			pentagon: true,
+			center:   goodgeo.Coord{10.53619907546767, 64.70000012793487},
+			boundary: []goodgeo.Coord{
+				{-10.444977544778325, 63.095054077525454}, {5.523646549290313, 55.706768465152265},
+				{25.082722326707874, 58.4015448703527}, {31.83128049908738, 68.92995788193983},
+				{0.32561035194326043, 73.31022368544396},
+			},
+		},
+		{
+			// Class III pentagons have extra vertices where their edges
+			// cross the edges of the icosahedron.
+			cell:     "81083ffffffffff",
+			pentagon: true,
+			center:   goodgeo.Coord{10.53619907546767, 64.70000012793487},
+			boundary: []goodgeo.Coord{
+				{4.012620898449968, 63.3270613280184}, {8.644221197607212, 61.89083847532621},
+				{11.080660058482366, 61.5405146000252}, {15.771773841154104, 62.88996835796253},
+				{17.535446308408343, 63.800792653212156}, {16.433696996747415, 66.32726173342832},
+				{14.81365872582752, 67.35176867523613}, {8.130261032188706, 67.46842788455002},
+				{5.239258880169474, 67.01563262841769}, {3.699933260287907, 64.5256084219684},
+			},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c := mustParseCell(t, tc.cell)
+			assert.Equal(t, tc.pentagon, c.IsPentagon())
+
+			center, err := CellToLatLng(c)
+			assert.NoError(t, err)
+			assertCoordsClose(t, []goodgeo.Coord{tc.center}, []goodgeo.Coord{center})
+
+			p, err := CellToBoundary(c)
+			assert.NoError(t, err)
+			ring := p.Coords()[0]
+			assert.Equal(t, ring[0], ring[len(ring)-1])
+			assertCoordsClose(t, tc.boundary, ring[:len(ring)-1])
+		})
+	}
+}
+
+func TestCellToBoundaryAntimeridian(t *testing.T) {
+	c, err := LatLngToCell(goodgeo.Coord{180, 0}, 3)
+	assert.NoError(t, err)
+	p, err := CellToBoundary(c)
+	assert.NoError(t, err)
+	ring := p.Coords()[0]
+	for i := 1; i < len(ring); i++ {
+		assert.True(t, math.Abs(ring[i][0]-ring[i-1][0]) < 180)
+	}
+}
+
+func TestGridDisk(t *testing.T) {
+	for i, tc := range []struct {
+		cell       string
+		disk, ring []string
+	}{
+		{
+			cell: "87283472bffffff",
+			disk: []string{"87283472bffffff", "87283472affffff", "87283470cffffff", "87283470dffffff", "872834776ffffff", "872834729ffffff", "872834728ffffff"},
+			ring: []string{"87283472effffff", "872834705ffffff", "872834701ffffff", "87283470effffff", "872834708ffffff", "872834709ffffff", "872834772ffffff", "872834770ffffff", "872834774ffffff", "8728340daffffff", "87283472dffffff", "87283472cffffff"},
+		},
+		{
+			// Pentagons have five neighbours.
+			cell: "8009fffffffffff",
+			disk: []string{"8007fffffffffff", "8009fffffffffff", "8019fffffffffff", "8001fffffffffff", "801ffffffffffff", "8011fffffffffff"},
+			ring: []string{"8039fffffffffff", "800ffffffffffff", "8035fffffffffff", "800bfffffffffff", "802dfffffffffff", "8005fffffffffff", "8003fffffffffff", "8021fffffffffff", "801bfffffffffff", "803ffffffffffff"},
+		},
+		{
+			// A pentagon.
+			cell: "81083ffffffffff",
+			disk: []string{"81093ffffffffff", "81097ffffffffff", "8109bffffffffff", "81083ffffffffff", "8108bffffffffff", "8108fffffffffff"},
+			ring: []string{"81077ffffffffff", "81017ffffffffff", "81193ffffffffff", "811f7ffffffffff", "81113ffffffffff", "81197ffffffffff", "81073ffffffffff", "81117ffffffffff", "81013ffffffffff", "811f3ffffffffff"},
+		},
+		{
+			cell: "8b1fb46622dcfff",
+			disk: []string{"8b1fb46622dcfff", "8b1fb46622d1fff", "8b1fb46622defff", "8b1fb46622d8fff", "8b1fb46622ddfff", "8b1fb46622c3fff", "8b1fb46622c2fff"},
+			ring: []string{"8b1fb46622d5fff", "8b1fb46622d0fff", "8b1fb46622d3fff", "8b1fb464492dfff", "8b1fb46622dafff", "8b1fb46622dbfff", "8b1fb46622d9fff", "8b1fb46622cafff", "8b1fb46622cefff", "8b1fb46622c1fff", "8b1fb46622c0fff", "8b1fb46622c6fff"},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c := mustParseCell(t, tc.cell)
+			disk, err := GridDisk(c, 1)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.disk, cellStrings(disk))
+
+			ring, err := GridRing(c, 2)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.ring, cellStrings(ring))
+
+			disk, distances, err := GridDiskDistances(c, 2)
+			assert.NoError(t, err)
+			assert.Equal(t, len(disk), 1+len(tc.disk)-1+len(tc.ring))
+			for j, d := range distances {
+				assert.True(t, d >= 0 && d <= 2)
+				if d == 0 {
+					assert.Equal(t, c, disk[j])
+				}
+			}
+		})
+	}
+}
+
+func TestHierarchy(t *testing.T) {
+	c := mustParseCell(t, "87283472bffffff")
+	parent, err := CellToParent(c, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "85283473fffffff", parent.String())
+	parent, err = CellToParent(c, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, c, parent)
+
+	child, err := CellToCenterChild(c, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "8a283472b007fff", child.String())
+
+	children, err := CellToChildren(c, 8)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"88283472b1fffff", "88283472b3fffff", "88283472b5fffff", "88283472b7fffff", "88283472b9fffff", "88283472bbfffff", "88283472bdfffff"}, cellStrings(children))
+	children, err = CellToChildren(c, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 343, len(children))
+	assert.True(t, slices.IsSorted(children))
+	for _, child := range children {
+		parent, err := CellToParent(child, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, c, parent)
+	}
+
+	// Pentagons have no children in the deleted k-axes direction.
+	pentagon := mustParseCell(t, "8009fffffffffff")
+	children, err = CellToChildren(pentagon, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"81083ffffffffff", "8108bffffffffff", "8108fffffffffff", "81093ffffffffff", "81097ffffffffff", "8109bffffffffff"}, cellStrings(children))
+	children, err = CellToChildren(pentagon, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 1+5*(343-1)/6, len(children))
+}
+
+func TestPolygonToCells(t *testing.T) {
+	exterior := []goodgeo.Coord{
+		{-122.4089866999972145, 37.813318999983238},
+		{-122.3544736999993603, 37.7198061999978478},
+		{-122.4798767000009008, 37.8151571999998453},
+		{-122.4089866999972145, 37.813318999983238},
+	}
+	hole := []goodgeo.Coord{
+		{-122.4471197, 37.7869802},
+		{-122.4590777, 37.7664102},
+		{-122.4137097, 37.7710682},
+		{-122.4471197, 37.7869802},
+	}
+	polygon := goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{exterior})
+	withHole := goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{exterior, hole})
+	transmeridian := goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{{{179, -1}, {-179, -1}, {-179, 1}, {179, 1}, {179, -1}}})
+
+	cells, err := PolygonToCells(polygon, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"872830820ffffff", "872830828ffffff", "87283082affffff", "87283082bffffff", "87283082effffff", "872830870ffffff", "872830876ffffff"}, cellStrings(cells))
+
+	for i, tc := range []struct {
+		g    goodgeo.T
+		res  int
+		want int
+	}{
+		{g: polygon, res: 9, want: 292},
+		{g: withHole, res: 9, want: 288},
+		{g: transmeridian, res: 4, want: 39},
+		{g: goodgeo.NewMultiPolygon(goodgeo.XY).MustSetCoords([][][]goodgeo.Coord{{exterior}, {exterior}}), res: 9, want: 292},
+		{g: goodgeo.NewPolygon(goodgeo.XY), res: 9, want: 0},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			cells, err := PolygonToCells(tc.g, tc.res)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, len(cells))
+			assert.True(t, slices.IsSorted(cells))
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	_, err := LatLngToCell(goodgeo.Coord{0, 0}, 16)
+	assert.IsError(t, err, ErrInvalidResolution)
+	_, err = LatLngToCell(goodgeo.Coord{math.NaN(), 0}, 5)
+	assert.IsError(t, err, ErrInvalidLatLng)
+	_, err = ParseCell("87283472bfffff")
+	assert.IsError(t, err, ErrInvalidCell)
+	_, err = ParseCell("cell")
+	assert.IsError(t, err, ErrInvalidCell)
+	// Pentagons have no child in the k-axes direction.
+	_, err = ParseCell("81087ffffffffff")
+	assert.IsError(t, err, ErrInvalidCell)
+	_, err = CellToBoundary(0)
+	assert.IsError(t, err, ErrInvalidCell)
+
+	c := mustParseCell(t, "87283472bffffff")
+	_, err = CellToParent(c, 8)
+	assert.IsError(t, err, ErrInvalidResolution)
+	_, err = CellToChildren(c, 6)
+	assert.IsError(t, err, ErrInvalidResolution)
+	_, err = GridDisk(c, -1)
+	assert.IsError(t, err, ErrInvalidDistance)
+
+	line := goodgeo.NewLineString(goodgeo.XY)
+	_, err = PolygonToCells(line, 5)
+	assert.IsError(t, err, goodgeo.UnsupportedTypeError{Value: line})
+}
@@ -0,0 +1,242 @@
+package h3
+
+import (
+	"math"
+	"slices"
+
+	"github.com/matoous/goodgeo"
+)
+
+// earthRadiusKm is the authalic radius of the earth used by the reference
+// implementation.
+const earthRadiusKm = 6371.007180918475
+
+// A loop is a ring in radians, without the closing vertex, and its
+// bounding box.
+type loop struct {
+	verts                    []latLng
+	north, south, east, west float64
+}
+
+// newLoop returns the loop of ring, which is in degrees and closed.
+func newLoop(ring []goodgeo.Coord) loop {
+	if n := len(ring); n > 1 && ring[0][0] == ring[n-1][0] && ring[0][1] == ring[n-1][1] {
+		ring = ring[:n-1]
+	}
+	l := loop{verts: make([]latLng, len(ring))}
+	for i, c := range ring {
+		l.verts[i] = toLatLng(c)
+	}
+	if len(l.verts) == 0 {
+		return l
+	}
+
+	l.south, l.west = math.MaxFloat64, math.MaxFloat64
+	l.north, l.east = -math.MaxFloat64, -math.MaxFloat64
+	minPosLng, maxNegLng := math.MaxFloat64, -math.MaxFloat64
+	transmeridian := false
+	for i, v := range l.verts {
+		next := l.verts[(i+1)%len(l.verts)]
+		l.south, l.north = math.Min(l.south, v.lat), math.Max(l.north, v.lat)
+		l.west, l.east = math.Min(l.west, v.lng), math.Max(l.east, v.lng)
+		if v.lng > 0 && v.lng < minPosLng {
+			minPosLng = v.lng
+		}
+		if v.lng < 0 && v.lng > maxNegLng {
+			maxNegLng = v.lng
+		}
+		// Arcs longer than 180 degrees cross the antimeridian.
+		if math.Abs(v.lng-next.lng) > math.Pi {
+			transmeridian = true
+		}
+	}
+	if transmeridian {
+		l.east, l.west = maxNegLng, minPosLng
+	}
+	return l
+}
+
+func (l *loop) transmeridian() bool {
+	return l.east < l.west
+}
+
+func (l *loop) bboxContains(p latLng) bool {
+	if p.lat < l.south || p.lat > l.north {
+		return false
+	}
+	if l.transmeridian() {
+		return p.lng >= l.west || p.lng <= l.east
+	}
+	return p.lng >= l.west && p.lng <= l.east
+}
+
+// contains returns true if p is inside l, casting a ray along the parallel
+// of p. Ties on vertices are broken by nudging p north and west.
+func (l *loop) contains(p latLng) bool {
+	if !l.bboxContains(p) {
+		return false
+	}
+	transmeridian := l.transmeridian()
+	normalize := func(lng float64) float64 {
+		if transmeridian && lng < 0 {
+			return lng + 2*math.Pi
+		}
+		return lng
+	}
+
+	contains := false
+	lat, lng := p.lat, normalize(p.lng)
+	for i, a := range l.verts {
+		b := l.verts[(i+1)%len(l.verts)]
+		if a.lat > b.lat {
+			a, b = b, a
+		}
+		if lat == a.lat || lat == b.lat {
+			lat += epsilonDouble
+		}
+		if lat < a.lat || lat > b.lat {
+			continue
+		}
+		aLng, bLng := normalize(a.lng), normalize(b.lng)
+		if aLng == lng || bLng == lng {
+			lng -= epsilonDouble
+		}
+		ratio := (lat - a.lat) / (b.lat - a.lat)
+		if normalize(aLng+(bLng-aLng)*ratio) > lng {
+			contains = !contains
+		}
+	}
+	return contains
+}
+
+// epsilonDouble is the difference between 1 and the next float64.
+const epsilonDouble = 0x1p-52
+
+// greatCircleDistanceKm returns the distance between a and b.
+func greatCircleDistanceKm(a, b latLng) float64 {
+	sinLat := math.Sin((b.lat - a.lat) / 2)
+	sinLng := math.Sin((b.lng - a.lng) / 2)
+	h := sinLat*sinLat + math.Cos(a.lat)*math.Cos(b.lat)*sinLng*sinLng
+	return 2 * math.Atan2(math.Sqrt(h), math.Sqrt(1-h)) * earthRadiusKm
+}
+
+// pentagonRadiusKm returns the distance from the center to the first vertex
+// of the first pentagon at res, the most distorted cell of the resolution.
+func pentagonRadiusKm(res int) float64 {
+	pentagon := newCell(res, 4, centerDigit)
+	center := pentagon.faceIJK().toGeo(res)
+	return greatCircleDistanceKm(center, pentagon.boundary()[0])
+}
+
+// PolygonToCells returns the cells at res whose centers lie inside g, which
+// must be a [goodgeo.Polygon] or a [goodgeo.MultiPolygon], in ascending
+// order. Like the reference implementation, edges are straight lines in
+// longitude and latitude, and an edge that spans more than 180 degrees of
+// longitude crosses the antimeridian.
+func PolygonToCells(g goodgeo.T, res int) ([]Cell, error) {
+	if res < 0 || res > MaxResolution {
+		return nil, ErrInvalidResolution
+	}
+	var polygons [][][]goodgeo.Coord
+	switch g := g.(type) {
+	case *goodgeo.Polygon:
+		polygons = [][][]goodgeo.Coord{g.Coords()}
+	case *goodgeo.MultiPolygon:
+		polygons = g.Coords()
+	default:
+		return nil, goodgeo.UnsupportedTypeError{Value: g}
+	}
+
+	seen := make(map[Cell]bool)
+	var cells []Cell
+	for _, rings := range polygons {
+		loops := make([]loop, 0, len(rings))
+		for _, ring := range rings {
+			for _, c := range ring {
+				if math.IsNaN(c[0]) || math.IsInf(c[0], 0) || math.IsNaN(c[1]) || math.IsInf(c[1], 0) {
+					return nil, ErrInvalidLatLng
+				}
+			}
+			loops = append(loops, newLoop(ring))
+		}
+		if len(loops) == 0 || len(loops[0].verts) == 0 {
+			continue
+		}
+		found, err := polygonToCells(loops, res)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range found {
+			if !seen[c] {
+				seen[c] = true
+				cells = append(cells, c)
+			}
+		}
+	}
+	slices.Sort(cells)
+	return cells, nil
+}
+
+// polygonToCells returns the cells at res whose centers lie inside the
+// polygon with the exterior loops[0] and the holes loops[1:]. It traces the
+// loops with cells and floods the polygon from them.
+func polygonToCells(loops []loop, res int) ([]Cell, error) {
+	inside := func(p latLng) bool {
+		if !loops[0].contains(p) {
+			return false
+		}
+		for _, hole := range loops[1:] {
+			if hole.contains(p) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Seed the search with the cells along the loops, sampled at most a
+	// cell diameter apart.
+	diameter := 2 * pentagonRadiusKm(res)
+	traced := make(map[Cell]bool)
+	var search []Cell
+	for _, l := range loops {
+		for i, a := range l.verts {
+			b := l.verts[(i+1)%len(l.verts)]
+			n := max(1, int(math.Ceil(greatCircleDistanceKm(a, b)/diameter)))
+			for j := range n {
+				p := latLng{
+					lat: a.lat*float64(n-j)/float64(n) + b.lat*float64(j)/float64(n),
+					lng: a.lng*float64(n-j)/float64(n) + b.lng*float64(j)/float64(n),
+				}
+				c := faceIJKToCell(geoToFaceIJK(p, res), res)
+				if !traced[c] {
+					traced[c] = true
+					search = append(search, c)
+				}
+			}
+		}
+	}
+
+	// Add the neighbours of the search cells whose centers are inside,
+	// until no new cells are found.
+	found := make(map[Cell]bool)
+	var cells []Cell
+	for len(search) > 0 {
+		var next []Cell
+		for _, c := range search {
+			disk, err := GridDisk(c, 1)
+			if err != nil {
+				return nil, err
+			}
+			for _, n := range disk {
+				if found[n] || !inside(n.faceIJK().toGeo(res)) {
+					continue
+				}
+				found[n] = true
+				cells = append(cells, n)
+				next = append(next, n)
+			}
+		}
+		search = next
+	}
+	return cells, nil
+}
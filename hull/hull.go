@@ -0,0 +1,219 @@
+// Package hull computes convex and concave hulls of geometries.
+//
+// Coordinates are treated as planar, like Turf does, except for the maximum
+// edge length of concave hulls, which is measured on the sphere.
+package hull
+
+import (
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/matoous/goodgeo"
+	"github.com/matoous/goodgeo/sorting"
+	"github.com/matoous/goodgeo/triangulate"
+)
+
+// ConvexHull returns the smallest convex polygon that contains all
+// coordinates of g, with a counter-clockwise ring, computed with Andrew's
+// monotone chain algorithm. Only the X and Y ordinates are kept. If the
+// coordinates of g are all collinear the hull has no area and ConvexHull
+// returns an empty polygon.
+func ConvexHull(g goodgeo.T) *goodgeo.Polygon {
+	var flatCoords []float64
+	appendXY(&flatCoords, g)
+	sort.Sort(sorting.NewFlatCoordSorting2D(goodgeo.XY, flatCoords))
+
+	n := len(flatCoords) / 2
+	// The lower hull from left to right, then the upper hull back.
+	hull := make([]goodgeo.Coord, 0, n+1)
+	for i := range n {
+		hull = pushHullPoint(hull, 0, goodgeo.Coord{flatCoords[2*i], flatCoords[2*i+1]})
+	}
+	lower := len(hull)
+	for i := n - 2; i >= 0; i-- {
+		hull = pushHullPoint(hull, lower-1, goodgeo.Coord{flatCoords[2*i], flatCoords[2*i+1]})
+	}
+
+	p := goodgeo.NewPolygon(goodgeo.XY).SetSRID(g.SRID())
+	if len(hull) < 4 {
+		return p
+	}
+	return p.MustSetCoords([][]goodgeo.Coord{hull})
+}
+
+// pushHullPoint appends c to hull after removing the points after start
+// that would make a clockwise or straight turn.
+func pushHullPoint(hull []goodgeo.Coord, start int, c goodgeo.Coord) []goodgeo.Coord {
+	for len(hull) >= start+2 && cross(hull[len(hull)-2], hull[len(hull)-1], c) <= 0 {
+		hull = hull[:len(hull)-1]
+	}
+	return append(hull, c)
+}
+
+// appendXY appends the X and Y ordinates of all coordinates of g to
+// flatCoords.
+func appendXY(flatCoords *[]float64, g goodgeo.T) {
+	if gc, ok := g.(*goodgeo.GeometryCollection); ok {
+		for _, member := range gc.Geoms() {
+			appendXY(flatCoords, member)
+		}
+		return
+	}
+	coords, stride := g.FlatCoords(), g.Stride()
+	for i := 0; i+1 < len(coords); i += stride {
+		*flatCoords = append(*flatCoords, coords[i], coords[i+1])
+	}
+}
+
+// ConcaveHull returns the concave hull of points, like Turf's concave: the
+// union of the triangles of the Delaunay triangulation of points whose
+// edges are all at most maxEdge long. The polygons have counter-clockwise
+// exterior rings and clockwise holes. If no triangle is short enough the
+// result is empty.
+func ConcaveHull(points *goodgeo.MultiPoint, maxEdge goodgeo.Meters) *goodgeo.MultiPolygon {
+	var coords []goodgeo.Coord
+	for _, c := range points.Coords() {
+		if c != nil {
+			coords = append(coords, goodgeo.Coord{c[0], c[1]})
+		}
+	}
+	d := triangulate.NewDelaunay(coords)
+
+	keep := make([]bool, d.NumTriangles())
+	for t := range keep {
+		keep[t] = true
+		for e := 3 * t; e < 3*t+3; e++ {
+			a, b := coords[d.Triangles[e]], coords[d.Triangles[triangulate.NextHalfedge(e)]]
+			if goodgeo.Distance(a, b) > maxEdge {
+				keep[t] = false
+				break
+			}
+		}
+	}
+
+	// The edges of the kept triangles that are not shared with other kept
+	// triangles make up the boundary, with the inside on their left.
+	outgoing := make(map[int][]int)
+	var boundary []int
+	for e, opposite := range d.Halfedges {
+		if keep[e/3] && (opposite == -1 || !keep[opposite/3]) {
+			outgoing[d.Triangles[e]] = append(outgoing[d.Triangles[e]], e)
+			boundary = append(boundary, e)
+		}
+	}
+
+	// Walk the boundary edges into loops, splitting the loops where they
+	// touch themselves so that regions and holes that meet at a point get
+	// separate rings.
+	var loops [][]int
+	used := make(map[int]bool, len(boundary))
+	for _, start := range boundary {
+		if used[start] {
+			continue
+		}
+		var edges []int
+		positions := make(map[int]int)
+		for e := start; !used[e]; e = nextBoundaryEdge(d, outgoing, e) {
+			used[e] = true
+			v := d.Triangles[e]
+			if i, ok := positions[v]; ok {
+				loops = append(loops, slices.Clone(edges[i:]))
+				for _, f := range edges[i:] {
+					delete(positions, d.Triangles[f])
+				}
+				edges = edges[:i]
+			}
+			positions[v] = len(edges)
+			edges = append(edges, e)
+		}
+		loops = append(loops, edges)
+	}
+
+	var exteriors, holes []ring
+	for _, edges := range loops {
+		var r ring
+		for _, e := range edges {
+			r.coords = append(r.coords, coords[d.Triangles[e]])
+		}
+		r.coords = append(r.coords, r.coords[0])
+		r.area = signedArea(r.coords)
+		if r.area > 0 {
+			exteriors = append(exteriors, r)
+		} else {
+			// A point inside the triangle of an edge is inside the polygon
+			// that the hole belongs to.
+			e := edges[0]
+			a, b, c := coords[d.Triangles[e]], coords[d.Triangles[triangulate.NextHalfedge(e)]], coords[d.Triangles[triangulate.PrevHalfedge(e)]]
+			r.inside = goodgeo.Coord{(a[0] + b[0] + c[0]) / 3, (a[1] + b[1] + c[1]) / 3}
+			holes = append(holes, r)
+		}
+	}
+
+	polygons := make([][][]goodgeo.Coord, len(exteriors))
+	shells := make([]*goodgeo.Polygon, len(exteriors))
+	for i, r := range exteriors {
+		polygons[i] = [][]goodgeo.Coord{r.coords}
+		shells[i] = goodgeo.NewPolygon(goodgeo.XY).MustSetCoords(polygons[i])
+	}
+	for _, h := range holes {
+		best := -1
+		for i, shell := range shells {
+			if (best == -1 || exteriors[i].area < exteriors[best].area) && goodgeo.BooleanPointInPolygon(h.inside, shell, nil) {
+				best = i
+			}
+		}
+		if best != -1 {
+			polygons[best] = append(polygons[best], h.coords)
+		}
+	}
+	return goodgeo.NewMultiPolygon(goodgeo.XY).MustSetCoords(polygons).SetSRID(points.SRID())
+}
+
+// A ring is a closed ring of boundary edges.
+type ring struct {
+	coords []goodgeo.Coord
+	// area is twice the signed area, positive for counter-clockwise rings.
+	area float64
+	// inside is a point inside the polygon that a hole belongs to.
+	inside goodgeo.Coord
+}
+
+// nextBoundaryEdge returns the boundary edge that follows e. Where several
+// boundary edges leave the same point, the one that turns the most to the
+// left is taken, so that polygons touching at a point get separate rings.
+func nextBoundaryEdge(d *triangulate.Delaunay, outgoing map[int][]int, e int) int {
+	from, to := d.Coords[d.Triangles[e]], d.Coords[d.Triangles[triangulate.NextHalfedge(e)]]
+	edges := outgoing[d.Triangles[triangulate.NextHalfedge(e)]]
+	if len(edges) == 1 {
+		return edges[0]
+	}
+	back := math.Atan2(from[1]-to[1], from[0]-to[0])
+	next, minAngle := -1, math.Inf(1)
+	for _, candidate := range edges {
+		c := d.Coords[d.Triangles[triangulate.NextHalfedge(candidate)]]
+		angle := back - math.Atan2(c[1]-to[1], c[0]-to[0])
+		for angle <= 0 {
+			angle += 2 * math.Pi
+		}
+		if angle < minAngle {
+			next, minAngle = candidate, angle
+		}
+	}
+	return next
+}
+
+// cross returns the cross product of the vectors from a to b and from b to
+// c, positive if a, b, c turn counter-clockwise.
+func cross(a, b, c goodgeo.Coord) float64 {
+	return (b[0]-a[0])*(c[1]-b[1]) - (b[1]-a[1])*(c[0]-b[0])
+}
+
+// signedArea returns twice the signed area of the closed ring coords.
+func signedArea(coords []goodgeo.Coord) float64 {
+	var a float64
+	for i := 1; i < len(coords); i++ {
+		a += coords[i-1][0]*coords[i][1] - coords[i][0]*coords[i-1][1]
+	}
+	return a
+}
@@ -0,0 +1,155 @@
+package hull
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/matoous/goodgeo"
+)
+
+func TestConvexHull(t *testing.T) {
+	for i, tc := range []struct {
+		g    goodgeo.T
+		want [][]goodgeo.Coord
+	}{
+		{
+			g: goodgeo.NewMultiPoint(goodgeo.XY),
+		},
+		{
+			g: goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{1, 2}),
+		},
+		{
+			g: goodgeo.NewLineString(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{0, 0}, {1, 1}, {2, 2}, {1, 1}}),
+		},
+		{
+			g: goodgeo.NewMultiPoint(goodgeo.XY).MustSetCoords([]goodgeo.Coord{
+				{1, 1}, {0, 0}, {2, 0}, {2, 2}, {0, 2}, {1, 0}, {0.5, 1.5}, {2, 2}, {0, 0},
+			}),
+			want: [][]goodgeo.Coord{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}},
+		},
+		{
+			g:    goodgeo.NewLineString(goodgeo.XYZ).MustSetCoords([]goodgeo.Coord{{0, 0, 5}, {3, 1, 6}, {1, 3, 7}, {1, 1, 8}}),
+			want: [][]goodgeo.Coord{{{0, 0}, {3, 1}, {1, 3}, {0, 0}}},
+		},
+		{
+			g: goodgeo.NewGeometryCollection().MustPush(
+				goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{-1, 1}),
+				goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{{{0, 0}, {2, 0}, {2, 2}, {0, 0}}}),
+			),
+			want: [][]goodgeo.Coord{{{-1, 1}, {0, 0}, {2, 0}, {2, 2}, {-1, 1}}},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := ConvexHull(tc.g)
+			assert.Equal(t, goodgeo.XY, got.Layout())
+			if tc.want == nil {
+				assert.True(t, got.Empty())
+				return
+			}
+			assert.Equal(t, tc.want, got.Coords())
+		})
+	}
+
+	p := goodgeo.NewMultiPoint(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{0, 0}, {1, 0}, {0, 1}}).SetSRID(4326)
+	assert.Equal(t, 4326, ConvexHull(p).SRID())
+}
+
+// grid returns the points of a grid with a spacing of 0.01°, about 1.1 km
+// at the equator, that are not inside the hole.
+func grid(x0, y0 float64, n int, hole func(x, y int) bool) []goodgeo.Coord {
+	var coords []goodgeo.Coord
+	for x := range n {
+		for y := range n {
+			if hole == nil || !hole(x, y) {
+				coords = append(coords, goodgeo.Coord{x0 + float64(x)/100, y0 + float64(y)/100})
+			}
+		}
+	}
+	return coords
+}
+
+func TestConcaveHull(t *testing.T) {
+	inner := func(x, y int) bool {
+		return 3 <= x && x <= 6 && 3 <= y && y <= 6
+	}
+	for i, tc := range []struct {
+		coords  []goodgeo.Coord
+		maxEdge goodgeo.Meters
+		// wantAreas are the twice the planar areas of the rings of each
+		// polygon.
+		wantAreas [][]float64
+	}{
+		{
+			coords:  grid(0, 0, 10, nil),
+			maxEdge: 1000,
+		},
+		{
+			coords:    grid(0, 0, 10, nil),
+			maxEdge:   1600,
+			wantAreas: [][]float64{{2 * 81e-4}},
+		},
+		{
+			// The corners of the hole are cut off by diagonals, which
+			// leaves 23 of its 25 cells.
+			coords:    grid(0, 0, 10, inner),
+			maxEdge:   1600,
+			wantAreas: [][]float64{{2 * 81e-4, -2 * 23e-4}},
+		},
+		{
+			// The hole is too small to be left out.
+			coords:    grid(0, 0, 10, inner),
+			maxEdge:   10000,
+			wantAreas: [][]float64{{2 * 81e-4}},
+		},
+		{
+			coords:    append(grid(0, 0, 10, inner), grid(1, 1, 4, nil)...),
+			maxEdge:   1600,
+			wantAreas: [][]float64{{2 * 81e-4, -2 * 23e-4}, {2 * 9e-4}},
+		},
+		{
+			// Two triangles that touch at a point.
+			coords:    []goodgeo.Coord{{0, 0}, {-0.01, -0.02}, {0.01, -0.02}, {-0.01, 0.02}, {0.01, 0.02}},
+			maxEdge:   3000,
+			wantAreas: [][]float64{{4e-4}, {4e-4}},
+		},
+		{
+			// Two clusters of two triangles that share a single vertex.
+			coords: []goodgeo.Coord{
+				{0, 0},
+				{-0.02, -0.01}, {-0.02, 0.01}, {-0.04, 0},
+				{0.02, -0.01}, {0.02, 0.01}, {0.04, 0},
+			},
+			maxEdge:   3000,
+			wantAreas: [][]float64{{8e-4}, {8e-4}},
+		},
+		{
+			// The boundary passes twice through {0.06, 0.02}, where a hole
+			// touches the exterior ring.
+			coords: []goodgeo.Coord{
+				{0.06, 0.06}, {0.07, 0.04}, {0.05, 0.06}, {0.06, 0.02}, {0.04, 0}, {0.02, 0.02}, {0.05, 0.05},
+				{0.04, 0.02}, {0.04, 0.01}, {0.02, 0.06}, {0.06, 0.04}, {0.03, 0.04}, {0.02, 0.07}, {0.04, 0.04},
+			},
+			maxEdge:   2500,
+			wantAreas: [][]float64{{2 * 14e-4, -2 * 4e-4}},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			points := goodgeo.NewMultiPoint(goodgeo.XY).MustSetCoords(tc.coords).SetSRID(4326)
+			got := ConcaveHull(points, tc.maxEdge)
+			assert.Equal(t, 4326, got.SRID())
+			assert.Zero(t, goodgeo.Validate(got))
+			var areas [][]float64
+			for _, polygon := range got.Coords() {
+				var polygonAreas []float64
+				for _, ring := range polygon {
+					polygonAreas = append(polygonAreas, math.Round(signedArea(ring)*1e8)/1e8)
+				}
+				areas = append(areas, polygonAreas)
+			}
+			assert.Equal(t, tc.wantAreas, areas)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package rtree
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/matoous/goodgeo"
+)
+
+// Load adds gs to t. The geometries are packed into a new tree with
+// Sort-Tile-Recursive, which is much faster than inserting them one by one
+// and produces a tree with less overlap. Empty geometries are ignored.
+func (t *Tree) Load(gs []goodgeo.T) {
+	items := make([]*node, 0, len(gs))
+	size, wrapped := 0, 0
+	for _, g := range gs {
+		if g.Empty() {
+			continue
+		}
+		nodes := itemNodes(g)
+		items = append(items, nodes...)
+		size++
+		if len(nodes) > 1 {
+			wrapped++
+		}
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.size += size
+	t.wrapped += wrapped
+	if len(items) < t.minEntries {
+		for _, item := range items {
+			t.insert(item)
+		}
+		return
+	}
+
+	packed := t.pack(items)
+	switch {
+	case len(t.root.children) == 0:
+		t.root = packed
+	case t.root.height == packed.height:
+		t.root = newNode([]*node{t.root, packed}, packed.height+1)
+	default:
+		if t.root.height < packed.height {
+			t.root, packed = packed, t.root
+		}
+		t.insert(packed)
+	}
+}
+
+// pack builds a tree from nodes of the same height with Sort-Tile-Recursive
+// packing: the nodes are sorted by X into vertical slices, each slice is
+// sorted by Y and cut into parent nodes. This is repeated level by level
+// until a single root remains.
+func (t *Tree) pack(nodes []*node) *node {
+	height := 1
+	for len(nodes) > t.maxEntries {
+		parents := int(math.Ceil(float64(len(nodes)) / float64(t.maxEntries)))
+		numSlices := int(math.Ceil(math.Sqrt(float64(parents))))
+		sliceSize := t.maxEntries * int(math.Ceil(float64(parents)/float64(numSlices)))
+
+		sortByCenter(nodes, 0)
+		next := make([]*node, 0, parents)
+		for i := 0; i < len(nodes); i += sliceSize {
+			slice := nodes[i:min(i+sliceSize, len(nodes))]
+			sortByCenter(slice, 1)
+			for j := 0; j < len(slice); j += t.maxEntries {
+				next = append(next, newNode(slices.Clone(slice[j:min(j+t.maxEntries, len(slice))]), height))
+			}
+		}
+		nodes = next
+		height++
+	}
+	return newNode(slices.Clone(nodes), height)
+}
+
+func sortByCenter(nodes []*node, dim int) {
+	center := func(n *node) float64 {
+		if dim == 0 {
+			return n.minX + n.maxX
+		}
+		return n.minY + n.maxY
+	}
+	slices.SortFunc(nodes, func(a, b *node) int { return cmp.Compare(center(a), center(b)) })
+}
@@ -0,0 +1,157 @@
+package rtree
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/matoous/goodgeo"
+)
+
+// Nearest returns up to k geometries closest to pt, nearest first.
+// Distances are measured in meters on the sphere with [goodgeo.Distance]:
+// to the closest vertex of points, to the closest point of the great circle
+// segments of lines and rings, and zero for points inside polygons. The
+// search wraps around the antimeridian.
+func (t *Tree) Nearest(pt goodgeo.Coord, k int) []goodgeo.T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var result []goodgeo.T
+	found := make(map[goodgeo.T]struct{})
+	q := &queue{{node: t.root}}
+	for q.Len() > 0 && len(result) < k {
+		e := heap.Pop(q).(entry)
+		if e.node.height == 0 {
+			// Geometries that cross the antimeridian are held by two nodes.
+			if _, ok := found[e.node.item]; !ok {
+				found[e.node.item] = struct{}{}
+				result = append(result, e.node.item)
+			}
+			continue
+		}
+		for _, c := range e.node.children {
+			var d goodgeo.Meters
+			if c.height == 0 {
+				d = distance(pt, c.item)
+			} else {
+				d = boxDistance(pt, c.box)
+			}
+			heap.Push(q, entry{node: c, distance: d})
+		}
+	}
+	return result
+}
+
+// An entry is a node in the queue of [Tree.Nearest]. distance is the exact
+// distance for geometries and a lower bound for other nodes.
+type entry struct {
+	node     *node
+	distance goodgeo.Meters
+}
+
+type queue []entry
+
+func (q queue) Len() int           { return len(q) }
+func (q queue) Less(i, j int) bool { return q[i].distance < q[j].distance }
+func (q queue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *queue) Push(x any)        { *q = append(*q, x.(entry)) }
+
+func (q *queue) Pop() any {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return e
+}
+
+// boxDistance returns the distance from pt to the closest point of b.
+func boxDistance(pt goodgeo.Coord, b box) goodgeo.Meters {
+	lon, lat := pt[0], pt[1]
+	if math.Mod(math.Mod(lon-b.minX, 360)+360, 360) <= b.maxX-b.minX {
+		// The closest point is on the same meridian.
+		switch {
+		case lat < b.minY:
+			return goodgeo.Distance(pt, goodgeo.Coord{lon, b.minY})
+		case lat > b.maxY:
+			return goodgeo.Distance(pt, goodgeo.Coord{lon, b.maxY})
+		default:
+			return 0
+		}
+	}
+	// The closest point is on one of the meridian edges, the distance to the
+	// points of a parallel only grows with the difference in longitude.
+	return min(meridianDistance(pt, b.minX, b.minY, b.maxY), meridianDistance(pt, b.maxX, b.minY, b.maxY))
+}
+
+// meridianDistance returns the distance from pt to the closest point of the
+// meridian at longitude lon between latitudes minLat and maxLat.
+func meridianDistance(pt goodgeo.Coord, lon, minLat, maxLat float64) goodgeo.Meters {
+	dLon := (lon - pt[0]) * math.Pi / 180
+	lat := pt[1] * math.Pi / 180
+	if math.Cos(dLon) <= 0 {
+		// The distance grows monotonically towards the pole on the other
+		// side, the closest point is an end.
+		return min(goodgeo.Distance(pt, goodgeo.Coord{lon, minLat}), goodgeo.Distance(pt, goodgeo.Coord{lon, maxLat}))
+	}
+	// The foot of the perpendicular from pt onto the meridian.
+	foot := math.Atan2(math.Sin(lat), math.Cos(lat)*math.Cos(dLon)) * 180 / math.Pi
+	return goodgeo.Distance(pt, goodgeo.Coord{lon, math.Max(minLat, math.Min(maxLat, foot))})
+}
+
+// distance returns the distance from pt to g.
+func distance(pt goodgeo.Coord, g goodgeo.T) goodgeo.Meters {
+	d := goodgeo.Meters(math.Inf(1))
+	switch g := g.(type) {
+	case *goodgeo.Point:
+		d = goodgeo.Distance(pt, g.Coords())
+	case *goodgeo.MultiPoint:
+		for _, c := range g.Coords() {
+			if c != nil {
+				d = min(d, goodgeo.Distance(pt, c))
+			}
+		}
+	case *goodgeo.LineString:
+		d = lineDistance(pt, g.Layout(), g.FlatCoords())
+	case *goodgeo.LinearRing:
+		d = lineDistance(pt, g.Layout(), g.FlatCoords())
+	case *goodgeo.MultiLineString:
+		for i := range g.NumLineStrings() {
+			d = min(d, lineDistance(pt, g.Layout(), g.LineString(i).FlatCoords()))
+		}
+	case *goodgeo.Polygon, *goodgeo.MultiPolygon:
+		if goodgeo.BooleanPointInPolygon(pt, g, nil) {
+			return 0
+		}
+		flatCoords := g.FlatCoords()
+		offset := 0
+		for _, end := range ringEnds(g) {
+			d = min(d, lineDistance(pt, g.Layout(), flatCoords[offset:end]))
+			offset = end
+		}
+	case *goodgeo.GeometryCollection:
+		for _, g := range g.Geoms() {
+			if !g.Empty() {
+				d = min(d, distance(pt, g))
+			}
+		}
+	}
+	return d
+}
+
+// ringEnds returns the ends of all rings of a Polygon or MultiPolygon.
+func ringEnds(g goodgeo.T) []int {
+	if endss := g.Endss(); endss != nil {
+		var ends []int
+		for _, e := range endss {
+			ends = append(ends, e...)
+		}
+		return ends
+	}
+	return g.Ends()
+}
+
+func lineDistance(pt goodgeo.Coord, layout goodgeo.Layout, flatCoords []float64) goodgeo.Meters {
+	if len(flatCoords) == layout.Stride() {
+		return goodgeo.Distance(pt, flatCoords)
+	}
+	line := goodgeo.NewLineStringFlat(layout, flatCoords)
+	return goodgeo.NearestPointOnLine(line, pt).Distance
+}
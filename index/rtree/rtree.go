@@ -0,0 +1,390 @@
+// Package rtree implements an in-memory R-tree spatial index of geometries.
+//
+// The tree is a port of the algorithms used by rbush: insertion chooses the
+// subtree that needs the least enlargement and splits overflowing nodes
+// along the axis with the smallest margin. Bulk loading uses
+// Sort-Tile-Recursive packing.
+//
+// Geometries are indexed by their two-dimensional geographic bounds in
+// degrees, see [goodgeo.GeographicBounds]. A geometry whose bounds cross the
+// antimeridian is indexed by the parts of its bounds on either side of it. A
+// Tree is safe for concurrent use: searches may run in parallel,
+// modifications are serialized.
+package rtree
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sync"
+
+	"github.com/matoous/goodgeo"
+)
+
+// DefaultMaxEntries is the maximum number of entries in a node used when
+// none is given to [New].
+const DefaultMaxEntries = 16
+
+// A box is a two-dimensional bounding box.
+type box struct {
+	minX, minY, maxX, maxY float64
+}
+
+func emptyBox() box {
+	return box{minX: math.Inf(1), minY: math.Inf(1), maxX: math.Inf(-1), maxY: math.Inf(-1)}
+}
+
+func boundsBox(b *goodgeo.Bounds) box {
+	return box{minX: b.Min(0), minY: b.Min(1), maxX: b.Max(0), maxY: b.Max(1)}
+}
+
+// geographicBoxes returns the boxes of the parts of b on either side of the
+// antimeridian.
+func geographicBoxes(b *goodgeo.GeographicBounds) []box {
+	parts := b.Bounds()
+	boxes := make([]box, len(parts))
+	for i, part := range parts {
+		boxes[i] = boundsBox(part)
+	}
+	return boxes
+}
+
+// itemNodes returns the nodes that hold g, one for each of the boxes of its
+// geographic bounds.
+func itemNodes(g goodgeo.T) []*node {
+	boxes := geographicBoxes(goodgeo.GeographicBoundsOf(g))
+	items := make([]*node, len(boxes))
+	for i, b := range boxes {
+		items[i] = &node{box: b, item: g}
+	}
+	return items
+}
+
+func (b *box) extend(o box) {
+	b.minX, b.minY = math.Min(b.minX, o.minX), math.Min(b.minY, o.minY)
+	b.maxX, b.maxY = math.Max(b.maxX, o.maxX), math.Max(b.maxY, o.maxY)
+}
+
+func (b box) area() float64 {
+	return (b.maxX - b.minX) * (b.maxY - b.minY)
+}
+
+func (b box) margin() float64 {
+	return (b.maxX - b.minX) + (b.maxY - b.minY)
+}
+
+func (b box) enlargedArea(o box) float64 {
+	return (math.Max(o.maxX, b.maxX) - math.Min(o.minX, b.minX)) *
+		(math.Max(o.maxY, b.maxY) - math.Min(o.minY, b.minY))
+}
+
+func (b box) intersectionArea(o box) float64 {
+	minX, minY := math.Max(b.minX, o.minX), math.Max(b.minY, o.minY)
+	maxX, maxY := math.Min(b.maxX, o.maxX), math.Min(b.maxY, o.maxY)
+	return math.Max(0, maxX-minX) * math.Max(0, maxY-minY)
+}
+
+func (b box) contains(o box) bool {
+	return b.minX <= o.minX && b.minY <= o.minY && o.maxX <= b.maxX && o.maxY <= b.maxY
+}
+
+func (b box) intersects(o box) bool {
+	return o.minX <= b.maxX && o.minY <= b.maxY && o.maxX >= b.minX && o.maxY >= b.minY
+}
+
+// A node is a node of the tree. Nodes of height 0 hold a geometry, leaves
+// have height 1.
+type node struct {
+	box
+	children []*node
+	height   int
+	item     goodgeo.T
+}
+
+func newNode(children []*node, height int) *node {
+	n := &node{children: children, height: height}
+	n.calcBox()
+	return n
+}
+
+func (n *node) calcBox() {
+	n.box = distBox(n.children, 0, len(n.children))
+}
+
+// distBox returns the bounding box of nodes[from:to].
+func distBox(nodes []*node, from, to int) box {
+	b := emptyBox()
+	for _, c := range nodes[from:to] {
+		b.extend(c.box)
+	}
+	return b
+}
+
+// A Tree is an R-tree of geometries.
+type Tree struct {
+	mu         sync.RWMutex
+	root       *node
+	size       int
+	maxEntries int
+	minEntries int
+	// wrapped is the number of geometries held by more than one node.
+	wrapped int
+}
+
+// New returns a new, empty Tree. maxEntries optionally sets the maximum
+// number of entries in a node, the default is [DefaultMaxEntries].
+func New(maxEntries ...int) *Tree {
+	m := DefaultMaxEntries
+	if len(maxEntries) > 0 && maxEntries[0] >= 4 {
+		m = maxEntries[0]
+	}
+	return &Tree{
+		root:       newNode(nil, 1),
+		maxEntries: m,
+		minEntries: max(2, int(math.Ceil(0.4*float64(m)))),
+	}
+}
+
+// Len returns the number of geometries in t.
+func (t *Tree) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.size
+}
+
+// Insert adds g to t. Empty geometries are ignored.
+func (t *Tree) Insert(g goodgeo.T) {
+	if g.Empty() {
+		return
+	}
+	items := itemNodes(g)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, item := range items {
+		t.insert(item)
+	}
+	t.size++
+	if len(items) > 1 {
+		t.wrapped++
+	}
+}
+
+// Delete removes g from t and returns true if it was found. Geometries are
+// compared by identity.
+func (t *Tree) Delete(g goodgeo.T) bool {
+	if g.Empty() {
+		return false
+	}
+	boxes := geographicBoxes(goodgeo.GeographicBoundsOf(g))
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// Check all boxes before removing any, so that a geometry whose bounds
+	// have changed since it was inserted is not left half deleted.
+	for _, b := range boxes {
+		if !t.root.contains(b) || !find(t.root, g, b) {
+			return false
+		}
+	}
+	for _, b := range boxes {
+		t.remove(t.root, g, b)
+	}
+	t.size--
+	if len(boxes) > 1 {
+		t.wrapped--
+	}
+	if len(t.root.children) == 0 {
+		t.root = newNode(nil, 1)
+	}
+	return true
+}
+
+// Search returns the geometries whose geographic bounds overlap b.
+func (t *Tree) Search(b *goodgeo.GeographicBounds) []goodgeo.T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	boxes := geographicBoxes(b)
+	var result []goodgeo.T
+	for _, q := range boxes {
+		result = t.search(result, t.root, q)
+	}
+	if len(boxes) == 1 && t.wrapped == 0 {
+		return result
+	}
+	seen := make(map[goodgeo.T]struct{}, len(result))
+	return slices.DeleteFunc(result, func(g goodgeo.T) bool {
+		if _, ok := seen[g]; ok {
+			return true
+		}
+		seen[g] = struct{}{}
+		return false
+	})
+}
+
+func (t *Tree) search(result []goodgeo.T, n *node, q box) []goodgeo.T {
+	if !n.intersects(q) {
+		return result
+	}
+	for _, c := range n.children {
+		switch {
+		case !c.intersects(q):
+		case c.height == 0:
+			result = append(result, c.item)
+		case q.contains(c.box):
+			result = all(result, c)
+		default:
+			result = t.search(result, c, q)
+		}
+	}
+	return result
+}
+
+// all appends the geometries in n to result.
+func all(result []goodgeo.T, n *node) []goodgeo.T {
+	if n.height == 0 {
+		return append(result, n.item)
+	}
+	for _, c := range n.children {
+		result = all(result, c)
+	}
+	return result
+}
+
+// insert adds n to the tree at a level where it fits by height.
+func (t *Tree) insert(n *node) {
+	path := t.chooseSubtree(n)
+	parent := path[len(path)-1]
+	parent.children = append(parent.children, n)
+	parent.extend(n.box)
+
+	level := len(path) - 1
+	for ; level >= 0 && len(path[level].children) > t.maxEntries; level-- {
+		t.split(path, level)
+	}
+	for ; level >= 0; level-- {
+		path[level].extend(n.box)
+	}
+}
+
+// chooseSubtree returns the path from the root to the node that n should be
+// added to.
+func (t *Tree) chooseSubtree(n *node) []*node {
+	var path []*node
+	for current := t.root; ; {
+		path = append(path, current)
+		if current.height <= n.height+1 || len(current.children) == 0 {
+			return path
+		}
+		var target *node
+		minArea, minEnlargement := math.Inf(1), math.Inf(1)
+		for _, c := range current.children {
+			area := c.area()
+			enlargement := n.enlargedArea(c.box) - area
+			if enlargement < minEnlargement || enlargement == minEnlargement && area < minArea {
+				minEnlargement = enlargement
+				minArea = area
+				target = c
+			}
+		}
+		current = target
+	}
+}
+
+// split splits the overflowing node path[level] in two.
+func (t *Tree) split(path []*node, level int) {
+	n := path[level]
+	t.chooseSplitAxis(n)
+	i := t.chooseSplitIndex(n)
+
+	sibling := newNode(slices.Clone(n.children[i:]), n.height)
+	n.children = n.children[:i:i]
+	n.calcBox()
+
+	if level > 0 {
+		parent := path[level-1]
+		parent.children = append(parent.children, sibling)
+		return
+	}
+	t.root = newNode([]*node{n, sibling}, n.height+1)
+}
+
+// chooseSplitAxis sorts the children of n along the axis with the smallest
+// sum of margins of the possible distributions.
+func (t *Tree) chooseSplitAxis(n *node) {
+	byX := func(a, b *node) int { return cmp.Compare(a.minX, b.minX) }
+	byY := func(a, b *node) int { return cmp.Compare(a.minY, b.minY) }
+	if t.allDistMargin(n, byX) < t.allDistMargin(n, byY) {
+		slices.SortFunc(n.children, byX)
+	}
+}
+
+func (t *Tree) allDistMargin(n *node, cmp func(a, b *node) int) float64 {
+	slices.SortFunc(n.children, cmp)
+	m, count := t.minEntries, len(n.children)
+	left, right := distBox(n.children, 0, m), distBox(n.children, count-m, count)
+	margin := left.margin() + right.margin()
+	for i := m; i < count-m; i++ {
+		left.extend(n.children[i].box)
+		margin += left.margin()
+	}
+	for i := count - m - 1; i >= m; i-- {
+		right.extend(n.children[i].box)
+		margin += right.margin()
+	}
+	return margin
+}
+
+// chooseSplitIndex returns the index that splits the children of n with the
+// least overlap, then the least area.
+func (t *Tree) chooseSplitIndex(n *node) int {
+	m, count := t.minEntries, len(n.children)
+	index := count - m
+	minOverlap, minArea := math.Inf(1), math.Inf(1)
+	for i := m; i <= count-m; i++ {
+		b1, b2 := distBox(n.children, 0, i), distBox(n.children, i, count)
+		overlap := b1.intersectionArea(b2)
+		area := b1.area() + b2.area()
+		if overlap < minOverlap || overlap == minOverlap && area < minArea {
+			minOverlap, minArea, index = overlap, area, i
+		}
+	}
+	return index
+}
+
+// find returns true if g, with bounds b, is in the subtree n.
+func find(n *node, g goodgeo.T, b box) bool {
+	for _, c := range n.children {
+		switch {
+		case !c.contains(b):
+		case c.height == 0:
+			if c.item == g {
+				return true
+			}
+		case find(c, g, b):
+			return true
+		}
+	}
+	return false
+}
+
+// remove removes g, with bounds b, from the subtree n and returns true if
+// it was found.
+func (t *Tree) remove(n *node, g goodgeo.T, b box) bool {
+	for i, c := range n.children {
+		if !c.contains(b) {
+			continue
+		}
+		if c.height == 0 {
+			if c.item != g {
+				continue
+			}
+		} else if !t.remove(c, g, b) {
+			continue
+		}
+		if c.height == 0 || len(c.children) == 0 {
+			n.children = slices.Delete(n.children, i, i+1)
+		}
+		n.calcBox()
+		return true
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package rtree
+
+import (
+	"math/rand"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/matoous/goodgeo"
+)
+
+func randomPoints(r *rand.Rand, n int) []goodgeo.T {
+	gs := make([]goodgeo.T, n)
+	for i := range gs {
+		gs[i] = goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{r.Float64()*360 - 180, r.Float64()*170 - 85})
+	}
+	return gs
+}
+
+func bruteForceSearch(gs []goodgeo.T, b *goodgeo.GeographicBounds) []goodgeo.T {
+	var result []goodgeo.T
+	for _, g := range gs {
+		if goodgeo.GeographicBoundsOf(g).Overlaps(goodgeo.XY, b) {
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+func sameSet(t *testing.T, want, got []goodgeo.T) {
+	t.Helper()
+	assert.Equal(t, len(want), len(got))
+	for _, g := range want {
+		assert.True(t, slices.Contains(got, g))
+	}
+}
+
+func TestTreeSearch(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	gs := randomPoints(r, 2000)
+	loaded := New()
+	loaded.Load(gs)
+	inserted := New(4)
+	for _, g := range gs {
+		inserted.Insert(g)
+	}
+	mixed := New()
+	for _, g := range gs[:300] {
+		mixed.Insert(g)
+	}
+	mixed.Load(gs[300:1900])
+	mixed.Load(gs[1900:])
+	assert.Equal(t, len(gs), loaded.Len())
+	assert.Equal(t, len(gs), inserted.Len())
+	assert.Equal(t, len(gs), mixed.Len())
+
+	for i := range 50 {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			minX, minY := r.Float64()*360-180, r.Float64()*170-85
+			b := goodgeo.NewGeographicBounds(goodgeo.XY).Set(minX, minY, minX+r.Float64()*40, minY+r.Float64()*40)
+			want := bruteForceSearch(gs, b)
+			sameSet(t, want, loaded.Search(b))
+			sameSet(t, want, inserted.Search(b))
+			sameSet(t, want, mixed.Search(b))
+		})
+	}
+}
+
+func TestTreeSearchAntimeridian(t *testing.T) {
+	east := goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{179.5, 0})
+	west := goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{-179.5, 0})
+	far := goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{0, 0})
+	tr := New()
+	tr.Load([]goodgeo.T{east, west, far})
+
+	sameSet(t, []goodgeo.T{east, west}, tr.Search(goodgeo.NewGeographicBounds(goodgeo.XY).Set(179, -1, -179, 1)))
+	assert.Equal(t, []goodgeo.T{east, west}, tr.Nearest(goodgeo.Coord{179.9, 0}, 2))
+
+	// A route from Fiji to Samoa is indexed on either side of the
+	// antimeridian, not across the whole globe.
+	route := goodgeo.NewLineString(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{178.4, -18.1}, {-171.8, -13.8}})
+	for _, tr := range []*Tree{New(), New(4)} {
+		tr.Load([]goodgeo.T{east, west, far})
+		tr.Insert(route)
+		assert.Equal(t, 4, tr.Len())
+		sameSet(t, []goodgeo.T{route}, tr.Search(goodgeo.NewGeographicBounds(goodgeo.XY).Set(-173, -16, -172, -15)))
+		sameSet(t, []goodgeo.T{route}, tr.Search(goodgeo.NewGeographicBounds(goodgeo.XY).Set(179, -16, 179.5, -15)))
+		sameSet(t, []goodgeo.T{far}, tr.Search(goodgeo.NewGeographicBounds(goodgeo.XY).Set(-10, -20, 10, 10)))
+		sameSet(t, []goodgeo.T{east, west, far, route}, tr.Search(goodgeo.NewGeographicBounds(goodgeo.XY).Set(-180, -90, 180, 90)))
+		assert.Equal(t, []goodgeo.T{route, west}, tr.Nearest(goodgeo.Coord{-175, -15}, 2))
+		assert.True(t, tr.Delete(route))
+		assert.Equal(t, 3, tr.Len())
+		sameSet(t, nil, tr.Search(goodgeo.NewGeographicBounds(goodgeo.XY).Set(-173, -16, -172, -15)))
+	}
+}
+
+func TestTreeNearest(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	gs := randomPoints(r, 1000)
+	gs = append(gs,
+		goodgeo.NewLineString(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{10, 10}, {20, 10}}),
+		goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{{{-10, -10}, {-5, -10}, {-5, -5}, {-10, -5}, {-10, -10}}}),
+	)
+	tr := New()
+	tr.Load(gs)
+
+	for i := range 50 {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			pt := goodgeo.Coord{r.Float64()*360 - 180, r.Float64()*170 - 85}
+			want := slices.Clone(gs)
+			sort.SliceStable(want, func(i, j int) bool { return distance(pt, want[i]) < distance(pt, want[j]) })
+			got := tr.Nearest(pt, 5)
+			assert.Equal(t, 5, len(got))
+			for j := range got {
+				assert.Equal(t, distance(pt, want[j]), distance(pt, got[j]))
+			}
+		})
+	}
+
+	assert.Equal(t, gs[len(gs)-1], tr.Nearest(goodgeo.Coord{-7, -7}, 1)[0])
+	assert.Equal(t, gs[len(gs)-2], tr.Nearest(goodgeo.Coord{15, 10.01}, 1)[0])
+}
+
+func TestTreeDelete(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	gs := randomPoints(r, 500)
+	tr := New(4)
+	tr.Load(gs[:250])
+	for _, g := range gs[250:] {
+		tr.Insert(g)
+	}
+	for _, g := range gs[:400] {
+		assert.True(t, tr.Delete(g))
+	}
+	assert.False(t, tr.Delete(gs[0]))
+	assert.False(t, tr.Delete(goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{0, 0})))
+	assert.Equal(t, 100, tr.Len())
+
+	all := goodgeo.NewGeographicBounds(goodgeo.XY).Set(-180, -90, 180, 90)
+	sameSet(t, gs[400:], tr.Search(all))
+	for _, g := range gs[400:] {
+		assert.True(t, tr.Delete(g))
+	}
+	assert.Equal(t, 0, tr.Len())
+	assert.Equal(t, 0, len(tr.Search(all)))
+}
+
+func TestTreeDeleteChangedBounds(t *testing.T) {
+	route := goodgeo.NewLineString(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{178.4, -18.1}, {-171.8, -13.8}})
+	for _, tr := range []*Tree{New(), New(4)} {
+		tr.Insert(route)
+		// Only the box east of the antimeridian still matches the indexed
+		// ones, the route must stay whole.
+		route.MustSetCoords([]goodgeo.Coord{{178.4, -18.1}, {-160, -13.8}})
+		assert.False(t, tr.Delete(route))
+		assert.Equal(t, 1, tr.Len())
+		sameSet(t, []goodgeo.T{route}, tr.Search(goodgeo.NewGeographicBounds(goodgeo.XY).Set(179, -16, 179.5, -15)))
+		sameSet(t, []goodgeo.T{route}, tr.Search(goodgeo.NewGeographicBounds(goodgeo.XY).Set(-173, -16, -172, -15)))
+
+		route.MustSetCoords([]goodgeo.Coord{{178.4, -18.1}, {-171.8, -13.8}})
+		assert.True(t, tr.Delete(route))
+		assert.Equal(t, 0, tr.Len())
+	}
+}
+
+func TestTreeConcurrentReaders(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	gs := randomPoints(r, 1000)
+	tr := New()
+	tr.Load(gs[:500])
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				if i == 0 {
+					tr.Insert(gs[500+j])
+					continue
+				}
+				tr.Search(goodgeo.NewGeographicBounds(goodgeo.XY).Set(-10, -10, 10, 10))
+				tr.Nearest(goodgeo.Coord{0, 0}, 3)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 600, tr.Len())
+}
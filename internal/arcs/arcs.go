@@ -0,0 +1,163 @@
+// Package arcs cuts lines and rings into arcs at the points where they meet
+// other lines and rings, so that the boundaries they share can be stored,
+// or simplified, once.
+package arcs
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// A Point is the position of a vertex of a path.
+type Point [2]float64
+
+// A Path is a line, or a ring if it is closed, in which case its last point
+// is its first.
+type Path struct {
+	Points []Point
+	Closed bool
+}
+
+// Junctions returns the ends of lines and the points at which paths that
+// pass through them come from or go to different points.
+func Junctions(paths []Path) map[Point]bool {
+	var (
+		junctions  = make(map[Point]bool)
+		neighbours = make(map[Point][2]Point)
+	)
+	for _, p := range paths {
+		points := p.Points
+		n := len(points)
+		if p.Closed {
+			n--
+		} else if n > 0 {
+			junctions[points[0]] = true
+			junctions[points[n-1]] = true
+		}
+		for i := 0; i < n; i++ {
+			if !p.Closed && (i == 0 || i == n-1) {
+				continue
+			}
+			prev, next := points[(i+n-1)%n], points[i+1]
+			if prev[0] > next[0] || prev[0] == next[0] && prev[1] > next[1] {
+				prev, next = next, prev
+			}
+			pair := [2]Point{prev, next}
+			if seen, ok := neighbours[points[i]]; !ok {
+				neighbours[points[i]] = pair
+			} else if seen != pair {
+				junctions[points[i]] = true
+			}
+		}
+	}
+	return junctions
+}
+
+// Cut cuts p into arcs at junctions and returns the indices of the points of
+// each arc in p. A ring without junctions is a single arc that starts at its
+// lowest point, a path without points has no arcs.
+func Cut(p Path, junctions map[Point]bool) [][]int {
+	points := p.Points
+	n := len(points)
+	if p.Closed {
+		n--
+	}
+	switch {
+	case len(points) == 0:
+		return nil
+	case n < 2:
+		return [][]int{indices(0, len(points), len(points))}
+	}
+	var cuts []int
+	for i := 0; i < n; i++ {
+		if junctions[points[i]] {
+			cuts = append(cuts, i)
+		}
+	}
+	if !p.Closed {
+		cuts = append(cuts, n-1)
+		var arcs [][]int
+		for i := 1; i < len(cuts); i++ {
+			if cuts[i] > cuts[i-1] {
+				arcs = append(arcs, indices(cuts[i-1], cuts[i]+1, n))
+			}
+		}
+		return arcs
+	}
+
+	if len(cuts) == 0 {
+		lowest := 0
+		for i := 1; i < n; i++ {
+			if c, l := points[i], points[lowest]; c[0] < l[0] || c[0] == l[0] && c[1] < l[1] {
+				lowest = i
+			}
+		}
+		cuts = []int{lowest}
+	}
+	first := cuts[0]
+	var arcs [][]int
+	for i, cut := range cuts {
+		end := first + n
+		if i+1 < len(cuts) {
+			end = cuts[i+1]
+		}
+		arcs = append(arcs, indices(cut, end+1, n))
+	}
+	return arcs
+}
+
+// indices returns the indices from start up to end, wrapped around n.
+func indices(start, end, n int) []int {
+	result := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		result = append(result, i%n)
+	}
+	return result
+}
+
+// A Set holds distinct arcs. An arc and its reverse are the same arc.
+type Set struct {
+	index map[string]int
+}
+
+// NewSet returns an empty Set.
+func NewSet() *Set {
+	return &Set{index: make(map[string]int)}
+}
+
+// Len returns the number of arcs in s.
+func (s *Set) Len() int {
+	return len(s.index)
+}
+
+// Add returns the index of arc in s, or the one's complement of the index of
+// its reverse, and whether it was added to s as the arc with index Len()-1.
+func (s *Set) Add(arc []Point) (int, bool) {
+	forward := key(arc, false)
+	if i, ok := s.index[forward]; ok {
+		return i, false
+	}
+	if i, ok := s.index[key(arc, true)]; ok {
+		return ^i, false
+	}
+	i := len(s.index)
+	s.index[forward] = i
+	return i, true
+}
+
+// key returns the points of arc, in reverse if reversed is true, as a
+// string.
+func key(arc []Point, reversed bool) string {
+	b := make([]byte, 0, 16*len(arc))
+	for i := range arc {
+		p := arc[i]
+		if reversed {
+			p = arc[len(arc)-1-i]
+		}
+		// Adding zero turns negative zeros into zeros, which are the same
+		// point.
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(p[0]+0))
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(p[1]+0))
+	}
+	return string(b)
+}
@@ -0,0 +1,43 @@
+package arcs
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestCut(t *testing.T) {
+	// A ring and a line that shares two of its edges.
+	ring := Path{Points: []Point{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}, Closed: true}
+	line := Path{Points: []Point{{3, -1}, {2, 0}, {2, 2}, {0, 2}, {-1, 3}}}
+	junctions := Junctions([]Path{ring, line})
+	assert.Equal(t, map[Point]bool{{2, 0}: true, {0, 2}: true, {3, -1}: true, {-1, 3}: true}, junctions)
+	assert.Equal(t, [][]int{{1, 2, 3}, {3, 0, 1}}, Cut(ring, junctions))
+	assert.Equal(t, [][]int{{0, 1}, {1, 2, 3}, {3, 4}}, Cut(line, junctions))
+
+	// A ring without junctions starts at its lowest point.
+	alone := Path{Points: []Point{{1, 1}, {0, 1}, {0, 0}, {1, 1}}, Closed: true}
+	assert.Equal(t, [][]int{{2, 0, 1, 2}}, Cut(alone, nil))
+	assert.Equal(t, [][]int(nil), Cut(Path{Closed: true}, nil))
+}
+
+func TestSet(t *testing.T) {
+	s := NewSet()
+	for _, tc := range []struct {
+		arc   []Point
+		index int
+		added bool
+	}{
+		{arc: []Point{{0, 0}, {1, 0}}, index: 0, added: true},
+		{arc: []Point{{1, 0}, {1, 1}}, index: 1, added: true},
+		{arc: []Point{{1, 0}, {0, 0}}, index: ^0},
+		{arc: []Point{{0, 0}, {1, 0}}, index: 0},
+		{arc: []Point{{math.Copysign(0, -1), 0}, {1, 0}}, index: 0},
+	} {
+		index, added := s.Add(tc.arc)
+		assert.Equal(t, tc.index, index)
+		assert.Equal(t, tc.added, added)
+	}
+	assert.Equal(t, 2, s.Len())
+}
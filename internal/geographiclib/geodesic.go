@@ -0,0 +1,889 @@
+// Package geographiclib solves the inverse geodesic problem and computes the
+// area of geodesic polygons on an ellipsoid of revolution. It is a port of
+// the parts of GeographicLib that are needed by goodgeo, see Charles F. F.
+// Karney, "Algorithms for geodesics", J. Geodesy 87, 43–55 (2013).
+//
+// Results are accurate to within about 15 nanometers for distances and
+// 0.1 square meters for areas on the Earth, and the inverse problem is
+// solved for all pairs of points, including nearly antipodal ones.
+package geographiclib
+
+import "math"
+
+const (
+	order  = 6
+	nC3x   = order * (order - 1) / 2
+	nC4x   = order * (order + 1) / 2
+	maxit1 = 20
+	maxit2 = maxit1 + 53 + 10
+)
+
+var (
+	tiny    = math.Sqrt(0x1p-1022)
+	tol0    = 0x1p-52
+	tol1    = 200 * tol0
+	tol2    = math.Sqrt(tol0)
+	tolb    = tol0
+	xthresh = 1000 * tol2
+)
+
+// A Geodesic holds the constants of the series expansions for an ellipsoid.
+// It is not modified after New and is safe for concurrent use.
+type Geodesic struct {
+	a, f, f1, e2, ep2, n, b, c2, etol2 float64
+	a3x                                [order]float64
+	c3x                                [nC3x]float64
+	c4x                                [nC4x]float64
+}
+
+// New returns a Geodesic for the ellipsoid with equatorial radius a and
+// flattening f.
+func New(a, f float64) *Geodesic {
+	g := &Geodesic{a: a, f: f, f1: 1 - f}
+	g.e2 = f * (2 - f)
+	g.ep2 = g.e2 / (g.f1 * g.f1)
+	g.n = f / (2 - f)
+	g.b = a * g.f1
+	var authalic float64
+	switch {
+	case g.e2 == 0:
+		authalic = 1
+	case g.e2 > 0:
+		authalic = math.Atanh(math.Sqrt(g.e2)) / math.Sqrt(g.e2)
+	default:
+		authalic = math.Atan(math.Sqrt(-g.e2)) / math.Sqrt(-g.e2)
+	}
+	g.c2 = (a*a + g.b*g.b*authalic) / 2
+	g.etol2 = 0.1 * tol2 / math.Sqrt(math.Max(0.001, math.Abs(f))*math.Min(1, 1-f/2)/2)
+	g.a3coeff()
+	g.c3coeff()
+	g.c4coeff()
+	return g
+}
+
+// Area returns the area of the whole ellipsoid.
+func (g *Geodesic) Area() float64 {
+	return 4 * math.Pi * g.c2
+}
+
+// Inverse returns the length s12 of the shortest geodesic from (lat1, lon1)
+// to (lat2, lon2), its azimuths azi1 and azi2 at both ends, and the area S12
+// between the geodesic and the equator, all in meters and degrees. The
+// azimuths are measured clockwise from north, in the range [-180, 180].
+func (g *Geodesic) Inverse(lat1, lon1, lat2, lon2 float64) (s12, azi1, azi2, S12 float64) {
+	var salp1, calp1, salp2, calp2 float64
+	s12, salp1, calp1, salp2, calp2, S12 = g.inverse(lat1, lon1, lat2, lon2)
+	return s12, atan2d(salp1, calp1), atan2d(salp2, calp2), S12
+}
+
+func (g *Geodesic) inverse(lat1, lon1, lat2, lon2 float64) (s12, salp1, calp1, salp2, calp2, S12 float64) {
+	var ca [order + 1]float64
+
+	// Make the longitude difference positive, and swap and reflect the
+	// points so that -90 <= lat1 <= -0 and lat1 <= lat2 <= -lat1. The signs
+	// record the transformation, 1 meaning no change.
+	lon12, lon12s := angDiff(lon1, lon2)
+	lonsign := 1.0
+	if math.Signbit(lon12) {
+		lonsign = -1
+	}
+	lon12 *= lonsign
+	lon12s *= lonsign
+	lam12 := lon12 * math.Pi / 180
+	slam12, clam12 := sincosde(lon12, lon12s)
+	// The supplementary longitude difference.
+	lon12s = (180 - lon12) - lon12s
+
+	lat1, lat2 = angRound(latFix(lat1)), angRound(latFix(lat2))
+	swapp := 1.0
+	if math.Abs(lat1) < math.Abs(lat2) || math.IsNaN(lat2) {
+		swapp = -1
+		lonsign *= -1
+		lat1, lat2 = lat2, lat1
+	}
+	latsign := -1.0
+	if math.Signbit(lat1) {
+		latsign = 1
+	}
+	lat1 *= latsign
+	lat2 *= latsign
+
+	sbet1, cbet1 := sincosd(lat1)
+	sbet1 *= g.f1
+	sbet1, cbet1 = norm2(sbet1, cbet1)
+	cbet1 = math.Max(tiny, cbet1)
+	sbet2, cbet2 := sincosd(lat2)
+	sbet2 *= g.f1
+	sbet2, cbet2 = norm2(sbet2, cbet2)
+	cbet2 = math.Max(tiny, cbet2)
+
+	// Force bet2 = ±bet1 exactly where the difference between their
+	// absolute values vanishes, Lambda12 relies on it.
+	if cbet1 < -sbet1 {
+		if cbet2 == cbet1 {
+			sbet2 = math.Copysign(sbet1, sbet2)
+		}
+	} else if math.Abs(sbet2) == -sbet1 {
+		cbet2 = cbet1
+	}
+
+	dn1 := math.Sqrt(1 + g.ep2*sbet1*sbet1)
+	dn2 := math.Sqrt(1 + g.ep2*sbet2*sbet2)
+
+	var sig12, s12x, m12x float64
+	// somg12 = 2 marks that it has not been computed.
+	omg12, somg12, comg12 := 0.0, 2.0, 0.0
+
+	meridian := lat1 == -90 || slam12 == 0
+	if meridian {
+		// The end points are on a single full meridian, so the geodesic
+		// might lie on it.
+		calp1, salp1 = clam12, slam12
+		calp2, salp2 = 1, 0
+		ssig1, csig1 := sbet1, calp1*cbet1
+		ssig2, csig2 := sbet2, calp2*cbet2
+		sig12 = math.Atan2(math.Max(0, csig1*ssig2-ssig1*csig2)+0, csig1*csig2+ssig1*ssig2)
+		s12x, m12x, _ = g.lengths(g.n, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2, ca[:])
+		// A meridional geodesic longer than half a meridian is not the
+		// shortest path.
+		if sig12 < 1 || m12x >= 0 {
+			if sig12 < 3*tiny || sig12 < tol0 && (s12x < 0 || m12x < 0) {
+				sig12, m12x, s12x = 0, 0, 0
+			}
+			m12x *= g.b
+			s12x *= g.b
+		} else {
+			meridian = false
+		}
+	}
+
+	switch {
+	case meridian:
+	case sbet1 == 0 && (g.f <= 0 || lon12s >= g.f*180):
+		// The geodesic runs along the equator.
+		calp1, calp2, salp1, salp2 = 0, 0, 1, 1
+		s12x = g.a * lam12
+		sig12 = lam12 / g.f1
+		omg12 = sig12
+		m12x = g.b * math.Sin(sig12)
+	default:
+		// Find a starting point for Newton's method.
+		var dnm float64
+		sig12, salp1, calp1, salp2, calp2, dnm = g.inverseStart(sbet1, cbet1, dn1, sbet2, cbet2, dn2, lam12, slam12, clam12, ca[:])
+		if sig12 >= 0 {
+			// Short lines.
+			s12x = sig12 * g.b * dnm
+			m12x = dnm * dnm * g.b * math.Sin(sig12/dnm)
+			omg12 = lam12 / (g.f1 * dnm)
+			break
+		}
+
+		// Newton's method on lambda12(alp1) - lam12 = 0, which has exactly
+		// one root in (0, pi) with a positive derivative there. A bracket
+		// (alp1a, alp1b) around the root is kept, and bisected whenever a
+		// Newton step leaves it.
+		var ssig1, csig1, ssig2, csig2, eps, domg12 float64
+		salp1a, calp1a, salp1b, calp1b := tiny, 1.0, tiny, -1.0
+		tripn, tripb := false, false
+		for numit := 0; ; numit++ {
+			var v, dv float64
+			v, salp2, calp2, sig12, ssig1, csig1, ssig2, csig2, eps, domg12, dv = g.lambda12(
+				sbet1, cbet1, dn1, sbet2, cbet2, dn2, salp1, calp1, slam12, clam12, numit < maxit1, ca[:])
+			limit := 1.0
+			if tripn {
+				limit = 8
+			}
+			// The reversed test lets NaNs escape.
+			if tripb || !(math.Abs(v) >= limit*tol0) || numit == maxit2 {
+				break
+			}
+			if v > 0 && (numit > maxit1 || calp1/salp1 > calp1b/salp1b) {
+				salp1b, calp1b = salp1, calp1
+			} else if v < 0 && (numit > maxit1 || calp1/salp1 < calp1a/salp1a) {
+				salp1a, calp1a = salp1, calp1
+			}
+			if numit < maxit1 && dv > 0 {
+				if dalp1 := -v / dv; math.Abs(dalp1) < math.Pi {
+					sdalp1, cdalp1 := math.Sincos(dalp1)
+					if nsalp1 := salp1*cdalp1 + calp1*sdalp1; nsalp1 > 0 {
+						calp1 = calp1*cdalp1 - salp1*sdalp1
+						salp1 = nsalp1
+						salp1, calp1 = norm2(salp1, calp1)
+						// Convergence may be linear where the slope
+						// vanishes, so test against epsilon rather than
+						// its square root.
+						tripn = math.Abs(v) <= 16*tol0
+						continue
+					}
+				}
+			}
+			salp1, calp1 = norm2((salp1a+salp1b)/2, (calp1a+calp1b)/2)
+			tripn = false
+			tripb = math.Abs(salp1a-salp1)+(calp1a-calp1) < tolb || math.Abs(salp1-salp1b)+(calp1-calp1b) < tolb
+		}
+		s12x, m12x, _ = g.lengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2, ca[:])
+		m12x *= g.b
+		s12x *= g.b
+		// omg12 = lam12 - domg12
+		sdomg12, cdomg12 := math.Sincos(domg12)
+		somg12 = slam12*cdomg12 - clam12*sdomg12
+		comg12 = clam12*cdomg12 + slam12*sdomg12
+	}
+	s12 = 0 + s12x
+
+	// The area between the geodesic and the equator.
+	salp0, calp0 := salp1*cbet1, math.Hypot(calp1, salp1*sbet1)
+	if calp0 != 0 && salp0 != 0 {
+		ssig1, csig1 := norm2(sbet1, calp1*cbet1)
+		ssig2, csig2 := norm2(sbet2, calp2*cbet2)
+		k2 := calp0 * calp0 * g.ep2
+		eps := k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+		a4 := g.a * g.a * calp0 * salp0 * g.e2
+		g.c4f(eps, ca[:])
+		S12 = a4 * (sinCosSeries(false, ssig2, csig2, ca[:order]) - sinCosSeries(false, ssig1, csig1, ca[:order]))
+	}
+	if !meridian && somg12 == 2 {
+		somg12, comg12 = math.Sincos(omg12)
+	}
+	var alp12 float64
+	if !meridian && comg12 > -0.7071 && sbet2-sbet1 < 1.75 {
+		// tan(Gamma/2) = tan(omg12/2) * (tan(bet1/2) + tan(bet2/2)) /
+		// (1 + tan(bet1/2) * tan(bet2/2)), with tan(x/2) = sin(x)/(1+cos(x)).
+		domg12, dbet1, dbet2 := 1+comg12, 1+cbet1, 1+cbet2
+		alp12 = 2 * math.Atan2(somg12*(sbet1*dbet2+sbet2*dbet1), domg12*(sbet1*sbet2+dbet1*dbet2))
+	} else {
+		salp12, calp12 := salp2*calp1-calp2*salp1, calp2*calp1+salp2*salp1
+		if salp12 == 0 && calp12 < 0 {
+			salp12, calp12 = tiny*calp1, -1
+		}
+		alp12 = math.Atan2(salp12, calp12)
+	}
+	S12 += g.c2 * alp12
+	S12 = S12*swapp*lonsign*latsign + 0
+
+	// Undo the transformation of the points.
+	if swapp < 0 {
+		salp1, salp2 = salp2, salp1
+		calp1, calp2 = calp2, calp1
+	}
+	salp1 *= swapp * lonsign
+	calp1 *= swapp * latsign
+	salp2 *= swapp * lonsign
+	calp2 *= swapp * latsign
+	return s12, salp1, calp1, salp2, calp2, S12
+}
+
+// lengths returns the distance s12b and the reduced length m12b, both
+// divided by b, and the coefficient m0 of the secular term of the reduced
+// length.
+func (g *Geodesic) lengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2 float64, ca []float64) (s12b, m12b, m0 float64) {
+	var cb [order + 1]float64
+	a1 := a1m1f(eps)
+	c1f(eps, ca)
+	a2 := a2m1f(eps)
+	c2f(eps, cb[:])
+	m0 = a1 - a2
+	a1, a2 = 1+a1, 1+a2
+	b1 := sinCosSeries(true, ssig2, csig2, ca[:order+1]) - sinCosSeries(true, ssig1, csig1, ca[:order+1])
+	s12b = a1 * (sig12 + b1)
+	b2 := sinCosSeries(true, ssig2, csig2, cb[:]) - sinCosSeries(true, ssig1, csig1, cb[:])
+	j12 := m0*sig12 + (a1*b1 - a2*b2)
+	// The parentheses ensure accurate cancellation for coincident points.
+	m12b = dn2*(csig1*ssig2) - dn1*(ssig1*csig2) - csig1*csig2*j12
+	return s12b, m12b, m0
+}
+
+// astroid returns the positive root k of
+// k^4 + 2k^3 - (x^2 + y^2 - 1)k^2 - 2y^2k - y^2 = 0.
+func astroid(x, y float64) float64 {
+	p, q := x*x, y*y
+	r := (p + q - 1) / 6
+	if q == 0 && r <= 0 {
+		// y = 0 with |x| <= 1.
+		return 0
+	}
+	s := p * q / 4
+	r2 := r * r
+	r3 := r * r2
+	// The discriminant of the quadratic equation for T3, zero on the evolute
+	// curve p^(1/3) + q^(1/3) = 1.
+	disc := s * (s + 2*r3)
+	u := r
+	if disc >= 0 {
+		t3 := s + r3
+		// Pick the sign of the root that maximizes |T3| to avoid
+		// cancellation.
+		if t3 < 0 {
+			t3 -= math.Sqrt(disc)
+		} else {
+			t3 += math.Sqrt(disc)
+		}
+		t := math.Cbrt(t3)
+		if t != 0 {
+			u += t + r2/t
+		}
+	} else {
+		// T is complex but u is real.
+		ang := math.Atan2(math.Sqrt(-disc), -(s + r3))
+		u += 2 * r * math.Cos(ang/3)
+	}
+	v := math.Sqrt(u*u + q)
+	uv := u + v
+	if u < 0 {
+		uv = q / (v - u)
+	}
+	w := (uv - q) / (2 * v)
+	return uv / (math.Sqrt(uv+w*w) + w)
+}
+
+// inverseStart returns a starting point salp1, calp1 for Newton's method
+// and a negative sig12, or, if Newton's method is not needed, the solution
+// sig12, salp1, calp1, salp2, calp2. dnm is set for short lines.
+func (g *Geodesic) inverseStart(sbet1, cbet1, dn1, sbet2, cbet2, dn2, lam12, slam12, clam12 float64, ca []float64) (sig12, salp1, calp1, salp2, calp2, dnm float64) {
+	sig12 = -1
+	// bet12 = bet2 - bet1 in [0, pi), bet12a = bet2 + bet1 in (-pi, 0].
+	sbet12 := sbet2*cbet1 - cbet2*sbet1
+	cbet12 := cbet2*cbet1 + sbet2*sbet1
+	sbet12a := sbet2*cbet1 + cbet2*sbet1
+	shortline := cbet12 >= 0 && sbet12 < 0.5 && cbet2*lam12 < 0.5
+	var somg12, comg12 float64
+	if shortline {
+		sbetm2 := (sbet1 + sbet2) * (sbet1 + sbet2)
+		sbetm2 /= sbetm2 + (cbet1+cbet2)*(cbet1+cbet2)
+		dnm = math.Sqrt(1 + g.ep2*sbetm2)
+		somg12, comg12 = math.Sincos(lam12 / (g.f1 * dnm))
+	} else {
+		somg12, comg12 = slam12, clam12
+	}
+
+	salp1 = cbet2 * somg12
+	if comg12 >= 0 {
+		calp1 = sbet12 + cbet2*sbet1*somg12*somg12/(1+comg12)
+	} else {
+		calp1 = sbet12a - cbet2*sbet1*somg12*somg12/(1-comg12)
+	}
+	ssig12 := math.Hypot(salp1, calp1)
+	csig12 := sbet1*sbet2 + cbet1*cbet2*comg12
+
+	switch {
+	case shortline && ssig12 < g.etol2:
+		// Really short lines.
+		salp2 = cbet1 * somg12
+		if comg12 >= 0 {
+			calp2 = sbet12 - cbet1*sbet2*somg12*somg12/(1+comg12)
+		} else {
+			calp2 = sbet12 - cbet1*sbet2*(1-comg12)
+		}
+		salp2, calp2 = norm2(salp2, calp2)
+		sig12 = math.Atan2(ssig12, csig12)
+	case math.Abs(g.n) > 0.1 || csig12 >= 0 || ssig12 >= 6*math.Abs(g.n)*math.Pi*cbet1*cbet1:
+		// The zeroth order spherical approximation is good enough.
+	default:
+		// Scale lam12 and bet2 to coordinates x, y in which the antipodal
+		// point is at the origin and the singular point at x = -1, y = 0.
+		var x, y, lamscale, betscale float64
+		lam12x := math.Atan2(-slam12, -clam12)
+		if g.f >= 0 {
+			k2 := sbet1 * sbet1 * g.ep2
+			eps := k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+			lamscale = g.f * cbet1 * g.a3f(eps) * math.Pi
+			betscale = lamscale * cbet1
+			x = lam12x / lamscale
+			y = sbet12a / betscale
+		} else {
+			cbet12a := cbet2*cbet1 - sbet2*sbet1
+			bet12a := math.Atan2(sbet12a, cbet12a)
+			_, m12b, m0 := g.lengths(g.n, math.Pi+bet12a, sbet1, -cbet1, dn1, sbet2, cbet2, dn2, ca)
+			x = -1 + m12b/(cbet1*cbet2*m0*math.Pi)
+			if x < -0.01 {
+				betscale = sbet12a / x
+			} else {
+				betscale = -g.f * cbet1 * cbet1 * math.Pi
+			}
+			lamscale = betscale / cbet1
+			y = lam12x / lamscale
+		}
+
+		if y > -tol1 && x > -1-xthresh {
+			// Strip near the cut.
+			if g.f >= 0 {
+				salp1 = math.Min(1, -x)
+				calp1 = -math.Sqrt(1 - salp1*salp1)
+			} else {
+				lower := -1.0
+				if x > -tol1 {
+					lower = 0
+				}
+				calp1 = math.Max(lower, x)
+				salp1 = math.Sqrt(1 - calp1*calp1)
+			}
+		} else {
+			// Estimate omg12 from the astroid problem and use the
+			// spherical formula for alp1. omg12 is near pi, so work with
+			// omg12a = pi - omg12.
+			k := astroid(x, y)
+			var omg12a float64
+			if g.f >= 0 {
+				omg12a = lamscale * (-x * k / (1 + k))
+			} else {
+				omg12a = lamscale * (-y * (1 + k) / k)
+			}
+			somg12, comg12 = math.Sincos(omg12a)
+			comg12 = -comg12
+			salp1 = cbet2 * somg12
+			calp1 = sbet12a - cbet2*sbet1*somg12*somg12/(1-comg12)
+		}
+	}
+	// The reversed test lets NaNs through.
+	if !(salp1 <= 0) {
+		salp1, calp1 = norm2(salp1, calp1)
+	} else {
+		salp1, calp1 = 1, 0
+	}
+	return sig12, salp1, calp1, salp2, calp2, dnm
+}
+
+// lambda12 returns the difference lam12 between the longitude of the end
+// of the geodesic that leaves point 1 with azimuth alp1 and reaches the
+// latitude of point 2 and the target longitude difference lam120, along
+// with the quantities computed on the way and, if diffp, the derivative of
+// lam12 with respect to alp1.
+func (g *Geodesic) lambda12(sbet1, cbet1, dn1, sbet2, cbet2, dn2, salp1, calp1, slam120, clam120 float64, diffp bool, ca []float64) (
+	lam12, salp2, calp2, sig12, ssig1, csig1, ssig2, csig2, eps, domg12, dlam12 float64,
+) {
+	if sbet1 == 0 && calp1 == 0 {
+		// Break the degeneracy of equatorial lines, which are handled
+		// elsewhere.
+		calp1 = -tiny
+	}
+	salp0 := salp1 * cbet1
+	calp0 := math.Hypot(calp1, salp1*sbet1)
+
+	// tan(bet1) = tan(sig1) * cos(alp1), tan(omg1) = sin(alp0) * tan(sig1).
+	ssig1 = sbet1
+	somg1 := salp0 * sbet1
+	csig1 = calp1 * cbet1
+	comg1 := csig1
+	ssig1, csig1 = norm2(ssig1, csig1)
+
+	// Enforce symmetries where |bet2| = -bet1, which can otherwise yield
+	// singularities in Newton's method.
+	if cbet2 != cbet1 {
+		salp2 = salp0 / cbet2
+	} else {
+		salp2 = salp1
+	}
+	if cbet2 != cbet1 || math.Abs(sbet2) != -sbet1 {
+		var d float64
+		if cbet1 < -sbet1 {
+			d = (cbet2 - cbet1) * (cbet1 + cbet2)
+		} else {
+			d = (sbet1 - sbet2) * (sbet1 + sbet2)
+		}
+		calp2 = math.Sqrt((calp1*cbet1)*(calp1*cbet1)+d) / cbet2
+	} else {
+		calp2 = math.Abs(calp1)
+	}
+	ssig2 = sbet2
+	somg2 := salp0 * sbet2
+	csig2 = calp2 * cbet2
+	comg2 := csig2
+	ssig2, csig2 = norm2(ssig2, csig2)
+
+	// sig12 = sig2 - sig1 and omg12 = omg2 - omg1, limited to [0, pi].
+	sig12 = math.Atan2(math.Max(0, csig1*ssig2-ssig1*csig2)+0, csig1*csig2+ssig1*ssig2)
+	somg12 := math.Max(0, comg1*somg2-somg1*comg2) + 0
+	comg12 := comg1*comg2 + somg1*somg2
+	// eta = omg12 - lam120
+	eta := math.Atan2(somg12*clam120-comg12*slam120, comg12*clam120+somg12*slam120)
+	k2 := calp0 * calp0 * g.ep2
+	eps = k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+	g.c3f(eps, ca)
+	b312 := sinCosSeries(true, ssig2, csig2, ca[:order]) - sinCosSeries(true, ssig1, csig1, ca[:order])
+	domg12 = -g.f * g.a3f(eps) * salp0 * (sig12 + b312)
+	lam12 = eta + domg12
+
+	if diffp {
+		if calp2 == 0 {
+			dlam12 = -2 * g.f1 * dn1 / sbet1
+		} else {
+			_, dlam12, _ = g.lengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2, ca)
+			dlam12 *= g.f1 / (calp2 * cbet2)
+		}
+	}
+	return lam12, salp2, calp2, sig12, ssig1, csig1, ssig2, csig2, eps, domg12, dlam12
+}
+
+// PolygonArea returns the area of the geodesic polygon with the vertices
+// at the longitudes and latitudes in flatCoords, with the given stride. The
+// area is positive if the polygon is traversed counter-clockwise, and the
+// ring may, but does not need to, be closed.
+func (g *Geodesic) PolygonArea(flatCoords []float64, stride int) float64 {
+	n := len(flatCoords) / stride
+	if n < 3 {
+		return 0
+	}
+	// The sum is kept with its round-off error, the terms are much larger
+	// than the area of small polygons.
+	var area, err float64
+	crossings := 0
+	for i := range n {
+		j := (i + 1) % n
+		lon1, lat1 := flatCoords[i*stride], flatCoords[i*stride+1]
+		lon2, lat2 := flatCoords[j*stride], flatCoords[j*stride+1]
+		_, _, _, _, _, S12 := g.inverse(lat1, lon1, lat2, lon2)
+		var e float64
+		area, e = sumx(area, S12)
+		err += e
+		crossings += transit(lon1, lon2)
+	}
+
+	// The sum is the clockwise area, modulo the area of the ellipsoid and
+	// off by half of it for every crossing of the prime meridian.
+	area0 := g.Area()
+	area = math.Remainder(area, area0) + err
+	if crossings&1 != 0 {
+		if area < 0 {
+			area += area0 / 2
+		} else {
+			area -= area0 / 2
+		}
+	}
+	area = -area
+	switch {
+	case area > area0/2:
+		area -= area0
+	case area <= -area0/2:
+		area += area0
+	}
+	return 0 + area
+}
+
+// transit returns 1 if the edge from lon1 to lon2 crosses the prime
+// meridian going east, -1 if it crosses it going west, and 0 otherwise.
+func transit(lon1, lon2 float64) int {
+	lon12, _ := angDiff(lon1, lon2)
+	lon1, lon2 = angNormalize(lon1), angNormalize(lon2)
+	switch {
+	case lon12 > 0 && (lon1 < 0 && lon2 >= 0 || lon1 > 0 && lon2 == 0):
+		return 1
+	case lon12 < 0 && lon1 >= 0 && lon2 < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func (g *Geodesic) a3f(eps float64) float64 {
+	return polyval(g.a3x[:], eps)
+}
+
+// c3f sets c[1] through c[order-1] to the C3 coefficients.
+func (g *Geodesic) c3f(eps float64, c []float64) {
+	mult := 1.0
+	o := 0
+	for l := 1; l < order; l++ {
+		m := order - l - 1
+		mult *= eps
+		c[l] = mult * polyval(g.c3x[o:o+m+1], eps)
+		o += m + 1
+	}
+}
+
+// c4f sets c[0] through c[order-1] to the C4 coefficients.
+func (g *Geodesic) c4f(eps float64, c []float64) {
+	mult := 1.0
+	o := 0
+	for l := range order {
+		m := order - l - 1
+		c[l] = mult * polyval(g.c4x[o:o+m+1], eps)
+		o += m + 1
+		mult *= eps
+	}
+}
+
+func (g *Geodesic) a3coeff() {
+	coeff := []float64{
+		// A3, coefficients of eps^5 down to eps^0, polynomials in n.
+		-3, 128,
+		-2, -3, 64,
+		-1, -3, -1, 16,
+		3, -1, -2, 8,
+		1, -1, 2,
+		1, 1,
+	}
+	o, k := 0, 0
+	for j := order - 1; j >= 0; j-- {
+		m := min(order-j-1, j)
+		g.a3x[k] = polyval(coeff[o:o+m+1], g.n) / coeff[o+m+1]
+		k++
+		o += m + 2
+	}
+}
+
+func (g *Geodesic) c3coeff() {
+	coeff := []float64{
+		// C3[1], coefficients of eps^5 down to eps^1, polynomials in n.
+		3, 128,
+		2, 5, 128,
+		-1, 3, 3, 64,
+		-1, 0, 1, 8,
+		-1, 1, 4,
+		// C3[2], eps^5 down to eps^2.
+		5, 256,
+		1, 3, 128,
+		-3, -2, 3, 64,
+		1, -3, 2, 32,
+		// C3[3], eps^5 down to eps^3.
+		7, 512,
+		-10, 9, 384,
+		5, -9, 5, 192,
+		// C3[4], eps^5 down to eps^4.
+		7, 512,
+		-14, 7, 512,
+		// C3[5], eps^5.
+		21, 2560,
+	}
+	o, k := 0, 0
+	for l := 1; l < order; l++ {
+		for j := order - 1; j >= l; j-- {
+			m := min(order-j-1, j)
+			g.c3x[k] = polyval(coeff[o:o+m+1], g.n) / coeff[o+m+1]
+			k++
+			o += m + 2
+		}
+	}
+}
+
+func (g *Geodesic) c4coeff() {
+	coeff := []float64{
+		// C4[0], coefficients of eps^5 down to eps^0, polynomials in n.
+		97, 15015,
+		1088, 156, 45045,
+		-224, -4784, 1573, 45045,
+		-10656, 14144, -4576, -858, 45045,
+		64, 624, -4576, 6864, -3003, 15015,
+		100, 208, 572, 3432, -12012, 30030, 45045,
+		// C4[1], eps^5 down to eps^1.
+		1, 9009,
+		-2944, 468, 135135,
+		5792, 1040, -1287, 135135,
+		5952, -11648, 9152, -2574, 135135,
+		-64, -624, 4576, -6864, 3003, 135135,
+		// C4[2], eps^5 down to eps^2.
+		8, 10725,
+		1856, -936, 225225,
+		-8448, 4992, -1144, 225225,
+		-1440, 4160, -4576, 1716, 225225,
+		// C4[3], eps^5 down to eps^3.
+		-136, 63063,
+		1024, -208, 105105,
+		3584, -3328, 1144, 315315,
+		// C4[4], eps^5 down to eps^4.
+		-128, 135135,
+		-2560, 832, 405405,
+		// C4[5], eps^5.
+		128, 99099,
+	}
+	o, k := 0, 0
+	for l := range order {
+		for j := order - 1; j >= l; j-- {
+			m := order - j - 1
+			g.c4x[k] = polyval(coeff[o:o+m+1], g.n) / coeff[o+m+1]
+			k++
+			o += m + 2
+		}
+	}
+}
+
+// a1m1f returns A1 - 1.
+func a1m1f(eps float64) float64 {
+	t := polyval([]float64{1, 4, 64, 0}, eps*eps) / 256
+	return (t + eps) / (1 - eps)
+}
+
+// c1f sets c[1] through c[order] to the C1 coefficients.
+func c1f(eps float64, c []float64) {
+	coeff := []float64{
+		// C1[l]/eps^l, polynomials in eps^2.
+		-1, 6, -16, 32,
+		-9, 64, -128, 2048,
+		9, -16, 768,
+		3, -5, 512,
+		-7, 1280,
+		-7, 2048,
+	}
+	seriesCoefficients(coeff, eps, c)
+}
+
+// a2m1f returns A2 - 1.
+func a2m1f(eps float64) float64 {
+	t := polyval([]float64{-11, -28, -192, 0}, eps*eps) / 256
+	return (t - eps) / (1 + eps)
+}
+
+// c2f sets c[1] through c[order] to the C2 coefficients.
+func c2f(eps float64, c []float64) {
+	coeff := []float64{
+		// C2[l]/eps^l, polynomials in eps^2.
+		1, 2, 16, 32,
+		35, 64, 384, 2048,
+		15, 80, 768,
+		7, 35, 512,
+		63, 1280,
+		77, 2048,
+	}
+	seriesCoefficients(coeff, eps, c)
+}
+
+func seriesCoefficients(coeff []float64, eps float64, c []float64) {
+	eps2, d := eps*eps, eps
+	o := 0
+	for l := 1; l <= order; l++ {
+		m := (order - l) / 2
+		c[l] = d * polyval(coeff[o:o+m+1], eps2) / coeff[o+m+1]
+		o += m + 2
+		d *= eps
+	}
+}
+
+// polyval evaluates the polynomial with the coefficients p, highest degree
+// first, at x.
+func polyval(p []float64, x float64) float64 {
+	var y float64
+	for _, c := range p {
+		y = y*x + c
+	}
+	return y
+}
+
+// sinCosSeries returns the sum of c[i] sin(2ix) for i in [1, len(c)) if
+// sinp, and the sum of c[i] cos((2i+1)x) for i in [0, len(c)) otherwise,
+// using Clenshaw summation.
+func sinCosSeries(sinp bool, sinx, cosx float64, c []float64) float64 {
+	k := len(c)
+	n := k
+	if sinp {
+		n--
+	}
+	ar := 2 * (cosx - sinx) * (cosx + sinx)
+	var y0, y1 float64
+	if n&1 != 0 {
+		k--
+		y0 = c[k]
+	}
+	for range n / 2 {
+		k--
+		y1 = ar*y0 - y1 + c[k]
+		k--
+		y0 = ar*y1 - y0 + c[k]
+	}
+	if sinp {
+		return 2 * sinx * cosx * y0
+	}
+	return cosx * (y0 - y1)
+}
+
+func norm2(s, c float64) (float64, float64) {
+	h := math.Hypot(s, c)
+	return s / h, c / h
+}
+
+// sumx returns the sum of u and v rounded to a float64 and its round-off
+// error.
+func sumx(u, v float64) (float64, float64) {
+	s := u + v
+	up := s - v
+	vpp := s - up
+	up -= u
+	vpp -= v
+	if s == 0 {
+		return s, s
+	}
+	return s, 0 - (up + vpp)
+}
+
+// angRound rounds tiny angles to zero, so that angles that are very close
+// to the equator or a meridian are treated as on it.
+func angRound(x float64) float64 {
+	const z = 1.0 / 16
+	y := math.Abs(x)
+	if w := z - y; w > 0 {
+		y = z - w
+	}
+	return math.Copysign(y, x)
+}
+
+func latFix(lat float64) float64 {
+	if math.Abs(lat) > 90 {
+		return math.NaN()
+	}
+	return lat
+}
+
+func angNormalize(x float64) float64 {
+	y := math.Remainder(x, 360)
+	if math.Abs(y) == 180 {
+		return math.Copysign(180, x)
+	}
+	return y
+}
+
+// angDiff returns the exact difference lon2 - lon1, reduced to [-180, 180],
+// as a sum d + e.
+func angDiff(x, y float64) (d, e float64) {
+	d, e = sumx(math.Remainder(-x, 360), math.Remainder(y, 360))
+	d, e = sumx(math.Remainder(d, 360), e)
+	if d == 0 || math.Abs(d) == 180 {
+		// Take the sign from y - x, or, if d = ±180, make d and e have
+		// opposite signs.
+		if e == 0 {
+			d = math.Copysign(d, y-x)
+		} else {
+			d = math.Copysign(d, -e)
+		}
+	}
+	return d, e
+}
+
+// sincosd returns the sine and cosine of x in degrees, reducing x exactly
+// to [-45, 45] before converting it to radians.
+func sincosd(x float64) (float64, float64) {
+	r, q := reduce(x)
+	return sincosq(x, r, q)
+}
+
+// sincosde returns the sine and cosine of x + t in degrees, where t is a
+// small correction to x.
+func sincosde(x, t float64) (float64, float64) {
+	r, q := reduce(x)
+	return sincosq(x, angRound(r+t), q)
+}
+
+// reduce returns r in [-45, 45] and q such that x = r + 90q modulo 360.
+func reduce(x float64) (float64, int) {
+	r := math.Mod(x, 360)
+	q := math.RoundToEven(r / 90)
+	return r - 90*q, int(q)
+}
+
+func sincosq(x, r float64, q int) (float64, float64) {
+	s, c := math.Sincos(r * math.Pi / 180)
+	switch q & 3 {
+	case 1:
+		s, c = c, -s
+	case 2:
+		s, c = -s, -c
+	case 3:
+		s, c = -c, s
+	}
+	c += 0
+	if s == 0 {
+		s = math.Copysign(s, x)
+	}
+	return s, c
+}
+
+func atan2d(y, x float64) float64 {
+	return math.Atan2(y, x) * 180 / math.Pi
+}
@@ -0,0 +1,55 @@
+package geographiclib
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+var wgs84 = New(6378137, 1/298.257223563)
+
+func TestInverse(t *testing.T) {
+	for i, tc := range []struct {
+		lat1, lon1, lat2, lon2 float64
+		s12, azi1, azi2        float64
+	}{
+		// Wellington to Salamanca, nearly antipodal.
+		{lat1: -41.32, lon1: 174.81, lat2: 40.96, lon2: -5.50, s12: 19959679.267353, azi1: 161.067669986, azi2: 18.825195123},
+		// JFK to LHR.
+		{lat1: 40.6, lon1: -73.8, lat2: 51.6, lon2: -0.5, s12: 5551759.400319, azi1: 51.198882845, azi2: 107.821776735},
+		{lat1: 0, lon1: 0, lat2: 0, lon2: 0},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			s12, azi1, azi2, _ := wgs84.Inverse(tc.lat1, tc.lon1, tc.lat2, tc.lon2)
+			assert.True(t, math.Abs(s12-tc.s12) < 1e-6, "s12 %f", s12)
+			if tc.s12 != 0 {
+				assert.True(t, math.Abs(azi1-tc.azi1) < 1e-8, "azi1 %f", azi1)
+				assert.True(t, math.Abs(azi2-tc.azi2) < 1e-8, "azi2 %f", azi2)
+			}
+		})
+	}
+}
+
+func TestPolygonArea(t *testing.T) {
+	// Cases from the tests of GeographicLib.
+	for i, tc := range []struct {
+		flatCoords []float64
+		want, tol  float64
+	}{
+		{flatCoords: []float64{0, 89, 90, 89, 180, 89, 270, 89}, want: 24952305678.0, tol: 1},
+		{flatCoords: []float64{0, -89, 90, -89, 180, -89, 270, -89}, want: -24952305678.0, tol: 1},
+		{flatCoords: []float64{-1, 0, 0, -1, 1, 0, 0, 1}, want: 24619419146.0, tol: 1},
+		{flatCoords: []float64{0, 90, 0, 0, 90, 0}, want: 63758202715511.0, tol: 1},
+		// A 1° by 1° cell at the equator, closed.
+		{flatCoords: []float64{0, 0, 1, 0, 1, 1, 0, 1, 0, 0}, want: 12308778361.469, tol: 1e-3},
+		// A cell crossing the antimeridian.
+		{flatCoords: []float64{179.5, 0, -179.5, 0, -179.5, 1, 179.5, 1}, want: 12308778361.469, tol: 1e-3},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := wgs84.PolygonArea(tc.flatCoords, 2)
+			assert.True(t, math.Abs(got-tc.want) < tc.tol, "got %f, want %f", got, tc.want)
+		})
+	}
+}
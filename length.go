@@ -1,12 +1,14 @@
 package goodgeo
 
 // Length measures a length of a geometry in the specified units, (Multi)Point's distance are ignored.
-func Length(ls *LineString) Meters {
+// Distances are measured with [Haversine] unless another [DistanceModel] is given.
+func Length(ls *LineString, model ...DistanceModel) Meters {
+	m := distanceModel(model)
 	length := Meters(0)
 	for i := ls.stride; i < len(ls.flatCoords); i += ls.stride {
 		a := ls.flatCoords[i-ls.stride : i]
 		b := ls.flatCoords[i : i+ls.stride]
-		length += Distance(a, b)
+		length += m.Distance(a, b)
 	}
 	return length
 }
@@ -0,0 +1,185 @@
+package goodgeo
+
+import (
+	"slices"
+	"sort"
+)
+
+// LineIntersect returns the points where the lines and rings of a intersect
+// those of b, ordered along a. Where they share a stretch, both of its ends
+// are returned. Points and empty geometries have no lines, polygons are
+// taken by their rings and members of geometry collections are included.
+// The points are two-dimensional and have the SRID of a.
+func LineIntersect(a, b T) *MultiPoint {
+	partsA := lineParts(a)
+	segments := appendSegments(nil, partsA, 0)
+	segments = appendSegments(segments, lineParts(b), len(partsA))
+	var points intersectionPoints
+	sweepSegments(segments, func(s, t *segment) {
+		if (s.part < len(partsA)) == (t.part < len(partsA)) {
+			return
+		}
+		if s.part >= len(partsA) {
+			s, t = t, s
+		}
+		points.add(s, segmentIntersection(s.a, s.b, t.a, t.b))
+	})
+	return points.multiPoint(a.SRID())
+}
+
+// Kinks returns the points where the lines and rings of g intersect
+// themselves or each other, ordered along them. Consecutive segments are
+// only taken to intersect where they overlap. Polygons are taken by their
+// rings, so the points where holes touch their exterior ring are included,
+// and members of geometry collections are included too. The points are
+// two-dimensional and have the SRID of g.
+func Kinks(g T) *MultiPoint {
+	parts := lineParts(g)
+	segments := appendSegments(nil, parts, 0)
+	var points intersectionPoints
+	sweepSegments(segments, func(s, t *segment) {
+		x := segmentIntersection(s.a, s.b, t.a, t.b)
+		if len(x) < 2 && adjacentSegments(s, t) {
+			return
+		}
+		points.add(s, x)
+	})
+	return points.multiPoint(g.SRID())
+}
+
+// lineParts returns the lines and rings of g.
+func lineParts(g T) [][]Coord {
+	switch g := g.(type) {
+	case *LineString:
+		return [][]Coord{g.Coords()}
+	case *LinearRing:
+		return [][]Coord{g.Coords()}
+	case *MultiLineString:
+		return g.Coords()
+	case *Polygon:
+		return g.Coords()
+	case *MultiPolygon:
+		var parts [][]Coord
+		for _, polygon := range g.Coords() {
+			parts = append(parts, polygon...)
+		}
+		return parts
+	case *GeometryCollection:
+		var parts [][]Coord
+		for _, g := range g.geoms {
+			parts = append(parts, lineParts(g)...)
+		}
+		return parts
+	default:
+		return nil
+	}
+}
+
+// A segment is a segment of a line or ring, identified by the index of the
+// line or ring and its own index in it.
+type segment struct {
+	a, b        Coord
+	part, index int
+	// last is true for the last segment of a closed line or ring.
+	last bool
+}
+
+// appendSegments appends the segments between the distinct consecutive
+// points of parts to segments, numbering the parts from first.
+func appendSegments(segments []segment, parts [][]Coord, first int) []segment {
+	for i, part := range parts {
+		part = dedupe(part)
+		for j := 1; j < len(part); j++ {
+			segments = append(segments, segment{a: part[j-1], b: part[j], part: first + i, index: j - 1})
+		}
+		if n := len(part); n > 2 && part[0][0] == part[n-1][0] && part[0][1] == part[n-1][1] {
+			segments[len(segments)-1].last = true
+		}
+	}
+	return segments
+}
+
+// adjacentSegments returns true if s and t follow each other in the same
+// line or ring, including the last and first segments of a closed one.
+func adjacentSegments(s, t *segment) bool {
+	if s.part != t.part {
+		return false
+	}
+	if s.index > t.index {
+		s, t = t, s
+	}
+	return t.index == s.index+1 || s.index == 0 && t.last
+}
+
+// sweepSegments calls f with each pair of segments whose bounding boxes,
+// grown by onSegmentTolerance, overlap. The first segment of each pair comes
+// first in segments, and the pairs are ordered by their first and then
+// their second segment.
+//
+// The boxes of the segments are packed into a static R-tree, which each
+// segment queries for the segments after it, so the time taken grows with
+// the number of segments times its logarithm plus the number of pairs.
+func sweepSegments(segments []segment, f func(s, t *segment)) {
+	boxes := make([]box, len(segments))
+	for i, s := range segments {
+		boxes[i] = segmentBox(s.a, s.b)
+	}
+	tree := newBoxTree(boxes)
+	var found []int
+	for i := range segments {
+		found = found[:0]
+		tree.search(boxes[i].grow(onSegmentTolerance), func(j int) {
+			if j > i {
+				found = append(found, j)
+			}
+		})
+		slices.Sort(found)
+		for _, j := range found {
+			f(&segments[i], &segments[j])
+		}
+	}
+}
+
+// intersectionPoints collects distinct intersection points ordered by the
+// segment they were found on and their position along it.
+type intersectionPoints []intersectionPoint
+
+type intersectionPoint struct {
+	part, index int
+	t           float64
+	c           Coord
+}
+
+// add adds the points x on the segment s.
+func (p *intersectionPoints) add(s *segment, x []Coord) {
+	for _, c := range x {
+		*p = append(*p, intersectionPoint{
+			part:  s.part,
+			index: s.index,
+			t:     segmentParameter(s.a, s.b, c),
+			c:     Coord{c[0], c[1]},
+		})
+	}
+}
+
+func (p intersectionPoints) multiPoint(srid int) *MultiPoint {
+	sort.SliceStable(p, func(i, j int) bool {
+		switch {
+		case p[i].part != p[j].part:
+			return p[i].part < p[j].part
+		case p[i].index != p[j].index:
+			return p[i].index < p[j].index
+		default:
+			return p[i].t < p[j].t
+		}
+	})
+	seen := make(map[[2]float64]bool)
+	var coords []Coord
+	for _, x := range p {
+		if key := [2]float64{x.c[0], x.c[1]}; !seen[key] {
+			seen[key] = true
+			coords = append(coords, x.c)
+		}
+	}
+	return NewMultiPoint(XY).MustSetCoords(coords).SetSRID(srid)
+}
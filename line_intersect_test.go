@@ -0,0 +1,162 @@
+package goodgeo
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestLineIntersect(t *testing.T) {
+	square := []Coord{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	for i, tc := range []struct {
+		a, b T
+		want []Coord
+	}{
+		{
+			a: NewLineString(XY).MustSetCoords([]Coord{{0, 0}, {10, 10}}),
+			b: NewLineString(XY).MustSetCoords([]Coord{{20, 0}, {30, 10}}),
+		},
+		{
+			a:    NewLineString(XY).MustSetCoords([]Coord{{0, 0}, {10, 10}}),
+			b:    NewLineString(XY).MustSetCoords([]Coord{{0, 10}, {10, 0}}),
+			want: []Coord{{5, 5}},
+		},
+		{
+			// Points are ordered along a and a vertex on the other line is
+			// found once.
+			a:    NewLineString(XYZ).MustSetCoords([]Coord{{-5, 5, 1}, {15, 5, 2}}),
+			b:    NewPolygon(XY).MustSetCoords([][]Coord{square, {{2, 2}, {2, 5}, {2, 8}, {8, 8}, {8, 2}, {2, 2}}}),
+			want: []Coord{{0, 5}, {2, 5}, {8, 5}, {10, 5}},
+		},
+		{
+			// Both ends of a shared stretch.
+			a:    NewLineString(XY).MustSetCoords([]Coord{{5, -5}, {5, 0}, {15, 0}}),
+			b:    NewPolygon(XY).MustSetCoords([][]Coord{square}),
+			want: []Coord{{5, 0}, {10, 0}},
+		},
+		{
+			a: NewMultiLineString(XY).MustSetCoords([][]Coord{{{0, 1}, {10, 1}}, {{0, 9}, {10, 9}}}),
+			b: NewGeometryCollection().MustPush(
+				NewPoint(XY).MustSetCoords(Coord{5, 1}),
+				NewLineString(XY).MustSetCoords([]Coord{{5, 0}, {5, 10}}),
+			),
+			want: []Coord{{5, 1}, {5, 9}},
+		},
+		{
+			a: NewPoint(XY).MustSetCoords(Coord{5, 5}),
+			b: NewLineString(XY).MustSetCoords([]Coord{{0, 0}, {10, 10}}),
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := LineIntersect(tc.a, tc.b)
+			assert.Equal(t, XY, got.Layout())
+			if tc.want == nil {
+				assert.True(t, got.Empty())
+				return
+			}
+			assert.Equal(t, tc.want, got.Coords())
+		})
+	}
+}
+
+func TestKinks(t *testing.T) {
+	for i, tc := range []struct {
+		g    T
+		want []Coord
+	}{
+		{
+			g: NewLineString(XY).MustSetCoords([]Coord{{0, 0}, {10, 0}, {10, 10}}),
+		},
+		{
+			g:    NewLineString(XY).MustSetCoords([]Coord{{0, 0}, {10, 10}, {10, 0}, {0, 10}}),
+			want: []Coord{{5, 5}},
+		},
+		{
+			// A closed line does not touch itself at its ends, repeated
+			// points are ignored.
+			g: NewLineString(XY).MustSetCoords([]Coord{{0, 0}, {10, 0}, {10, 0}, {10, 10}, {0, 0}}),
+		},
+		{
+			// A line that doubles back on itself.
+			g:    NewLineString(XY).MustSetCoords([]Coord{{0, 0}, {10, 0}, {5, 0}}),
+			want: []Coord{{5, 0}, {10, 0}},
+		},
+		{
+			// A bow-tie.
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{{{0, 0}, {10, 10}, {10, 0}, {0, 10}, {0, 0}}}),
+			want: []Coord{{5, 5}},
+		},
+		{
+			// A ring that touches itself.
+			g:    NewLinearRing(XY).MustSetCoords([]Coord{{0, 0}, {10, 0}, {10, 10}, {5, 0}, {0, 10}, {0, 0}}),
+			want: []Coord{{5, 0}},
+		},
+		{
+			// A hole that crosses the exterior ring.
+			g: NewPolygon(XY).MustSetCoords([][]Coord{
+				{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+				{{5, 5}, {5, 15}, {15, 15}, {15, 5}, {5, 5}},
+			}),
+			want: []Coord{{10, 5}, {5, 10}},
+		},
+		{
+			g:    NewMultiLineString(XY).MustSetCoords([][]Coord{{{0, 0}, {10, 0}}, {{5, -5}, {5, 5}}}),
+			want: []Coord{{5, 0}},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := Kinks(tc.g)
+			if tc.want == nil {
+				assert.True(t, got.Empty())
+				return
+			}
+			assert.Equal(t, tc.want, got.Coords())
+		})
+	}
+}
+
+func TestKinksRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for range 20 {
+		coords := make([]Coord, 2+r.Intn(40))
+		for i := range coords {
+			coords[i] = Coord{float64(r.Intn(20)), float64(r.Intn(20))}
+		}
+		// Compare with checking every pair of segments.
+		segments := appendSegments(nil, [][]Coord{coords}, 0)
+		var points intersectionPoints
+		for i := range segments {
+			for j := i + 1; j < len(segments); j++ {
+				s, t := &segments[i], &segments[j]
+				if x := segmentIntersection(s.a, s.b, t.a, t.b); len(x) == 2 || !adjacentSegments(s, t) {
+					points.add(s, x)
+				}
+			}
+		}
+		assert.Equal(t, points.multiPoint(0).Coords(), Kinks(NewLineString(XY).MustSetCoords(coords)).Coords())
+	}
+}
+
+// loopingTrack returns a track of n vertices that circles a thousand times
+// while drifting outwards, crossing the previous laps where it wobbles.
+func loopingTrack(n int) *LineString {
+	const laps = 1000
+	coords := make([]Coord, n)
+	for i := range coords {
+		theta := 2 * math.Pi * laps * float64(i) / float64(n)
+		r := 1 + 0.01*theta + 0.04*math.Sin(3*theta+float64(i%7))
+		coords[i] = Coord{r * math.Cos(theta), r * math.Sin(theta)}
+	}
+	return NewLineString(XY).MustSetCoords(coords)
+}
+
+func BenchmarkKinksLoopingTrack(b *testing.B) {
+	track := loopingTrack(50000)
+	b.ResetTimer()
+	for range b.N {
+		Kinks(track)
+	}
+}
@@ -0,0 +1,163 @@
+package goodgeo
+
+import "sort"
+
+// LineSplit splits line where it intersects splitter. Lines and rings of
+// splitter split line at every point where they cross or touch it, and at
+// both ends of the stretches they share with it. Points split line where
+// they are on it, otherwise at the nearest point on it as found by
+// [NearestPointOnLine]. Members of geometry collections split line too.
+//
+// The parts have the layout and SRID of line. A line that is not split is
+// returned as the only part.
+func LineSplit(line *LineString, splitter T) *MultiLineString {
+	coords := line.Coords()
+	return NewMultiLineString(line.layout).MustSetCoords(cutLine(coords, lineCuts(line, coords, splitter))).SetSRID(line.srid)
+}
+
+// LineChunk cuts line into parts that are segmentLength long, except for the
+// last one which is what remains. Distances are measured with [Haversine]
+// unless another [DistanceModel] is given. A line that is not longer than
+// segmentLength, or a segmentLength that is not positive, leaves line as the
+// only part.
+//
+// The parts have the layout and SRID of line.
+func LineChunk(line *LineString, segmentLength Meters, model ...DistanceModel) *MultiLineString {
+	m := distanceModel(model)
+	coords := line.Coords()
+	var cuts []lineCut
+	if segmentLength > 0 {
+		// Cuts closer to the end of the line than this would leave parts
+		// that are only rounding errors long.
+		const tolerance = 1e-6
+		total := Length(line, m)
+		next := segmentLength
+		var travelled Meters
+		for i := 1; i < len(coords) && next < total-tolerance; i++ {
+			a, b := coords[i-1], coords[i]
+			d := m.Distance(a, b)
+			for d > 0 && next <= travelled+d && next < total-tolerance {
+				t := float64((next - travelled) / d)
+				cuts = append(cuts, lineCut{index: i - 1, t: t, c: pointAlongSegment(a, b, t, m)})
+				next += segmentLength
+			}
+			travelled += d
+		}
+	}
+	return NewMultiLineString(line.layout).MustSetCoords(cutLine(coords, cuts)).SetSRID(line.srid)
+}
+
+// A lineCut is a point at which a line is cut, at the fraction t of the
+// segment from the vertex index to the next one.
+type lineCut struct {
+	index int
+	t     float64
+	c     Coord
+}
+
+// lineCuts returns the points at which splitter cuts line, whose
+// coordinates are coords.
+func lineCuts(line *LineString, coords []Coord, splitter T) []lineCut {
+	switch splitter := splitter.(type) {
+	case *Point:
+		if splitter.Empty() {
+			return nil
+		}
+		return []lineCut{nearestLineCut(line, coords, splitter.Coords())}
+	case *MultiPoint:
+		var cuts []lineCut
+		for _, c := range splitter.Coords() {
+			if c != nil {
+				cuts = append(cuts, nearestLineCut(line, coords, c))
+			}
+		}
+		return cuts
+	case *GeometryCollection:
+		var cuts []lineCut
+		for _, g := range splitter.geoms {
+			cuts = append(cuts, lineCuts(line, coords, g)...)
+		}
+		return cuts
+	}
+
+	// The segments of line are numbered by their first vertex, which
+	// appendSegments does not do for lines with repeated points.
+	var segments []segment
+	for i := 1; i < len(coords); i++ {
+		if a, b := coords[i-1], coords[i]; a[0] != b[0] || a[1] != b[1] {
+			segments = append(segments, segment{a: a, b: b, index: i - 1})
+		}
+	}
+	segments = appendSegments(segments, lineParts(splitter), 1)
+	var cuts []lineCut
+	sweepSegments(segments, func(s, t *segment) {
+		if (s.part == 0) == (t.part == 0) {
+			return
+		}
+		if s.part != 0 {
+			s, t = t, s
+		}
+		for _, x := range segmentIntersection(s.a, s.b, t.a, t.b) {
+			u := segmentParameter(s.a, s.b, x)
+			c := interpolate(s.a, s.b, u)
+			c[0], c[1] = x[0], x[1]
+			cuts = append(cuts, lineCut{index: s.index, t: u, c: c})
+		}
+	})
+	return cuts
+}
+
+// nearestLineCut returns the cut of line at c if c is on it, otherwise at
+// the point on it nearest to c.
+func nearestLineCut(line *LineString, coords []Coord, c Coord) lineCut {
+	for i := 1; i < len(coords); i++ {
+		if a, b := coords[i-1], coords[i]; onSegment(c[0], c[1], a[0], a[1], b[0], b[1]) {
+			t := segmentParameter(a, b, c)
+			x := interpolate(a, b, t)
+			x[0], x[1] = c[0], c[1]
+			return lineCut{index: i - 1, t: t, c: x}
+		}
+	}
+	nearest := NearestPointOnLine(line, c)
+	// The index of the nearest point is that of the vertex after it.
+	i := min(max(nearest.Index-1, 0), len(coords)-2)
+	a, b := coords[i], coords[i+1]
+	t := segmentParameter(a, b, nearest.Point)
+	x := interpolate(a, b, t)
+	x[0], x[1] = nearest.Point[0], nearest.Point[1]
+	return lineCut{index: i, t: t, c: x}
+}
+
+// cutLine returns the parts of the line with coordinates coords between
+// cuts. Parts without length are left out, as are repeated points.
+func cutLine(coords []Coord, cuts []lineCut) [][]Coord {
+	if len(coords) == 0 {
+		return nil
+	}
+	if len(coords) == 1 {
+		return [][]Coord{coords}
+	}
+	sort.SliceStable(cuts, func(i, j int) bool {
+		if cuts[i].index != cuts[j].index {
+			return cuts[i].index < cuts[j].index
+		}
+		return cuts[i].t < cuts[j].t
+	})
+	var lines [][]Coord
+	part := []Coord{coords[0]}
+	next := 0
+	for i := 1; i < len(coords); i++ {
+		for ; next < len(cuts) && cuts[next].index == i-1; next++ {
+			part = appendDistinct(part, cuts[next].c)
+			if len(part) > 1 {
+				lines = append(lines, part)
+			}
+			part = []Coord{cuts[next].c}
+		}
+		part = appendDistinct(part, coords[i])
+	}
+	if len(part) > 1 || len(lines) == 0 {
+		lines = append(lines, part)
+	}
+	return lines
+}
@@ -0,0 +1,104 @@
+package goodgeo
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestLineSplit(t *testing.T) {
+	line := NewLineString(XY).MustSetCoords([]Coord{{0, 0}, {10, 0}, {10, 10}}).SetSRID(4326)
+	for i, tc := range []struct {
+		line     *LineString
+		splitter T
+		want     [][]Coord
+	}{
+		{
+			line:     line,
+			splitter: NewLineString(XY).MustSetCoords([]Coord{{20, 0}, {20, 10}}),
+			want:     [][]Coord{{{0, 0}, {10, 0}, {10, 10}}},
+		},
+		{
+			line:     line,
+			splitter: NewLineString(XY).MustSetCoords([]Coord{{5, -5}, {5, 5}, {15, 5}}),
+			want:     [][]Coord{{{0, 0}, {5, 0}}, {{5, 0}, {10, 0}, {10, 5}}, {{10, 5}, {10, 10}}},
+		},
+		{
+			// Touching at a vertex and at the end of the line.
+			line:     line,
+			splitter: NewMultiLineString(XY).MustSetCoords([][]Coord{{{10, 0}, {15, -5}}, {{5, 10}, {15, 10}}}),
+			want:     [][]Coord{{{0, 0}, {10, 0}}, {{10, 0}, {10, 10}}},
+		},
+		{
+			// A shared stretch is a part of its own.
+			line:     line,
+			splitter: NewPolygon(XY).MustSetCoords([][]Coord{{{2, 0}, {4, 0}, {4, -2}, {2, 0}}}),
+			want:     [][]Coord{{{0, 0}, {2, 0}}, {{2, 0}, {4, 0}}, {{4, 0}, {10, 0}, {10, 10}}},
+		},
+		{
+			line:     NewLineString(XYZ).MustSetCoords([]Coord{{0, 0, 0}, {10, 0, 10}}),
+			splitter: NewMultiPoint(XY).MustSetCoords([]Coord{{7, 0}, {2, 0}, {0, 0}}),
+			want:     [][]Coord{{{0, 0, 0}, {2, 0, 2}}, {{2, 0, 2}, {7, 0, 7}}, {{7, 0, 7}, {10, 0, 10}}},
+		},
+		{
+			line: line,
+			splitter: NewGeometryCollection().MustPush(
+				NewPoint(XY).MustSetCoords(Coord{10, 0}),
+				NewLineString(XY).MustSetCoords([]Coord{{9, 5}, {11, 5}}),
+			),
+			want: [][]Coord{{{0, 0}, {10, 0}}, {{10, 0}, {10, 5}}, {{10, 5}, {10, 10}}},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := LineSplit(tc.line, tc.splitter)
+			assert.Equal(t, tc.line.Layout(), got.Layout())
+			assert.Equal(t, tc.line.SRID(), got.SRID())
+			assert.Equal(t, tc.want, got.Coords())
+		})
+	}
+
+	// A point off the line splits it at the nearest point on it.
+	got := LineSplit(line, NewPoint(XY).MustSetCoords(Coord{5, 1})).Coords()
+	assert.Equal(t, 2, len(got))
+	cut := got[0][1]
+	assert.True(t, math.Abs(cut[0]-5) < 1e-9 && math.Abs(cut[1]) < 1e-9, "cut at %v", cut)
+	assert.Equal(t, cut, got[1][0])
+}
+
+func TestLineChunk(t *testing.T) {
+	// About 1112 m per 0.01° along the equator.
+	line := NewLineString(XYZ).MustSetCoords([]Coord{{0, 0, 0}, {0.01, 0, 100}, {0.01, 0, 100}, {0.025, 0, 250}}).SetSRID(4326)
+	total := Length(line)
+	for i, tc := range []struct {
+		segmentLength Meters
+		wantParts     int
+	}{
+		{segmentLength: 0, wantParts: 1},
+		{segmentLength: total, wantParts: 1},
+		{segmentLength: total / 2, wantParts: 2},
+		{segmentLength: 1000, wantParts: 3},
+		{segmentLength: 100, wantParts: 28},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := LineChunk(line, tc.segmentLength)
+			assert.Equal(t, 4326, got.SRID())
+			assert.Equal(t, tc.wantParts, got.NumLineStrings())
+			var sum Meters
+			for j := range got.NumLineStrings() {
+				part := got.LineString(j)
+				coords := part.Coords()
+				length := Length(part)
+				if j < got.NumLineStrings()-1 {
+					assert.True(t, math.Abs(float64(length-tc.segmentLength)) < 1e-6, "part %d is %f m", j, length)
+					// The other ordinates are interpolated.
+					end := coords[len(coords)-1]
+					assert.True(t, math.Abs(end[2]-end[0]*1e4) < 1e-6)
+				}
+				sum += length
+			}
+			assert.True(t, math.Abs(float64(sum-total)) < 1e-6)
+		})
+	}
+}
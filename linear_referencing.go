@@ -0,0 +1,148 @@
+package goodgeo
+
+import "math"
+
+// LineSliceAlong returns the part of line between the distances start and
+// stop along it. The distances are clamped to the length of line and
+// swapped if start is greater than stop. Distances are measured with
+// [Haversine] unless another [DistanceModel] is given.
+func LineSliceAlong(line *LineString, start, stop Meters, model ...DistanceModel) *LineString {
+	m := distanceModel(model)
+	if start > stop {
+		start, stop = stop, start
+	}
+	coords := line.Coords()
+	slice := NewLineString(line.layout).SetSRID(line.srid)
+	if len(coords) == 0 {
+		return slice
+	}
+	distances := lineDistances(coords, m)
+	total := distances[len(distances)-1]
+	start, stop = min(max(start, 0), total), min(max(stop, 0), total)
+	var sliced []Coord
+	sliced = append(sliced, pointAtMeasure(coords, distances, start, m))
+	for i, d := range distances {
+		if start < d && d < stop {
+			sliced = append(sliced, coords[i])
+		}
+	}
+	sliced = append(sliced, pointAtMeasure(coords, distances, stop, m))
+	return slice.MustSetCoords(sliced)
+}
+
+// AddMeasure returns a copy of line with the distance along it from its
+// first point in the M ordinate of each point, replacing any M ordinate it
+// already has. Distances are measured with [Haversine] unless another
+// [DistanceModel] is given.
+func AddMeasure(line *LineString, model ...DistanceModel) *LineString {
+	layout := line.layout
+	switch layout {
+	case XY:
+		layout = XYM
+	case XYZ:
+		layout = XYZM
+	}
+	coords := line.Coords()
+	distances := lineDistances(coords, distanceModel(model))
+	measured := make([]Coord, len(coords))
+	for i, c := range coords {
+		measured[i] = make(Coord, layout.Stride())
+		copy(measured[i], c[:min(len(c), 3)])
+		measured[i][layout.MIndex()] = float64(distances[i])
+	}
+	return NewLineString(layout).MustSetCoords(measured).SetSRID(line.srid)
+}
+
+// LineLocatePoint returns the measure of the point on line nearest to pt,
+// found as by [NearestPointOnLine]. If line has an M ordinate, for example
+// from [AddMeasure], the measure is interpolated from it, otherwise it is
+// the distance along line. Distances are measured with [Haversine] unless
+// another [DistanceModel] is given.
+func LineLocatePoint(line *LineString, pt Coord, model ...DistanceModel) Meters {
+	m := distanceModel(model)
+	coords := line.Coords()
+	if len(coords) == 0 {
+		return 0
+	}
+	nearest := NearestPointOnLine(line, pt, m)
+	if line.layout.MIndex() == -1 || len(coords) == 1 {
+		return max(nearest.Location, 0)
+	}
+	// The index of the nearest point is that of the vertex after it.
+	i := min(max(nearest.Index-1, 0), len(coords)-2)
+	a, b := coords[i], coords[i+1]
+	var t float64
+	if d := m.Distance(a, b); d > 0 {
+		t = float64(m.Distance(a, nearest.Point) / d)
+	}
+	mIndex := line.layout.MIndex()
+	return Meters(a[mIndex] + min(t, 1)*(b[mIndex]-a[mIndex]))
+}
+
+// LineInterpolatePoint returns the point on line at measure. If line has an
+// M ordinate, for example from [AddMeasure], the point is found on the first
+// segment whose measures enclose measure, otherwise measure is the distance
+// along line. Measures before the start or after the end of line give its
+// first or last point. Other ordinates are interpolated linearly. Distances
+// are measured with [Haversine] unless another [DistanceModel] is given.
+func LineInterpolatePoint(line *LineString, measure Meters, model ...DistanceModel) *Point {
+	m := distanceModel(model)
+	coords := line.Coords()
+	if len(coords) == 0 {
+		return NewPointEmpty(line.layout).SetSRID(line.srid)
+	}
+	var measures []Meters
+	if mIndex := line.layout.MIndex(); mIndex != -1 {
+		measures = make([]Meters, len(coords))
+		for i, c := range coords {
+			measures[i] = Meters(c[mIndex])
+		}
+	} else {
+		measures = lineDistances(coords, m)
+	}
+	return NewPoint(line.layout).MustSetCoords(pointAtMeasure(coords, measures, measure, m)).SetSRID(line.srid)
+}
+
+// lineDistances returns the distance along the line of each of coords.
+func lineDistances(coords []Coord, m DistanceModel) []Meters {
+	distances := make([]Meters, len(coords))
+	for i := 1; i < len(coords); i++ {
+		distances[i] = distances[i-1] + m.Distance(coords[i-1], coords[i])
+	}
+	return distances
+}
+
+// pointAtMeasure returns the point at measure on the line through coords,
+// whose points have measures. For measures that no segment encloses, it is
+// the first or last point, whichever has the closer measure.
+func pointAtMeasure(coords []Coord, measures []Meters, measure Meters, m DistanceModel) Coord {
+	for i := 1; i < len(coords); i++ {
+		lo, hi := measures[i-1], measures[i]
+		if min(lo, hi) <= measure && measure <= max(lo, hi) {
+			if lo == hi {
+				return coords[i-1].Clone()
+			}
+			return pointAlongSegment(coords[i-1], coords[i], float64((measure-lo)/(hi-lo)), m)
+		}
+	}
+	if len(coords) == 1 || math.Abs(float64(measure-measures[0])) <= math.Abs(float64(measure-measures[len(measures)-1])) {
+		return coords[0].Clone()
+	}
+	return coords[len(coords)-1].Clone()
+}
+
+// pointAlongSegment returns the point at the fraction t of the length of the
+// segment from a to b. X and Y follow the path of m, other ordinates are
+// interpolated linearly.
+func pointAlongSegment(a, b Coord, t float64, m DistanceModel) Coord {
+	switch t {
+	case 0:
+		return a.Clone()
+	case 1:
+		return b.Clone()
+	}
+	c := interpolate(a, b, t)
+	dest := m.Destination(a, Meters(t)*m.Distance(a, b), m.Bearing(a, b))
+	c[0], c[1] = dest[0], dest[1]
+	return c
+}
@@ -0,0 +1,148 @@
+package goodgeo
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+// assertCoordsNear asserts that the coordinates are within 1e-9 of each
+// other.
+func assertCoordsNear(t *testing.T, want, got []Coord) {
+	t.Helper()
+	assert.Equal(t, len(want), len(got), "%v", got)
+	for i := range want {
+		assert.Equal(t, len(want[i]), len(got[i]), "%v", got)
+		for j := range want[i] {
+			assert.True(t, math.Abs(want[i][j]-got[i][j]) < 1e-9, "%v", got)
+		}
+	}
+}
+
+func TestLineSliceAlong(t *testing.T) {
+	line := NewLineString(XYZ).MustSetCoords([]Coord{{0, 0, 0}, {0.01, 0, 10}, {0.02, 0, 20}}).SetSRID(4326)
+	d := Distance(Coord{0, 0}, Coord{0.01, 0})
+	for i, tc := range []struct {
+		start, stop Meters
+		want        []Coord
+	}{
+		{
+			start: d / 2,
+			stop:  3 * d / 2,
+			want:  []Coord{{0.005, 0, 5}, {0.01, 0, 10}, {0.015, 0, 15}},
+		},
+		{
+			start: 3 * d / 2,
+			stop:  d / 2,
+			want:  []Coord{{0.005, 0, 5}, {0.01, 0, 10}, {0.015, 0, 15}},
+		},
+		{
+			start: d / 4,
+			stop:  d / 2,
+			want:  []Coord{{0.0025, 0, 2.5}, {0.005, 0, 5}},
+		},
+		{
+			start: d,
+			stop:  2 * d,
+			want:  []Coord{{0.01, 0, 10}, {0.02, 0, 20}},
+		},
+		{
+			start: -d,
+			stop:  10 * d,
+			want:  []Coord{{0, 0, 0}, {0.01, 0, 10}, {0.02, 0, 20}},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := LineSliceAlong(line, tc.start, tc.stop)
+			assert.Equal(t, XYZ, got.Layout())
+			assert.Equal(t, 4326, got.SRID())
+			assertCoordsNear(t, tc.want, got.Coords())
+		})
+	}
+}
+
+func TestAddMeasure(t *testing.T) {
+	d := float64(Distance(Coord{0, 0}, Coord{0.01, 0}))
+	for i, tc := range []struct {
+		line *LineString
+		want *LineString
+	}{
+		{
+			line: NewLineString(XY).MustSetCoords([]Coord{{0, 0}, {0.01, 0}, {0.02, 0}}).SetSRID(4326),
+			want: NewLineString(XYM).MustSetCoords([]Coord{{0, 0, 0}, {0.01, 0, d}, {0.02, 0, 2 * d}}).SetSRID(4326),
+		},
+		{
+			line: NewLineString(XYZ).MustSetCoords([]Coord{{0, 0, 5}, {0.01, 0, 6}}),
+			want: NewLineString(XYZM).MustSetCoords([]Coord{{0, 0, 5, 0}, {0.01, 0, 6, d}}),
+		},
+		{
+			line: NewLineString(XYM).MustSetCoords([]Coord{{0, 0, 100}, {0.01, 0, 200}}),
+			want: NewLineString(XYM).MustSetCoords([]Coord{{0, 0, 0}, {0.01, 0, d}}),
+		},
+		{
+			line: NewLineString(XY),
+			want: NewLineString(XYM),
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := AddMeasure(tc.line)
+			assert.Equal(t, tc.want.Layout(), got.Layout())
+			assert.Equal(t, tc.want.SRID(), got.SRID())
+			assertCoordsNear(t, tc.want.Coords(), got.Coords())
+		})
+	}
+}
+
+func TestLineLocatePoint(t *testing.T) {
+	d := Distance(Coord{0, 0}, Coord{0.01, 0})
+	line := NewLineString(XY).MustSetCoords([]Coord{{0, 0}, {0.01, 0}, {0.02, 0}})
+	// Kilometer markers that do not match the length of the line.
+	measured := NewLineString(XYM).MustSetCoords([]Coord{{0, 0, 100}, {0.01, 0, 200}, {0.02, 0, 300}})
+	for i, tc := range []struct {
+		line *LineString
+		pt   Coord
+		want Meters
+	}{
+		{line: line, pt: Coord{0.015, 0.001}, want: 3 * d / 2},
+		{line: line, pt: Coord{-1, 0}, want: 0},
+		{line: line, pt: Coord{1, 0}, want: 2 * d},
+		{line: measured, pt: Coord{0.015, 0}, want: 250},
+		{line: measured, pt: Coord{0.005, -0.001}, want: 150},
+		{line: measured, pt: Coord{1, 0}, want: 300},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := LineLocatePoint(tc.line, tc.pt)
+			assert.True(t, math.Abs(float64(got-tc.want)) < 1e-6, "got %v", got)
+		})
+	}
+}
+
+func TestLineInterpolatePoint(t *testing.T) {
+	d := Distance(Coord{0, 0}, Coord{0.01, 0})
+	line := NewLineString(XYZ).MustSetCoords([]Coord{{0, 0, 0}, {0.01, 0, 10}, {0.02, 0, 20}}).SetSRID(4326)
+	measured := NewLineString(XYM).MustSetCoords([]Coord{{0, 0, 100}, {0.01, 0, 200}, {0.02, 0, 300}})
+	for i, tc := range []struct {
+		line    *LineString
+		measure Meters
+		want    Coord
+	}{
+		{line: line, measure: 3 * d / 2, want: Coord{0.015, 0, 15}},
+		{line: line, measure: d, want: Coord{0.01, 0, 10}},
+		{line: line, measure: -d, want: Coord{0, 0, 0}},
+		{line: line, measure: 3 * d, want: Coord{0.02, 0, 20}},
+		{line: measured, measure: 250, want: Coord{0.015, 0, 250}},
+		{line: measured, measure: 50, want: Coord{0, 0, 100}},
+		{line: measured, measure: 1000, want: Coord{0.02, 0, 300}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := LineInterpolatePoint(tc.line, tc.measure)
+			assert.Equal(t, tc.line.Layout(), got.Layout())
+			assert.Equal(t, tc.line.SRID(), got.SRID())
+			assertCoordsNear(t, []Coord{tc.want}, []Coord{got.Coords()})
+		})
+	}
+
+	assert.True(t, LineInterpolatePoint(NewLineString(XY), 0).Empty())
+}
@@ -0,0 +1,515 @@
+package goodgeo
+
+import (
+	"math"
+	"slices"
+	"sort"
+)
+
+// MakeValid returns a valid version of g, which is returned as it is if it
+// is already valid. Coordinates that are not finite and repeated points are
+// removed, rings are closed, rings with fewer than three distinct points or
+// without area are dropped and lines with a single distinct point become
+// points.
+//
+// Polygons are rebuilt from the linework of their rings: a point is inside if
+// it is enclosed by an odd number of rings, so bow-ties are split into two
+// polygons and holes outside their shell become polygons of their own. The
+// polygons of a multi-polygon are then merged where they overlap. Exterior
+// rings of the result are counter-clockwise and holes are clockwise. A
+// polygon becomes a multi-polygon if it falls apart into several polygons.
+func MakeValid(g T) T {
+	if Validate(g) == nil {
+		return g
+	}
+	switch g := g.(type) {
+	case *Point:
+		if g.Empty() || finite(g.Coords()) {
+			return g
+		}
+		return NewPointEmpty(g.layout).SetSRID(g.srid)
+	case *MultiPoint:
+		coords := slices.DeleteFunc(g.Coords(), func(c Coord) bool { return c != nil && !finite(c) })
+		return NewMultiPoint(g.layout).MustSetCoords(coords).SetSRID(g.srid)
+	case *LineString:
+		line := cleanCoords(g.Coords())
+		if len(line) == 1 {
+			return NewPoint(g.layout).MustSetCoords(line[0]).SetSRID(g.srid)
+		}
+		return NewLineString(g.layout).MustSetCoords(line).SetSRID(g.srid)
+	case *MultiLineString:
+		var lines [][]Coord
+		for _, line := range g.Coords() {
+			if line := cleanCoords(line); len(line) > 1 {
+				lines = append(lines, line)
+			}
+		}
+		return NewMultiLineString(g.layout).MustSetCoords(lines).SetSRID(g.srid)
+	case *LinearRing:
+		ring := cleanRing(g.Coords())
+		if ring != nil && ringDoubleArea(ring) < 0 {
+			slices.Reverse(ring)
+		}
+		return NewLinearRing(g.layout).MustSetCoords(ring).SetSRID(g.srid)
+	case *Polygon:
+		polygons := polygonize(cleanRings(g.Coords()), true)
+		if len(polygons) > 1 {
+			return NewMultiPolygon(g.layout).MustSetCoords(polygons).SetSRID(g.srid)
+		}
+		p := NewPolygon(g.layout).SetSRID(g.srid)
+		if len(polygons) == 1 {
+			p.MustSetCoords(polygons[0])
+		}
+		return p
+	case *MultiPolygon:
+		var rings [][]Coord
+		for _, polygon := range g.Coords() {
+			for _, polygon := range polygonize(cleanRings(polygon), true) {
+				rings = append(rings, polygon...)
+			}
+		}
+		return NewMultiPolygon(g.layout).MustSetCoords(polygonize(rings, false)).SetSRID(g.srid)
+	case *GeometryCollection:
+		gc := NewGeometryCollection().SetSRID(g.srid)
+		for _, g := range g.geoms {
+			gc.MustPush(MakeValid(g))
+		}
+		return gc
+	default:
+		return g
+	}
+}
+
+func finite(c Coord) bool {
+	for _, ordinate := range c {
+		if math.IsNaN(ordinate) || math.IsInf(ordinate, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// cleanCoords returns the finite coordinates of coords without repeated
+// points.
+func cleanCoords(coords []Coord) []Coord {
+	var cleaned []Coord
+	for _, c := range coords {
+		if !finite(c) {
+			continue
+		}
+		if n := len(cleaned); n > 0 && c[0] == cleaned[n-1][0] && c[1] == cleaned[n-1][1] {
+			continue
+		}
+		cleaned = append(cleaned, c)
+	}
+	return cleaned
+}
+
+// cleanRing returns ring cleaned and closed, or nil if it has fewer than
+// three distinct points.
+func cleanRing(ring []Coord) []Coord {
+	ring = cleanCoords(ring)
+	if n := len(ring); n > 1 && ring[0][0] == ring[n-1][0] && ring[0][1] == ring[n-1][1] {
+		ring = ring[:n-1]
+	}
+	if len(ring) < 3 {
+		return nil
+	}
+	return append(ring, ring[0])
+}
+
+// cleanRings returns the rings that are left after cleaning.
+func cleanRings(rings [][]Coord) [][]Coord {
+	var cleaned [][]Coord
+	for _, ring := range rings {
+		if ring := cleanRing(ring); ring != nil {
+			cleaned = append(cleaned, ring)
+		}
+	}
+	return cleaned
+}
+
+// polygonize returns the polygons bounded by rings, which may cross
+// themselves and each other. With evenOdd a point is inside if it is
+// enclosed by an odd number of rings, otherwise if the rings wind around it
+// counter-clockwise more often than clockwise. Exterior rings are
+// counter-clockwise and holes are clockwise.
+//
+// The rings are split where they intersect into the edges of a planar
+// graph. The winding number of each face of the graph follows from the
+// number of times the edges between faces are traversed by the rings, and
+// the edges between faces inside and outside are linked into the rings of
+// the result.
+func polygonize(rings [][]Coord, evenOdd bool) [][][]Coord {
+	g := newRingGraph(rings)
+	if len(g.edges) == 0 {
+		return nil
+	}
+	inside := g.windingNumbers()
+	for h, w := range inside {
+		if evenOdd {
+			inside[h] = w & 1
+		} else if w > 0 {
+			inside[h] = 1
+		} else {
+			inside[h] = 0
+		}
+	}
+	isBoundary := func(h int) bool {
+		return inside[h] == 1 && inside[h^1] == 0
+	}
+
+	// Link the boundary half-edges into loops, turning as far left as
+	// possible where several meet so that the loops do not cross, and split
+	// the loops where they touch themselves.
+	var loops [][]Coord
+	used := make([]bool, 2*len(g.edges))
+	for start := range used {
+		if used[start] || !isBoundary(start) {
+			continue
+		}
+		var nodes []int
+		positions := make(map[int]int)
+		for h := start; !used[h]; {
+			used[h] = true
+			n := g.origin(h)
+			if i, ok := positions[n]; ok {
+				loops = append(loops, g.loop(nodes[i:]))
+				for _, m := range nodes[i:] {
+					delete(positions, m)
+				}
+				nodes = nodes[:i]
+			}
+			positions[n] = len(nodes)
+			nodes = append(nodes, n)
+			for h = g.turn(h); !isBoundary(h); {
+				h = g.turn(h ^ 1)
+			}
+		}
+		loops = append(loops, g.loop(nodes))
+	}
+
+	// Loops at an even depth are exterior rings and the others holes of the
+	// smallest loop around them.
+	areas := make([]float64, len(loops))
+	bounds := make([]*Bounds, len(loops))
+	for i, loop := range loops {
+		areas[i] = math.Abs(ringDoubleArea(loop))
+		bounds[i] = NewBounds(XY)
+		for _, c := range loop {
+			bounds[i].extendFlatCoords(c, 0, 2, 2)
+		}
+	}
+	depths := make([]int, len(loops))
+	parents := make([]int, len(loops))
+	for i, loop := range loops {
+		parents[i] = -1
+		for j, other := range loops {
+			if i != j && areas[j] > areas[i] && bounds[j].OverlapsPoint(XY, loop[0]) && ringInside(loop, other) {
+				depths[i]++
+				if parents[i] == -1 || areas[j] < areas[parents[i]] {
+					parents[i] = j
+				}
+			}
+		}
+	}
+	var polygons [][][]Coord
+	polygonIndex := make(map[int]int)
+	for i, loop := range loops {
+		if depths[i]%2 == 0 {
+			if ringDoubleArea(loop) < 0 {
+				slices.Reverse(loop)
+			}
+			polygonIndex[i] = len(polygons)
+			polygons = append(polygons, [][]Coord{loop})
+		}
+	}
+	for i, loop := range loops {
+		if depths[i]%2 == 1 {
+			if ringDoubleArea(loop) > 0 {
+				slices.Reverse(loop)
+			}
+			p := polygonIndex[parents[i]]
+			polygons[p] = append(polygons[p], loop)
+		}
+	}
+	return polygons
+}
+
+// A ringGraph is the planar graph formed by a set of rings. Each edge e is
+// made of the half-edges 2e, from the edge's first to its second node, and
+// 2e+1 in the opposite direction.
+type ringGraph struct {
+	nodes []Coord
+	// edges holds the nodes of each edge and count the number of times the
+	// rings traverse it from the first to the second node minus the number
+	// of times in the opposite direction.
+	edges []ringGraphEdge
+	// outgoing holds the half-edges that leave each node sorted
+	// counter-clockwise, and position the index of each half-edge in it.
+	outgoing [][]int
+	position []int
+}
+
+type ringGraphEdge struct {
+	from, to, count int
+}
+
+func newRingGraph(rings [][]Coord) *ringGraph {
+	g := &ringGraph{}
+	// Points closer than onSegmentTolerance are the same node, found in a
+	// grid of cells of that size.
+	cells := make(map[[2]int64][]int)
+	node := func(c Coord) int {
+		x, y := int64(math.Floor(c[0]/onSegmentTolerance)), int64(math.Floor(c[1]/onSegmentTolerance))
+		for dx := int64(-1); dx <= 1; dx++ {
+			for dy := int64(-1); dy <= 1; dy++ {
+				for _, i := range cells[[2]int64{x + dx, y + dy}] {
+					if math.Hypot(g.nodes[i][0]-c[0], g.nodes[i][1]-c[1]) <= onSegmentTolerance {
+						return i
+					}
+				}
+			}
+		}
+		cells[[2]int64{x, y}] = append(cells[[2]int64{x, y}], len(g.nodes))
+		g.nodes = append(g.nodes, c)
+		return len(g.nodes) - 1
+	}
+
+	// Split the segments at the points where they intersect.
+	type split struct {
+		t    float64
+		node int
+	}
+	type ringSegment struct {
+		a, b   Coord
+		splits []split
+	}
+	var segments []*ringSegment
+	for _, ring := range rings {
+		for i := 1; i < len(ring); i++ {
+			segments = append(segments, &ringSegment{a: ring[i-1], b: ring[i], splits: []split{{0, node(ring[i-1])}, {1, node(ring[i])}}})
+		}
+	}
+	sweep := make([]segment, len(segments))
+	for i, s := range segments {
+		sweep[i] = segment{a: s.a, b: s.b, index: i}
+	}
+	sweepSegments(sweep, func(a, b *segment) {
+		s, other := segments[a.index], segments[b.index]
+		for _, x := range segmentIntersection(s.a, s.b, other.a, other.b) {
+			var n int
+			if i := slices.IndexFunc([]Coord{s.a, s.b, other.a, other.b}, func(c Coord) bool { return equals(c, x) }); i != -1 {
+				n = node([]Coord{s.a, s.b, other.a, other.b}[i])
+			} else {
+				// Interpolate the other ordinates along the first segment.
+				c := interpolate(s.a, s.b, segmentParameter(s.a, s.b, x))
+				c[0], c[1] = x[0], x[1]
+				n = node(c)
+			}
+			s.splits = append(s.splits, split{segmentParameter(s.a, s.b, x), n})
+			other.splits = append(other.splits, split{segmentParameter(other.a, other.b, x), n})
+		}
+	})
+
+	edges := make(map[[2]int]int)
+	for _, s := range segments {
+		sort.Slice(s.splits, func(i, j int) bool { return s.splits[i].t < s.splits[j].t })
+		for i := 1; i < len(s.splits); i++ {
+			from, to := s.splits[i-1].node, s.splits[i].node
+			if from == to {
+				continue
+			}
+			key, sign := [2]int{from, to}, 1
+			if from > to {
+				key, sign = [2]int{to, from}, -1
+			}
+			e, ok := edges[key]
+			if !ok {
+				e = len(g.edges)
+				edges[key] = e
+				g.edges = append(g.edges, ringGraphEdge{from: key[0], to: key[1]})
+			}
+			g.edges[e].count += sign
+		}
+	}
+	g.edges = slices.DeleteFunc(g.edges, func(e ringGraphEdge) bool { return e.count == 0 })
+
+	g.outgoing = make([][]int, len(g.nodes))
+	for e, edge := range g.edges {
+		g.outgoing[edge.from] = append(g.outgoing[edge.from], 2*e)
+		g.outgoing[edge.to] = append(g.outgoing[edge.to], 2*e+1)
+	}
+	g.position = make([]int, 2*len(g.edges))
+	for n, outgoing := range g.outgoing {
+		angles := make(map[int]float64, len(outgoing))
+		for _, h := range outgoing {
+			to := g.nodes[g.origin(h^1)]
+			angles[h] = math.Atan2(to[1]-g.nodes[n][1], to[0]-g.nodes[n][0])
+		}
+		sort.Slice(outgoing, func(i, j int) bool { return angles[outgoing[i]] < angles[outgoing[j]] })
+		for i, h := range outgoing {
+			g.position[h] = i
+		}
+	}
+	return g
+}
+
+// interpolate returns the point at the fraction t of the segment from a to
+// b, with all ordinates interpolated.
+func interpolate(a, b Coord, t float64) Coord {
+	c := make(Coord, len(a))
+	for i := range c {
+		c[i] = a[i] + t*(b[i]-a[i])
+	}
+	return c
+}
+
+// origin returns the node that the half-edge h leaves.
+func (g *ringGraph) origin(h int) int {
+	if h&1 == 0 {
+		return g.edges[h/2].from
+	}
+	return g.edges[h/2].to
+}
+
+// turn returns the half-edge that follows h around the face on its left:
+// the half-edge leaving the end of h that is next clockwise from the
+// reverse of h.
+func (g *ringGraph) turn(h int) int {
+	outgoing := g.outgoing[g.origin(h^1)]
+	return outgoing[(g.position[h^1]+len(outgoing)-1)%len(outgoing)]
+}
+
+// loop returns the closed ring through nodes, starting at the lowest of
+// the leftmost nodes.
+func (g *ringGraph) loop(nodes []int) []Coord {
+	start := 0
+	for i, n := range nodes {
+		if c, s := g.nodes[n], g.nodes[nodes[start]]; c[0] < s[0] || c[0] == s[0] && c[1] < s[1] {
+			start = i
+		}
+	}
+	ring := make([]Coord, 0, len(nodes)+1)
+	for i := range nodes {
+		ring = append(ring, g.nodes[nodes[(start+i)%len(nodes)]])
+	}
+	return append(ring, ring[0])
+}
+
+// windingNumbers returns the winding number of the face on the left of each
+// half-edge.
+func (g *ringGraph) windingNumbers() []int {
+	// Find the faces, their half-edges and their areas.
+	faces := make([]int, 2*len(g.edges))
+	for h := range faces {
+		faces[h] = -1
+	}
+	var faceEdges [][]int
+	var faceAreas []float64
+	for start := range faces {
+		if faces[start] != -1 {
+			continue
+		}
+		var edges []int
+		var ring []Coord
+		for h := start; faces[h] == -1; h = g.turn(h) {
+			faces[h] = len(faceEdges)
+			edges = append(edges, h)
+			ring = append(ring, g.nodes[g.origin(h)])
+		}
+		faceEdges = append(faceEdges, edges)
+		faceAreas = append(faceAreas, ringDoubleArea(append(ring, ring[0])))
+	}
+
+	// Find the connected components of the graph and the outer face of
+	// each, which is the only face with a negative area.
+	components := make([]int, len(g.nodes))
+	for n := range components {
+		components[n] = -1
+	}
+	var roots, outer []int
+	for n := range g.nodes {
+		if components[n] != -1 || len(g.outgoing[n]) == 0 {
+			continue
+		}
+		c := len(roots)
+		roots = append(roots, n)
+		outer = append(outer, -1)
+		components[n] = c
+		for stack := []int{n}; len(stack) > 0; {
+			m := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, h := range g.outgoing[m] {
+				if f := faces[h]; outer[c] == -1 || faceAreas[f] < faceAreas[outer[c]] {
+					outer[c] = f
+				}
+				if to := g.origin(h ^ 1); components[to] == -1 {
+					components[to] = c
+					stack = append(stack, to)
+				}
+			}
+		}
+	}
+
+	// The outer face of each component has the winding number of the other
+	// components around it. From there, crossing a half-edge from its right
+	// to its left adds its count.
+	windings := make([]int, len(faceEdges))
+	known := make([]bool, len(faceEdges))
+	for c, root := range roots {
+		windings[outer[c]] = g.windingNumber(g.nodes[root], func(e int) bool { return components[g.edges[e].from] != c })
+		known[outer[c]] = true
+		for stack := []int{outer[c]}; len(stack) > 0; {
+			f := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, h := range faceEdges[f] {
+				if other := faces[h^1]; !known[other] {
+					windings[other] = windings[f] - g.count(h)
+					known[other] = true
+					stack = append(stack, other)
+				}
+			}
+		}
+	}
+
+	numbers := make([]int, len(faces))
+	for h, f := range faces {
+		numbers[h] = windings[f]
+	}
+	return numbers
+}
+
+// count returns the number of times the rings traverse the half-edge h,
+// minus the number of times they traverse its reverse.
+func (g *ringGraph) count(h int) int {
+	if h&1 == 0 {
+		return g.edges[h/2].count
+	}
+	return -g.edges[h/2].count
+}
+
+// windingNumber returns the number of times the edges for which include is
+// true wind around c counter-clockwise.
+func (g *ringGraph) windingNumber(c Coord, include func(e int) bool) int {
+	var w int
+	for e, edge := range g.edges {
+		if !include(e) {
+			continue
+		}
+		a, b := g.nodes[edge.from], g.nodes[edge.to]
+		switch {
+		case a[1] <= c[1] && b[1] > c[1] && orientation(a, b, c) > 0:
+			w += edge.count
+		case a[1] > c[1] && b[1] <= c[1] && orientation(a, b, c) < 0:
+			w -= edge.count
+		}
+	}
+	return w
+}
+
+// orientation returns twice the signed area of the triangle a, b, c,
+// positive if it is counter-clockwise.
+func orientation(a, b, c Coord) float64 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
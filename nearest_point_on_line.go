@@ -12,7 +12,11 @@ type NearestPointResult struct {
 }
 
 // NearestPointOnLine returns the closest point on a line to a given point.
-func NearestPointOnLine(line *LineString, pt Coord) NearestPointResult {
+// The closest point is found on the great circle segments of the line,
+// distances are measured with [Haversine] unless another [DistanceModel] is
+// given.
+func NearestPointOnLine(line *LineString, pt Coord, model ...DistanceModel) NearestPointResult {
+	m := distanceModel(model)
 	closest := NearestPointResult{
 		Point:    nil,
 		Distance: Meters(math.Inf(1)),
@@ -30,7 +34,7 @@ func NearestPointOnLine(line *LineString, pt Coord) NearestPointResult {
 		start := coords[i-stride : i]
 		stop := coords[i : i+stride]
 
-		segLen := Distance(start, stop)
+		segLen := m.Distance(start, stop)
 
 		var intersect Coord
 		var useEnd bool
@@ -46,13 +50,13 @@ func NearestPointOnLine(line *LineString, pt Coord) NearestPointResult {
 			intersect, useEnd = nearestPointOnSegment(start, stop, ptVec)
 		}
 
-		d := Distance(pt, intersect)
+		d := m.Distance(pt, intersect)
 		if d < closest.Distance {
 			closest = NearestPointResult{
 				Point:    intersect,
 				Distance: d,
 				Index:    i/stride + btoi(useEnd),
-				Location: length + Distance(start, intersect),
+				Location: length + m.Distance(start, intersect),
 			}
 		}
 
@@ -0,0 +1,561 @@
+package overlay
+
+import (
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/matoous/goodgeo"
+)
+
+// tolerance is the distance, in degrees, below which two nodes are merged
+// and a vertex is considered to lie on a segment. It corresponds to roughly
+// 1 µm.
+const tolerance = 1e-11
+
+// maxNodingPasses limits the number of times segments are split at their
+// mutual intersections. New nodes may lie on other segments, so noding is
+// repeated until no segment needs to be split, or fails with [ErrNotNoded].
+const maxNodingPasses = 8
+
+// A segment is a part of an edge of an input ring between nodes u and v,
+// directed so that the interior of its polygon is on its left.
+type segment struct {
+	u, v int
+	src  int
+}
+
+// A split is a node on a segment at fraction t of its length.
+type split struct {
+	t    float64
+	node int
+}
+
+// An edge is an edge of the noded graph between nodes u < v. count holds,
+// for each input, the number of its segments running from u to v minus the
+// number of its segments running from v to u. left and right hold whether
+// each side of the edge lies inside each input. The edge at index i is made
+// of the half-edges 2i, from u to v, and 2i+1 in the opposite direction.
+type edge struct {
+	u, v        int
+	count       [2]int
+	left, right [2]bool
+}
+
+// A dart is a directed edge of the result.
+type dart struct {
+	from, to int
+	used     bool
+}
+
+type graph struct {
+	nodes     []goodgeo.Coord
+	cells     map[[2]int64][]int
+	segments  []segment
+	edges     []*edge
+	edgeIndex map[[2]int]int
+	// outgoing holds the half-edges that leave each node sorted
+	// counter-clockwise, and position the index of each half-edge in it.
+	outgoing [][]int
+	position []int
+}
+
+func newGraph() *graph {
+	return &graph{
+		cells:     make(map[[2]int64][]int),
+		edgeIndex: make(map[[2]int]int),
+	}
+}
+
+func (g *graph) addPolygons(src int, mp *goodgeo.MultiPolygon) {
+	for _, rings := range mp.Coords() {
+		for _, ring := range rings {
+			for i := 1; i < len(ring); i++ {
+				g.segments = append(g.segments, segment{u: g.nodeAt(ring[i-1]), v: g.nodeAt(ring[i]), src: src})
+			}
+		}
+	}
+}
+
+// nodeAt returns the node at c, creating it if there is no node within
+// tolerance.
+func (g *graph) nodeAt(c goodgeo.Coord) int {
+	cx, cy := int64(math.Floor(c[0]/tolerance)), int64(math.Floor(c[1]/tolerance))
+	for dx := int64(-1); dx <= 1; dx++ {
+		for dy := int64(-1); dy <= 1; dy++ {
+			for _, n := range g.cells[[2]int64{cx + dx, cy + dy}] {
+				if math.Abs(g.nodes[n][0]-c[0]) <= tolerance && math.Abs(g.nodes[n][1]-c[1]) <= tolerance {
+					return n
+				}
+			}
+		}
+	}
+	n := len(g.nodes)
+	g.nodes = append(g.nodes, c)
+	g.cells[[2]int64{cx, cy}] = append(g.cells[[2]int64{cx, cy}], n)
+	return n
+}
+
+// node splits the segments at their mutual intersections and builds the
+// edges of the graph. Edges along which the segments of both inputs cancel
+// out are dropped. It returns [ErrNotNoded] if the segments still intersect
+// after maxNodingPasses.
+func (g *graph) node() error {
+	for pass := 0; g.split(); pass++ {
+		if pass == maxNodingPasses {
+			return ErrNotNoded
+		}
+	}
+	for _, s := range g.segments {
+		g.addEdge(s.u, s.v, s.src)
+	}
+	g.edges = slices.DeleteFunc(g.edges, func(e *edge) bool { return e.count == [2]int{} })
+	return nil
+}
+
+// split splits the segments at their mutual intersections and returns true
+// if any segment was split.
+func (g *graph) split() bool {
+	splits := make([][]split, len(g.segments))
+	order := make([]int, len(g.segments))
+	for i := range order {
+		order[i] = i
+	}
+	minX := func(s segment) float64 { return math.Min(g.nodes[s.u][0], g.nodes[s.v][0]) }
+	sort.Slice(order, func(i, j int) bool { return minX(g.segments[order[i]]) < minX(g.segments[order[j]]) })
+
+	found := false
+	for k, i := range order {
+		s := g.segments[i]
+		a, b := g.nodes[s.u], g.nodes[s.v]
+		maxX := math.Max(a[0], b[0]) + tolerance
+		for _, j := range order[k+1:] {
+			o := g.segments[j]
+			if minX(o) > maxX {
+				break
+			}
+			c, d := g.nodes[o.u], g.nodes[o.v]
+			for _, x := range intersection(a, b, c, d) {
+				// Extra ordinates are taken from the first input.
+				from, to := a, b
+				if o.src < s.src {
+					from, to = c, d
+				}
+				n := g.nodeAt(vertexOr(x, interpolate(from, to, parameter(from, to, x), x), a, b, c, d))
+				if n != s.u && n != s.v {
+					splits[i] = append(splits[i], split{t: parameter(a, b, g.nodes[n]), node: n})
+					found = true
+				}
+				if n != o.u && n != o.v {
+					splits[j] = append(splits[j], split{t: parameter(c, d, g.nodes[n]), node: n})
+					found = true
+				}
+			}
+		}
+	}
+	if !found {
+		return false
+	}
+
+	segments := make([]segment, 0, len(g.segments))
+	for i, s := range g.segments {
+		sort.SliceStable(splits[i], func(j, k int) bool { return splits[i][j].t < splits[i][k].t })
+		u := s.u
+		for _, sp := range append(splits[i], split{t: 1, node: s.v}) {
+			if sp.node != u {
+				segments = append(segments, segment{u: u, v: sp.node, src: s.src})
+				u = sp.node
+			}
+		}
+	}
+	g.segments = segments
+	return true
+}
+
+func (g *graph) addEdge(from, to, src int) {
+	if from == to {
+		return
+	}
+	key, dir := [2]int{from, to}, 1
+	if from > to {
+		key, dir = [2]int{to, from}, -1
+	}
+	i, ok := g.edgeIndex[key]
+	if !ok {
+		i = len(g.edges)
+		g.edgeIndex[key] = i
+		g.edges = append(g.edges, &edge{u: key[0], v: key[1]})
+	}
+	g.edges[i].count[src] += dir
+}
+
+// label determines for each edge on which sides the inputs lie.
+//
+// The edges divide the plane into faces. The winding number of each input
+// around the outer face of every connected part of the graph is counted
+// from the edges of the other parts, and from there crossing an edge from
+// its right to its left adds its count. Deriving the labels from the noded
+// edges, rather than the input, keeps them consistent with the graph.
+func (g *graph) label() {
+	g.outgoing = make([][]int, len(g.nodes))
+	for i, e := range g.edges {
+		g.outgoing[e.u] = append(g.outgoing[e.u], 2*i)
+		g.outgoing[e.v] = append(g.outgoing[e.v], 2*i+1)
+	}
+	g.position = make([]int, 2*len(g.edges))
+	for n, outgoing := range g.outgoing {
+		angles := make(map[int]float64, len(outgoing))
+		for _, h := range outgoing {
+			to := g.nodes[g.origin(h^1)]
+			angles[h] = math.Atan2(to[1]-g.nodes[n][1], to[0]-g.nodes[n][0])
+		}
+		sort.Slice(outgoing, func(i, j int) bool { return angles[outgoing[i]] < angles[outgoing[j]] })
+		for i, h := range outgoing {
+			g.position[h] = i
+		}
+	}
+
+	// Find the faces, their half-edges and their areas.
+	faces := make([]int, 2*len(g.edges))
+	for h := range faces {
+		faces[h] = -1
+	}
+	var faceEdges [][]int
+	var faceAreas []float64
+	for start := range faces {
+		if faces[start] != -1 {
+			continue
+		}
+		var edges []int
+		var ring []goodgeo.Coord
+		for h := start; faces[h] == -1; h = g.turn(h) {
+			faces[h] = len(faceEdges)
+			edges = append(edges, h)
+			ring = append(ring, g.nodes[g.origin(h)])
+		}
+		faceEdges = append(faceEdges, edges)
+		faceAreas = append(faceAreas, signedArea(append(ring, ring[0])))
+	}
+
+	// Find the connected components of the graph and the outer face of
+	// each, which is the face with the most negative area.
+	components := make([]int, len(g.nodes))
+	for n := range components {
+		components[n] = -1
+	}
+	var roots, outer []int
+	for n := range g.nodes {
+		if components[n] != -1 || len(g.outgoing[n]) == 0 {
+			continue
+		}
+		c := len(roots)
+		roots = append(roots, n)
+		outer = append(outer, -1)
+		components[n] = c
+		for stack := []int{n}; len(stack) > 0; {
+			m := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, h := range g.outgoing[m] {
+				if f := faces[h]; outer[c] == -1 || faceAreas[f] < faceAreas[outer[c]] {
+					outer[c] = f
+				}
+				if to := g.origin(h ^ 1); components[to] == -1 {
+					components[to] = c
+					stack = append(stack, to)
+				}
+			}
+		}
+	}
+
+	windings := make([][2]int, len(faceEdges))
+	known := make([]bool, len(faceEdges))
+	for c, root := range roots {
+		for src := range 2 {
+			windings[outer[c]][src] = g.winding(g.nodes[root], src, func(e *edge) bool { return components[e.u] != c })
+		}
+		known[outer[c]] = true
+		for stack := []int{outer[c]}; len(stack) > 0; {
+			f := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, h := range faceEdges[f] {
+				if other := faces[h^1]; !known[other] {
+					for src := range 2 {
+						windings[other][src] = windings[f][src] - g.count(h, src)
+					}
+					known[other] = true
+					stack = append(stack, other)
+				}
+			}
+		}
+	}
+
+	for i, e := range g.edges {
+		left, right := windings[faces[2*i]], windings[faces[2*i+1]]
+		for src := range 2 {
+			e.left[src], e.right[src] = left[src] > 0, right[src] > 0
+		}
+	}
+}
+
+// origin returns the node that the half-edge h leaves.
+func (g *graph) origin(h int) int {
+	if h&1 == 0 {
+		return g.edges[h/2].u
+	}
+	return g.edges[h/2].v
+}
+
+// turn returns the half-edge that follows h around the face on its left:
+// the half-edge leaving the end of h that is next clockwise from the
+// reverse of h.
+func (g *graph) turn(h int) int {
+	outgoing := g.outgoing[g.origin(h^1)]
+	return outgoing[(g.position[h^1]+len(outgoing)-1)%len(outgoing)]
+}
+
+// count returns the number of segments of input src running along the
+// half-edge h minus the number running along its reverse.
+func (g *graph) count(h, src int) int {
+	if h&1 == 0 {
+		return g.edges[h/2].count[src]
+	}
+	return -g.edges[h/2].count[src]
+}
+
+// winding returns the number of times the edges of input src for which
+// include is true wind around c counter-clockwise.
+func (g *graph) winding(c goodgeo.Coord, src int, include func(e *edge) bool) int {
+	w := 0
+	for _, e := range g.edges {
+		if e.count[src] == 0 || !include(e) {
+			continue
+		}
+		p, q := g.nodes[e.u], g.nodes[e.v]
+		cross := (q[0]-p[0])*(c[1]-p[1]) - (c[0]-p[0])*(q[1]-p[1])
+		switch {
+		case p[1] <= c[1] && c[1] < q[1] && cross > 0:
+			w += e.count[src]
+		case q[1] <= c[1] && c[1] < p[1] && cross < 0:
+			w -= e.count[src]
+		}
+	}
+	return w
+}
+
+// build returns the polygons of the result of op.
+func (g *graph) build(op Op) [][][]goodgeo.Coord {
+	var darts []*dart
+	out := make([][]*dart, len(g.nodes))
+	for _, e := range g.edges {
+		left, right := op.includes(e.left[0], e.left[1]), op.includes(e.right[0], e.right[1])
+		if left == right {
+			continue
+		}
+		d := &dart{from: e.u, to: e.v}
+		if right {
+			d.from, d.to = e.v, e.u
+		}
+		darts = append(darts, d)
+		out[d.from] = append(out[d.from], d)
+	}
+
+	var shells, holes [][]goodgeo.Coord
+	for _, start := range darts {
+		if start.used {
+			continue
+		}
+		walk, ok := g.trace(start, out)
+		if !ok {
+			continue
+		}
+		for _, ring := range splitWalk(walk) {
+			coords := make([]goodgeo.Coord, 0, len(ring)+1)
+			for _, n := range ring {
+				coords = append(coords, g.nodes[n])
+			}
+			coords = append(coords, coords[0])
+			switch area := signedArea(coords); {
+			case area > 0:
+				shells = append(shells, coords)
+			case area < 0:
+				holes = append(holes, coords)
+			}
+		}
+	}
+	return assignHoles(shells, holes)
+}
+
+// trace follows the darts starting at start, keeping the result on the
+// left, and returns the visited nodes. At every node it takes the outgoing
+// dart that is next clockwise from the dart it arrived on, which traces the
+// boundary of a single face.
+func (g *graph) trace(start *dart, out [][]*dart) ([]int, bool) {
+	var walk []int
+	for d := start; ; {
+		d.used = true
+		walk = append(walk, d.from)
+		p, q := g.nodes[d.from], g.nodes[d.to]
+		reverse := math.Atan2(p[1]-q[1], p[0]-q[0])
+		var next *dart
+		best := math.Inf(1)
+		for _, o := range out[d.to] {
+			r := g.nodes[o.to]
+			delta := reverse - math.Atan2(r[1]-q[1], r[0]-q[0])
+			if delta <= 0 {
+				delta += 2 * math.Pi
+			}
+			if delta < best {
+				next, best = o, delta
+			}
+		}
+		switch {
+		case next == start:
+			return walk, true
+		case next == nil || next.used:
+			return nil, false
+		}
+		d = next
+	}
+}
+
+// splitWalk splits a closed walk that visits some nodes more than once into
+// simple rings.
+func splitWalk(walk []int) [][]int {
+	var rings [][]int
+	var stack []int
+	position := make(map[int]int)
+	for _, n := range walk {
+		if i, ok := position[n]; ok {
+			ring := slices.Clone(stack[i:])
+			rings = append(rings, ring)
+			for _, m := range stack[i+1:] {
+				delete(position, m)
+			}
+			stack = stack[:i+1]
+			continue
+		}
+		position[n] = len(stack)
+		stack = append(stack, n)
+	}
+	if len(stack) > 0 {
+		rings = append(rings, stack)
+	}
+	return rings
+}
+
+// assignHoles returns polygons formed by the shells and the holes that lie
+// in them. Each hole is assigned to the smallest shell containing it.
+func assignHoles(shells, holes [][]goodgeo.Coord) [][][]goodgeo.Coord {
+	polygons := make([][][]goodgeo.Coord, len(shells))
+	shellPolygons := make([]*goodgeo.Polygon, len(shells))
+	for i, shell := range shells {
+		polygons[i] = [][]goodgeo.Coord{shell}
+		shellPolygons[i] = goodgeo.NewPolygonFlat(goodgeo.XY, flatXY(shell), []int{2 * len(shell)})
+	}
+	opts := &goodgeo.PointInPolygonOptions{IgnoreBoundary: true}
+	for _, hole := range holes {
+		mid := goodgeo.Coord{(hole[0][0] + hole[1][0]) / 2, (hole[0][1] + hole[1][1]) / 2}
+		best, bestArea := -1, math.Inf(1)
+		for i, shell := range shells {
+			if area := signedArea(shell); area < bestArea && goodgeo.BooleanPointInPolygon(mid, shellPolygons[i], opts) {
+				best, bestArea = i, area
+			}
+		}
+		if best >= 0 {
+			polygons[best] = append(polygons[best], hole)
+		}
+	}
+	return polygons
+}
+
+func flatXY(coords []goodgeo.Coord) []float64 {
+	flatCoords := make([]float64, 0, 2*len(coords))
+	for _, c := range coords {
+		flatCoords = append(flatCoords, c[0], c[1])
+	}
+	return flatCoords
+}
+
+// intersection returns the intersection of the segments ab and cd: nothing,
+// a single point, or the ends of a collinear overlap. Vertices that lie on
+// the other segment are returned exactly.
+func intersection(a, b, c, d goodgeo.Coord) []goodgeo.Coord {
+	if math.Max(a[0], b[0]) < math.Min(c[0], d[0])-tolerance ||
+		math.Min(a[0], b[0]) > math.Max(c[0], d[0])+tolerance ||
+		math.Max(a[1], b[1]) < math.Min(c[1], d[1])-tolerance ||
+		math.Min(a[1], b[1]) > math.Max(c[1], d[1])+tolerance {
+		return nil
+	}
+
+	var onOther []goodgeo.Coord
+	for _, x := range []goodgeo.Coord{a, b} {
+		if onSegment(x, c, d) {
+			onOther = append(onOther, x)
+		}
+	}
+	for _, x := range []goodgeo.Coord{c, d} {
+		if onSegment(x, a, b) && !slices.ContainsFunc(onOther, func(y goodgeo.Coord) bool { return sameXY(x, y) }) {
+			onOther = append(onOther, x)
+		}
+	}
+	if len(onOther) > 0 {
+		return onOther
+	}
+
+	rx, ry := b[0]-a[0], b[1]-a[1]
+	sx, sy := d[0]-c[0], d[1]-c[1]
+	denom := rx*sy - ry*sx
+	if denom == 0 {
+		return nil
+	}
+	qx, qy := c[0]-a[0], c[1]-a[1]
+	t := (qx*sy - qy*sx) / denom
+	u := (qx*ry - qy*rx) / denom
+	if t <= 0 || t >= 1 || u <= 0 || u >= 1 {
+		return nil
+	}
+	return []goodgeo.Coord{{a[0] + t*rx, a[1] + t*ry}}
+}
+
+// onSegment returns true if x lies on the segment ab.
+func onSegment(x, a, b goodgeo.Coord) bool {
+	if x[0] < math.Min(a[0], b[0])-tolerance || x[0] > math.Max(a[0], b[0])+tolerance ||
+		x[1] < math.Min(a[1], b[1])-tolerance || x[1] > math.Max(a[1], b[1])+tolerance {
+		return false
+	}
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return false
+	}
+	return math.Abs((x[0]-a[0])*dy-(x[1]-a[1])*dx)/length <= tolerance
+}
+
+// parameter returns the position of x on the segment ab as a fraction of
+// its length.
+func parameter(a, b, x goodgeo.Coord) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	return math.Max(0, math.Min(1, ((x[0]-a[0])*dx+(x[1]-a[1])*dy)/(dx*dx+dy*dy)))
+}
+
+// vertexOr returns the vertex with the same X and Y as x, or c if there is
+// none.
+func vertexOr(x, c goodgeo.Coord, vertices ...goodgeo.Coord) goodgeo.Coord {
+	for _, v := range vertices {
+		if sameXY(x, v) {
+			return v
+		}
+	}
+	return c
+}
+
+// interpolate returns the coordinate at fraction t of the segment ab with
+// the X and Y of x. Other ordinates are interpolated linearly.
+func interpolate(a, b goodgeo.Coord, t float64, x goodgeo.Coord) goodgeo.Coord {
+	c := make(goodgeo.Coord, len(a))
+	for i := range c {
+		c[i] = a[i] + t*(b[i]-a[i])
+	}
+	c[0], c[1] = x[0], x[1]
+	return c
+}
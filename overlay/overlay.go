@@ -0,0 +1,214 @@
+// Package overlay implements boolean operations on polygons: union,
+// intersection, difference and symmetric difference.
+//
+// The inputs are noded into a planar graph in which every edge is labelled
+// with its position relative to both inputs, the edges that separate the
+// result from the rest of the plane are kept and finally linked into rings.
+// Coordinates are treated as planar, like Turf does. The inputs are expected
+// to be valid polygons; rings may have either orientation.
+package overlay
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/matoous/goodgeo"
+)
+
+// ErrNotNoded is returned when the inputs cannot be split into
+// non-intersecting edges, which happens when splitting segments at their
+// intersections keeps creating new intersections because of rounding.
+var ErrNotNoded = errors.New("overlay: inputs could not be noded")
+
+// An Op is a boolean operation.
+type Op int
+
+const (
+	// OpUnion keeps the points that are in either input.
+	OpUnion Op = iota
+	// OpIntersection keeps the points that are in both inputs.
+	OpIntersection
+	// OpDifference keeps the points of the first input that are not in the
+	// second.
+	OpDifference
+	// OpSymDifference keeps the points that are in exactly one input.
+	OpSymDifference
+)
+
+// String returns a human-readable string representing op.
+func (op Op) String() string {
+	switch op {
+	case OpUnion:
+		return "Union"
+	case OpIntersection:
+		return "Intersection"
+	case OpDifference:
+		return "Difference"
+	case OpSymDifference:
+		return "SymDifference"
+	default:
+		return fmt.Sprintf("Op(%d)", int(op))
+	}
+}
+
+// includes returns true if a point with the given membership in the inputs
+// is part of the result of op.
+func (op Op) includes(inA, inB bool) bool {
+	switch op {
+	case OpUnion:
+		return inA || inB
+	case OpIntersection:
+		return inA && inB
+	case OpDifference:
+		return inA && !inB
+	case OpSymDifference:
+		return inA != inB
+	default:
+		return false
+	}
+}
+
+// Union returns the union of a and b.
+func Union(a, b goodgeo.T) (*goodgeo.MultiPolygon, error) {
+	return Overlay(a, b, OpUnion)
+}
+
+// Intersection returns the intersection of a and b.
+func Intersection(a, b goodgeo.T) (*goodgeo.MultiPolygon, error) {
+	return Overlay(a, b, OpIntersection)
+}
+
+// Difference returns the part of a that is not in b.
+func Difference(a, b goodgeo.T) (*goodgeo.MultiPolygon, error) {
+	return Overlay(a, b, OpDifference)
+}
+
+// SymDifference returns the parts of a and b that are not in both.
+func SymDifference(a, b goodgeo.T) (*goodgeo.MultiPolygon, error) {
+	return Overlay(a, b, OpSymDifference)
+}
+
+// Overlay returns the result of op applied to a and b, which must be
+// [goodgeo.Polygon]s or [goodgeo.MultiPolygon]s with the same layout. The
+// result has the layout and SRID of a. Exterior rings of the result are
+// counter-clockwise and holes are clockwise.
+func Overlay(a, b goodgeo.T, op Op) (*goodgeo.MultiPolygon, error) {
+	pa, err := polygons(a)
+	if err != nil {
+		return nil, err
+	}
+	pb, err := polygons(b)
+	if err != nil {
+		return nil, err
+	}
+	layout := a.Layout()
+	switch {
+	case a.Empty():
+		layout = b.Layout()
+	case !b.Empty() && b.Layout() != layout:
+		return nil, goodgeo.LayoutMismatchError{Got: b.Layout(), Want: layout}
+	}
+
+	g := newGraph()
+	g.addPolygons(0, pa)
+	g.addPolygons(1, pb)
+	if err := g.node(); err != nil {
+		return nil, err
+	}
+	g.label()
+
+	mp := goodgeo.NewMultiPolygon(layout)
+	for _, rings := range g.build(op) {
+		p, err := goodgeo.NewPolygon(layout).SetCoords(rings)
+		if err != nil {
+			return nil, err
+		}
+		if err := mp.Push(p); err != nil {
+			return nil, err
+		}
+	}
+	return mp.SetSRID(a.SRID()), nil
+}
+
+// polygons returns g as a MultiPolygon with exterior rings oriented
+// counter-clockwise, holes oriented clockwise, and degenerate rings removed.
+func polygons(g goodgeo.T) (*goodgeo.MultiPolygon, error) {
+	var coords [][][]goodgeo.Coord
+	switch g := g.(type) {
+	case *goodgeo.Polygon:
+		coords = [][][]goodgeo.Coord{g.Coords()}
+	case *goodgeo.MultiPolygon:
+		coords = g.Coords()
+	default:
+		return nil, goodgeo.UnsupportedTypeError{Value: g}
+	}
+
+	mp := goodgeo.NewMultiPolygon(g.Layout())
+	for _, rings := range coords {
+		var polygon [][]goodgeo.Coord
+		for i, ring := range rings {
+			ring = cleanRing(ring)
+			area := signedArea(ring)
+			if area == 0 {
+				if i == 0 {
+					break
+				}
+				continue
+			}
+			if (i == 0) != (area > 0) {
+				reverse(ring)
+			}
+			polygon = append(polygon, ring)
+		}
+		if len(polygon) == 0 {
+			continue
+		}
+		p, err := goodgeo.NewPolygon(g.Layout()).SetCoords(polygon)
+		if err != nil {
+			return nil, err
+		}
+		if err := mp.Push(p); err != nil {
+			return nil, err
+		}
+	}
+	return mp, nil
+}
+
+// cleanRing returns the ring closed and without consecutive duplicate
+// coordinates.
+func cleanRing(ring []goodgeo.Coord) []goodgeo.Coord {
+	cleaned := make([]goodgeo.Coord, 0, len(ring)+1)
+	for _, c := range ring {
+		if len(cleaned) > 0 && sameXY(cleaned[len(cleaned)-1], c) {
+			continue
+		}
+		cleaned = append(cleaned, c)
+	}
+	if len(cleaned) > 1 && sameXY(cleaned[0], cleaned[len(cleaned)-1]) {
+		cleaned = cleaned[:len(cleaned)-1]
+	}
+	if len(cleaned) < 3 {
+		return nil
+	}
+	return append(cleaned, cleaned[0])
+}
+
+// signedArea returns the planar area of the closed ring, positive if the
+// ring is counter-clockwise.
+func signedArea(ring []goodgeo.Coord) float64 {
+	var area float64
+	for i := 1; i < len(ring); i++ {
+		area += ring[i-1][0]*ring[i][1] - ring[i][0]*ring[i-1][1]
+	}
+	return area / 2
+}
+
+func reverse(ring []goodgeo.Coord) {
+	for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+		ring[i], ring[j] = ring[j], ring[i]
+	}
+}
+
+func sameXY(a, b goodgeo.Coord) bool {
+	return a[0] == b[0] && a[1] == b[1]
+}
@@ -0,0 +1,150 @@
+package overlay
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/matoous/goodgeo"
+)
+
+func square(x, y, size float64) *goodgeo.Polygon {
+	return goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{
+		{{x, y}, {x + size, y}, {x + size, y + size}, {x, y + size}, {x, y}},
+	})
+}
+
+func circle(x, y, r float64, n int) *goodgeo.Polygon {
+	ring := make([]goodgeo.Coord, 0, n+1)
+	for i := range n {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		ring = append(ring, goodgeo.Coord{x + r*math.Cos(a), y + r*math.Sin(a)})
+	}
+	return goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{append(ring, ring[0])})
+}
+
+func TestOverlay(t *testing.T) {
+	for i, tc := range []struct {
+		a, b        goodgeo.T
+		op          Op
+		area        float64
+		numPolygons int
+		numRings    int
+	}{
+		{a: square(0, 0, 10), b: square(5, 5, 10), op: OpUnion, area: 175, numPolygons: 1, numRings: 1},
+		{a: square(0, 0, 10), b: square(5, 5, 10), op: OpIntersection, area: 25, numPolygons: 1, numRings: 1},
+		{a: square(0, 0, 10), b: square(5, 5, 10), op: OpDifference, area: 75, numPolygons: 1, numRings: 1},
+		{a: square(0, 0, 10), b: square(5, 5, 10), op: OpSymDifference, area: 150, numPolygons: 2, numRings: 2},
+		{a: square(0, 0, 10), b: square(20, 20, 10), op: OpUnion, area: 200, numPolygons: 2, numRings: 2},
+		{a: square(0, 0, 10), b: square(20, 20, 10), op: OpIntersection, area: 0},
+		{a: square(0, 0, 10), b: square(2, 2, 2), op: OpDifference, area: 96, numPolygons: 1, numRings: 2},
+		{a: square(0, 0, 10), b: square(2, 2, 2), op: OpUnion, area: 100, numPolygons: 1, numRings: 1},
+		// Polygons sharing an edge are merged.
+		{a: square(0, 0, 10), b: square(10, 0, 10), op: OpUnion, area: 200, numPolygons: 1, numRings: 1},
+		{a: square(0, 0, 10), b: square(10, 0, 10), op: OpIntersection, area: 0},
+		// Polygons touching at a corner stay separate.
+		{a: square(0, 0, 10), b: square(10, 10, 10), op: OpUnion, area: 200, numPolygons: 2, numRings: 2},
+		// A hole touching the exterior ring at a vertex stays a hole.
+		{
+			a: square(0, 0, 4),
+			b: goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{
+				{{0, 0}, {2, 1}, {1, 2}, {0, 0}},
+			}),
+			op: OpDifference, area: 14.5, numPolygons: 1, numRings: 2,
+		},
+		{
+			a:  goodgeo.NewMultiPolygon(goodgeo.XY).MustSetCoords([][][]goodgeo.Coord{square(0, 0, 10).Coords(), square(20, 0, 10).Coords()}),
+			b:  square(5, 0, 20),
+			op: OpUnion, area: 500, numPolygons: 1, numRings: 1,
+		},
+		// A polygon in the hole of another is a separate part of the graph.
+		{
+			a: goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{
+				square(0, 0, 10).Coords()[0], {{2, 2}, {2, 8}, {8, 8}, {8, 2}, {2, 2}},
+			}),
+			b:  square(4, 4, 2),
+			op: OpUnion, area: 68, numPolygons: 2, numRings: 3,
+		},
+		{
+			a: goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{
+				square(0, 0, 10).Coords()[0], {{2, 2}, {2, 8}, {8, 8}, {8, 2}, {2, 2}},
+			}),
+			b:  square(4, 4, 2),
+			op: OpIntersection, area: 0,
+		},
+		{a: square(0, 0, 10), b: goodgeo.NewPolygon(goodgeo.XY), op: OpUnion, area: 100, numPolygons: 1, numRings: 1},
+		{a: goodgeo.NewPolygon(goodgeo.NoLayout), b: square(0, 0, 10), op: OpUnion, area: 100, numPolygons: 1, numRings: 1},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := Overlay(tc.a, tc.b, tc.op)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.area, got.Area())
+			assert.Equal(t, tc.numPolygons, got.NumPolygons())
+			numRings := 0
+			for j := range got.NumPolygons() {
+				p := got.Polygon(j)
+				numRings += p.NumLinearRings()
+				for k := range p.NumLinearRings() {
+					ring := p.LinearRing(k)
+					assert.Equal(t, k == 0, ring.Area() > 0, "orientation of ring %d of polygon %d", k, j)
+				}
+			}
+			assert.Equal(t, tc.numRings, numRings)
+		})
+	}
+}
+
+func TestOverlayManyVertices(t *testing.T) {
+	a, b := circle(0, 0, 1, 20000), circle(0.5, 0.3, 1, 20000)
+	union, err := Union(a, b)
+	assert.NoError(t, err)
+	intersection, err := Intersection(a, b)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, union.NumPolygons())
+	assert.Equal(t, 1, intersection.NumPolygons())
+	want := a.Area() + b.Area()
+	assert.True(t, math.Abs(union.Area()+intersection.Area()-want) < 1e-9*want)
+
+	difference, err := Difference(a, b)
+	assert.NoError(t, err)
+	assert.True(t, math.Abs(difference.Area()+intersection.Area()-a.Area()) < 1e-9*want)
+}
+
+func BenchmarkUnion(b *testing.B) {
+	c1, c2 := circle(0, 0, 1, 20000), circle(0.5, 0.3, 1, 20000)
+	for range b.N {
+		_, _ = Union(c1, c2)
+	}
+}
+
+func TestOverlayKeepsLayoutAndSRID(t *testing.T) {
+	a := goodgeo.NewPolygon(goodgeo.XYZ).MustSetCoords([][]goodgeo.Coord{
+		{{0, 0, 1}, {10, 0, 1}, {10, 10, 3}, {0, 10, 3}, {0, 0, 1}},
+	}).SetSRID(4326)
+	b := goodgeo.NewPolygon(goodgeo.XYZ).MustSetCoords([][]goodgeo.Coord{
+		{{5, -5, 0}, {15, -5, 0}, {15, 5, 0}, {5, 5, 0}, {5, -5, 0}},
+	})
+	got, err := Union(a, b)
+	assert.NoError(t, err)
+	assert.Equal(t, goodgeo.XYZ, got.Layout())
+	assert.Equal(t, 4326, got.SRID())
+	assert.Equal(t, 175, got.Area())
+	for _, c := range got.Polygon(0).LinearRing(0).Coords() {
+		if c[0] == 10 && c[1] == 5 {
+			assert.Equal(t, 2, c[2])
+		}
+	}
+}
+
+func TestOverlayErrors(t *testing.T) {
+	point := goodgeo.NewPoint(goodgeo.XY)
+	_, err := Union(square(0, 0, 1), point)
+	assert.IsError(t, err, goodgeo.UnsupportedTypeError{Value: point})
+
+	_, err = Union(square(0, 0, 1), goodgeo.NewPolygon(goodgeo.XYZ).MustSetCoords([][]goodgeo.Coord{
+		{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 0, 0}},
+	}))
+	assert.IsError(t, err, goodgeo.LayoutMismatchError{Got: goodgeo.XYZ, Want: goodgeo.XY})
+}
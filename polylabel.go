@@ -0,0 +1,152 @@
+package goodgeo
+
+import (
+	"container/heap"
+	"math"
+)
+
+// PolyLabel returns the pole of inaccessibility of p: the point inside p that
+// is farthest from its edges, found within precision with the algorithm of
+// Mapbox's [polylabel]. The coordinates are scaled by the cosine of the
+// latitude of the middle of p, so that distances are close to meters for
+// small polygons. A precision that is not positive is treated as 1 meter.
+// The result has the XY layout and the SRID of p, and is empty if p is
+// empty.
+//
+// [polylabel]: https://github.com/mapbox/polylabel
+func PolyLabel(p *Polygon, precision Meters) *Point {
+	if p.Empty() || p.ends[0] == 0 {
+		return NewPointEmpty(XY).SetSRID(p.SRID())
+	}
+	if precision <= 0 {
+		precision = 1
+	}
+	b := NewBounds(p.layout).extendFlatCoords(p.flatCoords, 0, p.ends[0], p.stride)
+	minX, minY, maxX, maxY := b.Min(0), b.Min(1), b.Max(0), b.Max(1)
+	kx := math.Cos((minY + maxY) / 2 * math.Pi / 180)
+	label := func(x, y float64) *Point {
+		return NewPoint(XY).MustSetCoords(Coord{x, y}).SetSRID(p.SRID())
+	}
+
+	width, height := (maxX-minX)*kx, maxY-minY
+	cellSize := math.Min(width, height)
+	if cellSize == 0 {
+		return label(minX, minY)
+	}
+	l := polyLabeler{p: p, kx: kx}
+	tolerance := float64(RadiansToDegrees(LengthToRadians(precision)))
+
+	// Cover the polygon with square cells.
+	var queue cellQueue
+	h := cellSize / 2
+	for x := minX * kx; x < maxX*kx; x += cellSize {
+		for y := minY; y < maxY; y += cellSize {
+			heap.Push(&queue, l.cell(x+h, y+h, h))
+		}
+	}
+
+	// Start with the centroid of the exterior ring or the middle of the
+	// bounds, whichever is farther from the edges.
+	best := l.cell(minX*kx+width/2, minY+height/2, 0)
+	var c centerOfMass
+	c.addPolygon(p.flatCoords, 0, p.ends[:1], p.stride)
+	if c.area != 0 {
+		if centroid := l.cell(c.areaX/c.area*kx, c.areaY/c.area, 0); centroid.d > best.d {
+			best = centroid
+		}
+	}
+
+	for queue.Len() > 0 {
+		c := heap.Pop(&queue).(polyLabelCell)
+		if c.d > best.d {
+			best = c
+		}
+		// Skip cells that cannot contain a better point.
+		if c.max-best.d <= tolerance {
+			continue
+		}
+		h := c.h / 2
+		heap.Push(&queue, l.cell(c.x-h, c.y-h, h))
+		heap.Push(&queue, l.cell(c.x+h, c.y-h, h))
+		heap.Push(&queue, l.cell(c.x-h, c.y+h, h))
+		heap.Push(&queue, l.cell(c.x+h, c.y+h, h))
+	}
+	return label(best.x/kx, best.y)
+}
+
+// A polyLabeler measures distances to the edges of a polygon in coordinates
+// whose X ordinates are scaled by kx.
+type polyLabeler struct {
+	p  *Polygon
+	kx float64
+}
+
+// cell returns the square cell with the center x, y and half the side h.
+func (l polyLabeler) cell(x, y, h float64) polyLabelCell {
+	d := l.signedDistance(x, y)
+	return polyLabelCell{x: x, y: y, h: h, d: d, max: d + h*math.Sqrt2}
+}
+
+// signedDistance returns the distance from x, y to the closest edge of the
+// polygon, negative if x, y is outside it.
+func (l polyLabeler) signedDistance(x, y float64) float64 {
+	flatCoords, stride := l.p.flatCoords, l.p.stride
+	inside := false
+	minDist := math.Inf(1)
+	offset := 0
+	for _, end := range l.p.ends {
+		for i, j := offset, end-stride; i < end; j, i = i, i+stride {
+			ax, ay := flatCoords[i]*l.kx, flatCoords[i+1]
+			bx, by := flatCoords[j]*l.kx, flatCoords[j+1]
+			if (ay > y) != (by > y) && x < (bx-ax)*(y-ay)/(by-ay)+ax {
+				inside = !inside
+			}
+			minDist = math.Min(minDist, segmentDistanceSquared(x, y, ax, ay, bx, by))
+		}
+		offset = end
+	}
+	if inside {
+		return math.Sqrt(minDist)
+	}
+	return -math.Sqrt(minDist)
+}
+
+// segmentDistanceSquared returns the squared distance from x, y to the
+// segment from a to b.
+func segmentDistanceSquared(x, y, ax, ay, bx, by float64) float64 {
+	dx, dy := bx-ax, by-ay
+	if dx != 0 || dy != 0 {
+		t := ((x-ax)*dx + (y-ay)*dy) / (dx*dx + dy*dy)
+		switch {
+		case t > 1:
+			ax, ay = bx, by
+		case t > 0:
+			ax, ay = ax+dx*t, ay+dy*t
+		}
+	}
+	dx, dy = x-ax, y-ay
+	return dx*dx + dy*dy
+}
+
+// A polyLabelCell is a square cell with the center x, y and half the side h.
+// d is the distance from its center to the polygon and max the largest
+// distance to the polygon that a point within the cell can have.
+type polyLabelCell struct {
+	x, y, h, d, max float64
+}
+
+// A cellQueue is a priority queue of cells with the largest max first.
+type cellQueue []polyLabelCell
+
+func (q cellQueue) Len() int           { return len(q) }
+func (q cellQueue) Less(i, j int) bool { return q[i].max > q[j].max }
+func (q cellQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *cellQueue) Push(x any) { *q = append(*q, x.(polyLabelCell)) }
+
+func (q *cellQueue) Pop() any {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
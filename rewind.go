@@ -0,0 +1,61 @@
+package goodgeo
+
+// IsClockwise returns true if lr winds clockwise, that is if its signed area
+// is negative. Rings without area are not clockwise.
+func IsClockwise(lr *LinearRing) bool {
+	return doubleArea1(lr.flatCoords, 0, len(lr.flatCoords), lr.stride) < 0
+}
+
+// Rewind returns a copy of g with the rings of its polygons wound
+// consistently. If rfc7946 is true exterior rings are counter-clockwise and
+// holes clockwise, as required by RFC 7946, otherwise the other way around.
+// Linear rings are wound like exterior rings, geometry collections are
+// rewound member by member and other geometries are returned as they are.
+func Rewind(g T, rfc7946 bool) T {
+	switch g := g.(type) {
+	case *LinearRing:
+		g = g.Clone()
+		rewind1(g.flatCoords, 0, len(g.flatCoords), g.stride, !rfc7946)
+		return g
+	case *Polygon:
+		g = g.Clone()
+		rewind2(g.flatCoords, 0, g.ends, g.stride, rfc7946)
+		return g
+	case *MultiPolygon:
+		g = g.Clone()
+		offset := 0
+		for _, ends := range g.endss {
+			if len(ends) == 0 {
+				continue
+			}
+			rewind2(g.flatCoords, offset, ends, g.stride, rfc7946)
+			offset = ends[len(ends)-1]
+		}
+		return g
+	case *GeometryCollection:
+		gc := NewGeometryCollection().SetSRID(g.srid)
+		for _, g := range g.geoms {
+			gc.MustPush(Rewind(g, rfc7946))
+		}
+		return gc
+	default:
+		return g
+	}
+}
+
+// rewind1 reverses the ring from offset to end if it is not wound as wanted.
+// Rings without area are left as they are.
+func rewind1(flatCoords []float64, offset, end, stride int, clockwise bool) {
+	if doubleArea := doubleArea1(flatCoords, offset, end, stride); doubleArea != 0 && (doubleArea < 0) != clockwise {
+		reverse1(flatCoords, offset, end, stride)
+	}
+}
+
+// rewind2 winds the rings of a polygon, exterior counter-clockwise if rfc7946
+// and holes the other way.
+func rewind2(flatCoords []float64, offset int, ends []int, stride int, rfc7946 bool) {
+	for i, end := range ends {
+		rewind1(flatCoords, offset, end, stride, (i > 0) == rfc7946)
+		offset = end
+	}
+}
@@ -0,0 +1,103 @@
+package goodgeo
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestIsClockwise(t *testing.T) {
+	for i, tc := range []struct {
+		coords []Coord
+		want   bool
+	}{
+		{
+			coords: []Coord{{0, 0}, {1, 0}, {1, 1}, {0, 0}},
+			want:   false,
+		},
+		{
+			coords: []Coord{{0, 0}, {1, 1}, {1, 0}, {0, 0}},
+			want:   true,
+		},
+		{
+			coords: []Coord{{0, 0}, {1, 1}, {2, 2}, {0, 0}},
+			want:   false,
+		},
+		{
+			coords: nil,
+			want:   false,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tc.want, IsClockwise(NewLinearRing(XY).MustSetCoords(tc.coords)))
+		})
+	}
+}
+
+func TestRewind(t *testing.T) {
+	cw := []Coord{{0, 0}, {0, 4}, {4, 4}, {4, 0}, {0, 0}}
+	ccw := []Coord{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}
+	holeCW := []Coord{{1, 1}, {1, 2}, {2, 2}, {1, 1}}
+	holeCCW := []Coord{{1, 1}, {2, 2}, {1, 2}, {1, 1}}
+	for i, tc := range []struct {
+		g       T
+		rfc7946 bool
+		want    T
+	}{
+		{
+			g:       NewLinearRing(XY).MustSetCoords(cw),
+			rfc7946: true,
+			want:    NewLinearRing(XY).MustSetCoords(ccw),
+		},
+		{
+			g:       NewLinearRing(XY).MustSetCoords(ccw),
+			rfc7946: false,
+			want:    NewLinearRing(XY).MustSetCoords(cw),
+		},
+		{
+			g:       NewPolygon(XY).MustSetCoords([][]Coord{cw, holeCCW}).SetSRID(4326),
+			rfc7946: true,
+			want:    NewPolygon(XY).MustSetCoords([][]Coord{ccw, holeCW}).SetSRID(4326),
+		},
+		{
+			g:       NewPolygon(XY).MustSetCoords([][]Coord{ccw, holeCW}),
+			rfc7946: true,
+			want:    NewPolygon(XY).MustSetCoords([][]Coord{ccw, holeCW}),
+		},
+		{
+			g:       NewPolygon(XY).MustSetCoords([][]Coord{ccw, holeCW}),
+			rfc7946: false,
+			want:    NewPolygon(XY).MustSetCoords([][]Coord{cw, holeCCW}),
+		},
+		{
+			g:       NewPolygon(XYZ).MustSetCoords([][]Coord{{{0, 0, 1}, {0, 1, 2}, {1, 0, 3}, {0, 0, 1}}}),
+			rfc7946: true,
+			want:    NewPolygon(XYZ).MustSetCoords([][]Coord{{{0, 0, 1}, {1, 0, 3}, {0, 1, 2}, {0, 0, 1}}}),
+		},
+		{
+			g:       NewMultiPolygon(XY).MustSetCoords([][][]Coord{{ccw}, {}, {cw, holeCCW}}),
+			rfc7946: true,
+			want:    NewMultiPolygon(XY).MustSetCoords([][][]Coord{{ccw}, {}, {ccw, holeCW}}),
+		},
+		{
+			g: NewGeometryCollection().MustPush(
+				NewLineString(XY).MustSetCoords(cw),
+				NewPolygon(XY).MustSetCoords([][]Coord{cw}),
+			),
+			rfc7946: true,
+			want: NewGeometryCollection().MustPush(
+				NewLineString(XY).MustSetCoords(cw),
+				NewPolygon(XY).MustSetCoords([][]Coord{ccw}),
+			),
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tc.want, Rewind(tc.g, tc.rfc7946))
+		})
+	}
+
+	p := NewPolygon(XY).MustSetCoords([][]Coord{cw})
+	Rewind(p, true)
+	assert.Equal(t, [][]Coord{cw}, p.Coords())
+}
@@ -0,0 +1,114 @@
+package s2
+
+import "math"
+
+// A cell is a CellID together with its geometry, for testing it against
+// regions.
+type cell struct {
+	id     CellID
+	face   int
+	level  int
+	u, v   [2]float64
+	center point
+	// vertices are counter-clockwise, starting at the lower left corner
+	// in (u,v) coordinates.
+	vertices [4]point
+}
+
+func newCell(id CellID) *cell {
+	f, i, j, _ := id.faceIJOrientation()
+	c := &cell{id: id, face: f, level: id.Level(), center: id.center()}
+	size := 1 << (MaxLevel - c.level)
+	i, j = i&-size, j&-size
+	c.u = [2]float64{stToUV(ijToSTMin(i)), stToUV(ijToSTMin(i + size))}
+	c.v = [2]float64{stToUV(ijToSTMin(j)), stToUV(ijToSTMin(j + size))}
+	c.vertices = [4]point{
+		faceUVToXYZ(f, c.u[0], c.v[0]).normalize(),
+		faceUVToXYZ(f, c.u[1], c.v[0]).normalize(),
+		faceUVToXYZ(f, c.u[1], c.v[1]).normalize(),
+		faceUVToXYZ(f, c.u[0], c.v[1]).normalize(),
+	}
+	return c
+}
+
+// containsPoint returns true if p lies inside c or on its boundary.
+func (c *cell) containsPoint(p point) bool {
+	u, v, ok := faceXYZToUV(c.face, p)
+	if !ok {
+		return false
+	}
+	// Expand the bounds by the error of the conversion from (u,v) to (s,t)
+	// coordinates, so that every cell contains the points that map to it.
+	const margin = (1.125 + 0x1p-52) * 0x1p-52
+	return c.u[0]-margin <= u && u <= c.u[1]+margin && c.v[0]-margin <= v && v <= c.v[1]+margin
+}
+
+// crosses returns true if the edge ab crosses or touches the boundary of c.
+func (c *cell) crosses(a, b point) bool {
+	for k := range 4 {
+		if crossingSign(a, b, c.vertices[k], c.vertices[(k+1)%4]) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// poleMinLat is the lowest latitude of the faces that contain the poles.
+var poleMinLat = math.Asin(math.Sqrt(1./3)) - 0.5*dblEpsilon
+
+const dblEpsilon = 0x1p-52
+
+// rectBound returns the bounding rectangle of c.
+func (c *cell) rectBound() rect {
+	if c.level > 0 {
+		// Except for the faces, the extremes of latitude are attained at
+		// one pair of diagonally opposite vertices and those of longitude at
+		// the other. The vertex with the largest absolute latitude is the
+		// one closest to the axis of the face that points towards the pole.
+		var i, j int
+		if u := c.u[0] + c.u[1]; uAxisZ(c.face) == 0 && u < 0 || uAxisZ(c.face) != 0 && u > 0 {
+			i = 1
+		}
+		if v := c.v[0] + c.v[1]; vAxisZ(c.face) == 0 && v < 0 || vAxisZ(c.face) != 0 && v > 0 {
+			j = 1
+		}
+		vertex := func(i, j int) point {
+			return faceUVToXYZ(c.face, c.u[i], c.v[j])
+		}
+		lat := [2]float64{vertex(i, j).latitude(), vertex(1-i, 1-j).latitude()}
+		if lat[0] > lat[1] {
+			lat[0], lat[1] = lat[1], lat[0]
+		}
+		lng := emptyInterval().addPoint(vertex(i, 1-j).longitude()).addPoint(vertex(1-i, j).longitude())
+		return rect{lat: lat, lng: lng}.expanded(2*dblEpsilon, 2*dblEpsilon).polarClosure()
+	}
+
+	// The four faces around the equator extend to ±45 degrees of latitude
+	// at the midpoints of their edges, the polar faces down to ±35.26
+	// degrees at their vertices.
+	var r rect
+	switch c.face {
+	case 0:
+		r = rect{lat: [2]float64{-math.Pi / 4, math.Pi / 4}, lng: interval{-math.Pi / 4, math.Pi / 4}}
+	case 1:
+		r = rect{lat: [2]float64{-math.Pi / 4, math.Pi / 4}, lng: interval{math.Pi / 4, 3 * math.Pi / 4}}
+	case 2:
+		r = rect{lat: [2]float64{poleMinLat, math.Pi / 2}, lng: fullInterval()}
+	case 3:
+		r = rect{lat: [2]float64{-math.Pi / 4, math.Pi / 4}, lng: interval{3 * math.Pi / 4, -3 * math.Pi / 4}}
+	case 4:
+		r = rect{lat: [2]float64{-math.Pi / 4, math.Pi / 4}, lng: interval{-3 * math.Pi / 4, -math.Pi / 4}}
+	default:
+		r = rect{lat: [2]float64{-math.Pi / 2, -poleMinLat}, lng: fullInterval()}
+	}
+	return r.expanded(dblEpsilon, 0)
+}
+
+// uAxisZ and vAxisZ return the z coordinate of the u and v axes of face.
+func uAxisZ(face int) float64 {
+	return [numFaces]float64{0, 0, 0, -1, -1, 0}[face]
+}
+
+func vAxisZ(face int) float64 {
+	return [numFaces]float64{1, 1, 0, 0, 0, 0}[face]
+}
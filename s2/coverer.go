@@ -0,0 +1,248 @@
+package s2
+
+import (
+	"container/heap"
+	"slices"
+
+	"github.com/matoous/goodgeo"
+)
+
+// A RegionCoverer approximates regions with sets of cells. The cells of a
+// covering are between MinLevel and MaxLevel, their level minus MinLevel
+// is a multiple of LevelMod, and there are at most MaxCells of them unless
+// the region spans several faces or MinLevel forces more cells.
+//
+// Coverings are computed greedily from the faces down: the cell that
+// intersects the fewest of its children is subdivided first, as long as the
+// covering keeps within MaxCells.
+type RegionCoverer struct {
+	// MinLevel is the level of the largest cells used.
+	MinLevel int
+	// MaxLevel is the level of the smallest cells used.
+	MaxLevel int
+	// LevelMod, between 1 and 3, restricts the levels used to every
+	// first, second or third level from MinLevel, which makes the
+	// quadtree behave as if it had a branching factor of 4, 16 or 64.
+	LevelMod int
+	// MaxCells is the desired maximum number of cells.
+	MaxCells int
+}
+
+// NewRegionCoverer returns a RegionCoverer with the defaults of the
+// reference implementation: all levels and at most eight cells.
+func NewRegionCoverer() *RegionCoverer {
+	return &RegionCoverer{
+		MinLevel: 0,
+		MaxLevel: MaxLevel,
+		LevelMod: 1,
+		MaxCells: 8,
+	}
+}
+
+// Covering returns the cells, in ascending order, that cover g, which may
+// be any geometry except a [goodgeo.LinearRing]. Cells that lie completely
+// inside polygons are returned at the lowest level possible.
+func (rc *RegionCoverer) Covering(g goodgeo.T) ([]CellID, error) {
+	s, err := newShape(g)
+	if err != nil {
+		return nil, err
+	}
+	if s.empty() {
+		return nil, nil
+	}
+	return rc.covering(s), nil
+}
+
+// CoveringBounds returns the cells, in ascending order, that cover b, which
+// is taken to be a rectangle of longitudes and latitudes. If the minimum
+// longitude of b is greater than its maximum longitude, b is taken to cross
+// the antimeridian.
+func (rc *RegionCoverer) CoveringBounds(b *goodgeo.Bounds) []CellID {
+	if b.Layout() == goodgeo.NoLayout || b.Min(1) > b.Max(1) {
+		return nil
+	}
+	return rc.covering(rectFromBounds(b))
+}
+
+func (rc *RegionCoverer) covering(r region) []CellID {
+	c := &coverer{
+		minLevel: max(0, min(MaxLevel, rc.MinLevel)),
+		maxLevel: max(0, min(MaxLevel, rc.MaxLevel)),
+		levelMod: max(1, min(3, rc.LevelMod)),
+		maxCells: rc.MaxCells,
+		region:   r,
+	}
+	for face := range numFaces {
+		c.addCandidate(c.newCandidate(newCell(cellIDFromFace(face))))
+	}
+	for c.pq.Len() > 0 {
+		cand := heap.Pop(&c.pq).(*candidate)
+		if cand.cell.level < c.minLevel || len(cand.children) == 1 || len(c.result)+c.pq.Len()+len(cand.children) <= c.maxCells {
+			for _, child := range cand.children {
+				c.addCandidate(child)
+			}
+		} else {
+			cand.terminal = true
+			c.addCandidate(cand)
+		}
+	}
+	cells := normalize(c.result)
+	if c.minLevel > 0 || c.levelMod > 1 {
+		cells = denormalize(cells, c.minLevel, c.levelMod)
+	}
+	return cells
+}
+
+type coverer struct {
+	minLevel, maxLevel, levelMod, maxCells int
+	region                                 region
+	result                                 []CellID
+	pq                                     priorityQueue
+}
+
+// A candidate is a cell that intersects the region and may be subdivided.
+type candidate struct {
+	cell *cell
+	// terminal is true if the cell is not to be subdivided.
+	terminal bool
+	// children are the descendants levelMod levels down that intersect
+	// the region.
+	children []*candidate
+	priority int
+}
+
+// newCandidate returns the candidate of c, or nil if c does not intersect
+// the region.
+func (c *coverer) newCandidate(cl *cell) *candidate {
+	if !c.region.intersectsCell(cl) {
+		return nil
+	}
+	cand := &candidate{cell: cl}
+	if cl.level >= c.minLevel && (cl.level+c.levelMod > c.maxLevel || c.region.containsCell(cl)) {
+		cand.terminal = true
+	}
+	return cand
+}
+
+// expandChildren adds the descendants of cl numLevels down that intersect
+// the region to cand and returns how many of them are terminal.
+func (c *coverer) expandChildren(cand *candidate, cl *cell, numLevels int) int {
+	numLevels--
+	var numTerminals int
+	for _, id := range cl.id.children() {
+		child := newCell(id)
+		if numLevels > 0 {
+			if c.region.intersectsCell(child) {
+				numTerminals += c.expandChildren(cand, child, numLevels)
+			}
+			continue
+		}
+		if childCand := c.newCandidate(child); childCand != nil {
+			cand.children = append(cand.children, childCand)
+			if childCand.terminal {
+				numTerminals++
+			}
+		}
+	}
+	return numTerminals
+}
+
+// addCandidate adds terminal candidates to the result and queues the others
+// to be subdivided.
+func (c *coverer) addCandidate(cand *candidate) {
+	if cand == nil {
+		return
+	}
+	if cand.terminal {
+		c.result = append(c.result, cand.cell.id)
+		return
+	}
+	numLevels := c.levelMod
+	if cand.cell.level < c.minLevel {
+		numLevels = 1
+	}
+	numTerminals := c.expandChildren(cand, cand.cell, numLevels)
+	shift := 2 * c.levelMod
+	switch {
+	case len(cand.children) == 0:
+	case numTerminals == 1<<shift && cand.cell.level >= c.minLevel:
+		// The cell is covered by its children, use it instead.
+		cand.terminal = true
+		c.addCandidate(cand)
+	default:
+		// Cells with fewer children, and fewer of those terminal, are
+		// subdivided first.
+		cand.priority = -((cand.cell.level<<shift+len(cand.children))<<shift + numTerminals)
+		heap.Push(&c.pq, cand)
+	}
+}
+
+type priorityQueue []*candidate
+
+func (pq priorityQueue) Len() int           { return len(pq) }
+func (pq priorityQueue) Less(i, j int) bool { return pq[i].priority > pq[j].priority }
+func (pq priorityQueue) Swap(i, j int)      { pq[i], pq[j] = pq[j], pq[i] }
+
+func (pq *priorityQueue) Push(x any) {
+	*pq = append(*pq, x.(*candidate))
+}
+
+func (pq *priorityQueue) Pop() any {
+	item := (*pq)[len(*pq)-1]
+	*pq = (*pq)[:len(*pq)-1]
+	return item
+}
+
+// normalize sorts cells, removes the cells that are contained by others and
+// replaces groups of four siblings by their parent.
+func normalize(cells []CellID) []CellID {
+	slices.Sort(cells)
+	out := make([]CellID, 0, len(cells))
+	for _, id := range cells {
+		if n := len(out); n > 0 && out[n-1].Contains(id) {
+			continue
+		}
+		for len(out) > 0 && id.Contains(out[len(out)-1]) {
+			out = out[:len(out)-1]
+		}
+		for n := len(out); n >= 3 && areSiblings(out[n-3], out[n-2], out[n-1], id); n = len(out) {
+			out = out[:n-3]
+			id = id.immediateParent()
+		}
+		out = append(out, id)
+	}
+	return out
+}
+
+// areSiblings returns true if a, b, c and d are the four children of a
+// cell.
+func areSiblings(a, b, c, d CellID) bool {
+	if a^b^c != d {
+		return false
+	}
+	mask := d.lsb() << 1
+	mask = ^(mask + mask<<1)
+	m := uint64(d) & mask
+	return uint64(a)&mask == m && uint64(b)&mask == m && uint64(c)&mask == m && !d.isFace()
+}
+
+// denormalize replaces the cells coarser than minLevel, or at a level not
+// allowed by levelMod, by their descendants at the next allowed level.
+func denormalize(cells []CellID, minLevel, levelMod int) []CellID {
+	var out []CellID
+	for _, id := range cells {
+		level := id.Level()
+		newLevel := max(level, minLevel)
+		if levelMod > 1 {
+			newLevel = min(MaxLevel, newLevel+(MaxLevel-(newLevel-minLevel))%levelMod)
+		}
+		if newLevel == level {
+			out = append(out, id)
+			continue
+		}
+		for child := id.childBeginAtLevel(newLevel); child != id.childEndAtLevel(newLevel); child = child.next() {
+			out = append(out, child)
+		}
+	}
+	return out
+}
@@ -0,0 +1,331 @@
+package s2
+
+import (
+	"math"
+
+	"github.com/matoous/goodgeo"
+)
+
+// An interval is a closed interval of longitudes in radians. An interval
+// with lo > hi is inverted and crosses the antimeridian. The full interval
+// is [-π, π] and the empty interval is [π, -π].
+type interval struct {
+	lo, hi float64
+}
+
+func emptyInterval() interval {
+	return interval{math.Pi, -math.Pi}
+}
+
+func fullInterval() interval {
+	return interval{-math.Pi, math.Pi}
+}
+
+// intervalFromPointPair returns the shorter interval that contains a and b.
+func intervalFromPointPair(a, b float64) interval {
+	if a == -math.Pi {
+		a = math.Pi
+	}
+	if b == -math.Pi {
+		b = math.Pi
+	}
+	if positiveDistance(a, b) <= math.Pi {
+		return interval{a, b}
+	}
+	return interval{b, a}
+}
+
+// positiveDistance returns the distance from a to b going east.
+func positiveDistance(a, b float64) float64 {
+	d := b - a
+	if d >= 0 {
+		return d
+	}
+	return (b + math.Pi) - (a - math.Pi)
+}
+
+func (i interval) isEmpty() bool {
+	return i.lo == math.Pi && i.hi == -math.Pi
+}
+
+func (i interval) isFull() bool {
+	return i.lo == -math.Pi && i.hi == math.Pi
+}
+
+func (i interval) isInverted() bool {
+	return i.lo > i.hi
+}
+
+func (i interval) center() float64 {
+	c := 0.5 * (i.lo + i.hi)
+	switch {
+	case !i.isInverted():
+		return c
+	case c <= 0:
+		return c + math.Pi
+	default:
+		return c - math.Pi
+	}
+}
+
+func (i interval) length() float64 {
+	l := i.hi - i.lo
+	if l >= 0 {
+		return l
+	}
+	if l += 2 * math.Pi; l > 0 {
+		return l
+	}
+	return -1
+}
+
+func (i interval) fastContains(p float64) bool {
+	if i.isInverted() {
+		return (p >= i.lo || p <= i.hi) && !i.isEmpty()
+	}
+	return p >= i.lo && p <= i.hi
+}
+
+func (i interval) contains(p float64) bool {
+	if p == -math.Pi {
+		p = math.Pi
+	}
+	return i.fastContains(p)
+}
+
+func (i interval) containsInterval(o interval) bool {
+	if i.isInverted() {
+		if o.isInverted() {
+			return o.lo >= i.lo && o.hi <= i.hi
+		}
+		return (o.lo >= i.lo || o.hi <= i.hi) && !i.isEmpty()
+	}
+	if o.isInverted() {
+		return i.isFull() || o.isEmpty()
+	}
+	return o.lo >= i.lo && o.hi <= i.hi
+}
+
+func (i interval) intersects(o interval) bool {
+	if i.isEmpty() || o.isEmpty() {
+		return false
+	}
+	if i.isInverted() {
+		return o.isInverted() || o.lo <= i.hi || o.hi >= i.lo
+	}
+	if o.isInverted() {
+		return o.lo <= i.hi || o.hi >= i.lo
+	}
+	return o.lo <= i.hi && o.hi >= i.lo
+}
+
+// addPoint returns the smallest interval that contains i and p.
+func (i interval) addPoint(p float64) interval {
+	if p == -math.Pi {
+		p = math.Pi
+	}
+	switch {
+	case i.fastContains(p):
+		return i
+	case i.isEmpty():
+		return interval{p, p}
+	case positiveDistance(p, i.lo) < positiveDistance(i.hi, p):
+		return interval{p, i.hi}
+	default:
+		return interval{i.lo, p}
+	}
+}
+
+// expanded returns i with margin added on both sides.
+func (i interval) expanded(margin float64) interval {
+	if i.isEmpty() {
+		return i
+	}
+	if i.length()+2*margin+2*dblEpsilon >= 2*math.Pi {
+		return fullInterval()
+	}
+	r := interval{math.Remainder(i.lo-margin, 2*math.Pi), math.Remainder(i.hi+margin, 2*math.Pi)}
+	if r.lo <= -math.Pi {
+		r.lo = math.Pi
+	}
+	if r.hi == -math.Pi && r.lo != math.Pi {
+		r.hi = math.Pi
+	}
+	return r
+}
+
+// A rect is a rectangle of latitudes and longitudes in radians. Its edges
+// of constant latitude are not geodesics.
+type rect struct {
+	lat [2]float64
+	lng interval
+}
+
+// rectFromBounds returns the rectangle of b. If the minimum longitude of b
+// is greater than its maximum longitude, b is taken to cross the
+// antimeridian.
+func rectFromBounds(b *goodgeo.Bounds) rect {
+	r := rect{lat: [2]float64{
+		max(-90, b.Min(1)) * math.Pi / 180,
+		min(90, b.Max(1)) * math.Pi / 180,
+	}}
+	if b.Min(0) <= b.Max(0) && b.Max(0)-b.Min(0) >= 360 {
+		r.lng = fullInterval()
+		return r
+	}
+	r.lng = interval{
+		math.Remainder(b.Min(0), 360) * math.Pi / 180,
+		math.Remainder(b.Max(0), 360) * math.Pi / 180,
+	}
+	if r.lng.lo == -math.Pi {
+		r.lng.lo = math.Pi
+	}
+	if r.lng.hi == -math.Pi {
+		r.lng.hi = math.Pi
+	}
+	return r
+}
+
+func (r rect) isEmpty() bool {
+	return r.lat[0] > r.lat[1]
+}
+
+func (r rect) containsLatLng(lat, lng float64) bool {
+	return r.lat[0] <= lat && lat <= r.lat[1] && r.lng.contains(lng)
+}
+
+func (r rect) containsPoint(p point) bool {
+	return r.containsLatLng(p.latitude(), p.longitude())
+}
+
+func (r rect) contains(o rect) bool {
+	return (o.lat[0] > o.lat[1] || r.lat[0] <= o.lat[0] && o.lat[1] <= r.lat[1]) && r.lng.containsInterval(o.lng)
+}
+
+func (r rect) intersects(o rect) bool {
+	return max(r.lat[0], o.lat[0]) <= min(r.lat[1], o.lat[1]) && r.lng.intersects(o.lng)
+}
+
+// expanded returns r with the margins added on all sides, with the
+// latitudes clamped to the poles.
+func (r rect) expanded(latMargin, lngMargin float64) rect {
+	lat := [2]float64{r.lat[0] - latMargin, r.lat[1] + latMargin}
+	if lat[0] > lat[1] {
+		return rect{lat: lat, lng: emptyInterval()}
+	}
+	return rect{
+		lat: [2]float64{max(-math.Pi/2, lat[0]), min(math.Pi/2, lat[1])},
+		lng: r.lng.expanded(lngMargin),
+	}
+}
+
+// polarClosure returns r with the full range of longitudes if it touches
+// a pole.
+func (r rect) polarClosure() rect {
+	if r.lat[0] == -math.Pi/2 || r.lat[1] == math.Pi/2 {
+		r.lng = fullInterval()
+	}
+	return r
+}
+
+// vertex returns the k-th vertex of r, counter-clockwise starting at the
+// south-west corner.
+func (r rect) vertex(k int) point {
+	lat, lng := r.lat[k>>1], r.lng.lo
+	if k == 1 || k == 2 {
+		lng = r.lng.hi
+	}
+	return point{math.Cos(lat) * math.Cos(lng), math.Cos(lat) * math.Sin(lng), math.Sin(lat)}
+}
+
+func (r rect) center() point {
+	lat, lng := (r.lat[0]+r.lat[1])/2, r.lng.center()
+	return point{math.Cos(lat) * math.Cos(lng), math.Cos(lat) * math.Sin(lng), math.Sin(lat)}
+}
+
+func (r rect) containsCell(c *cell) bool {
+	return !r.isEmpty() && r.contains(c.rectBound())
+}
+
+func (r rect) intersectsCell(c *cell) bool {
+	// Once the cases where one contains the other are disposed of, the
+	// regions intersect if and only if their boundaries do.
+	if r.isEmpty() {
+		return false
+	}
+	if r.containsPoint(c.center) || c.containsPoint(r.center()) {
+		return true
+	}
+	if !r.intersects(c.rectBound()) {
+		return false
+	}
+	var lats, lngs [4]float64
+	for k, v := range c.vertices {
+		lats[k], lngs[k] = v.latitude(), v.longitude()
+		if r.containsLatLng(lats[k], lngs[k]) || c.containsPoint(r.vertex(k)) {
+			return true
+		}
+	}
+	// Two of the edges of the rectangle are curved, so the edges of the
+	// cell are tested against each of them separately.
+	for k := range 4 {
+		edgeLng := intervalFromPointPair(lngs[k], lngs[(k+1)%4])
+		if !r.lng.intersects(edgeLng) {
+			continue
+		}
+		a, b := c.vertices[k], c.vertices[(k+1)%4]
+		if edgeLng.contains(r.lng.lo) && intersectsLngEdge(a, b, r.lat, r.lng.lo) ||
+			edgeLng.contains(r.lng.hi) && intersectsLngEdge(a, b, r.lat, r.lng.hi) ||
+			intersectsLatEdge(a, b, r.lat[0], r.lng) ||
+			intersectsLatEdge(a, b, r.lat[1], r.lng) {
+			return true
+		}
+	}
+	return false
+}
+
+// intersectsLngEdge returns true if the edge ab crosses the meridian at lng
+// between the latitudes lat.
+func intersectsLngEdge(a, b point, lat [2]float64, lng float64) bool {
+	c := point{math.Cos(lat[0]) * math.Cos(lng), math.Cos(lat[0]) * math.Sin(lng), math.Sin(lat[0])}
+	d := point{math.Cos(lat[1]) * math.Cos(lng), math.Cos(lat[1]) * math.Sin(lng), math.Sin(lat[1])}
+	return crossingSign(a, b, c, d) > 0
+}
+
+// intersectsLatEdge returns true if the edge ab crosses the parallel at lat
+// within the longitudes lng.
+func intersectsLatEdge(a, b point, lat float64, lng interval) bool {
+	// The normal of the plane of ab, pointing north.
+	z := a.cross(b).normalize()
+	if z.z < 0 {
+		z = z.mul(-1)
+	}
+	// Complete it to an orthonormal frame in which x points to where the
+	// great circle of ab reaches its highest latitude.
+	y := z.cross(point{0, 0, 1}).normalize()
+	x := y.cross(z)
+
+	sinLat := math.Sin(lat)
+	if math.Abs(sinLat) >= x.z {
+		// The great circle does not reach lat.
+		return false
+	}
+	// The great circle crosses lat at ±theta from x.
+	cosTheta := sinLat / x.z
+	sinTheta := math.Sqrt(1 - cosTheta*cosTheta)
+	theta := math.Atan2(sinTheta, cosTheta)
+	abTheta := intervalFromPointPair(math.Atan2(a.dot(y), a.dot(x)), math.Atan2(b.dot(y), b.dot(x)))
+	if abTheta.contains(theta) {
+		p := x.mul(cosTheta).add(y.mul(sinTheta))
+		if lng.contains(math.Atan2(p.y, p.x)) {
+			return true
+		}
+	}
+	if abTheta.contains(-theta) {
+		p := x.mul(cosTheta).add(y.mul(-sinTheta))
+		if lng.contains(math.Atan2(p.y, p.x)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,253 @@
+package s2
+
+import "github.com/matoous/goodgeo"
+
+// A region is a set of points on the sphere that can be approximated by
+// cells.
+type region interface {
+	// intersectsCell returns true if the region may intersect c.
+	intersectsCell(c *cell) bool
+	// containsCell returns true if the region contains c.
+	containsCell(c *cell) bool
+}
+
+// A loop is a ring of a polygon, without the closing vertex, oriented
+// counter-clockwise so that the interior is on the left.
+type loop struct {
+	vertices []point
+	// ref is a point inside the loop that containment is tested
+	// against.
+	ref point
+}
+
+// refOffset is the distance, in radians, of the reference point of a loop
+// from its longest edge.
+const refOffset = 1e-9
+
+// newLoop returns the loop of ring, which is in degrees, or nil if the ring
+// has fewer than three distinct vertices or no area.
+func newLoop(ring []goodgeo.Coord) *loop {
+	coords := make([]goodgeo.Coord, 0, len(ring))
+	for _, c := range ring {
+		if n := len(coords); n > 0 && coords[n-1][0] == c[0] && coords[n-1][1] == c[1] {
+			continue
+		}
+		coords = append(coords, c)
+	}
+	if n := len(coords); n > 1 && coords[0][0] == coords[n-1][0] && coords[0][1] == coords[n-1][1] {
+		coords = coords[:n-1]
+	}
+	if len(coords) < 3 {
+		return nil
+	}
+	// Rings may have either orientation, the interior is the side with
+	// the smaller area in longitude and latitude.
+	var area float64
+	for i, c := range coords {
+		next := coords[(i+1)%len(coords)]
+		area += c[0]*next[1] - next[0]*c[1]
+	}
+	if area == 0 {
+		return nil
+	}
+	l := &loop{vertices: make([]point, len(coords))}
+	for i, c := range coords {
+		l.vertices[i] = pointFromCoord(c)
+	}
+	if area < 0 {
+		for i, j := 0, len(l.vertices)-1; i < j; i, j = i+1, j-1 {
+			l.vertices[i], l.vertices[j] = l.vertices[j], l.vertices[i]
+		}
+	}
+
+	// The reference point lies just left of the midpoint of the longest
+	// edge.
+	var longest int
+	var longestDist float64
+	for i, a := range l.vertices {
+		b := l.vertices[(i+1)%len(l.vertices)]
+		if d := a.dot(b); i == 0 || d < longestDist {
+			longest, longestDist = i, d
+		}
+	}
+	a, b := l.vertices[longest], l.vertices[(longest+1)%len(l.vertices)]
+	l.ref = a.add(b).normalize().add(a.cross(b).normalize().mul(refOffset)).normalize()
+	return l
+}
+
+// edge returns the i-th edge of l.
+func (l *loop) edge(i int) (point, point) {
+	return l.vertices[i], l.vertices[(i+1)%len(l.vertices)]
+}
+
+// containsPoint returns true if p lies inside l, by counting the edges
+// crossed on the way from the reference point to p.
+func (l *loop) containsPoint(p point) bool {
+	inside := true
+	// Paths longer than a quarter of a great circle are split so that
+	// neither half is close to antipodal.
+	from := l.ref
+	if from.dot(p) < 0 {
+		mid := from.add(p)
+		if mid.norm() < 1e-6 {
+			mid = from.ortho()
+		}
+		mid = mid.normalize()
+		inside = l.crossings(from, mid) != inside
+		from = mid
+	}
+	return l.crossings(from, p) != inside
+}
+
+// crossings returns true if the edge ab crosses l an odd number of times.
+func (l *loop) crossings(a, b point) bool {
+	odd := false
+	for i := range l.vertices {
+		c, d := l.edge(i)
+		if crossingSign(a, b, c, d) > 0 {
+			odd = !odd
+		}
+	}
+	return odd
+}
+
+// A shape is a collection of polygons, lines and points.
+type shape struct {
+	// polygons are lists of loops, the first of which is the exterior.
+	polygons [][]*loop
+	lines    [][]point
+	points   []point
+}
+
+// newShape returns the shape of g.
+func newShape(g goodgeo.T) (*shape, error) {
+	s := &shape{}
+	if err := s.add(g); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *shape) add(g goodgeo.T) error {
+	switch g := g.(type) {
+	case *goodgeo.Point:
+		if !g.Empty() {
+			s.points = append(s.points, pointFromCoord(g.Coords()))
+		}
+	case *goodgeo.MultiPoint:
+		for _, c := range g.Coords() {
+			if c != nil {
+				s.points = append(s.points, pointFromCoord(c))
+			}
+		}
+	case *goodgeo.LineString:
+		s.addLine(g.Coords())
+	case *goodgeo.MultiLineString:
+		for _, line := range g.Coords() {
+			s.addLine(line)
+		}
+	case *goodgeo.Polygon:
+		s.addPolygon(g.Coords())
+	case *goodgeo.MultiPolygon:
+		for _, polygon := range g.Coords() {
+			s.addPolygon(polygon)
+		}
+	case *goodgeo.GeometryCollection:
+		for _, g := range g.Geoms() {
+			if err := s.add(g); err != nil {
+				return err
+			}
+		}
+	default:
+		return goodgeo.UnsupportedTypeError{Value: g}
+	}
+	return nil
+}
+
+func (s *shape) addLine(coords []goodgeo.Coord) {
+	line := make([]point, len(coords))
+	for i, c := range coords {
+		line[i] = pointFromCoord(c)
+	}
+	if len(line) == 1 {
+		s.points = append(s.points, line[0])
+	} else if len(line) > 1 {
+		s.lines = append(s.lines, line)
+	}
+}
+
+func (s *shape) addPolygon(rings [][]goodgeo.Coord) {
+	var polygon []*loop
+	for i, ring := range rings {
+		l := newLoop(ring)
+		if l == nil {
+			if i == 0 {
+				return
+			}
+			continue
+		}
+		polygon = append(polygon, l)
+	}
+	if len(polygon) > 0 {
+		s.polygons = append(s.polygons, polygon)
+	}
+}
+
+func (s *shape) empty() bool {
+	return len(s.polygons) == 0 && len(s.lines) == 0 && len(s.points) == 0
+}
+
+// containsPoint returns true if p lies inside one of the polygons of s.
+func (s *shape) containsPoint(p point) bool {
+	for _, polygon := range s.polygons {
+		if polygon[0].containsPoint(p) && !holesContain(polygon[1:], p) {
+			return true
+		}
+	}
+	return false
+}
+
+func holesContain(holes []*loop, p point) bool {
+	for _, hole := range holes {
+		if hole.containsPoint(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// boundaryMeets returns true if an edge or a vertex of s meets c.
+func (s *shape) boundaryMeets(c *cell) bool {
+	for _, polygon := range s.polygons {
+		for _, l := range polygon {
+			for i, v := range l.vertices {
+				if c.containsPoint(v) || c.crosses(l.edge(i)) {
+					return true
+				}
+			}
+		}
+	}
+	for _, line := range s.lines {
+		for i, v := range line {
+			if c.containsPoint(v) || i > 0 && c.crosses(line[i-1], v) {
+				return true
+			}
+		}
+	}
+	for _, p := range s.points {
+		if c.containsPoint(p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *shape) intersectsCell(c *cell) bool {
+	return s.boundaryMeets(c) || s.containsPoint(c.center)
+}
+
+func (s *shape) containsCell(c *cell) bool {
+	// Only the interiors of polygons can contain a cell, and only if none
+	// of the edges reaches into it.
+	return len(s.polygons) > 0 && !s.boundaryMeets(c) && s.containsPoint(c.center)
+}
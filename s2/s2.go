@@ -0,0 +1,269 @@
+// Package s2 implements the cells of the S2 geometry library, see
+// http://s2geometry.io.
+//
+// S2 projects the sphere onto the six faces of a cube and divides each face
+// by a quadtree, so that a cell is identified by its face followed by up to
+// 30 levels of child positions. The cells of every level are ordered along
+// a Hilbert curve and packed into a 64-bit CellID, which makes ranges of ids
+// map to compact regions. This package returns the same ids as the
+// reference implementation.
+//
+// Coordinates are longitudes and latitudes in degrees, in that order. Edges
+// between them are geodesics.
+package s2
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/matoous/goodgeo"
+)
+
+// MaxLevel is the level of the smallest cells, which are about 1 cm across.
+const MaxLevel = 30
+
+// Errors returned by the functions of this package.
+var (
+	ErrInvalidCellID = errors.New("s2: invalid cell id")
+	ErrInvalidLatLng = errors.New("s2: latitude or longitude is not finite")
+	ErrInvalidLevel  = errors.New("s2: invalid level")
+)
+
+// A CellID is the 64-bit identifier of a cell.
+type CellID uint64
+
+// The layout of a CellID, from the most significant bit: three bits of
+// face, two bits of child position for each level of the cell, a single
+// set bit and zeros for the remaining levels.
+const (
+	numFaces = 6
+	faceBits = 3
+	posBits  = 2*MaxLevel + 1
+	maxSize  = 1 << MaxLevel
+)
+
+// CellIDFromCoord returns the cell at level that contains c.
+func CellIDFromCoord(c goodgeo.Coord, level int) (CellID, error) {
+	if level < 0 || level > MaxLevel {
+		return 0, ErrInvalidLevel
+	}
+	if len(c) < 2 || math.IsNaN(c[0]) || math.IsInf(c[0], 0) || math.IsNaN(c[1]) || math.IsInf(c[1], 0) {
+		return 0, ErrInvalidLatLng
+	}
+	return cellIDFromPoint(pointFromCoord(c)).parent(level), nil
+}
+
+// CellIDFromPoint returns the cell at level that contains p.
+func CellIDFromPoint(p *goodgeo.Point, level int) (CellID, error) {
+	return CellIDFromCoord(p.Coords(), level)
+}
+
+// ParseCellID parses the token of a cell, as returned by [CellID.String].
+func ParseCellID(s string) (CellID, error) {
+	if len(s) == 0 || len(s) > 16 {
+		return 0, ErrInvalidCellID
+	}
+	v, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, ErrInvalidCellID
+	}
+	id := CellID(v << (4 * (16 - len(s))))
+	if !id.IsValid() {
+		return 0, ErrInvalidCellID
+	}
+	return id, nil
+}
+
+// String returns the token of id: its hexadecimal representation without
+// trailing zeros. Tokens of cells sort in the same order as their ids.
+func (id CellID) String() string {
+	s := strconv.FormatUint(uint64(id), 16)
+	s = strings.TrimRight(strings.Repeat("0", 16-len(s))+s, "0")
+	if len(s) == 0 {
+		return "X"
+	}
+	return s
+}
+
+// IsValid returns true if id is the id of a cell.
+func (id CellID) IsValid() bool {
+	return id.Face() < numFaces && id.lsb()&0x1555555555555555 != 0
+}
+
+// Face returns the face of the cube that id lies on, between 0 and 5.
+func (id CellID) Face() int {
+	return int(uint64(id) >> posBits)
+}
+
+// Level returns the level of id, between 0 for the faces and [MaxLevel].
+func (id CellID) Level() int {
+	return MaxLevel - bits.TrailingZeros64(uint64(id))>>1
+}
+
+// IsLeaf returns true if id is a cell at [MaxLevel].
+func (id CellID) IsLeaf() bool {
+	return uint64(id)&1 != 0
+}
+
+// Parent returns the ancestor of id at level, which must not be finer than
+// the level of id.
+func (id CellID) Parent(level int) (CellID, error) {
+	if !id.IsValid() {
+		return 0, ErrInvalidCellID
+	}
+	if level < 0 || level > id.Level() {
+		return 0, ErrInvalidLevel
+	}
+	return id.parent(level), nil
+}
+
+// Children returns the four children of id, in ascending order.
+func (id CellID) Children() ([4]CellID, error) {
+	if !id.IsValid() {
+		return [4]CellID{}, ErrInvalidCellID
+	}
+	if id.IsLeaf() {
+		return [4]CellID{}, ErrInvalidLevel
+	}
+	return id.children(), nil
+}
+
+// Contains returns true if other is id or one of its descendants.
+func (id CellID) Contains(other CellID) bool {
+	return id.RangeMin() <= other && other <= id.RangeMax()
+}
+
+// Intersects returns true if id contains other or other contains id.
+func (id CellID) Intersects(other CellID) bool {
+	return other.RangeMin() <= id.RangeMax() && other.RangeMax() >= id.RangeMin()
+}
+
+// RangeMin returns the first leaf cell contained by id.
+func (id CellID) RangeMin() CellID {
+	return id - CellID(id.lsb()-1)
+}
+
+// RangeMax returns the last leaf cell contained by id.
+func (id CellID) RangeMax() CellID {
+	return id + CellID(id.lsb()-1)
+}
+
+// Center returns the center of id.
+func (id CellID) Center() (goodgeo.Coord, error) {
+	if !id.IsValid() {
+		return nil, ErrInvalidCellID
+	}
+	return coordFromPoint(id.center()), nil
+}
+
+// Polygon returns the boundary of id, a quadrilateral with geodesic edges.
+// The longitudes of the ring are continuous, so that a cell that crosses
+// the antimeridian has longitudes beyond ±180. The two faces that contain
+// the poles are not representable as polygons in longitude and latitude.
+func (id CellID) Polygon() (*goodgeo.Polygon, error) {
+	if !id.IsValid() {
+		return nil, ErrInvalidCellID
+	}
+	c := newCell(id)
+	ring := make([]float64, 0, 10)
+	var prev float64
+	for k, v := range c.vertices {
+		lng := v.longitude() * 180 / math.Pi
+		if k > 0 {
+			// Unwrap the longitude relative to the previous vertex.
+			for lng-prev > 180 {
+				lng -= 360
+			}
+			for lng-prev < -180 {
+				lng += 360
+			}
+		}
+		ring = append(ring, lng, v.latitude()*180/math.Pi)
+		prev = lng
+	}
+	ring = append(ring, ring[0], ring[1])
+	return goodgeo.NewPolygonFlat(goodgeo.XY, ring, []int{len(ring)}), nil
+}
+
+// coordFromPoint returns the longitude and latitude of p in degrees.
+func coordFromPoint(p point) goodgeo.Coord {
+	return goodgeo.Coord{p.longitude() * 180 / math.Pi, p.latitude() * 180 / math.Pi}
+}
+
+// cellIDFromFace returns the cell of face.
+func cellIDFromFace(face int) CellID {
+	return CellID(uint64(face)<<posBits + lsbForLevel(0))
+}
+
+// cellIDFromPoint returns the leaf cell that contains p.
+func cellIDFromPoint(p point) CellID {
+	f := face(p)
+	u, v := validFaceXYZToUV(f, p)
+	return cellIDFromFaceIJ(f, stToIJ(uvToST(u)), stToIJ(uvToST(v)))
+}
+
+// lsb returns the lowest set bit of id.
+func (id CellID) lsb() uint64 {
+	return uint64(id) & -uint64(id)
+}
+
+// lsbForLevel returns the lowest set bit of the cells at level.
+func lsbForLevel(level int) uint64 {
+	return 1 << (2 * (MaxLevel - level))
+}
+
+func (id CellID) parent(level int) CellID {
+	lsb := lsbForLevel(level)
+	return CellID(uint64(id)&-lsb | lsb)
+}
+
+func (id CellID) immediateParent() CellID {
+	lsb := id.lsb() << 2
+	return CellID(uint64(id)&-lsb | lsb)
+}
+
+func (id CellID) isFace() bool {
+	return uint64(id)&(lsbForLevel(0)-1) == 0
+}
+
+func (id CellID) children() [4]CellID {
+	lsb := CellID(id.lsb())
+	first := id - lsb + lsb>>2
+	lsb >>= 1
+	return [4]CellID{first, first + lsb, first + 2*lsb, first + 3*lsb}
+}
+
+// childBeginAtLevel returns the first descendant of id at level.
+func (id CellID) childBeginAtLevel(level int) CellID {
+	return CellID(uint64(id) - id.lsb() + lsbForLevel(level))
+}
+
+// childEndAtLevel returns the cell after the last descendant of id at
+// level.
+func (id CellID) childEndAtLevel(level int) CellID {
+	return CellID(uint64(id) + id.lsb() + lsbForLevel(level))
+}
+
+// next returns the following cell at the level of id.
+func (id CellID) next() CellID {
+	return CellID(uint64(id) + id.lsb()<<1)
+}
+
+// center returns the center of id on the sphere.
+func (id CellID) center() point {
+	f, i, j, _ := id.faceIJOrientation()
+	// The center is at the corner shared by the children of the cell,
+	// in units of half a leaf cell.
+	delta := 0
+	switch {
+	case id.IsLeaf():
+		delta = 1
+	case (i^int(uint64(id)>>2))&1 != 0:
+		delta = 2
+	}
+	si, ti := 2*i+delta, 2*j+delta
+	return faceUVToXYZ(f, stToUV(float64(si)/(2*maxSize)), stToUV(float64(ti)/(2*maxSize))).normalize()
+}
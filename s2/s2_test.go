@@ -0,0 +1,199 @@
+package s2
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/matoous/goodgeo"
+)
+
+func TestCellIDFromCoord(t *testing.T) {
+	for i, tc := range []struct {
+		c     goodgeo.Coord
+		level int
+		want  string
+	}{
+		{c: goodgeo.Coord{-122.4194, 37.7749}, level: 30, want: "8085809e8e8d8c61"},
+		{c: goodgeo.Coord{-122.4194, 37.7749}, level: 10, want: "808581"},
+		{c: goodgeo.Coord{0, 0}, level: 0, want: "1"},
+		{c: goodgeo.Coord{0, 0}, level: 5, want: "1004"},
+		{c: goodgeo.Coord{139.6917, 35.6895}, level: 15, want: "60188cd4c"},
+		{c: goodgeo.Coord{-180, 0}, level: 12, want: "7000001"},
+		{c: goodgeo.Coord{0, 90}, level: 7, want: "50004"},
+		{c: goodgeo.Coord{0, -90}, level: 20, want: "b0000000001"},
+		{c: goodgeo.Coord{14.4378, 50.0755}, level: 23, want: "470b948f6ff04"},
+		{c: goodgeo.Coord{-46.6333, -23.5505}, level: 1, want: "94"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			id, err := CellIDFromCoord(tc.c, tc.level)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, id.String())
+			assert.Equal(t, tc.level, id.Level())
+			assert.True(t, id.IsValid())
+			parsed, err := ParseCellID(tc.want)
+			assert.NoError(t, err)
+			assert.Equal(t, id, parsed)
+		})
+	}
+}
+
+func TestCellIDPolygon(t *testing.T) {
+	id, err := ParseCellID("89c25")
+	assert.NoError(t, err)
+	p, err := id.Polygon()
+	assert.NoError(t, err)
+	want := []float64{
+		-74.217932561, 40.827706513,
+		-74.217932561, 40.510849005,
+		-73.841906341, 40.457710214,
+		-73.841906341, 40.774477568,
+		-74.217932561, 40.827706513,
+	}
+	assert.Equal(t, len(want), len(p.FlatCoords()))
+	for i, v := range p.FlatCoords() {
+		assert.True(t, math.Abs(v-want[i]) < 1e-9, "%d: %v != %v", i, v, want[i])
+	}
+	center, err := id.Center()
+	assert.NoError(t, err)
+	assert.True(t, math.Abs(center[0]+74.030012250) < 1e-9 && math.Abs(center[1]-40.643076629) < 1e-9)
+}
+
+func TestCellIDPolygonAntimeridian(t *testing.T) {
+	id, err := CellIDFromCoord(goodgeo.Coord{180, 0}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, id.Face())
+	p, err := id.Polygon()
+	assert.NoError(t, err)
+	b := p.Bounds()
+	assert.True(t, math.Abs(b.Min(0)-135) < 1e-9 && math.Abs(b.Max(0)-225) < 1e-9)
+}
+
+func TestHierarchy(t *testing.T) {
+	id, err := ParseCellID("89c25")
+	assert.NoError(t, err)
+	children, err := id.Children()
+	assert.NoError(t, err)
+	assert.Equal(t, [4]CellID{0x89c2440000000000, 0x89c24c0000000000, 0x89c2540000000000, 0x89c25c0000000000}, children)
+	for _, child := range children {
+		parent, err := child.Parent(id.Level())
+		assert.NoError(t, err)
+		assert.Equal(t, id, parent)
+		assert.True(t, id.Contains(child))
+		assert.True(t, child.Intersects(id))
+		assert.False(t, child.Contains(id))
+	}
+	assert.True(t, id.RangeMin().IsLeaf() && id.RangeMax().IsLeaf())
+	assert.False(t, children[0].Intersects(children[1]))
+
+	_, err = id.Parent(id.Level() + 1)
+	assert.IsError(t, err, ErrInvalidLevel)
+	_, err = id.RangeMin().Children()
+	assert.IsError(t, err, ErrInvalidLevel)
+}
+
+func TestRegionCovererPolygon(t *testing.T) {
+	sf := goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{
+		{{-122.51, 37.70}, {-122.35, 37.70}, {-122.35, 37.82}, {-122.51, 37.82}, {-122.51, 37.70}},
+	})
+	for i, tc := range []struct {
+		rc   *RegionCoverer
+		want []string
+	}{
+		{
+			rc:   NewRegionCoverer(),
+			want: []string{"80857fc", "808581", "808587", "808f79", "808f7d", "808f7f", "808f81", "808f821"},
+		},
+		{
+			rc:   &RegionCoverer{MinLevel: 8, MaxLevel: 14, LevelMod: 2, MaxCells: 10},
+			want: []string{"80857f9", "80857ff", "808581", "808587", "808f79", "808f7d", "808f7f", "808f81", "808f821"},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			cells, err := tc.rc.Covering(sf)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, tokens(cells))
+		})
+	}
+
+	// Cells at a fixed level cover the polygon and those inside the hole
+	// are left out.
+	rc := &RegionCoverer{MinLevel: 12, MaxLevel: 12, MaxCells: 1000}
+	cells, err := rc.Covering(sf)
+	assert.NoError(t, err)
+	assert.Equal(t, 59, len(cells))
+	withHole := goodgeo.NewPolygon(goodgeo.XY).MustSetCoords(append(sf.Coords(),
+		[]goodgeo.Coord{{-122.45, 37.74}, {-122.45, 37.78}, {-122.41, 37.78}, {-122.41, 37.74}, {-122.45, 37.74}},
+	))
+	holeCells, err := rc.Covering(withHole)
+	assert.NoError(t, err)
+	assert.True(t, len(holeCells) < len(cells))
+	center, err := CellIDFromCoord(goodgeo.Coord{-122.43, 37.76}, 12)
+	assert.NoError(t, err)
+	assert.False(t, containsCell(holeCells, center))
+	assert.True(t, containsCell(cells, center))
+}
+
+func TestRegionCovererLineString(t *testing.T) {
+	line := goodgeo.NewLineString(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{2.3522, 48.8566}, {-0.1278, 51.5074}})
+	cells, err := (&RegionCoverer{MaxLevel: 10, LevelMod: 1, MaxCells: 6}).Covering(line)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"47d8ab", "47df", "47e0ac", "47e7", "487604"}, tokens(cells))
+}
+
+func TestRegionCovererBounds(t *testing.T) {
+	for i, tc := range []struct {
+		b    *goodgeo.Bounds
+		want []string
+	}{
+		{
+			b:    goodgeo.NewBounds(goodgeo.XY).Set(5, 45, 11, 48),
+			want: []string{"477f", "479", "47ed", "47f3", "47f5"},
+		},
+		{
+			b:    goodgeo.NewBounds(goodgeo.XY).Set(3.1*180/math.Pi, -0.1*180/math.Pi, -3.1*180/math.Pi, 0.1*180/math.Pi),
+			want: []string{"6534", "655", "6fdc", "6ff", "701", "7024", "7ab", "7acc"},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tc.want, tokens(NewRegionCoverer().CoveringBounds(tc.b)))
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	_, err := CellIDFromCoord(goodgeo.Coord{0, 0}, MaxLevel+1)
+	assert.IsError(t, err, ErrInvalidLevel)
+	_, err = CellIDFromCoord(goodgeo.Coord{math.NaN(), 0}, 10)
+	assert.IsError(t, err, ErrInvalidLatLng)
+	for _, s := range []string{"", "X", "zz", "d", "6", "89c25000000000000"} {
+		_, err = ParseCellID(s)
+		assert.IsError(t, err, ErrInvalidCellID, s)
+	}
+	_, err = CellID(0).Polygon()
+	assert.IsError(t, err, ErrInvalidCellID)
+	assert.Equal(t, "X", CellID(0).String())
+
+	ring := goodgeo.NewLinearRing(goodgeo.XY).MustSetCoords([]goodgeo.Coord{{0, 0}, {1, 0}, {1, 1}, {0, 0}})
+	_, err = NewRegionCoverer().Covering(ring)
+	assert.IsError(t, err, goodgeo.UnsupportedTypeError{Value: ring})
+}
+
+func tokens(cells []CellID) []string {
+	s := make([]string, len(cells))
+	for i, id := range cells {
+		s[i] = id.String()
+	}
+	return s
+}
+
+func containsCell(cells []CellID, id CellID) bool {
+	for _, c := range cells {
+		if c.Contains(id) {
+			return true
+		}
+	}
+	return false
+}
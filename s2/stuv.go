@@ -0,0 +1,285 @@
+package s2
+
+import "math"
+
+// A point is a vector in three dimensions. Points on the sphere have unit
+// length.
+type point struct {
+	x, y, z float64
+}
+
+func (p point) add(q point) point {
+	return point{p.x + q.x, p.y + q.y, p.z + q.z}
+}
+
+func (p point) mul(m float64) point {
+	return point{m * p.x, m * p.y, m * p.z}
+}
+
+func (p point) dot(q point) float64 {
+	return p.x*q.x + p.y*q.y + p.z*q.z
+}
+
+func (p point) cross(q point) point {
+	return point{p.y*q.z - p.z*q.y, p.z*q.x - p.x*q.z, p.x*q.y - p.y*q.x}
+}
+
+func (p point) norm() float64 {
+	return math.Sqrt(p.dot(p))
+}
+
+// normalize returns p scaled to unit length, or p if it has zero length.
+func (p point) normalize() point {
+	n := p.norm()
+	if n == 0 {
+		return p
+	}
+	return p.mul(1 / n)
+}
+
+// ortho returns a unit vector orthogonal to p.
+func (p point) ortho() point {
+	switch {
+	case math.Abs(p.x) <= math.Abs(p.y) && math.Abs(p.x) <= math.Abs(p.z):
+		return p.cross(point{1, 0, 0}).normalize()
+	case math.Abs(p.y) <= math.Abs(p.z):
+		return p.cross(point{0, 1, 0}).normalize()
+	default:
+		return p.cross(point{0, 0, 1}).normalize()
+	}
+}
+
+// pointFromCoord returns the point on the unit sphere at the longitude and
+// latitude of c, in degrees.
+func pointFromCoord(c []float64) point {
+	lng, lat := c[0]*math.Pi/180, c[1]*math.Pi/180
+	return point{math.Cos(lat) * math.Cos(lng), math.Cos(lat) * math.Sin(lng), math.Sin(lat)}
+}
+
+// latitude returns the latitude of p in radians.
+func (p point) latitude() float64 {
+	return math.Atan2(p.z, math.Hypot(p.x, p.y))
+}
+
+// longitude returns the longitude of p in radians.
+func (p point) longitude() float64 {
+	return math.Atan2(p.y, p.x)
+}
+
+// det returns the determinant of a, b and c, which is positive if the
+// points are counter-clockwise. It is computed as (c×a)·b so that abc and
+// cba are never both counter-clockwise.
+func det(a, b, c point) float64 {
+	return c.cross(a).dot(b)
+}
+
+// crossingSign returns 1 if the edges ab and cd cross at a point interior
+// to both, -1 if they do not cross, and 0 if two of the points coincide or
+// three of them are collinear.
+func crossingSign(a, b, c, d point) int {
+	var pos, neg, zero bool
+	for _, s := range [4]float64{-det(a, b, c), det(a, b, d), -det(c, d, b), det(c, d, a)} {
+		switch {
+		case s > 0:
+			pos = true
+		case s < 0:
+			neg = true
+		default:
+			zero = true
+		}
+	}
+	switch {
+	case pos && neg:
+		return -1
+	case zero:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// The cube faces are projected onto the sphere with a quadratic transform
+// of the (s,t) coordinates in [0,1] into (u,v) coordinates in [-1,1], which
+// keeps the area of the cells within a factor of two of each other.
+
+func stToUV(s float64) float64 {
+	if s >= 0.5 {
+		return (1 / 3.) * (4*s*s - 1)
+	}
+	return (1 / 3.) * (1 - 4*(1-s)*(1-s))
+}
+
+func uvToST(u float64) float64 {
+	if u >= 0 {
+		return 0.5 * math.Sqrt(1+3*u)
+	}
+	return 1 - 0.5*math.Sqrt(1-3*u)
+}
+
+// ijToSTMin returns the s or t coordinate of the lower edge of the leaf
+// cell at i or j.
+func ijToSTMin(i int) float64 {
+	return float64(i) / maxSize
+}
+
+// stToIJ returns the i or j coordinate of the leaf cell containing s or t.
+func stToIJ(s float64) int {
+	return max(0, min(maxSize-1, int(math.Floor(maxSize*s))))
+}
+
+// face returns the face of the cube that p projects onto.
+func face(p point) int {
+	c := [3]float64{p.x, p.y, p.z}
+	var f int
+	switch {
+	case math.Abs(p.x) > math.Abs(p.y):
+		if math.Abs(p.x) > math.Abs(p.z) {
+			f = 0
+		} else {
+			f = 2
+		}
+	case math.Abs(p.y) > math.Abs(p.z):
+		f = 1
+	default:
+		f = 2
+	}
+	if c[f] < 0 {
+		f += 3
+	}
+	return f
+}
+
+// validFaceXYZToUV returns the (u,v) coordinates of p on face, which must be
+// the face p projects onto.
+func validFaceXYZToUV(face int, p point) (float64, float64) {
+	switch face {
+	case 0:
+		return p.y / p.x, p.z / p.x
+	case 1:
+		return -p.x / p.y, p.z / p.y
+	case 2:
+		return -p.x / p.z, -p.y / p.z
+	case 3:
+		return p.z / p.x, p.y / p.x
+	case 4:
+		return p.z / p.y, -p.x / p.y
+	default:
+		return -p.y / p.z, -p.x / p.z
+	}
+}
+
+// faceXYZToUV returns the (u,v) coordinates of p on face, or false if p
+// lies in the opposite hemisphere.
+func faceXYZToUV(face int, p point) (float64, float64, bool) {
+	var w float64
+	switch face {
+	case 0, 3:
+		w = p.x
+	case 1, 4:
+		w = p.y
+	default:
+		w = p.z
+	}
+	if face < 3 && w <= 0 || face >= 3 && w >= 0 {
+		return 0, 0, false
+	}
+	u, v := validFaceXYZToUV(face, p)
+	return u, v, true
+}
+
+// faceUVToXYZ returns the point, not necessarily of unit length, at (u,v)
+// on face.
+func faceUVToXYZ(face int, u, v float64) point {
+	switch face {
+	case 0:
+		return point{1, u, v}
+	case 1:
+		return point{-u, 1, v}
+	case 2:
+		return point{-u, -v, 1}
+	case 3:
+		return point{-1, -v, -u}
+	case 4:
+		return point{v, -1, -u}
+	default:
+		return point{v, u, -1}
+	}
+}
+
+// The Hilbert curve that orders the cells of a face is traversed by lookup
+// tables that map four bits of i and j and an orientation to eight bits of
+// position and a new orientation, and back.
+const (
+	lookupBits = 4
+	swapMask   = 0x01
+	invertMask = 0x02
+)
+
+var (
+	posToIJ = [4][4]int{
+		{0, 1, 3, 2}, // canonical order:    (0,0), (0,1), (1,1), (1,0)
+		{0, 2, 3, 1}, // axes swapped:       (0,0), (1,0), (1,1), (0,1)
+		{3, 2, 0, 1}, // bits inverted:      (1,1), (1,0), (0,0), (0,1)
+		{3, 1, 0, 2}, // swapped & inverted: (1,1), (0,1), (0,0), (1,0)
+	}
+	posToOrientation = [4]int{swapMask, 0, 0, invertMask | swapMask}
+	lookupIJ         [1 << (2*lookupBits + 2)]int
+	lookupPos        [1 << (2*lookupBits + 2)]int
+)
+
+func init() {
+	for orientation := range 4 {
+		initLookupCell(0, 0, 0, orientation, 0, orientation)
+	}
+}
+
+func initLookupCell(level, i, j, origOrientation, pos, orientation int) {
+	if level == lookupBits {
+		ij := i<<lookupBits + j
+		lookupPos[ij<<2+origOrientation] = pos<<2 + orientation
+		lookupIJ[pos<<2+origOrientation] = ij<<2 + orientation
+		return
+	}
+	r := posToIJ[orientation]
+	for k := range 4 {
+		initLookupCell(level+1, i<<1+r[k]>>1, j<<1+r[k]&1, origOrientation, pos<<2+k, orientation^posToOrientation[k])
+	}
+}
+
+// cellIDFromFaceIJ returns the leaf cell at (i,j) on face.
+func cellIDFromFaceIJ(f, i, j int) CellID {
+	n := uint64(f) << (posBits - 1)
+	bits := f & swapMask
+	for k := 7; k >= 0; k-- {
+		const mask = 1<<lookupBits - 1
+		bits += (i >> (k * lookupBits) & mask) << (lookupBits + 2)
+		bits += (j >> (k * lookupBits) & mask) << 2
+		bits = lookupPos[bits]
+		n |= uint64(bits>>2) << (k * 2 * lookupBits)
+		bits &= swapMask | invertMask
+	}
+	return CellID(n*2 + 1)
+}
+
+// faceIJOrientation returns the face, the (i,j) coordinates of the leaf
+// cell at the lower left corner of id, and the orientation of the Hilbert
+// curve within id.
+func (id CellID) faceIJOrientation() (f, i, j, orientation int) {
+	f = id.Face()
+	orientation = f & swapMask
+	nbits := MaxLevel - 7*lookupBits
+	for k := 7; k >= 0; k-- {
+		orientation += int(uint64(id)>>(k*2*lookupBits+1)) & (1<<(2*nbits) - 1) << 2
+		orientation = lookupIJ[orientation]
+		i += orientation >> (lookupBits + 2) << (k * lookupBits)
+		j += orientation >> 2 & (1<<lookupBits - 1) << (k * lookupBits)
+		orientation &= swapMask | invertMask
+		nbits = lookupBits
+	}
+	// The position of the lowest set bit determines whether the last
+	// subdivision swapped the axes.
+	if id.lsb()&0x1111111111111110 != 0 {
+		orientation ^= swapMask
+	}
+	return f, i, j, orientation
+}
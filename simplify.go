@@ -1,12 +1,16 @@
 package goodgeo
 
 import (
+	"container/heap"
 	"math"
+	"sort"
 )
 
 // RamerDouglasPeucker simplifies a line string using the
 // [Ramer–Douglas–Peucker](https://en.wikipedia.org/wiki/Ramer%E2%80%93Douglas%E2%80%93Peucker_algorithm) algorithm.
-func RamerDouglasPeucker(ls *LineString, epsilon Meters) *LineString {
+// Distances are measured with [Haversine] unless another [DistanceModel] is given.
+func RamerDouglasPeucker(ls *LineString, epsilon Meters, model ...DistanceModel) *LineString {
+	m := distanceModel(model)
 	nls := NewLineString(ls.layout)
 
 	coords := ls.Coords()
@@ -23,7 +27,9 @@ func RamerDouglasPeucker(ls *LineString, epsilon Meters) *LineString {
 	}
 
 	simplified = append(simplified, coords[0])
-	ramerDouglasPeuckerRecursive(coords, epsilon, 0, n-1, &simplified)
+	ramerDouglasPeuckerRecursive(m, coords, epsilon, 0, n-1, func(i int) {
+		simplified = append(simplified, coords[i])
+	})
 	simplified = append(simplified, coords[n-1])
 
 	nls.MustSetCoords(simplified)
@@ -31,10 +37,11 @@ func RamerDouglasPeucker(ls *LineString, epsilon Meters) *LineString {
 }
 
 func ramerDouglasPeuckerRecursive(
+	m DistanceModel,
 	points []Coord,
 	epsilon Meters,
 	start, end int,
-	simplified *[]Coord,
+	keep func(i int),
 ) {
 	var (
 		largestIndex    = -1
@@ -42,7 +49,7 @@ func ramerDouglasPeuckerRecursive(
 	)
 
 	for i := start + 1; i < end; i++ {
-		d := Crossarc(points[start], points[end], points[i])
+		d := crossarc(m, points[start], points[end], points[i])
 		if d > largestDistance || largestIndex == -1 {
 			largestIndex = i
 			largestDistance = d
@@ -50,18 +57,31 @@ func ramerDouglasPeuckerRecursive(
 	}
 
 	if largestDistance > epsilon && largestIndex != -1 {
-		ramerDouglasPeuckerRecursive(points, epsilon, start, largestIndex, simplified)
-		*simplified = append(*simplified, points[largestIndex])
-		ramerDouglasPeuckerRecursive(points, epsilon, largestIndex, end, simplified)
+		ramerDouglasPeuckerRecursive(m, points, epsilon, start, largestIndex, keep)
+		keep(largestIndex)
+		ramerDouglasPeuckerRecursive(m, points, epsilon, largestIndex, end, keep)
 	}
 }
 
+// Crossarc returns the distance of c from the great circle segment between a
+// and b on a sphere with radius [EarthRadius], or the distance to a or b if
+// c lies beyond that end of the segment.
 func Crossarc(a, b, c Coord) Meters {
-	bear12 := Bearing(a, b)
-	bear13 := Bearing(a, c)
-	dis13 := Distance(a, c)
+	return crossarc(Haversine{}, a, b, c)
+}
+
+// crossarc returns the cross-track distance of c from the segment between a
+// and b. It takes the distances and bearings from the model but solves the
+// triangle with spherical trigonometry on a sphere with radius
+// [EarthRadius], so it is exact for [Haversine] and, for other models,
+// accurate as long as the distances are small compared to the radius of the
+// Earth.
+func crossarc(m DistanceModel, a, b, c Coord) Meters {
+	bear12 := float64(DegreesToRadians(m.Bearing(a, b)))
+	bear13 := float64(DegreesToRadians(m.Bearing(a, c)))
+	dis13 := m.Distance(a, c)
 
-	diff := math.Abs(float64(bear13 - bear12))
+	diff := math.Abs(bear13 - bear12)
 	if diff > math.Pi {
 		diff = 2*math.Pi - diff
 	}
@@ -70,14 +90,427 @@ func Crossarc(a, b, c Coord) Meters {
 		return dis13
 	}
 
-	dxt := math.Asin(math.Sin(float64(dis13)/EarthRadius)*math.Sin(float64(bear13-bear12))) * EarthRadius
+	dxt := math.Asin(math.Sin(float64(dis13)/EarthRadius)*math.Sin(bear13-bear12)) * EarthRadius
 
-	dis12 := Distance(a, b)
-	dis14 := math.Acos(math.Cos(float64(dis13)/EarthRadius)/math.Cos(dxt/EarthRadius)) * EarthRadius
+	dis12 := m.Distance(a, b)
+	dis14 := math.Acos(math.Min(1, math.Cos(float64(dis13)/EarthRadius)/math.Cos(dxt/EarthRadius))) * EarthRadius
 
-	if dis14 > float64(dis12*EarthRadius) {
-		return Distance(b, c)
+	if dis14 > float64(dis12) {
+		return m.Distance(b, c)
 	}
 
 	return Meters(math.Abs(dxt))
 }
+
+// VisvalingamWhyatt simplifies a line string using the
+// [Visvalingam–Whyatt](https://en.wikipedia.org/wiki/Visvalingam%E2%80%93Whyatt_algorithm)
+// algorithm. Vertices are removed, smallest first, while the triangle they
+// form with their neighbours has a smaller area than minArea. The area of a
+// triangle is measured on a sphere with radius [EarthRadius] and is never
+// less than the area of a triangle removed before it, so that removing a
+// vertex does not make its neighbours easier to remove. The first and the
+// last vertex are always kept.
+func VisvalingamWhyatt(ls *LineString, minArea SquareMeters) *LineString {
+	coords := ls.Coords()
+	if len(coords) == 0 {
+		return nil
+	}
+	return NewLineString(ls.layout).MustSetCoords(keptCoords(coords, visvalingamWhyatt(coords, minArea))).SetSRID(ls.srid)
+}
+
+// visvalingamWhyatt returns which of coords the Visvalingam–Whyatt algorithm
+// keeps.
+func visvalingamWhyatt(coords []Coord, minArea SquareMeters) []bool {
+	n := len(coords)
+	keep := make([]bool, n)
+	for i := range keep {
+		keep[i] = true
+	}
+	if n < 3 {
+		return keep
+	}
+
+	prev, next := make([]int, n), make([]int, n)
+	queue := make(triangleQueue, 0, n-2)
+	triangles := make([]*triangle, n)
+	for i := 1; i < n-1; i++ {
+		prev[i], next[i] = i-1, i+1
+		triangles[i] = &triangle{vertex: i, area: triangleArea(coords[i-1], coords[i], coords[i+1]), index: len(queue)}
+		queue = append(queue, triangles[i])
+	}
+	heap.Init(&queue)
+
+	for queue.Len() > 0 {
+		t := heap.Pop(&queue).(*triangle)
+		if t.area >= minArea {
+			break
+		}
+		keep[t.vertex] = false
+		p, q := prev[t.vertex], next[t.vertex]
+		next[p], prev[q] = q, p
+		for _, i := range []int{p, q} {
+			if i == 0 || i == n-1 {
+				continue
+			}
+			u := triangles[i]
+			u.area = max(t.area, triangleArea(coords[prev[i]], coords[i], coords[next[i]]))
+			heap.Fix(&queue, u.index)
+		}
+	}
+	return keep
+}
+
+// triangleArea returns the area of the triangle abc on a sphere with radius
+// [EarthRadius].
+func triangleArea(a, b, c Coord) SquareMeters {
+	flatCoords := []float64{a[0], a[1], b[0], b[1], c[0], c[1]}
+	return SquareMeters(math.Abs(sphericalRingArea(flatCoords, 2)))
+}
+
+// A triangle is a vertex in a [triangleQueue] with the area of the triangle
+// it forms with its neighbours.
+type triangle struct {
+	vertex int
+	area   SquareMeters
+	index  int
+}
+
+// triangleQueue is a min-heap of triangles ordered by area.
+type triangleQueue []*triangle
+
+func (q triangleQueue) Len() int { return len(q) }
+
+func (q triangleQueue) Less(i, j int) bool {
+	if q[i].area != q[j].area {
+		return q[i].area < q[j].area
+	}
+	return q[i].vertex < q[j].vertex
+}
+
+func (q triangleQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index, q[j].index = i, j
+}
+
+func (q *triangleQueue) Push(x any) {
+	t := x.(*triangle)
+	t.index = len(*q)
+	*q = append(*q, t)
+}
+
+func (q *triangleQueue) Pop() any {
+	old := *q
+	t := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return t
+}
+
+// keptCoords returns the coords that keep marks as kept.
+func keptCoords(coords []Coord, keep []bool) []Coord {
+	var kept []Coord
+	for i, c := range coords {
+		if keep[i] {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// SimplifyAlgorithm selects the algorithm used by [Simplify].
+type SimplifyAlgorithm int
+
+const (
+	// DouglasPeucker removes vertices like [RamerDouglasPeucker], keeping
+	// those further than SimplifyOptions.Tolerance from the simplified line.
+	DouglasPeucker SimplifyAlgorithm = iota
+	// Visvalingam removes vertices like [VisvalingamWhyatt], keeping those
+	// that form triangles of at least SimplifyOptions.MinArea.
+	Visvalingam
+)
+
+// SimplifyOptions configures [Simplify].
+type SimplifyOptions struct {
+	// Algorithm is the algorithm that chooses the vertices to remove.
+	Algorithm SimplifyAlgorithm
+	// Tolerance is the distance used by [DouglasPeucker].
+	Tolerance Meters
+	// MinArea is the triangle area used by [Visvalingam].
+	MinArea SquareMeters
+	// PreserveTopology restores removed vertices until no line or ring
+	// crosses itself or another one, no vertex ends up on the other side of
+	// a simplified segment, and no ring has fewer than three distinct
+	// vertices.
+	PreserveTopology bool
+	// Model measures distances, [Haversine] is used if it is nil.
+	Model DistanceModel
+}
+
+// Simplify simplifies the lines and rings of g one by one. The first and
+// last vertex of each of them is always kept. Without opts.PreserveTopology,
+// holes that collapse to fewer than three distinct vertices are removed, as
+// are polygons whose exterior ring collapses; with it, the lines and rings
+// of g are simplified together and keep their topology. Members of geometry
+// collections are simplified independently of each other, points are
+// returned as they are.
+//
+// The result has the layout and SRID of g.
+func Simplify(g T, opts SimplifyOptions) T {
+	switch g := g.(type) {
+	case *LineString:
+		coords := simplifyParts([][]Coord{g.Coords()}, []bool{false}, opts)
+		return NewLineString(g.layout).MustSetCoords(coords[0]).SetSRID(g.srid)
+	case *LinearRing:
+		coords := simplifyParts([][]Coord{g.Coords()}, []bool{true}, opts)
+		return NewLinearRing(g.layout).MustSetCoords(coords[0]).SetSRID(g.srid)
+	case *MultiLineString:
+		coords := g.Coords()
+		return NewMultiLineString(g.layout).MustSetCoords(simplifyParts(coords, make([]bool, len(coords)), opts)).SetSRID(g.srid)
+	case *Polygon:
+		coords := simplifyPolygons([][][]Coord{g.Coords()}, opts)
+		if len(coords) == 0 {
+			return NewPolygon(g.layout).SetSRID(g.srid)
+		}
+		return NewPolygon(g.layout).MustSetCoords(coords[0]).SetSRID(g.srid)
+	case *MultiPolygon:
+		return NewMultiPolygon(g.layout).MustSetCoords(simplifyPolygons(g.Coords(), opts)).SetSRID(g.srid)
+	case *GeometryCollection:
+		gc := NewGeometryCollection().SetSRID(g.srid)
+		for _, g := range g.geoms {
+			gc.MustPush(Simplify(g, opts))
+		}
+		return gc
+	default:
+		return g
+	}
+}
+
+// simplifyPolygons simplifies the rings of polygons together and removes
+// the rings that collapse.
+func simplifyPolygons(polygons [][][]Coord, opts SimplifyOptions) [][][]Coord {
+	var rings [][]Coord
+	for _, polygon := range polygons {
+		rings = append(rings, polygon...)
+	}
+	closed := make([]bool, len(rings))
+	for i := range closed {
+		closed[i] = true
+	}
+	return rebuildPolygons(polygons, simplifyParts(rings, closed, opts))
+}
+
+// rebuildPolygons returns polygons with their rings replaced, in order, by
+// rings, without the holes that collapsed to fewer than three distinct
+// vertices and without the polygons whose exterior ring did.
+func rebuildPolygons(polygons [][][]Coord, rings [][]Coord) [][][]Coord {
+	var simplified [][][]Coord
+	for _, polygon := range polygons {
+		var kept [][]Coord
+		for i := range polygon {
+			if len(rings[i]) >= 4 {
+				kept = append(kept, rings[i])
+			} else if i == 0 {
+				break
+			}
+		}
+		if len(kept) > 0 {
+			simplified = append(simplified, kept)
+		}
+		rings = rings[len(polygon):]
+	}
+	return simplified
+}
+
+// simplifyParts simplifies lines, or rings where closed is true.
+func simplifyParts(parts [][]Coord, closed []bool, opts SimplifyOptions) [][]Coord {
+	m := distanceModel([]DistanceModel{opts.Model})
+	keep := make([][]bool, len(parts))
+	for i, part := range parts {
+		switch opts.Algorithm {
+		case Visvalingam:
+			keep[i] = visvalingamWhyatt(part, opts.MinArea)
+		default:
+			keep[i] = make([]bool, len(part))
+			if n := len(part); n > 0 {
+				keep[i][0], keep[i][n-1] = true, true
+				ramerDouglasPeuckerRecursive(m, part, opts.Tolerance, 0, n-1, func(j int) { keep[i][j] = true })
+			}
+		}
+	}
+	if opts.PreserveTopology {
+		preserveTopology(m, parts, closed, keep)
+	}
+	simplified := make([][]Coord, len(parts))
+	for i, part := range parts {
+		simplified[i] = keptCoords(part, keep[i])
+	}
+	return simplified
+}
+
+// A simplifiedSection is the stretch of a part from one kept vertex to the
+// next.
+type simplifiedSection struct {
+	part, start, end int
+}
+
+// preserveTopology restores vertices of parts that keep marks as removed
+// until rings keep at least three distinct vertices and the simplified
+// parts neither intersect where the original ones do not nor move vertices
+// to the other side of a simplified segment. Each vertex restored is the
+// one furthest from the simplified segment that conflicts, so at worst the
+// original parts are restored.
+func preserveTopology(m DistanceModel, parts [][]Coord, closed []bool, keep [][]bool) {
+	for i, part := range parts {
+		if !closed[i] {
+			continue
+		}
+		for kept := countKept(keep[i]); kept < min(4, len(part)); kept++ {
+			var (
+				furthest = -1
+				distance Meters
+			)
+			for _, s := range sections(i, keep[i]) {
+				if j, d := furthestVertex(m, part, s); j != -1 && (furthest == -1 || d > distance) {
+					furthest, distance = j, d
+				}
+			}
+			if furthest == -1 {
+				break
+			}
+			keep[i][furthest] = true
+		}
+	}
+
+	for {
+		conflicts := topologyConflicts(parts, closed, keep)
+		if len(conflicts) == 0 {
+			return
+		}
+		for _, s := range conflicts {
+			if j, _ := furthestVertex(m, parts[s.part], s); j != -1 {
+				keep[s.part][j] = true
+			}
+		}
+	}
+}
+
+// topologyConflicts returns the simplified sections that intersect another
+// section, or that have a kept vertex between them and the original part.
+func topologyConflicts(parts [][]Coord, closed []bool, keep [][]bool) []simplifiedSection {
+	var (
+		segments   []segment
+		sectionsOf = make([][]simplifiedSection, len(parts))
+		vertices   []simplifiedSection
+	)
+	for i, part := range parts {
+		sectionsOf[i] = sections(i, keep[i])
+		for j, s := range sectionsOf[i] {
+			segments = append(segments, segment{a: part[s.start], b: part[s.end], part: i, index: j})
+			vertices = append(vertices, simplifiedSection{part: i, start: s.start, end: s.start})
+		}
+		if n := len(sectionsOf[i]); n > 0 {
+			if closed[i] && n > 1 {
+				segments[len(segments)-1].last = true
+			} else {
+				vertices = append(vertices, simplifiedSection{part: i, start: sectionsOf[i][n-1].end, end: sectionsOf[i][n-1].end})
+			}
+		}
+	}
+
+	conflicting := make(map[simplifiedSection]bool)
+	mark := func(s *segment) {
+		if section := sectionsOf[s.part][s.index]; section.end-section.start > 1 {
+			conflicting[section] = true
+		}
+	}
+	sweepSegments(segments, func(s, t *segment) {
+		relation, x := intersectSegments(s.a, s.b, t.a, t.b)
+		switch {
+		case relation == disjointSegments:
+			return
+		case adjacentSegments(s, t) && relation != overlappingSegments:
+			return
+		case relation == touchingSegments && (equals(x, s.a) || equals(x, s.b)) && (equals(x, t.a) || equals(x, t.b)):
+			return
+		}
+		mark(s)
+		mark(t)
+	})
+
+	coord := func(v simplifiedSection) Coord { return parts[v.part][v.start] }
+	sort.Slice(vertices, func(i, j int) bool { return coord(vertices[i])[0] < coord(vertices[j])[0] })
+	for i, part := range parts {
+		for _, s := range sectionsOf[i] {
+			if s.end-s.start < 2 || conflicting[s] {
+				continue
+			}
+			flatCoords := make([]float64, 0, 2*(s.end-s.start+1))
+			for _, c := range part[s.start : s.end+1] {
+				flatCoords = append(flatCoords, c[0], c[1])
+			}
+			b := NewBounds(XY).extendFlatCoords(flatCoords, 0, len(flatCoords), 2)
+			first := sort.Search(len(vertices), func(j int) bool { return coord(vertices[j])[0] >= b.Min(0) })
+			for _, v := range vertices[first:] {
+				c := coord(v)
+				if c[0] > b.Max(0) {
+					break
+				}
+				if c[1] < b.Min(1) || c[1] > b.Max(1) || v.part == i && (v.start == s.start || v.start == s.end) {
+					continue
+				}
+				if inside, _ := pointInRing(c, flatCoords, 0, len(flatCoords), 2); inside {
+					conflicting[s] = true
+					break
+				}
+			}
+		}
+	}
+
+	conflicts := make([]simplifiedSection, 0, len(conflicting))
+	for s := range conflicting {
+		conflicts = append(conflicts, s)
+	}
+	return conflicts
+}
+
+// sections returns the sections between the vertices of the part that keep
+// marks as kept.
+func sections(part int, keep []bool) []simplifiedSection {
+	var sections []simplifiedSection
+	start := -1
+	for i, k := range keep {
+		if !k {
+			continue
+		}
+		if start != -1 {
+			sections = append(sections, simplifiedSection{part: part, start: start, end: i})
+		}
+		start = i
+	}
+	return sections
+}
+
+// furthestVertex returns the vertex of part strictly inside the section s
+// that is furthest from the segment between its ends, and its distance, or
+// -1 if there is none.
+func furthestVertex(m DistanceModel, part []Coord, s simplifiedSection) (int, Meters) {
+	var (
+		furthest = -1
+		distance Meters
+	)
+	for i := s.start + 1; i < s.end; i++ {
+		if d := crossarc(m, part[s.start], part[s.end], part[i]); furthest == -1 || d > distance {
+			furthest, distance = i, d
+		}
+	}
+	return furthest, distance
+}
+
+func countKept(keep []bool) int {
+	var n int
+	for _, k := range keep {
+		if k {
+			n++
+		}
+	}
+	return n
+}
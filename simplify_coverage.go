@@ -0,0 +1,123 @@
+package goodgeo
+
+import "github.com/matoous/goodgeo/internal/arcs"
+
+// SimplifyCoverage simplifies the polygons and multipolygons of coverage
+// together, so that the boundaries they share stay shared and no gaps or
+// slivers open up between them. The rings are cut into arcs at the vertices
+// where the rings that pass through them part ways, each arc is simplified
+// once with opts, see [Simplify], and the rings are rebuilt from the
+// simplified arcs. Arcs keep their ends, and shared boundaries must have the
+// same vertices in all the rings that share them.
+//
+// Rings start at one of the ends of their arcs, or at their lowest vertex
+// if they are not cut, and keep their orientation. Collapsed rings are
+// handled like in [Simplify]. Other geometries are returned as they are,
+// the results have the layouts and SRIDs of the members of coverage.
+func SimplifyCoverage(coverage []T, opts SimplifyOptions) []T {
+	var (
+		polygons = make([][][][]Coord, len(coverage))
+		rings    [][]Coord
+	)
+	for i, g := range coverage {
+		switch g := g.(type) {
+		case *Polygon:
+			polygons[i] = [][][]Coord{g.Coords()}
+		case *MultiPolygon:
+			polygons[i] = g.Coords()
+		}
+		for _, polygon := range polygons[i] {
+			for _, ring := range polygon {
+				rings = append(rings, closeRing(dedupe(ring)))
+			}
+		}
+	}
+
+	paths := make([]arcs.Path, len(rings))
+	for i, ring := range rings {
+		points := make([]arcs.Point, len(ring))
+		for j, c := range ring {
+			points[j] = arcs.Point{c[0], c[1]}
+		}
+		paths[i] = arcs.Path{Points: points, Closed: true}
+	}
+	var (
+		junctions = arcs.Junctions(paths)
+		set       = arcs.NewSet()
+		distinct  [][]Coord
+		closed    []bool
+		refs      = make([][]int, len(rings))
+	)
+	for i, p := range paths {
+		for _, indices := range arcs.Cut(p, junctions) {
+			arc, points := make([]Coord, len(indices)), make([]arcs.Point, len(indices))
+			for j, k := range indices {
+				arc[j], points[j] = rings[i][k], p.Points[k]
+			}
+			index, added := set.Add(points)
+			if added {
+				distinct = append(distinct, arc)
+				closed = append(closed, len(points) > 1 && points[0] == points[len(points)-1])
+			}
+			refs[i] = append(refs[i], index)
+		}
+	}
+
+	simplified := simplifyParts(distinct, closed, opts)
+	for i := range rings {
+		var ring []Coord
+		for j, index := range refs[i] {
+			var arc []Coord
+			if index < 0 {
+				arc = reversedCoords(simplified[^index])
+			} else {
+				arc = simplified[index]
+			}
+			if j > 0 {
+				arc = arc[1:]
+			}
+			ring = append(ring, arc...)
+		}
+		rings[i] = ring
+	}
+
+	results := make([]T, len(coverage))
+	for i, g := range coverage {
+		n := 0
+		for _, polygon := range polygons[i] {
+			n += len(polygon)
+		}
+		memberRings := rings[:n]
+		rings = rings[n:]
+		switch g := g.(type) {
+		case *Polygon:
+			if coords := rebuildPolygons(polygons[i], memberRings); len(coords) > 0 {
+				results[i] = NewPolygon(g.layout).MustSetCoords(coords[0]).SetSRID(g.srid)
+			} else {
+				results[i] = NewPolygon(g.layout).SetSRID(g.srid)
+			}
+		case *MultiPolygon:
+			results[i] = NewMultiPolygon(g.layout).MustSetCoords(rebuildPolygons(polygons[i], memberRings)).SetSRID(g.srid)
+		default:
+			results[i] = g
+		}
+	}
+	return results
+}
+
+// closeRing returns ring with its first vertex repeated at its end if it is
+// not there already.
+func closeRing(ring []Coord) []Coord {
+	if n := len(ring); n > 0 && (ring[0][0] != ring[n-1][0] || ring[0][1] != ring[n-1][1]) {
+		return append(ring[:n:n], ring[0])
+	}
+	return ring
+}
+
+func reversedCoords(coords []Coord) []Coord {
+	reversed := make([]Coord, len(coords))
+	for i, c := range coords {
+		reversed[len(coords)-1-i] = c
+	}
+	return reversed
+}
@@ -0,0 +1,49 @@
+package goodgeo
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestSimplifyCoverage(t *testing.T) {
+	// The wiggly border shared by the squares is simplified once.
+	left := NewPolygon(XY).MustSetCoords([][]Coord{
+		{{0, 0}, {1, 0}, {1.01, 0.3}, {0.99, 0.6}, {1, 1}, {0, 1}, {0, 0}},
+	}).SetSRID(4326)
+	right := NewMultiPolygon(XY).MustSetCoords([][][]Coord{{
+		{{1, 0}, {2, 0}, {2, 1}, {1, 1}, {0.99, 0.6}, {1.01, 0.3}, {1, 0}},
+	}})
+	point := NewPoint(XY).MustSetCoords(Coord{0, 0})
+
+	got := SimplifyCoverage([]T{left, right, point}, SimplifyOptions{Tolerance: 5000})
+	assert.Equal(t, 3, len(got))
+	assert.Equal(t, 4326, got[0].SRID())
+	assert.Equal(t, [][]Coord{
+		{{1, 0}, {1, 1}, {0, 1}, {0, 0}, {1, 0}},
+	}, got[0].(*Polygon).Coords())
+	assert.Equal(t, [][][]Coord{{
+		{{1, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 0}},
+	}}, got[1].(*MultiPolygon).Coords())
+	assert.Equal(t, T(point), got[2])
+}
+
+func TestSimplifyCoverageIsland(t *testing.T) {
+	// The island fills the hole of the lake exactly.
+	island := []Coord{{0.4, 0.4}, {0.6, 0.4}, {0.61, 0.5}, {0.6, 0.6}, {0.4, 0.6}, {0.4, 0.4}}
+	lake := NewPolygon(XY).MustSetCoords([][]Coord{
+		{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}},
+		{{0.4, 0.4}, {0.4, 0.6}, {0.6, 0.6}, {0.61, 0.5}, {0.6, 0.4}, {0.4, 0.4}},
+	})
+
+	for _, opts := range []SimplifyOptions{
+		{Tolerance: 5000},
+		{Algorithm: Visvalingam, MinArea: 1e8},
+	} {
+		got := SimplifyCoverage([]T{lake, NewPolygon(XY).MustSetCoords([][]Coord{island})}, opts)
+		hole, shell := got[0].(*Polygon).Coords()[1], got[1].(*Polygon).Coords()[0]
+		assert.Equal(t, 5, len(shell))
+		assert.Equal(t, reversedCoords(shell), hole)
+		assert.Equal(t, Area(got[0])+Area(got[1]), Area(NewPolygon(XY).MustSetCoords([][]Coord{got[0].(*Polygon).Coords()[0]})))
+	}
+}
@@ -0,0 +1,151 @@
+package goodgeo
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestCrossarc(t *testing.T) {
+	a, b := Coord{0, 0}, Coord{2, 0}
+	// A point a third of the way along the great circle from c to d.
+	c, d := Coord{10, 20}, Coord{11, 21}
+	onSegment := Destination(NewPoint(XY).MustSetCoords(c), Distance(c, d)/3, Bearing(c, d)).Coords()
+
+	for i, tc := range []struct {
+		a, b, c Coord
+		want    Meters
+	}{
+		// Beside the segment, the distance is measured to the great circle.
+		{a: a, b: b, c: Coord{1, 1}, want: EarthRadius * math.Pi / 180},
+		{a: a, b: b, c: Coord{1, -1}, want: EarthRadius * math.Pi / 180},
+		// Past either end, the distance is measured to that end.
+		{a: a, b: b, c: Coord{3, 1}, want: Distance(b, Coord{3, 1})},
+		{a: a, b: b, c: Coord{-1, 1}, want: Distance(a, Coord{-1, 1})},
+		// Points on the segment, where rounding may push the cosine of the
+		// along-track distance past 1.
+		{a: a, b: b, c: Coord{1, 0}, want: 0},
+		{a: c, b: d, c: onSegment, want: 0},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := Crossarc(tc.a, tc.b, tc.c)
+			assert.False(t, math.IsNaN(float64(got)))
+			assert.True(t, math.Abs(float64(got-tc.want)) < 1e-6, "got %v, want %v", got, tc.want)
+		})
+	}
+}
+
+func TestVisvalingamWhyatt(t *testing.T) {
+	ls := NewLineString(XYZ).MustSetCoords([]Coord{{0, 0, 1}, {1, 0.001, 2}, {2, 0, 3}, {3, 1, 4}}).SetSRID(4326)
+
+	got := VisvalingamWhyatt(ls, 1e8)
+	assert.Equal(t, XYZ, got.Layout())
+	assert.Equal(t, 4326, got.SRID())
+	assert.Equal(t, []Coord{{0, 0, 1}, {2, 0, 3}, {3, 1, 4}}, got.Coords())
+
+	assert.Equal(t, ls.Coords(), VisvalingamWhyatt(ls, 1e6).Coords())
+	assert.Equal(t, []Coord{{0, 0, 1}, {3, 1, 4}}, VisvalingamWhyatt(ls, 1e12).Coords())
+}
+
+func TestSimplify(t *testing.T) {
+	// The tip of the exterior ring holds the hole inside the polygon.
+	shell := []Coord{{0, 0.3}, {0.5, 0.1}, {1, 0.3}, {1, 1}, {0, 1}, {0, 0.3}}
+	hole := []Coord{{0.45, 0.2}, {0.55, 0.2}, {0.5, 0.25}, {0.45, 0.2}}
+	island := []Coord{{2.45, 0.2}, {2.55, 0.2}, {2.5, 0.25}, {2.45, 0.2}}
+	polygon := NewPolygon(XY).MustSetCoords([][]Coord{shell, hole}).SetSRID(4326)
+	simplifiedShell := []Coord{{0, 0.3}, {1, 0.3}, {1, 1}, {0, 1}, {0, 0.3}}
+
+	for i, tc := range []struct {
+		g    T
+		opts SimplifyOptions
+		want any
+	}{
+		{
+			g:    polygon,
+			opts: SimplifyOptions{Tolerance: 30000},
+			want: [][]Coord{simplifiedShell},
+		},
+		{
+			g:    polygon,
+			opts: SimplifyOptions{Algorithm: Visvalingam, MinArea: 2e9},
+			want: [][]Coord{simplifiedShell},
+		},
+		{
+			g:    polygon,
+			opts: SimplifyOptions{Tolerance: 30000, PreserveTopology: true},
+			want: [][]Coord{shell, hole},
+		},
+		{
+			g:    polygon,
+			opts: SimplifyOptions{Algorithm: Visvalingam, MinArea: 2e9, PreserveTopology: true},
+			want: [][]Coord{shell, hole},
+		},
+		{
+			// Polygons whose exterior ring collapses are removed.
+			g:    NewMultiPolygon(XY).MustSetCoords([][][]Coord{{shell}, {island}}),
+			opts: SimplifyOptions{Tolerance: 30000},
+			want: [][][]Coord{{simplifiedShell}},
+		},
+		{
+			// Rings do not collapse.
+			g:    NewMultiPolygon(XY).MustSetCoords([][][]Coord{{shell}, {island}}),
+			opts: SimplifyOptions{Tolerance: 30000, PreserveTopology: true},
+			want: [][][]Coord{{simplifiedShell}, {island}},
+		},
+		{
+			// The straight line would cross the other one.
+			g: NewMultiLineString(XY).MustSetCoords([][]Coord{
+				{{0, 0}, {0.5, 0.1}, {1, 0}},
+				{{0.5, 0.05}, {0.5, -1}},
+			}),
+			opts: SimplifyOptions{Tolerance: 30000, PreserveTopology: true},
+			want: [][]Coord{{{0, 0}, {0.5, 0.1}, {1, 0}}, {{0.5, 0.05}, {0.5, -1}}},
+		},
+		{
+			g: NewMultiLineString(XY).MustSetCoords([][]Coord{
+				{{0, 0}, {0.5, 0.1}, {1, 0}},
+				{{0.5, 0.05}, {0.5, -1}},
+			}),
+			opts: SimplifyOptions{Tolerance: 30000},
+			want: [][]Coord{{{0, 0}, {1, 0}}, {{0.5, 0.05}, {0.5, -1}}},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := Simplify(tc.g, tc.opts)
+			assert.Equal(t, tc.g.Layout(), got.Layout())
+			assert.Equal(t, tc.g.SRID(), got.SRID())
+			switch got := got.(type) {
+			case *Polygon:
+				assert.Equal(t, tc.want, any(got.Coords()))
+			case *MultiPolygon:
+				assert.Equal(t, tc.want, any(got.Coords()))
+			case *MultiLineString:
+				assert.Equal(t, tc.want, any(got.Coords()))
+			}
+		})
+	}
+}
+
+func TestSimplifyPreserveTopologyIsValid(t *testing.T) {
+	// Removing the small bulge of the bottom edge would cut off the dent
+	// in the top one.
+	shell := []Coord{{2, 0.5}, {1, -0.1}, {0, 0.5}, {0, 0}, {1, -0.2}, {2, 0}, {2, 0.5}}
+	polygon := NewPolygon(XY).MustSetCoords([][]Coord{shell})
+	assert.Equal(t, 0, len(Validate(polygon)))
+
+	for i, opts := range []SimplifyOptions{
+		{Tolerance: 30000},
+		{Algorithm: Visvalingam, MinArea: 2.6e9},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := Simplify(polygon, opts).(*Polygon)
+			assert.NotEqual(t, 0, len(Validate(got)))
+
+			opts.PreserveTopology = true
+			got = Simplify(polygon, opts).(*Polygon)
+			assert.Equal(t, 0, len(Validate(got)))
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package transform
+
+import (
+	"math"
+
+	"github.com/matoous/goodgeo"
+	"github.com/matoous/goodgeo/geodesic"
+)
+
+// A LambertConformalConic is a Lambert conformal conic projection of an
+// ellipsoid with two standard parallels, computed as described in [EPSG
+// Guidance Note 7-2]. Setting both standard parallels to the same latitude
+// gives the variant with one standard parallel and a scale factor of one.
+// The constants of the cone are computed once by [NewLambertConformalConic].
+//
+// [EPSG Guidance Note 7-2]: https://epsg.org/guidance-notes.html
+type LambertConformalConic struct {
+	params LambertConformalConicParams
+	cone   lccCone
+}
+
+// LambertConformalConicParams are the parameters of a
+// [LambertConformalConic].
+type LambertConformalConicParams struct {
+	// Ellipsoid is the ellipsoid of the datum.
+	Ellipsoid geodesic.Ellipsoid
+	// CentralMeridian is the longitude of the false origin in degrees.
+	CentralMeridian float64
+	// LatitudeOfOrigin is the latitude of the false origin in degrees.
+	LatitudeOfOrigin float64
+	// StandardParallel1 and StandardParallel2 are the latitudes, in
+	// degrees, at which the scale is true.
+	StandardParallel1, StandardParallel2 float64
+	// FalseEasting and FalseNorthing are the coordinates of the false
+	// origin in meters.
+	FalseEasting, FalseNorthing float64
+	// EPSG is the SRID of the projected coordinates.
+	EPSG int
+}
+
+// NewLambertConformalConic returns the Lambert conformal conic projection
+// with params.
+func NewLambertConformalConic(params LambertConformalConicParams) LambertConformalConic {
+	return LambertConformalConic{params: params, cone: params.cone()}
+}
+
+// Params returns the parameters of p.
+func (p LambertConformalConic) Params() LambertConformalConicParams {
+	return p.params
+}
+
+// SRID returns the EPSG code of the parameters of p.
+func (p LambertConformalConic) SRID() int {
+	return p.params.EPSG
+}
+
+// Forward projects a longitude and latitude to easting and northing.
+func (p LambertConformalConic) Forward(c goodgeo.Coord) {
+	s := &p.cone
+	r := s.radius(c[1] * math.Pi / 180)
+	theta := s.n * (c[0] - p.params.CentralMeridian) * math.Pi / 180
+	c[0] = p.params.FalseEasting + r*math.Sin(theta)
+	c[1] = p.params.FalseNorthing + s.r0 - r*math.Cos(theta)
+}
+
+// Inverse maps an easting and northing back to a longitude and latitude.
+func (p LambertConformalConic) Inverse(c goodgeo.Coord) {
+	s := &p.cone
+	x, y := c[0]-p.params.FalseEasting, s.r0-(c[1]-p.params.FalseNorthing)
+	if s.n < 0 {
+		x, y = -x, -y
+	}
+	r := math.Copysign(math.Hypot(x, y), s.n)
+	theta := math.Atan2(x, y)
+	t := math.Pow(r/(s.a*s.f), 1/s.n)
+	lat := math.Pi/2 - 2*math.Atan(t)
+	for range 10 {
+		esinLat := s.e * math.Sin(lat)
+		next := math.Pi/2 - 2*math.Atan(t*math.Pow((1-esinLat)/(1+esinLat), s.e/2))
+		if math.Abs(next-lat) < 1e-14 {
+			lat = next
+			break
+		}
+		lat = next
+	}
+	c[0] = p.params.CentralMeridian + theta/s.n*180/math.Pi
+	c[1] = lat * 180 / math.Pi
+}
+
+// lccCone holds the constants of a Lambert conformal conic projection.
+type lccCone struct {
+	// e is the eccentricity.
+	e float64
+	// n is the cone constant.
+	n float64
+	// f is the radius of the parallel with t equal to one, in units of the
+	// equatorial radius, divided by the cone constant.
+	f float64
+	// a is the equatorial radius.
+	a float64
+	// r0 is the radius of the latitude of origin.
+	r0 float64
+}
+
+func (p LambertConformalConicParams) cone() lccCone {
+	s := lccCone{e: eccentricity(p.Ellipsoid.F), a: p.Ellipsoid.A}
+	lat1 := p.StandardParallel1 * math.Pi / 180
+	lat2 := p.StandardParallel2 * math.Pi / 180
+	m1, t1 := s.m(lat1), s.t(lat1)
+	if lat1 == lat2 {
+		s.n = math.Sin(lat1)
+	} else {
+		s.n = (math.Log(m1) - math.Log(s.m(lat2))) / (math.Log(t1) - math.Log(s.t(lat2)))
+	}
+	s.f = m1 / (s.n * math.Pow(t1, s.n))
+	s.r0 = s.radius(p.LatitudeOfOrigin * math.Pi / 180)
+	return s
+}
+
+func (s lccCone) m(lat float64) float64 {
+	esinLat := s.e * math.Sin(lat)
+	return math.Cos(lat) / math.Sqrt(1-esinLat*esinLat)
+}
+
+func (s lccCone) t(lat float64) float64 {
+	esinLat := s.e * math.Sin(lat)
+	return math.Tan(math.Pi/4-lat/2) / math.Pow((1-esinLat)/(1+esinLat), s.e/2)
+}
+
+// radius returns the radius of the parallel at latitude lat.
+func (s lccCone) radius(lat float64) float64 {
+	return s.a * s.f * math.Pow(s.t(lat), s.n)
+}
@@ -0,0 +1,125 @@
+package transform
+
+import (
+	"errors"
+	"math"
+
+	"github.com/matoous/goodgeo"
+)
+
+// SRIDs of the coordinate reference systems handled by this package.
+const (
+	SRIDWGS84       = 4326
+	SRIDWebMercator = 3857
+)
+
+// Errors returned by the projections.
+var (
+	ErrInvalidUTMZone = errors.New("transform: invalid UTM zone")
+	ErrOutsideUTM     = errors.New("transform: latitude outside UTM coverage")
+)
+
+// A Projection maps longitudes and latitudes in degrees on the WGS84 datum
+// to planar coordinates, typically in meters, and back. Both methods modify
+// the first two ordinates of a coordinate in place, further ordinates are
+// left untouched.
+type Projection interface {
+	// SRID returns the SRID of the projected coordinates.
+	SRID() int
+	// Forward projects a longitude and latitude to planar coordinates.
+	Forward(c goodgeo.Coord)
+	// Inverse maps planar coordinates back to a longitude and latitude.
+	Inverse(c goodgeo.Coord)
+}
+
+// Forward projects the coordinates of g in place with p and sets the SRID
+// of g to that of p.
+func Forward(g goodgeo.T, p Projection) (goodgeo.T, error) {
+	return transformInPlace(g, p.Forward, p.SRID())
+}
+
+// Inverse maps the coordinates of g, projected with p, back to longitudes
+// and latitudes in place and sets the SRID of g to [SRIDWGS84].
+func Inverse(g goodgeo.T, p Projection) (goodgeo.T, error) {
+	return transformInPlace(g, p.Inverse, SRIDWGS84)
+}
+
+// transformInPlace applies f to all coordinates of g, including those of the
+// members of geometry collections, and sets their SRID to srid.
+func transformInPlace(g goodgeo.T, f func(goodgeo.Coord), srid int) (goodgeo.T, error) {
+	if gc, ok := g.(*goodgeo.GeometryCollection); ok {
+		for _, member := range gc.Geoms() {
+			if _, err := transformInPlace(member, f, srid); err != nil {
+				return nil, err
+			}
+		}
+		return gc.SetSRID(srid), nil
+	}
+	g, err := goodgeo.SetSRID(g, srid)
+	if err != nil {
+		return nil, err
+	}
+	return goodgeo.TransformInPlace(g, f), nil
+}
+
+// webMercatorMaxLatitude is the latitude at which the Web Mercator
+// projection is cut off to make the world square.
+const webMercatorMaxLatitude = 85.05112877980659
+
+// WebMercator is the spherical Mercator projection used by web maps,
+// EPSG:3857. Latitudes beyond about 85.05° are clamped.
+var WebMercator Projection = webMercator{}
+
+type webMercator struct{}
+
+func (webMercator) SRID() int {
+	return SRIDWebMercator
+}
+
+func (webMercator) Forward(c goodgeo.Coord) {
+	lat := max(-webMercatorMaxLatitude, min(webMercatorMaxLatitude, c[1]))
+	c[0] = goodgeo.WGS84SemiMajorAxis * c[0] * math.Pi / 180
+	c[1] = goodgeo.WGS84SemiMajorAxis * math.Log(math.Tan(math.Pi/4+lat*math.Pi/360))
+}
+
+func (webMercator) Inverse(c goodgeo.Coord) {
+	c[0] = c[0] / goodgeo.WGS84SemiMajorAxis * 180 / math.Pi
+	c[1] = (2*math.Atan(math.Exp(c[1]/goodgeo.WGS84SemiMajorAxis)) - math.Pi/2) * 180 / math.Pi
+}
+
+// eccentricity returns the first eccentricity of an ellipsoid with
+// flattening f.
+func eccentricity(f float64) float64 {
+	return math.Sqrt(f * (2 - f))
+}
+
+// conformalTan returns the tangent of the conformal latitude of a point at
+// latitude lat, in radians, on an ellipsoid with eccentricity e.
+func conformalTan(lat, e float64) float64 {
+	sinLat := math.Sin(lat)
+	return math.Sinh(math.Atanh(sinLat) - e*math.Atanh(e*sinLat))
+}
+
+// latitudeFromConformalTan returns the latitude, in radians, whose
+// conformal latitude has the tangent taup, using Newton's method as
+// described in [Karney (2011)].
+//
+// [Karney (2011)]: https://doi.org/10.1007/s00190-011-0445-3
+func latitudeFromConformalTan(taup, e float64) float64 {
+	if math.IsInf(taup, 0) {
+		return math.Copysign(math.Pi/2, taup)
+	}
+	e2m := 1 - e*e
+	tau := taup / e2m
+	for range 5 {
+		tau1 := math.Hypot(1, tau)
+		sigma := math.Sinh(e * math.Atanh(e*tau/tau1))
+		taupa := math.Hypot(1, sigma)*tau - sigma*tau1
+		dtau := (taup - taupa) / math.Hypot(1, taupa) * (1 + e2m*tau*tau) / (e2m * tau1)
+		tau += dtau
+		if math.Abs(dtau) < 1e-14*max(1, math.Abs(tau)) {
+			break
+		}
+	}
+	return math.Atan(tau)
+}
@@ -0,0 +1,23 @@
+package transform_test
+
+import (
+	"fmt"
+
+	"github.com/matoous/goodgeo"
+	"github.com/matoous/goodgeo/transform"
+)
+
+func ExampleForward() {
+	p := goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{-79.387139, 43.642567})
+	utm, err := transform.UTMFromPoint(p)
+	if err != nil {
+		panic(err)
+	}
+	g, err := transform.Forward(p, utm)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("EPSG:%d %.0f %.0f\n", g.SRID(), g.FlatCoords()[0], g.FlatCoords()[1])
+
+	// Output: EPSG:32617 630084 4833439
+}
@@ -0,0 +1,237 @@
+package transform
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/matoous/goodgeo"
+	"github.com/matoous/goodgeo/geodesic"
+)
+
+// usFoot is the length of the US survey foot in meters.
+const usFoot = 1200.0 / 3937
+
+func TestProjections(t *testing.T) {
+	utm17, err := UTM(17, true)
+	assert.NoError(t, err)
+	utm23s, err := UTM(23, false)
+	assert.NoError(t, err)
+	for i, tc := range []struct {
+		p         Projection
+		c         goodgeo.Coord
+		want      goodgeo.Coord
+		tolerance float64
+	}{
+		{
+			p:         WebMercator,
+			c:         goodgeo.Coord{0, 0},
+			want:      goodgeo.Coord{0, 0},
+			tolerance: 1e-9,
+		},
+		{
+			p:         WebMercator,
+			c:         goodgeo.Coord{180, webMercatorMaxLatitude},
+			want:      goodgeo.Coord{20037508.342789244, 20037508.342789244},
+			tolerance: 1e-6,
+		},
+		{
+			p:         WebMercator,
+			c:         goodgeo.Coord{-0.1278, 51.5074, 11},
+			want:      goodgeo.Coord{-14226.630, 6711542.475, 11},
+			tolerance: 1e-3,
+		},
+		{
+			// The CN Tower.
+			p:         utm17,
+			c:         goodgeo.Coord{-79.387139, 43.642567},
+			want:      goodgeo.Coord{630084, 4833438},
+			tolerance: 1,
+		},
+		{
+			p:         utm23s,
+			c:         goodgeo.Coord{-45, 0},
+			want:      goodgeo.Coord{500000, 10000000},
+			tolerance: 1e-6,
+		},
+		{
+			// The example of EPSG Guidance Note 7-2 for the British
+			// National Grid.
+			p: NewTransverseMercator(TransverseMercatorParams{
+				Ellipsoid:        geodesic.NewEllipsoid(6377563.396, 1/299.3249646),
+				CentralMeridian:  -2,
+				LatitudeOfOrigin: 49,
+				ScaleFactor:      0.9996012717,
+				FalseEasting:     400000,
+				FalseNorthing:    -100000,
+			}),
+			c:         goodgeo.Coord{0.5, 50.5},
+			want:      goodgeo.Coord{577274.99, 69740.50},
+			tolerance: 1e-2,
+		},
+		{
+			// The example of EPSG Guidance Note 7-2 for Texas South
+			// Central.
+			p: NewLambertConformalConic(LambertConformalConicParams{
+				Ellipsoid:         geodesic.NewEllipsoid(6378206.4, 1/294.978698213898),
+				CentralMeridian:   -99,
+				LatitudeOfOrigin:  27 + 50.0/60,
+				StandardParallel1: 28 + 23.0/60,
+				StandardParallel2: 30 + 17.0/60,
+				FalseEasting:      2000000 * usFoot,
+			}),
+			c:         goodgeo.Coord{-96, 28.5},
+			want:      goodgeo.Coord{2963503.91 * usFoot, 254759.80 * usFoot},
+			tolerance: 1e-2,
+		},
+		{
+			// A cone opening to the north.
+			p: NewLambertConformalConic(LambertConformalConicParams{
+				Ellipsoid:         geodesic.WGS84,
+				CentralMeridian:   135,
+				LatitudeOfOrigin:  -50,
+				StandardParallel1: -30,
+				StandardParallel2: -30,
+			}),
+			c:         goodgeo.Coord{135, -50},
+			want:      goodgeo.Coord{0, 0},
+			tolerance: 1e-6,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c := append(goodgeo.Coord(nil), tc.c...)
+			tc.p.Forward(c)
+			assert.Equal(t, len(tc.want), len(c))
+			for j := range c {
+				assert.True(t, math.Abs(c[j]-tc.want[j]) < tc.tolerance, "%v != %v", c, tc.want)
+			}
+			tc.p.Inverse(c)
+			for j := range c {
+				assert.True(t, math.Abs(c[j]-tc.c[j]) < 1e-9, "%v != %v", c, tc.c)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	projections := []Projection{
+		WebMercator,
+		NewTransverseMercator(TransverseMercatorParams{Ellipsoid: geodesic.WGS84, CentralMeridian: 15, LatitudeOfOrigin: 30, ScaleFactor: 1}),
+		NewLambertConformalConic(LambertConformalConicParams{Ellipsoid: geodesic.WGS84, CentralMeridian: 10, LatitudeOfOrigin: 52, StandardParallel1: 35, StandardParallel2: 65}),
+		NewLambertConformalConic(LambertConformalConicParams{Ellipsoid: geodesic.WGS84, CentralMeridian: -60, LatitudeOfOrigin: -40, StandardParallel1: -20, StandardParallel2: -60}),
+	}
+	for i, p := range projections {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			for lng := -20.0; lng <= 40; lng += 7.5 {
+				for lat := -80.0; lat <= 80; lat += 10 {
+					c := goodgeo.Coord{lng, lat}
+					p.Forward(c)
+					p.Inverse(c)
+					assert.True(t, math.Abs(c[0]-lng) < 1e-9 && math.Abs(c[1]-lat) < 1e-9, "%v != %v, %v", c, lng, lat)
+				}
+			}
+		})
+	}
+}
+
+func TestUTMZone(t *testing.T) {
+	for i, tc := range []struct {
+		c         goodgeo.Coord
+		wantZone  int
+		wantNorth bool
+		wantErr   error
+	}{
+		{c: goodgeo.Coord{-180, 0}, wantZone: 1, wantNorth: true},
+		{c: goodgeo.Coord{180, -1}, wantZone: 1, wantNorth: false},
+		{c: goodgeo.Coord{179.9, 10}, wantZone: 60, wantNorth: true},
+		{c: goodgeo.Coord{14.42, 50.09}, wantZone: 33, wantNorth: true},
+		{c: goodgeo.Coord{-46.63, -23.55}, wantZone: 23, wantNorth: false},
+		{c: goodgeo.Coord{5.32, 60.39}, wantZone: 32, wantNorth: true},
+		{c: goodgeo.Coord{2.5, 63}, wantZone: 31, wantNorth: true},
+		{c: goodgeo.Coord{8, 78}, wantZone: 31, wantNorth: true},
+		{c: goodgeo.Coord{15.65, 78.22}, wantZone: 33, wantNorth: true},
+		{c: goodgeo.Coord{25, 80}, wantZone: 35, wantNorth: true},
+		{c: goodgeo.Coord{40, 80}, wantZone: 37, wantNorth: true},
+		{c: goodgeo.Coord{0, 85}, wantErr: ErrOutsideUTM},
+		{c: goodgeo.Coord{0, -81}, wantErr: ErrOutsideUTM},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			zone, north, err := UTMZone(tc.c)
+			if tc.wantErr != nil {
+				assert.IsError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantZone, zone)
+			assert.Equal(t, tc.wantNorth, north)
+		})
+	}
+
+	p, err := UTMFromPoint(goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{-46.63, -23.55}))
+	assert.NoError(t, err)
+	assert.Equal(t, 32723, p.SRID())
+	_, err = UTMFromCoord(goodgeo.Coord{0, 90})
+	assert.IsError(t, err, ErrOutsideUTM)
+	for _, zone := range []int{0, 61} {
+		_, err = UTM(zone, true)
+		assert.IsError(t, err, ErrInvalidUTMZone)
+	}
+}
+
+func TestForwardInverse(t *testing.T) {
+	line := goodgeo.NewLineString(goodgeo.XYZ).MustSetCoords([]goodgeo.Coord{{14.42, 50.09, 200}, {14.43, 50.08, 210}})
+	polygon := goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{
+		{{14, 50}, {15, 50}, {15, 51}, {14, 51}, {14, 50}},
+	}).SetSRID(SRIDWGS84)
+	gc := goodgeo.NewGeometryCollection().MustPush(
+		goodgeo.NewPoint(goodgeo.XY).MustSetCoords(goodgeo.Coord{14.42, 50.09}),
+		goodgeo.NewGeometryCollection().MustPush(polygon),
+	)
+	p, err := UTMFromCoord(goodgeo.Coord{14.42, 50.09})
+	assert.NoError(t, err)
+	for i, g := range []goodgeo.T{line, gc} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var want []float64
+			forEachCoords(g, func(g goodgeo.T) {
+				want = append(want, g.FlatCoords()...)
+			})
+
+			projected, err := Forward(g, p)
+			assert.NoError(t, err)
+			var got []float64
+			forEachCoords(projected, func(g goodgeo.T) {
+				assert.Equal(t, 32633, g.SRID())
+				got = append(got, g.FlatCoords()...)
+			})
+			assert.Equal(t, 32633, projected.SRID())
+			assert.True(t, 400000 < got[0] && got[0] < 500000 && 5500000 < got[1] && got[1] < 5600000, "%v", got)
+
+			unprojected, err := Inverse(projected, p)
+			assert.NoError(t, err)
+			got = got[:0]
+			forEachCoords(unprojected, func(g goodgeo.T) {
+				assert.Equal(t, SRIDWGS84, g.SRID())
+				got = append(got, g.FlatCoords()...)
+			})
+			assert.Equal(t, len(want), len(got))
+			for j := range want {
+				assert.True(t, math.Abs(want[j]-got[j]) < 1e-9)
+			}
+		})
+	}
+
+	_, err = Forward(nil, WebMercator)
+	assert.Error(t, err)
+}
+
+func forEachCoords(g goodgeo.T, f func(goodgeo.T)) {
+	if gc, ok := g.(*goodgeo.GeometryCollection); ok {
+		for _, member := range gc.Geoms() {
+			forEachCoords(member, f)
+		}
+		return
+	}
+	f(g)
+}
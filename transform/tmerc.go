@@ -0,0 +1,194 @@
+package transform
+
+import (
+	"math"
+
+	"github.com/matoous/goodgeo"
+	"github.com/matoous/goodgeo/geodesic"
+)
+
+// A TransverseMercator is a transverse Mercator projection of an
+// ellipsoid. It is computed with the sixth order series of [Krüger], which
+// are accurate to within a few nanometers up to 4000 km from the central
+// meridian. The coefficients of the series are computed once by
+// [NewTransverseMercator].
+//
+// [Krüger]: https://arxiv.org/abs/1002.1417
+type TransverseMercator struct {
+	params TransverseMercatorParams
+	series tmSeries
+}
+
+// TransverseMercatorParams are the parameters of a [TransverseMercator].
+type TransverseMercatorParams struct {
+	// Ellipsoid is the ellipsoid of the datum.
+	Ellipsoid geodesic.Ellipsoid
+	// CentralMeridian is the longitude of the natural origin in degrees.
+	CentralMeridian float64
+	// LatitudeOfOrigin is the latitude of the natural origin in degrees.
+	LatitudeOfOrigin float64
+	// ScaleFactor is the scale factor on the central meridian.
+	ScaleFactor float64
+	// FalseEasting and FalseNorthing are the coordinates of the natural
+	// origin in meters.
+	FalseEasting, FalseNorthing float64
+	// EPSG is the SRID of the projected coordinates.
+	EPSG int
+}
+
+// NewTransverseMercator returns the transverse Mercator projection with
+// params.
+func NewTransverseMercator(params TransverseMercatorParams) TransverseMercator {
+	return TransverseMercator{params: params, series: params.series()}
+}
+
+// UTM returns the projection of the zone, between 1 and 60, of the
+// Universal Transverse Mercator system on the WGS84 ellipsoid, in the
+// northern or southern hemisphere.
+func UTM(zone int, north bool) (TransverseMercator, error) {
+	if zone < 1 || zone > 60 {
+		return TransverseMercator{}, ErrInvalidUTMZone
+	}
+	p := TransverseMercatorParams{
+		Ellipsoid:       geodesic.WGS84,
+		CentralMeridian: float64(6*zone - 183),
+		ScaleFactor:     0.9996,
+		FalseEasting:    500000,
+		EPSG:            32600 + zone,
+	}
+	if !north {
+		p.FalseNorthing = 10000000
+		p.EPSG = 32700 + zone
+	}
+	return NewTransverseMercator(p), nil
+}
+
+// UTMZone returns the Universal Transverse Mercator zone and hemisphere of
+// c, taking the exceptions around Norway and Svalbard into account.
+func UTMZone(c goodgeo.Coord) (zone int, north bool, err error) {
+	lng, lat := math.Mod(math.Mod(c[0]+180, 360)+360, 360)-180, c[1]
+	if !(-80 <= lat && lat <= 84) {
+		return 0, false, ErrOutsideUTM
+	}
+	zone = int((lng+180)/6) + 1
+	switch {
+	case 56 <= lat && lat < 64 && 3 <= lng && lng < 12:
+		zone = 32
+	case 72 <= lat && 0 <= lng && lng < 42:
+		zone = 2*int((lng+3)/12) + 31
+	}
+	return zone, lat >= 0, nil
+}
+
+// UTMFromCoord returns the projection of the Universal Transverse Mercator
+// zone of c.
+func UTMFromCoord(c goodgeo.Coord) (TransverseMercator, error) {
+	zone, north, err := UTMZone(c)
+	if err != nil {
+		return TransverseMercator{}, err
+	}
+	return UTM(zone, north)
+}
+
+// UTMFromPoint returns the projection of the Universal Transverse Mercator
+// zone of p.
+func UTMFromPoint(p *goodgeo.Point) (TransverseMercator, error) {
+	return UTMFromCoord(p.Coords())
+}
+
+// Params returns the parameters of p.
+func (p TransverseMercator) Params() TransverseMercatorParams {
+	return p.params
+}
+
+// SRID returns the EPSG code of the parameters of p.
+func (p TransverseMercator) SRID() int {
+	return p.params.EPSG
+}
+
+// Forward projects a longitude and latitude to easting and northing.
+func (p TransverseMercator) Forward(c goodgeo.Coord) {
+	s := &p.series
+	lng := (c[0] - p.params.CentralMeridian) * math.Pi / 180
+	taup := conformalTan(c[1]*math.Pi/180, s.e)
+	xip := math.Atan2(taup, math.Cos(lng))
+	etap := math.Asinh(math.Sin(lng) / math.Hypot(taup, math.Cos(lng)))
+	xi, eta := xip, etap
+	for j, a := range s.alpha {
+		k := 2 * float64(j+1)
+		xi += a * math.Sin(k*xip) * math.Cosh(k*etap)
+		eta += a * math.Cos(k*xip) * math.Sinh(k*etap)
+	}
+	c[0] = p.params.FalseEasting + p.params.ScaleFactor*s.a*eta
+	c[1] = p.params.FalseNorthing + p.params.ScaleFactor*(s.a*xi-s.m0)
+}
+
+// Inverse maps an easting and northing back to a longitude and latitude.
+func (p TransverseMercator) Inverse(c goodgeo.Coord) {
+	s := &p.series
+	eta := (c[0] - p.params.FalseEasting) / (p.params.ScaleFactor * s.a)
+	xi := ((c[1]-p.params.FalseNorthing)/p.params.ScaleFactor + s.m0) / s.a
+	xip, etap := xi, eta
+	for j, b := range s.beta {
+		k := 2 * float64(j+1)
+		xip -= b * math.Sin(k*xi) * math.Cosh(k*eta)
+		etap -= b * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+	taup := math.Sin(xip) / math.Hypot(math.Sinh(etap), math.Cos(xip))
+	c[0] = p.params.CentralMeridian + math.Atan2(math.Sinh(etap), math.Cos(xip))*180/math.Pi
+	c[1] = latitudeFromConformalTan(taup, s.e) * 180 / math.Pi
+}
+
+// tmSeries holds the constants of the series of a transverse Mercator
+// projection.
+type tmSeries struct {
+	// e is the eccentricity.
+	e float64
+	// a is the rectifying radius.
+	a float64
+	// m0 is the distance along the meridian from the equator to the
+	// latitude of origin.
+	m0 float64
+	// alpha and beta are the coefficients of the forward and inverse
+	// series.
+	alpha, beta [6]float64
+}
+
+func (p TransverseMercatorParams) series() tmSeries {
+	f := p.Ellipsoid.F
+	n := f / (2 - f)
+	n2 := n * n
+	n3 := n2 * n
+	n4 := n3 * n
+	n5 := n4 * n
+	n6 := n5 * n
+	s := tmSeries{
+		e: eccentricity(f),
+		a: p.Ellipsoid.A / (1 + n) * (1 + n2/4 + n4/64 + n6/256),
+		alpha: [6]float64{
+			n/2 - 2*n2/3 + 5*n3/16 + 41*n4/180 - 127*n5/288 + 7891*n6/37800,
+			13*n2/48 - 3*n3/5 + 557*n4/1440 + 281*n5/630 - 1983433*n6/1935360,
+			61*n3/240 - 103*n4/140 + 15061*n5/26880 + 167603*n6/181440,
+			49561*n4/161280 - 179*n5/168 + 6601661*n6/7257600,
+			34729*n5/80640 - 3418889*n6/1995840,
+			212378941 * n6 / 319334400,
+		},
+		beta: [6]float64{
+			n/2 - 2*n2/3 + 37*n3/96 - n4/360 - 81*n5/512 + 96199*n6/604800,
+			n2/48 + n3/15 - 437*n4/1440 + 46*n5/105 - 1118711*n6/3870720,
+			17*n3/480 - 37*n4/840 - 209*n5/4480 + 5569*n6/90720,
+			4397*n4/161280 - 11*n5/504 - 830251*n6/7257600,
+			4583*n5/161280 - 108847*n6/3991680,
+			20648693 * n6 / 638668800,
+		},
+	}
+	if p.LatitudeOfOrigin != 0 {
+		xi := math.Atan(conformalTan(p.LatitudeOfOrigin*math.Pi/180, s.e))
+		m0 := xi
+		for j, a := range s.alpha {
+			m0 += a * math.Sin(2*float64(j+1)*xi)
+		}
+		s.m0 = s.a * m0
+	}
+	return s
+}
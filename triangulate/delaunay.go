@@ -0,0 +1,442 @@
+// Package triangulate computes Delaunay triangulations of points in the
+// plane, Voronoi diagrams and constrained triangulations of polygons.
+//
+// The triangulation is built with the sweep-hull algorithm of [Delaunator]:
+// the points are added in order of their distance from a seed triangle,
+// each is connected to the visible edges of the convex hull of the points
+// before it, and the new triangles are flipped until they satisfy the
+// Delaunay condition. Coordinates are treated as planar, like Turf does.
+//
+// [Delaunator]: https://github.com/mapbox/delaunator
+package triangulate
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/matoous/goodgeo"
+)
+
+// epsilon is the distance below which consecutive points are treated as
+// duplicates.
+const epsilon = 0x1p-52
+
+// A Delaunay is a Delaunay triangulation of a set of points.
+//
+// Triangles and edges are identified by indices into Triangles: triangle t
+// has the vertices Triangles[3*t], Triangles[3*t+1] and Triangles[3*t+2], and
+// edge e goes from vertex Triangles[e] to the next vertex of its triangle.
+type Delaunay struct {
+	// Coords are the triangulated points.
+	Coords []goodgeo.Coord
+	// Triangles holds the indices into Coords of the vertices of the
+	// triangles, three per triangle, in counter-clockwise order.
+	Triangles []int
+	// Halfedges holds the index of the opposite edge in the adjacent
+	// triangle for each edge, or -1 for edges on the convex hull.
+	Halfedges []int
+	// Hull holds the indices into Coords of the points on the convex hull in
+	// counter-clockwise order. If the points are collinear and there are no
+	// triangles, it holds the distinct points in order along their line.
+	Hull []int
+}
+
+// NewDelaunay returns the Delaunay triangulation of coords. Duplicate
+// points are left out of the triangulation.
+func NewDelaunay(coords []goodgeo.Coord) *Delaunay {
+	d := &Delaunay{Coords: coords}
+	n := len(coords)
+	if n == 0 {
+		return d
+	}
+	t := &triangulator{
+		xy: make([]float64, 2*n),
+	}
+	// The y axis is flipped so that the triangles, which the algorithm
+	// orients clockwise, come out counter-clockwise.
+	for i, c := range coords {
+		t.xy[2*i], t.xy[2*i+1] = c[0], -c[1]
+	}
+	t.triangulate(d)
+	return d
+}
+
+// NumTriangles returns the number of triangles of d.
+func (d *Delaunay) NumTriangles() int {
+	return len(d.Triangles) / 3
+}
+
+// NextHalfedge returns the edge that follows e in its triangle.
+func NextHalfedge(e int) int {
+	if e%3 == 2 {
+		return e - 2
+	}
+	return e + 1
+}
+
+// PrevHalfedge returns the edge that precedes e in its triangle.
+func PrevHalfedge(e int) int {
+	if e%3 == 0 {
+		return e + 2
+	}
+	return e - 1
+}
+
+// A triangulator holds the state of the sweep-hull algorithm.
+type triangulator struct {
+	xy        []float64
+	triangles []int
+	halfedges []int
+	// hullPrev and hullNext link the points on the hull, points removed
+	// from the hull link to themselves.
+	hullPrev, hullNext []int
+	// hullTri holds the edge of a triangle on the hull that starts at each
+	// point on the hull.
+	hullTri   []int
+	hullStart int
+	// hullHash buckets the points on the hull by their angle around the
+	// center to quickly find the hull edges visible from a new point.
+	hullHash  []int
+	hashSize  int
+	cx, cy    float64
+	edgeStack []int
+}
+
+func (t *triangulator) triangulate(d *Delaunay) {
+	n := len(t.xy) / 2
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for i := range n {
+		x, y := t.xy[2*i], t.xy[2*i+1]
+		minX, minY = min(minX, x), min(minY, y)
+		maxX, maxY = max(maxX, x), max(maxY, y)
+	}
+	cx, cy := (minX+maxX)/2, (minY+maxY)/2
+
+	// Seed with the point closest to the center, its closest point and the
+	// point that makes the smallest circumcircle with them.
+	i0, i1, i2 := -1, -1, -1
+	minDist := math.Inf(1)
+	for i := range n {
+		if d := dist(cx, cy, t.xy[2*i], t.xy[2*i+1]); d < minDist {
+			i0, minDist = i, d
+		}
+	}
+	x0, y0 := t.xy[2*i0], t.xy[2*i0+1]
+	minDist = math.Inf(1)
+	for i := range n {
+		if i == i0 {
+			continue
+		}
+		if d := dist(x0, y0, t.xy[2*i], t.xy[2*i+1]); d < minDist && d > 0 {
+			i1, minDist = i, d
+		}
+	}
+	if i1 == -1 {
+		d.Hull = []int{i0}
+		return
+	}
+	x1, y1 := t.xy[2*i1], t.xy[2*i1+1]
+	minRadius := math.Inf(1)
+	for i := range n {
+		if i == i0 || i == i1 {
+			continue
+		}
+		if r := circumradius(x0, y0, x1, y1, t.xy[2*i], t.xy[2*i+1]); r < minRadius {
+			i2, minRadius = i, r
+		}
+	}
+	if i2 == -1 {
+		// The points are collinear, order them along their line.
+		dists := make([]float64, n)
+		ids := make([]int, n)
+		for i := range n {
+			ids[i] = i
+			dists[i] = (t.xy[2*i]-x0)*(x1-x0) + (t.xy[2*i+1]-y0)*(y1-y0)
+		}
+		slices.SortStableFunc(ids, func(a, b int) int {
+			return cmp.Compare(dists[a], dists[b])
+		})
+		for j, i := range ids {
+			if j == 0 || dists[i] > dists[ids[j-1]] {
+				d.Hull = append(d.Hull, i)
+			}
+		}
+		return
+	}
+	x2, y2 := t.xy[2*i2], t.xy[2*i2+1]
+	if orient(x0, y0, x1, y1, x2, y2) {
+		i1, i2 = i2, i1
+		x1, y1, x2, y2 = x2, y2, x1, y1
+	}
+	t.cx, t.cy = circumcenter(x0, y0, x1, y1, x2, y2)
+
+	// Sweep the points in order of their distance from the circumcenter
+	// of the seed triangle.
+	dists := make([]float64, n)
+	ids := make([]int, n)
+	for i := range n {
+		ids[i] = i
+		dists[i] = dist(t.xy[2*i], t.xy[2*i+1], t.cx, t.cy)
+	}
+	slices.SortStableFunc(ids, func(a, b int) int {
+		return cmp.Compare(dists[a], dists[b])
+	})
+
+	t.hashSize = int(math.Ceil(math.Sqrt(float64(n))))
+	t.hullHash = make([]int, t.hashSize)
+	for i := range t.hullHash {
+		t.hullHash[i] = -1
+	}
+	t.hullPrev = make([]int, n)
+	t.hullNext = make([]int, n)
+	t.hullTri = make([]int, n)
+	t.hullStart = i0
+	t.hullNext[i0], t.hullPrev[i2] = i1, i1
+	t.hullNext[i1], t.hullPrev[i0] = i2, i2
+	t.hullNext[i2], t.hullPrev[i1] = i0, i0
+	t.hullTri[i0], t.hullTri[i1], t.hullTri[i2] = 0, 1, 2
+	t.hullHash[t.hashKey(x0, y0)] = i0
+	t.hullHash[t.hashKey(x1, y1)] = i1
+	t.hullHash[t.hashKey(x2, y2)] = i2
+	hullSize := 3
+
+	maxTriangles := max(2*n-5, 1)
+	t.triangles = make([]int, 0, 3*maxTriangles)
+	t.halfedges = make([]int, 0, 3*maxTriangles)
+	t.addTriangle(i0, i1, i2, -1, -1, -1)
+
+	var xp, yp float64
+	for k, i := range ids {
+		x, y := t.xy[2*i], t.xy[2*i+1]
+		if k > 0 && math.Abs(x-xp) <= epsilon && math.Abs(y-yp) <= epsilon {
+			continue
+		}
+		xp, yp = x, y
+		if i == i0 || i == i1 || i == i2 {
+			continue
+		}
+
+		// Find an edge of the hull visible from the point.
+		start := 0
+		key := t.hashKey(x, y)
+		for j := range t.hashSize {
+			start = t.hullHash[(key+j)%t.hashSize]
+			if start != -1 && start != t.hullNext[start] {
+				break
+			}
+		}
+		start = t.hullPrev[start]
+		e := start
+		for {
+			q := t.hullNext[e]
+			if orient(x, y, t.xy[2*e], t.xy[2*e+1], t.xy[2*q], t.xy[2*q+1]) {
+				break
+			}
+			e = q
+			if e == start {
+				e = -1
+				break
+			}
+		}
+		if e == -1 {
+			// The point is a near-duplicate of a point on the hull.
+			continue
+		}
+
+		// Add the first triangle from the point and flip it if needed.
+		tri := t.addTriangle(e, i, t.hullNext[e], -1, -1, t.hullTri[e])
+		t.hullTri[i] = t.legalize(tri + 2)
+		t.hullTri[e] = tri
+		hullSize++
+
+		// Walk forward through the hull, adding more triangles.
+		next := t.hullNext[e]
+		for {
+			q := t.hullNext[next]
+			if !orient(x, y, t.xy[2*next], t.xy[2*next+1], t.xy[2*q], t.xy[2*q+1]) {
+				break
+			}
+			tri = t.addTriangle(next, i, q, t.hullTri[i], -1, t.hullTri[next])
+			t.hullTri[i] = t.legalize(tri + 2)
+			t.hullNext[next] = next
+			hullSize--
+			next = q
+		}
+
+		// Walk backward from the other side.
+		if e == start {
+			for {
+				q := t.hullPrev[e]
+				if !orient(x, y, t.xy[2*q], t.xy[2*q+1], t.xy[2*e], t.xy[2*e+1]) {
+					break
+				}
+				tri = t.addTriangle(q, i, e, -1, t.hullTri[e], t.hullTri[q])
+				t.legalize(tri + 2)
+				t.hullTri[q] = tri
+				t.hullNext[e] = e
+				hullSize--
+				e = q
+			}
+		}
+
+		t.hullStart = e
+		t.hullPrev[i] = e
+		t.hullNext[e] = i
+		t.hullPrev[next] = i
+		t.hullNext[i] = next
+		t.hullHash[t.hashKey(x, y)] = i
+		t.hullHash[t.hashKey(t.xy[2*e], t.xy[2*e+1])] = e
+	}
+
+	d.Hull = make([]int, 0, hullSize)
+	for e, j := t.hullStart, 0; j < hullSize; j++ {
+		d.Hull = append(d.Hull, e)
+		e = t.hullNext[e]
+	}
+	d.Triangles = t.triangles
+	d.Halfedges = t.halfedges
+}
+
+func (t *triangulator) hashKey(x, y float64) int {
+	return int(math.Floor(pseudoAngle(x-t.cx, y-t.cy)*float64(t.hashSize))) % t.hashSize
+}
+
+// legalize flips the edge a and the edges around it until the triangles
+// satisfy the Delaunay condition and returns the edge that replaced the edge
+// before a.
+func (t *triangulator) legalize(a int) int {
+	var ar int
+	t.edgeStack = t.edgeStack[:0]
+	for {
+		b := t.halfedges[a]
+		a0 := a - a%3
+		ar = a0 + (a+2)%3
+		if b == -1 {
+			if len(t.edgeStack) == 0 {
+				break
+			}
+			a = t.edgeStack[len(t.edgeStack)-1]
+			t.edgeStack = t.edgeStack[:len(t.edgeStack)-1]
+			continue
+		}
+
+		b0 := b - b%3
+		al := a0 + (a+1)%3
+		bl := b0 + (b+2)%3
+		p0 := t.triangles[ar]
+		pr := t.triangles[a]
+		pl := t.triangles[al]
+		p1 := t.triangles[bl]
+		if !inCircle(
+			t.xy[2*p0], t.xy[2*p0+1],
+			t.xy[2*pr], t.xy[2*pr+1],
+			t.xy[2*pl], t.xy[2*pl+1],
+			t.xy[2*p1], t.xy[2*p1+1],
+		) {
+			if len(t.edgeStack) == 0 {
+				break
+			}
+			a = t.edgeStack[len(t.edgeStack)-1]
+			t.edgeStack = t.edgeStack[:len(t.edgeStack)-1]
+			continue
+		}
+
+		t.triangles[a] = p1
+		t.triangles[b] = p0
+		hbl := t.halfedges[bl]
+		if hbl == -1 {
+			// The flipped edge was on the hull, update the reference to it.
+			e := t.hullStart
+			for {
+				if t.hullTri[e] == bl {
+					t.hullTri[e] = a
+					break
+				}
+				e = t.hullPrev[e]
+				if e == t.hullStart {
+					break
+				}
+			}
+		}
+		t.link(a, hbl)
+		t.link(b, t.halfedges[ar])
+		t.link(ar, bl)
+		t.edgeStack = append(t.edgeStack, b0+(b+1)%3)
+	}
+	return ar
+}
+
+func (t *triangulator) link(a, b int) {
+	t.halfedges[a] = b
+	if b != -1 {
+		t.halfedges[b] = a
+	}
+}
+
+// addTriangle adds the triangle i0, i1, i2 whose edges are opposite to a, b
+// and c and returns its first edge.
+func (t *triangulator) addTriangle(i0, i1, i2, a, b, c int) int {
+	e := len(t.triangles)
+	t.triangles = append(t.triangles, i0, i1, i2)
+	t.halfedges = append(t.halfedges, -1, -1, -1)
+	t.link(e, a)
+	t.link(e+1, b)
+	t.link(e+2, c)
+	return e
+}
+
+// pseudoAngle returns a number between 0 and 1 that increases with the
+// angle of the vector dx, dy.
+func pseudoAngle(dx, dy float64) float64 {
+	p := dx / (math.Abs(dx) + math.Abs(dy))
+	if dy > 0 {
+		return (3 - p) / 4
+	}
+	return (1 + p) / 4
+}
+
+func dist(ax, ay, bx, by float64) float64 {
+	dx, dy := ax-bx, ay-by
+	return dx*dx + dy*dy
+}
+
+// orient returns true if r lies to the left of the line from p to q.
+func orient(px, py, qx, qy, rx, ry float64) bool {
+	return (qy-py)*(rx-qx)-(qx-px)*(ry-qy) < 0
+}
+
+// inCircle returns true if p lies inside the circumcircle of the clockwise
+// triangle a, b, c.
+func inCircle(ax, ay, bx, by, cx, cy, px, py float64) bool {
+	dx, dy := ax-px, ay-py
+	ex, ey := bx-px, by-py
+	fx, fy := cx-px, cy-py
+	ap := dx*dx + dy*dy
+	bp := ex*ex + ey*ey
+	cp := fx*fx + fy*fy
+	return dx*(ey*cp-bp*fy)-dy*(ex*cp-bp*fx)+ap*(ex*fy-ey*fx) < 0
+}
+
+// circumradius returns the squared radius of the circumcircle of a, b, c,
+// or +Inf if they are collinear.
+func circumradius(ax, ay, bx, by, cx, cy float64) float64 {
+	x, y := circumcenter(0, 0, bx-ax, by-ay, cx-ax, cy-ay)
+	r := x*x + y*y
+	if math.IsNaN(r) {
+		return math.Inf(1)
+	}
+	return r
+}
+
+// circumcenter returns the center of the circumcircle of a, b, c.
+func circumcenter(ax, ay, bx, by, cx, cy float64) (float64, float64) {
+	dx, dy := bx-ax, by-ay
+	ex, ey := cx-ax, cy-ay
+	bl := dx*dx + dy*dy
+	cl := ex*ex + ey*ey
+	d := 0.5 / (dx*ey - dy*ex)
+	return ax + (ey*bl-dy*cl)*d, ay + (dx*cl-ex*bl)*d
+}
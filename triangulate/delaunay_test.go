@@ -0,0 +1,127 @@
+package triangulate
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/matoous/goodgeo"
+)
+
+func TestDelaunay(t *testing.T) {
+	for i, tc := range []struct {
+		coords        []goodgeo.Coord
+		wantTriangles int
+		wantHull      []int
+	}{
+		{
+			coords: nil,
+		},
+		{
+			coords:   []goodgeo.Coord{{1, 1}, {1, 1}},
+			wantHull: []int{0},
+		},
+		{
+			coords:   []goodgeo.Coord{{2, 2}, {0, 0}, {1, 1}, {3, 3}, {1, 1}},
+			wantHull: []int{3, 0, 2, 1},
+		},
+		{
+			coords:        []goodgeo.Coord{{0, 0}, {1, 0}, {0, 1}},
+			wantTriangles: 1,
+			wantHull:      []int{0, 1, 2},
+		},
+		{
+			coords:        []goodgeo.Coord{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {1, 1}},
+			wantTriangles: 4,
+			wantHull:      []int{0, 1, 2, 3},
+		},
+		{
+			coords:        []goodgeo.Coord{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}, {2, 2}},
+			wantTriangles: 2,
+			wantHull:      []int{0, 1, 2, 3},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			d := NewDelaunay(tc.coords)
+			assert.Equal(t, tc.wantTriangles, d.NumTriangles())
+			if d.NumTriangles() > 0 {
+				assert.Equal(t, tc.wantHull, rotate(d.Hull))
+			} else {
+				assert.Equal(t, tc.wantHull, d.Hull)
+			}
+			checkDelaunay(t, d)
+		})
+	}
+}
+
+func TestDelaunayRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i, n := range []int{10, 100, 1000} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			coords := make([]goodgeo.Coord, n)
+			for j := range coords {
+				coords[j] = goodgeo.Coord{r.Float64() * 10, r.Float64() * 10}
+			}
+			// Points on a grid have many cocircular neighbours.
+			for x := range 10 {
+				for y := range 10 {
+					coords = append(coords, goodgeo.Coord{float64(x), float64(y)})
+				}
+			}
+			d := NewDelaunay(coords)
+			checkDelaunay(t, d)
+			// Euler's formula for a triangulation of points in general
+			// position, ignoring the hull points that are collinear.
+			assert.True(t, d.NumTriangles() <= 2*len(coords)-2-len(d.Hull))
+		})
+	}
+}
+
+// checkDelaunay checks that the triangles of d are counter-clockwise, that
+// their edges are linked and that no point is inside their circumcircles.
+func checkDelaunay(t *testing.T, d *Delaunay) {
+	t.Helper()
+	assert.Equal(t, len(d.Triangles), len(d.Halfedges))
+	for e, opposite := range d.Halfedges {
+		if opposite == -1 {
+			continue
+		}
+		assert.Equal(t, e, d.Halfedges[opposite])
+		assert.Equal(t, d.Triangles[e], d.Triangles[NextHalfedge(opposite)])
+		assert.Equal(t, d.Triangles[NextHalfedge(e)], d.Triangles[opposite])
+	}
+	for i := 0; i < len(d.Triangles); i += 3 {
+		a, b, c := d.Coords[d.Triangles[i]], d.Coords[d.Triangles[i+1]], d.Coords[d.Triangles[i+2]]
+		assert.True(t, (b[0]-a[0])*(c[1]-a[1])-(b[1]-a[1])*(c[0]-a[0]) > 0, "triangle %d is not counter-clockwise", i/3)
+		cx, cy := circumcenter(a[0], a[1], b[0], b[1], c[0], c[1])
+		r := math.Hypot(a[0]-cx, a[1]-cy)
+		for j, p := range d.Coords {
+			assert.True(t, math.Hypot(p[0]-cx, p[1]-cy) > r-1e-9*r, "point %d is inside the circumcircle of triangle %d", j, i/3)
+		}
+	}
+	if d.NumTriangles() > 0 {
+		for j, i := range d.Hull {
+			a, b := d.Coords[i], d.Coords[d.Hull[(j+1)%len(d.Hull)]]
+			for _, p := range d.Coords {
+				assert.True(t, (b[0]-a[0])*(p[1]-a[1])-(b[1]-a[1])*(p[0]-a[0]) >= 0, "hull is not convex")
+			}
+		}
+	}
+}
+
+// rotate rotates a hull to start at its smallest index.
+func rotate(hull []int) []int {
+	if len(hull) == 0 {
+		return nil
+	}
+	start := 0
+	for i, v := range hull {
+		if v < hull[start] {
+			start = i
+		}
+	}
+	return append(hull[start:len(hull):len(hull)], hull[:start]...)
+}
@@ -0,0 +1,359 @@
+package triangulate
+
+import (
+	"errors"
+
+	"github.com/matoous/goodgeo"
+)
+
+// ErrIntersectingConstraints is returned by [ConstrainedTriangles] when
+// the edges of a polygon intersect.
+var ErrIntersectingConstraints = errors.New("triangulate: intersecting constraints")
+
+// Triangles returns the Delaunay triangulation of points as counter-clockwise
+// triangles, with the layout and SRID of points.
+func Triangles(points *goodgeo.MultiPoint) *goodgeo.MultiPolygon {
+	var coords []goodgeo.Coord
+	for _, c := range points.Coords() {
+		if c != nil {
+			coords = append(coords, c)
+		}
+	}
+	d := NewDelaunay(coords)
+	return d.multiPolygon(points.Layout(), points.SRID(), nil)
+}
+
+// ConstrainedTriangles returns the constrained Delaunay triangulation of p
+// as counter-clockwise triangles, with the layout and SRID of p. The
+// triangles cover p exactly: the edges of its rings are edges of the
+// triangles, and the triangles are as close to Delaunay as the edges allow.
+//
+// The edges are inserted into the Delaunay triangulation of the vertices of
+// p by flipping the edges they cross, as described by [Sloan (1993)],
+// the triangles outside p are removed and the rest are flipped until they
+// are Delaunay.
+//
+// [Sloan (1993)]: https://doi.org/10.1016/0045-7949(93)90239-A
+func ConstrainedTriangles(p *goodgeo.Polygon) (*goodgeo.MultiPolygon, error) {
+	var coords []goodgeo.Coord
+	var rings [][]int
+	for _, ring := range p.Coords() {
+		indices := make([]int, len(ring))
+		for i, c := range ring {
+			indices[i] = len(coords)
+			coords = append(coords, c)
+		}
+		rings = append(rings, indices)
+	}
+	d := NewDelaunay(coords)
+	if d.NumTriangles() == 0 {
+		return goodgeo.NewMultiPolygon(p.Layout()).SetSRID(p.SRID()), nil
+	}
+
+	m := newMesh(d)
+	// Map duplicate vertices, which are left out of the triangulation, to
+	// the vertex that was kept.
+	kept := make(map[[2]float64]int)
+	for i, e := range m.vertexEdge {
+		if e != -1 {
+			kept[[2]float64{coords[i][0], coords[i][1]}] = i
+		}
+	}
+	for _, ring := range rings {
+		for i := 1; i < len(ring); i++ {
+			a := kept[[2]float64{coords[ring[i-1]][0], coords[ring[i-1]][1]}]
+			b := kept[[2]float64{coords[ring[i]][0], coords[ring[i]][1]}]
+			if a == b {
+				continue
+			}
+			if err := m.insertConstraint(a, b); err != nil {
+				return nil, err
+			}
+		}
+	}
+	m.restoreDelaunay()
+	return d.multiPolygon(p.Layout(), p.SRID(), m.inside()), nil
+}
+
+// multiPolygon returns the triangles of d, or only those for which keep is
+// true if keep is not nil.
+func (d *Delaunay) multiPolygon(layout goodgeo.Layout, srid int, keep []bool) *goodgeo.MultiPolygon {
+	var polygons [][][]goodgeo.Coord
+	for t := range d.NumTriangles() {
+		if keep != nil && !keep[t] {
+			continue
+		}
+		a, b, c := d.Coords[d.Triangles[3*t]], d.Coords[d.Triangles[3*t+1]], d.Coords[d.Triangles[3*t+2]]
+		polygons = append(polygons, [][]goodgeo.Coord{{a, b, c, a}})
+	}
+	return goodgeo.NewMultiPolygon(layout).MustSetCoords(polygons).SetSRID(srid)
+}
+
+// A mesh is a triangulation whose edges can be flipped and constrained.
+type mesh struct {
+	d *Delaunay
+	// vertexEdge holds an edge that starts at each vertex, or -1 for
+	// vertices left out of the triangulation.
+	vertexEdge []int
+	// constrained holds the constrained edges by their vertices.
+	constrained map[[2]int]bool
+}
+
+func newMesh(d *Delaunay) *mesh {
+	return &mesh{
+		d:           d,
+		vertexEdge:  d.vertexEdges(),
+		constrained: make(map[[2]int]bool),
+	}
+}
+
+func edgeKey(a, b int) [2]int {
+	return [2]int{min(a, b), max(a, b)}
+}
+
+func (m *mesh) isConstrained(e int) bool {
+	return m.constrained[edgeKey(m.d.Triangles[e], m.d.Triangles[NextHalfedge(e)])]
+}
+
+// around returns the edges that start at the vertex v.
+func (m *mesh) around(v int) []int {
+	d := m.d
+	start := m.vertexEdge[v]
+	edges := []int{start}
+	// Turn counter-clockwise until back at the start or at the hull, then
+	// clockwise from the start.
+	for e := d.Halfedges[PrevHalfedge(start)]; e != start; e = d.Halfedges[PrevHalfedge(e)] {
+		if e == -1 {
+			for e := d.Halfedges[start]; e != -1; e = d.Halfedges[NextHalfedge(e)] {
+				edges = append(edges, NextHalfedge(e))
+			}
+			break
+		}
+		edges = append(edges, e)
+	}
+	return edges
+}
+
+// edge returns the edge from the vertex a to the vertex b, or -1 if there is
+// none.
+func (m *mesh) edge(a, b int) int {
+	for _, e := range m.around(a) {
+		if m.d.Triangles[NextHalfedge(e)] == b {
+			return e
+		}
+	}
+	return -1
+}
+
+// flip replaces the edge e, shared by two triangles, by the other diagonal
+// of their quadrilateral and returns the edge of the new diagonal in the
+// triangle of e.
+func (m *mesh) flip(e int) int {
+	d := m.d
+	o := d.Halfedges[e]
+	en, ep := NextHalfedge(e), PrevHalfedge(e)
+	on, op := NextHalfedge(o), PrevHalfedge(o)
+	// The triangles p, q, r and q, p, s become s, q, r and r, p, s.
+	p, q, r, s := d.Triangles[e], d.Triangles[en], d.Triangles[ep], d.Triangles[op]
+	d.Triangles[e] = s
+	d.Triangles[o] = r
+	hop, hep := d.Halfedges[op], d.Halfedges[ep]
+	m.link(e, hop)
+	m.link(o, hep)
+	m.link(ep, op)
+	m.vertexEdge[p], m.vertexEdge[q], m.vertexEdge[r], m.vertexEdge[s] = on, en, ep, e
+	return ep
+}
+
+func (m *mesh) link(a, b int) {
+	m.d.Halfedges[a] = b
+	if b != -1 {
+		m.d.Halfedges[b] = a
+	}
+}
+
+// orient2 returns the orientation of the vertex c relative to the line from
+// the vertex a to the vertex b: positive if c is to the left, negative if it
+// is to the right and zero if it is on the line.
+func (m *mesh) orient2(a, b, c int) float64 {
+	pa, pb, pc := m.d.Coords[a], m.d.Coords[b], m.d.Coords[c]
+	return (pb[0]-pa[0])*(pc[1]-pa[1]) - (pb[1]-pa[1])*(pc[0]-pa[0])
+}
+
+// onSegment returns true if the vertex c lies on the segment from the
+// vertex a to the vertex b, strictly between them.
+func (m *mesh) onSegment(a, b, c int) bool {
+	if m.orient2(a, b, c) != 0 {
+		return false
+	}
+	pa, pb, pc := m.d.Coords[a], m.d.Coords[b], m.d.Coords[c]
+	dot := (pc[0]-pa[0])*(pb[0]-pa[0]) + (pc[1]-pa[1])*(pb[1]-pa[1])
+	return dot > 0 && dot < (pb[0]-pa[0])*(pb[0]-pa[0])+(pb[1]-pa[1])*(pb[1]-pa[1])
+}
+
+// insertConstraint makes the segment from the vertex a to the vertex b an
+// edge of the triangulation.
+func (m *mesh) insertConstraint(a, b int) error {
+	d := m.d
+	for a != b {
+		// Find the triangle around a that the segment leaves through, or a
+		// vertex on the segment.
+		next := -1
+		var crossing [][2]int
+		for _, e := range m.around(a) {
+			j, k := d.Triangles[NextHalfedge(e)], d.Triangles[PrevHalfedge(e)]
+			if j == b || m.onSegment(a, b, j) {
+				next = j
+				break
+			}
+			if k == b || m.onSegment(a, b, k) {
+				next = k
+				break
+			}
+			if m.orient2(a, b, j) < 0 && m.orient2(a, b, k) > 0 {
+				crossing = append(crossing, [2]int{j, k})
+				break
+			}
+		}
+		if next == -1 && crossing == nil {
+			return ErrIntersectingConstraints
+		}
+
+		// Follow the segment through the triangles it crosses, each edge from
+		// the vertex right of it to the vertex left of it.
+		for next == -1 {
+			j, k := crossing[len(crossing)-1][0], crossing[len(crossing)-1][1]
+			if m.constrained[edgeKey(j, k)] {
+				return ErrIntersectingConstraints
+			}
+			e := d.Halfedges[m.edge(j, k)]
+			if e == -1 {
+				return ErrIntersectingConstraints
+			}
+			switch v := d.Triangles[PrevHalfedge(e)]; {
+			case v == b || m.onSegment(a, b, v):
+				next = v
+			case m.orient2(a, b, v) < 0:
+				crossing = append(crossing, [2]int{v, k})
+			case m.orient2(a, b, v) > 0:
+				crossing = append(crossing, [2]int{j, v})
+			default:
+				return ErrIntersectingConstraints
+			}
+		}
+
+		// If a vertex lies on the segment, the part up to it is inserted
+		// first.
+		if err := m.removeCrossings(a, next, crossing); err != nil {
+			return err
+		}
+		m.constrained[edgeKey(a, next)] = true
+		a = next
+	}
+	return nil
+}
+
+// removeCrossings flips the edges that cross the segment from the vertex a
+// to the vertex b until none do.
+func (m *mesh) removeCrossings(a, b int, crossing [][2]int) error {
+	d := m.d
+	for stuck := 0; len(crossing) > 0; {
+		e := m.edge(crossing[0][0], crossing[0][1])
+		crossing = crossing[1:]
+		o := d.Halfedges[e]
+		p, q := d.Triangles[e], d.Triangles[NextHalfedge(e)]
+		r, s := d.Triangles[PrevHalfedge(e)], d.Triangles[PrevHalfedge(o)]
+		// The edge can only be flipped if the quadrilateral around it is
+		// strictly convex, otherwise it is tried again later.
+		if m.orient2(s, q, r) <= 0 || m.orient2(r, p, s) <= 0 {
+			crossing = append(crossing, [2]int{p, q})
+			if stuck++; stuck > len(crossing) {
+				return ErrIntersectingConstraints
+			}
+			continue
+		}
+		stuck = 0
+		m.flip(e)
+		// The new diagonal still crosses the segment if its ends are on
+		// opposite sides of it.
+		if r != a && r != b && s != a && s != b {
+			if or, os := m.orient2(a, b, r), m.orient2(a, b, s); or < 0 && os > 0 {
+				crossing = append(crossing, [2]int{r, s})
+			} else if or > 0 && os < 0 {
+				crossing = append(crossing, [2]int{s, r})
+			}
+		}
+	}
+	return nil
+}
+
+// restoreDelaunay flips the unconstrained edges that are not Delaunay until
+// all are.
+func (m *mesh) restoreDelaunay() {
+	d := m.d
+	for flipped := true; flipped; {
+		flipped = false
+		for e, o := range d.Halfedges {
+			if o == -1 || e > o || m.isConstrained(e) {
+				continue
+			}
+			p, q := d.Coords[d.Triangles[e]], d.Coords[d.Triangles[NextHalfedge(e)]]
+			r, s := d.Coords[d.Triangles[PrevHalfedge(e)]], d.Coords[d.Triangles[PrevHalfedge(o)]]
+			// inCircle expects the triangle in clockwise order.
+			if inCircle(p[0], p[1], r[0], r[1], q[0], q[1], s[0], s[1]) {
+				m.flip(e)
+				flipped = true
+			}
+		}
+	}
+}
+
+// inside returns for each triangle whether it is inside the constrained
+// edges: whether an odd number of them separate it from the outside of the
+// convex hull.
+func (m *mesh) inside() []bool {
+	d := m.d
+	depth := make([]int, d.NumTriangles())
+	for t := range depth {
+		depth[t] = -1
+	}
+	// Flood the triangles one depth at a time, crossing only unconstrained
+	// edges within a depth.
+	var stack, next []int
+	for e, o := range d.Halfedges {
+		if o != -1 {
+			continue
+		}
+		if m.isConstrained(e) {
+			next = append(next, e/3)
+		} else {
+			stack = append(stack, e/3)
+		}
+	}
+	for level := 0; len(stack) > 0 || len(next) > 0; level++ {
+		for len(stack) > 0 {
+			t := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if depth[t] != -1 {
+				continue
+			}
+			depth[t] = level
+			for e := 3 * t; e < 3*t+3; e++ {
+				o := d.Halfedges[e]
+				switch {
+				case o == -1 || depth[o/3] != -1:
+				case m.isConstrained(e):
+					next = append(next, o/3)
+				default:
+					stack = append(stack, o/3)
+				}
+			}
+		}
+		stack, next = next, stack
+	}
+	keep := make([]bool, len(depth))
+	for t, dep := range depth {
+		keep[t] = dep%2 == 1
+	}
+	return keep
+}
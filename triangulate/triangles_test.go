@@ -0,0 +1,159 @@
+package triangulate
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/matoous/goodgeo"
+)
+
+func TestTriangles(t *testing.T) {
+	points := goodgeo.NewMultiPoint(goodgeo.XYZ).MustSetCoords([]goodgeo.Coord{
+		{0, 0, 1}, {2, 0, 2}, nil, {2, 2, 3}, {0, 2, 4}, {1, 1, 5},
+	}).SetSRID(4326)
+	got := Triangles(points)
+	assert.Equal(t, goodgeo.XYZ, got.Layout())
+	assert.Equal(t, 4326, got.SRID())
+	assert.Equal(t, 4, got.NumPolygons())
+	var area float64
+	for _, polygon := range got.Coords() {
+		a := signedArea(polygon[0])
+		assert.True(t, a > 0)
+		area += a / 2
+	}
+	assert.Equal(t, 4.0, area)
+
+	assert.Equal(t, 0, Triangles(goodgeo.NewMultiPoint(goodgeo.XY)).NumPolygons())
+}
+
+func TestConstrainedTriangles(t *testing.T) {
+	for i, tc := range []struct {
+		coords        [][]goodgeo.Coord
+		wantTriangles int
+		wantArea      float64
+		wantErr       error
+	}{
+		{
+			coords: nil,
+		},
+		{
+			coords:        [][]goodgeo.Coord{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}},
+			wantTriangles: 1,
+			wantArea:      0.5,
+		},
+		{
+			// A U shape, whose Delaunay triangulation crosses the notch.
+			coords:        [][]goodgeo.Coord{{{0, 0}, {3, 0}, {3, 3}, {2, 3}, {2, 1}, {1, 1}, {1, 3}, {0, 3}, {0, 0}}},
+			wantTriangles: 6,
+			wantArea:      7,
+		},
+		{
+			// A thin spike, whose Delaunay triangulation crosses its edges.
+			coords:        [][]goodgeo.Coord{{{0, 0}, {10, 0}, {10, 1}, {5, 0.1}, {0, 1}, {0, 0}}},
+			wantTriangles: 3,
+			wantArea:      5.5,
+		},
+		{
+			// A square with a square hole, and a vertex in the middle of an
+			// edge of the hole.
+			coords: [][]goodgeo.Coord{
+				{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+				{{1, 1}, {1, 3}, {3, 3}, {3, 1}, {1, 1}},
+				{{0, 0}, {0, 0}},
+			},
+			wantTriangles: 8,
+			wantArea:      12,
+		},
+		{
+			// The hole touches the exterior at a vertex.
+			coords: [][]goodgeo.Coord{
+				{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+				{{0, 0}, {2, 3}, {3, 2}, {0, 0}},
+			},
+			wantTriangles: 5,
+			wantArea:      13.5,
+		},
+		{
+			// The edge from (2, 0) to (2, 4) passes through (2, 2).
+			coords:        [][]goodgeo.Coord{{{0, 0}, {2, 0}, {2, 4}, {0, 4}, {0, 0}}, {{2, 2}, {1, 3}, {1, 1}, {2, 2}}},
+			wantTriangles: 6,
+			wantArea:      7,
+		},
+		{
+			coords:  [][]goodgeo.Coord{{{0, 0}, {2, 2}, {2, 0}, {0, 2}, {0, 0}}},
+			wantErr: ErrIntersectingConstraints,
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			p := goodgeo.NewPolygon(goodgeo.XY).MustSetCoords(tc.coords).SetSRID(4326)
+			got, err := ConstrainedTriangles(p)
+			assert.IsError(t, err, tc.wantErr)
+			if tc.wantErr != nil {
+				return
+			}
+			assert.Equal(t, 4326, got.SRID())
+			assert.Equal(t, tc.wantTriangles, got.NumPolygons())
+			var area float64
+			for _, polygon := range got.Coords() {
+				a := signedArea(polygon[0])
+				assert.True(t, a > 0)
+				area += a / 2
+				// The triangles are inside p.
+				centroid := goodgeo.Coord{
+					(polygon[0][0][0] + polygon[0][1][0] + polygon[0][2][0]) / 3,
+					(polygon[0][0][1] + polygon[0][1][1] + polygon[0][2][1]) / 3,
+				}
+				assert.True(t, goodgeo.BooleanPointInPolygon(centroid, p, nil))
+			}
+			assert.Equal(t, tc.wantArea, math.Round(area*1e9)/1e9)
+		})
+	}
+}
+
+func TestConstrainedTrianglesRandom(t *testing.T) {
+	// A star shaped polygon with a star shaped hole.
+	r := rand.New(rand.NewSource(1))
+	star := func(n int, r0, r1 float64, clockwise bool) []goodgeo.Coord {
+		coords := make([]goodgeo.Coord, n+1)
+		for i := range n {
+			angle := 2 * math.Pi * float64(i) / float64(n)
+			if clockwise {
+				angle = -angle
+			}
+			radius := r0 + r.Float64()*(r1-r0)
+			coords[i] = goodgeo.Coord{radius * math.Cos(angle), radius * math.Sin(angle)}
+		}
+		coords[n] = coords[0]
+		return coords
+	}
+	for i := range 10 {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			p := goodgeo.NewPolygon(goodgeo.XY).MustSetCoords([][]goodgeo.Coord{
+				star(100, 5, 10, false),
+				star(50, 1, 4, true),
+			})
+			got, err := ConstrainedTriangles(p)
+			assert.NoError(t, err)
+			var area float64
+			for _, polygon := range got.Coords() {
+				area += signedArea(polygon[0]) / 2
+			}
+			want := (signedArea(p.Coords()[0]) + signedArea(p.Coords()[1])) / 2
+			assert.True(t, math.Abs(area-want) < 1e-9*want)
+			assert.Equal(t, 100+50, got.NumPolygons())
+		})
+	}
+}
+
+// signedArea returns twice the signed area of the closed ring coords.
+func signedArea(coords []goodgeo.Coord) float64 {
+	var a float64
+	for i := 1; i < len(coords); i++ {
+		a += coords[i-1][0]*coords[i][1] - coords[i][0]*coords[i-1][1]
+	}
+	return a
+}
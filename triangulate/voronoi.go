@@ -0,0 +1,168 @@
+package triangulate
+
+import (
+	"math"
+
+	"github.com/matoous/goodgeo"
+)
+
+// Voronoi returns the Voronoi diagram of points clipped to b: the ith
+// polygon is the region of b that is closer to the ith point than to any
+// other point, with a counter-clockwise ring. Duplicate points get the same
+// cell, empty points and cells outside b get empty polygons.
+//
+// The cells are built from the circumcenters of the Delaunay triangles
+// around each point. Four points far outside b and the points are added to
+// the triangulation, which bounds the cells of all points without changing
+// them within b.
+func Voronoi(points *goodgeo.MultiPoint, b *goodgeo.Bounds) []*goodgeo.Polygon {
+	coords, index := pointCoords(points)
+	cells := make([]*goodgeo.Polygon, len(index))
+	for i := range cells {
+		cells[i] = goodgeo.NewPolygon(goodgeo.XY).SetSRID(points.SRID())
+	}
+	if len(coords) == 0 || b.IsEmpty() {
+		return cells
+	}
+
+	minX, minY, maxX, maxY := b.Min(0), b.Min(1), b.Max(0), b.Max(1)
+	for _, c := range coords {
+		minX, minY = min(minX, c[0]), min(minY, c[1])
+		maxX, maxY = max(maxX, c[0]), max(maxY, c[1])
+	}
+	// A point within b is at most 2r from any point and more than 13r from
+	// the far points, so it is never in the cells of the far points.
+	cx, cy := (minX+maxX)/2, (minY+maxY)/2
+	r := math.Hypot(maxX-minX, maxY-minY) / 2
+	if r == 0 {
+		r = 1
+	}
+	n := len(coords)
+	coords = append(coords,
+		goodgeo.Coord{cx - 10*r*math.Sqrt2, cy},
+		goodgeo.Coord{cx + 10*r*math.Sqrt2, cy},
+		goodgeo.Coord{cx, cy - 10*r*math.Sqrt2},
+		goodgeo.Coord{cx, cy + 10*r*math.Sqrt2},
+	)
+	d := NewDelaunay(coords)
+	edges := d.vertexEdges()
+	centers := make([]goodgeo.Coord, d.NumTriangles())
+	for t := range centers {
+		a, b, c := coords[d.Triangles[3*t]], coords[d.Triangles[3*t+1]], coords[d.Triangles[3*t+2]]
+		x, y := circumcenter(a[0], a[1], b[0], b[1], c[0], c[1])
+		centers[t] = goodgeo.Coord{x, y}
+	}
+
+	cellCoords := make([][]goodgeo.Coord, n)
+	for i := range n {
+		e0 := edges[i]
+		if e0 == -1 {
+			continue
+		}
+		// Walk around the point counter-clockwise.
+		var ring []goodgeo.Coord
+		for e := e0; ; {
+			ring = append(ring, centers[e/3])
+			e = d.Halfedges[PrevHalfedge(e)]
+			if e == e0 || e == -1 {
+				break
+			}
+		}
+		ring = clipRect(ring, b.Min(0), b.Min(1), b.Max(0), b.Max(1))
+		if len(ring) >= 3 {
+			cellCoords[i] = append(ring, ring[0])
+		}
+	}
+	var kept map[[2]float64]int
+	for i, j := range index {
+		if j == -1 {
+			continue
+		}
+		if edges[j] == -1 {
+			// Duplicate points are left out of the triangulation, use the
+			// cell of the point that was kept.
+			if kept == nil {
+				kept = make(map[[2]float64]int)
+				for k, c := range coords[:n] {
+					if edges[k] != -1 {
+						kept[[2]float64{c[0], c[1]}] = k
+					}
+				}
+			}
+			j = kept[[2]float64{coords[j][0], coords[j][1]}]
+		}
+		if cellCoords[j] != nil {
+			cells[i].MustSetCoords([][]goodgeo.Coord{cellCoords[j]})
+		}
+	}
+	return cells
+}
+
+// pointCoords returns the X and Y ordinates of the non-empty points of
+// points and the index into them of each point, -1 for empty points.
+func pointCoords(points *goodgeo.MultiPoint) ([]goodgeo.Coord, []int) {
+	var coords []goodgeo.Coord
+	index := make([]int, points.NumPoints())
+	for i, c := range points.Coords() {
+		if c == nil {
+			index[i] = -1
+			continue
+		}
+		index[i] = len(coords)
+		coords = append(coords, goodgeo.Coord{c[0], c[1]})
+	}
+	return coords, index
+}
+
+// vertexEdges returns an edge that starts at each point, or -1 for points
+// left out of the triangulation. For points on the hull, the edge is the
+// one on the hull.
+func (d *Delaunay) vertexEdges() []int {
+	edges := make([]int, len(d.Coords))
+	for i := range edges {
+		edges[i] = -1
+	}
+	for e, v := range d.Triangles {
+		if edges[v] == -1 || d.Halfedges[e] == -1 {
+			edges[v] = e
+		}
+	}
+	return edges
+}
+
+// clipRect clips the convex polygon ring to the rectangle from minX, minY to
+// maxX, maxY with the Sutherland–Hodgman algorithm.
+func clipRect(ring []goodgeo.Coord, minX, minY, maxX, maxY float64) []goodgeo.Coord {
+	for _, edge := range []struct {
+		dim   int
+		v     float64
+		upper bool
+	}{
+		{0, minX, false},
+		{0, maxX, true},
+		{1, minY, false},
+		{1, maxY, true},
+	} {
+		inside := func(c goodgeo.Coord) bool {
+			if edge.upper {
+				return c[edge.dim] <= edge.v
+			}
+			return c[edge.dim] >= edge.v
+		}
+		var clipped []goodgeo.Coord
+		for i, c := range ring {
+			prev := ring[(i+len(ring)-1)%len(ring)]
+			if inside(c) != inside(prev) {
+				t := (edge.v - prev[edge.dim]) / (c[edge.dim] - prev[edge.dim])
+				p := goodgeo.Coord{prev[0] + t*(c[0]-prev[0]), prev[1] + t*(c[1]-prev[1])}
+				p[edge.dim] = edge.v
+				clipped = append(clipped, p)
+			}
+			if inside(c) {
+				clipped = append(clipped, c)
+			}
+		}
+		ring = clipped
+	}
+	return ring
+}
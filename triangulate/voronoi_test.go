@@ -0,0 +1,96 @@
+package triangulate
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/matoous/goodgeo"
+)
+
+func TestVoronoi(t *testing.T) {
+	b := goodgeo.NewBounds(goodgeo.XY).Set(0, 0, 4, 4)
+	for i, tc := range []struct {
+		coords    []goodgeo.Coord
+		b         *goodgeo.Bounds
+		wantAreas []float64
+	}{
+		{
+			coords:    nil,
+			b:         b,
+			wantAreas: []float64{},
+		},
+		{
+			coords:    []goodgeo.Coord{{1, 1}},
+			b:         b,
+			wantAreas: []float64{16},
+		},
+		{
+			coords:    []goodgeo.Coord{{1, 1}, {3, 1}},
+			b:         b,
+			wantAreas: []float64{8, 8},
+		},
+		{
+			coords:    []goodgeo.Coord{{1, 1}, {3, 1}, {3, 3}, {1, 3}, nil, {3, 3}},
+			b:         b,
+			wantAreas: []float64{4, 4, 4, 4, 0, 4},
+		},
+		{
+			// The cell of a point far outside the bounds is empty.
+			coords:    []goodgeo.Coord{{1, 2}, {3, 2}, {20, 2}},
+			b:         b,
+			wantAreas: []float64{8, 8, 0},
+		},
+		{
+			coords:    []goodgeo.Coord{{1, 1}, {3, 1}},
+			b:         goodgeo.NewBounds(goodgeo.XY),
+			wantAreas: []float64{0, 0},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			points := goodgeo.NewMultiPoint(goodgeo.XY).MustSetCoords(tc.coords).SetSRID(4326)
+			cells := Voronoi(points, tc.b)
+			areas := make([]float64, len(cells))
+			for j, cell := range cells {
+				assert.Equal(t, 4326, cell.SRID())
+				if !cell.Empty() {
+					areas[j] = math.Round(signedArea(cell.Coords()[0])/2*1e9) / 1e9
+				}
+			}
+			assert.Equal(t, tc.wantAreas, areas)
+		})
+	}
+}
+
+func TestVoronoiRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	b := goodgeo.NewBounds(goodgeo.XY).Set(-10, -10, 10, 10)
+	coords := make([]goodgeo.Coord, 500)
+	for i := range coords {
+		coords[i] = goodgeo.Coord{r.Float64()*30 - 15, r.Float64()*30 - 15}
+	}
+	points := goodgeo.NewMultiPoint(goodgeo.XY).MustSetCoords(coords)
+	cells := Voronoi(points, b)
+
+	// The cells cover the bounds, and each point is closer to the points
+	// of its cell than any other point.
+	var area float64
+	for i, cell := range cells {
+		if cell.Empty() {
+			continue
+		}
+		ring := cell.Coords()[0]
+		assert.True(t, signedArea(ring) > 0)
+		area += signedArea(ring) / 2
+		for _, c := range ring {
+			d := math.Hypot(c[0]-coords[i][0], c[1]-coords[i][1])
+			for _, other := range coords {
+				assert.True(t, d <= math.Hypot(c[0]-other[0], c[1]-other[1])+1e-9)
+			}
+		}
+	}
+	assert.True(t, math.Abs(area-400) < 1e-9)
+}
@@ -10,6 +10,13 @@ import (
 // by the Haversine formula to reduce error.
 const EarthRadius = 6371008.8
 
+// The semi-major axis and flattening of the [WGS84](https://apps.dtic.mil/sti/citations/ADA280358)
+// reference ellipsoid. Used by geom functions that model the Earth as an ellipsoid.
+const (
+	WGS84SemiMajorAxis = 6378137.0
+	WGS84Flattening    = 1 / 298.257223563
+)
+
 type Meter struct{}
 
 type Meters float64
@@ -18,6 +25,12 @@ func (m Meters) String() string {
 	return fmt.Sprintf("%.3f m", m)
 }
 
+type SquareMeters float64
+
+func (m SquareMeters) String() string {
+	return fmt.Sprintf("%.3f m²", m)
+}
+
 type Radian struct{}
 
 type Radians float64
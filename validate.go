@@ -0,0 +1,487 @@
+package goodgeo
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// A ValidationReason is the reason why a geometry is invalid, following the
+// OGC Simple Features specification.
+type ValidationReason int
+
+const (
+	// InvalidCoordinate is a coordinate with an ordinate that is NaN or
+	// infinite.
+	InvalidCoordinate ValidationReason = iota + 1
+	// TooFewPoints is a line with fewer than two distinct points or a ring
+	// with fewer than three.
+	TooFewPoints
+	// RingNotClosed is a ring whose last coordinate differs from its first.
+	RingNotClosed
+	// RepeatedPoint is a ring with two equal consecutive coordinates.
+	RepeatedPoint
+	// SelfIntersection is a ring that crosses or touches itself, rings of a
+	// polygon that cross or share an edge, or polygons of a multi-polygon
+	// that do.
+	SelfIntersection
+	// WrongOrientation is an exterior ring that is not counter-clockwise or a
+	// hole that is not clockwise.
+	WrongOrientation
+	// HoleOutsideShell is a hole that is not inside the exterior ring of its
+	// polygon.
+	HoleOutsideShell
+	// NestedHoles is a hole inside another hole of the same polygon.
+	NestedHoles
+	// DisconnectedInterior is a polygon whose rings touch so that its
+	// interior is split into several parts.
+	DisconnectedInterior
+	// NestedShells is a polygon of a multi-polygon inside another.
+	NestedShells
+)
+
+// String returns a human-readable string representing r.
+func (r ValidationReason) String() string {
+	switch r {
+	case InvalidCoordinate:
+		return "Invalid coordinate"
+	case TooFewPoints:
+		return "Too few points"
+	case RingNotClosed:
+		return "Ring not closed"
+	case RepeatedPoint:
+		return "Repeated point"
+	case SelfIntersection:
+		return "Self-intersection"
+	case WrongOrientation:
+		return "Wrong ring orientation"
+	case HoleOutsideShell:
+		return "Hole lies outside shell"
+	case NestedHoles:
+		return "Holes are nested"
+	case DisconnectedInterior:
+		return "Interior is disconnected"
+	case NestedShells:
+		return "Nested shells"
+	default:
+		return fmt.Sprintf("ValidationReason(%d)", int(r))
+	}
+}
+
+// A ValidationError describes why and where a geometry is invalid.
+type ValidationError struct {
+	Reason ValidationReason
+	// Location is the coordinate at which the problem was found.
+	Location Coord
+	// Path holds the indices of the invalid part, outermost first: the
+	// geometry in a GeometryCollection, the polygon in a MultiPolygon, the
+	// ring in a Polygon.
+	Path []int
+}
+
+func (e ValidationError) Error() string {
+	if len(e.Location) < 2 {
+		return e.Reason.String()
+	}
+	return fmt.Sprintf("%s at or near point %g %g", e.Reason, e.Location[0], e.Location[1])
+}
+
+// Validate returns the reasons why g is not valid according to the OGC
+// Simple Features specification, or nil if it is valid. Exterior rings must
+// be counter-clockwise and holes clockwise, as in RFC 7946, and rings must
+// not repeat points. Coordinates are treated as planar. Once a ring is found
+// to be structurally broken, for example not closed, the checks of how it
+// relates to other rings are skipped.
+func Validate(g T) []ValidationError {
+	var v validator
+	v.validate(g)
+	return v.errs
+}
+
+// A validator collects the validation errors of a geometry.
+type validator struct {
+	errs []ValidationError
+	path []int
+}
+
+func (v *validator) report(reason ValidationReason, location Coord, path ...int) {
+	v.errs = append(v.errs, ValidationError{
+		Reason:   reason,
+		Location: location,
+		Path:     append(append([]int(nil), v.path...), path...),
+	})
+}
+
+// within calls f with i appended to the path.
+func (v *validator) within(i int, f func()) {
+	v.path = append(v.path, i)
+	f()
+	v.path = v.path[:len(v.path)-1]
+}
+
+func (v *validator) validate(g T) {
+	switch g := g.(type) {
+	case *Point:
+		if !g.Empty() {
+			v.coords([]Coord{g.Coords()})
+		}
+	case *MultiPoint:
+		for i, c := range g.Coords() {
+			if c != nil {
+				v.within(i, func() { v.coords([]Coord{c}) })
+			}
+		}
+	case *LineString:
+		v.line(g.Coords())
+	case *MultiLineString:
+		for i, line := range g.Coords() {
+			v.within(i, func() { v.line(line) })
+		}
+	case *LinearRing:
+		if ring := v.ring(g.Coords(), false); ring != nil {
+			v.selfIntersections(ring)
+		}
+	case *Polygon:
+		v.polygon(g.Coords())
+	case *MultiPolygon:
+		polygons := g.Coords()
+		valid := true
+		for i, polygon := range polygons {
+			n := len(v.errs)
+			v.within(i, func() { v.polygon(polygon) })
+			valid = valid && len(v.errs) == n
+		}
+		if valid {
+			v.shells(polygons)
+		}
+	case *GeometryCollection:
+		for i, g := range g.Geoms() {
+			v.within(i, func() { v.validate(g) })
+		}
+	}
+}
+
+// coords reports the coordinates that are not finite and returns whether
+// all are.
+func (v *validator) coords(coords []Coord) bool {
+	for _, c := range coords {
+		for _, ordinate := range c {
+			if math.IsNaN(ordinate) || math.IsInf(ordinate, 0) {
+				v.report(InvalidCoordinate, c)
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (v *validator) line(line []Coord) {
+	if len(line) == 0 || !v.coords(line) {
+		return
+	}
+	for _, c := range line[1:] {
+		if c[0] != line[0][0] || c[1] != line[0][1] {
+			return
+		}
+	}
+	v.report(TooFewPoints, line[0])
+}
+
+// ring checks the structure of ring and its orientation, clockwise for
+// holes, and returns it without repeated points, or nil if it is broken.
+func (v *validator) ring(ring []Coord, hole bool) []Coord {
+	if len(ring) == 0 {
+		v.report(TooFewPoints, nil)
+		return nil
+	}
+	if !v.coords(ring) {
+		return nil
+	}
+	first, last := ring[0], ring[len(ring)-1]
+	if first[0] != last[0] || first[1] != last[1] {
+		v.report(RingNotClosed, last)
+		return nil
+	}
+	distinct := []Coord{ring[0]}
+	repeated := false
+	for _, c := range ring[1:] {
+		if prev := distinct[len(distinct)-1]; c[0] == prev[0] && c[1] == prev[1] {
+			if !repeated {
+				v.report(RepeatedPoint, c)
+				repeated = true
+			}
+			continue
+		}
+		distinct = append(distinct, c)
+	}
+	if len(distinct) < 4 {
+		v.report(TooFewPoints, ring[0])
+		return nil
+	}
+	// Rings that cross themselves may have no area and no orientation.
+	if area := ringDoubleArea(distinct); area != 0 && (area < 0) != hole {
+		v.report(WrongOrientation, ring[0])
+	}
+	return distinct
+}
+
+// selfIntersections reports the first place where the closed ring without
+// repeated points crosses or touches itself, and returns whether there is
+// none.
+func (v *validator) selfIntersections(ring []Coord) bool {
+	var found Coord
+	var foundKey [4]int
+	sweepSegments(appendSegments(nil, [][]Coord{ring}, 0), func(s, t *segment) {
+		kind, p := intersectSegments(s.a, s.b, t.a, t.b)
+		// Consecutive segments share an end, and only intersect otherwise if
+		// they overlap.
+		if kind == disjointSegments || adjacentSegments(s, t) && kind != overlappingSegments {
+			return
+		}
+		if key := pairKey(s, t); found == nil || slices.Compare(key[:], foundKey[:]) < 0 {
+			found, foundKey = p, key
+		}
+	})
+	if found != nil {
+		v.report(SelfIntersection, found)
+		return false
+	}
+	return true
+}
+
+func (v *validator) polygon(polygon [][]Coord) {
+	if len(polygon) == 0 {
+		return
+	}
+	rings := make([][]Coord, len(polygon))
+	broken := false
+	for i, ring := range polygon {
+		v.within(i, func() {
+			rings[i] = v.ring(ring, i > 0)
+			broken = broken || rings[i] == nil || !v.selfIntersections(rings[i])
+		})
+	}
+	if broken {
+		return
+	}
+
+	// Rings may only touch at points, and not in a way that splits the
+	// interior, which happens if the graph that links the rings to the
+	// points where they touch has a cycle.
+	components := make([]int, len(rings))
+	for i := range components {
+		components[i] = i
+	}
+	component := func(i int) int {
+		for components[i] != i {
+			i = components[i]
+		}
+		return i
+	}
+	type touch struct {
+		at  Coord
+		key [4]int
+	}
+	var touches []touch
+	var crossing Coord
+	var crossingKey [4]int
+	sweepSegments(appendSegments(nil, rings, 0), func(s, t *segment) {
+		if s.part == t.part {
+			return
+		}
+		switch kind, p := intersectSegments(s.a, s.b, t.a, t.b); kind {
+		case disjointSegments:
+		case touchingSegments:
+			touches = append(touches, touch{p, pairKey(s, t)})
+		default:
+			if key := pairKey(s, t); crossing == nil || slices.Compare(key[:], crossingKey[:]) < 0 {
+				crossing, crossingKey = p, key
+			}
+		}
+	})
+	if crossing != nil {
+		v.report(SelfIntersection, crossing, crossingKey[1])
+		return
+	}
+	slices.SortStableFunc(touches, func(a, b touch) int { return slices.Compare(a.key[:], b.key[:]) })
+	points := make(map[[2]float64]int)
+	linked := make(map[[2]int]bool)
+	for _, touch := range touches {
+		key := [2]float64{touch.at[0], touch.at[1]}
+		p, ok := points[key]
+		if !ok {
+			p = len(components)
+			points[key] = p
+			components = append(components, p)
+		}
+		for _, r := range touch.key[:2] {
+			if linked[[2]int{r, p}] {
+				continue
+			}
+			linked[[2]int{r, p}] = true
+			cr, cp := component(r), component(p)
+			if cr == cp {
+				v.report(DisconnectedInterior, touch.at, touch.key[1])
+				return
+			}
+			components[cp] = cr
+		}
+	}
+
+	for i, hole := range rings[1:] {
+		if !ringInside(hole, rings[0]) {
+			v.report(HoleOutsideShell, hole[0], i+1)
+			return
+		}
+		for j, other := range rings[1:] {
+			if i != j && ringInside(hole, other) {
+				v.report(NestedHoles, hole[0], i+1)
+				return
+			}
+		}
+	}
+}
+
+// shells checks that the valid polygons do not overlap.
+func (v *validator) shells(polygons [][][]Coord) {
+	shells := make([][]Coord, len(polygons))
+	bounds := make([]*Bounds, len(polygons))
+	for i, polygon := range polygons {
+		if len(polygon) > 0 {
+			shells[i] = dedupe(polygon[0])
+			bounds[i] = NewBounds(XY)
+			for _, c := range shells[i] {
+				bounds[i].extendFlatCoords(c, 0, 2, 2)
+			}
+		}
+	}
+	for i := range polygons {
+		for j := i + 1; j < len(polygons); j++ {
+			if shells[i] == nil || shells[j] == nil || !bounds[i].Overlaps(XY, bounds[j]) {
+				continue
+			}
+			a, b := shells[i], shells[j]
+			if _, crossing := ringsIntersection(a, b); crossing != nil {
+				v.report(SelfIntersection, crossing, j)
+				return
+			}
+			if polygonContainsRing(polygons[i], b) || polygonContainsRing(polygons[j], a) {
+				v.report(NestedShells, b[0], j)
+				return
+			}
+		}
+	}
+}
+
+// polygonContainsRing returns true if the ring, which does not cross the
+// rings of polygon, is inside polygon.
+func polygonContainsRing(polygon [][]Coord, ring []Coord) bool {
+	if !ringInside(ring, dedupe(polygon[0])) {
+		return false
+	}
+	for _, hole := range polygon[1:] {
+		if ringInside(ring, dedupe(hole)) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringsIntersection returns the distinct points where two rings touch, or
+// the first point where they cross or share a segment.
+func ringsIntersection(a, b []Coord) ([]Coord, Coord) {
+	var touches []Coord
+	var crossing Coord
+	var crossingKey [4]int
+	sweepSegments(appendSegments(nil, [][]Coord{a, b}, 0), func(s, t *segment) {
+		if s.part == t.part {
+			return
+		}
+		switch kind, p := intersectSegments(s.a, s.b, t.a, t.b); kind {
+		case disjointSegments:
+		case touchingSegments:
+			if !slices.ContainsFunc(touches, func(touch Coord) bool { return touch[0] == p[0] && touch[1] == p[1] }) {
+				touches = append(touches, p)
+			}
+		default:
+			if key := pairKey(s, t); crossing == nil || slices.Compare(key[:], crossingKey[:]) < 0 {
+				crossing, crossingKey = p, key
+			}
+		}
+	})
+	if crossing != nil {
+		return nil, crossing
+	}
+	return touches, nil
+}
+
+// pairKey orders pairs of segments as nested loops over the rings and their
+// segments would find them.
+func pairKey(s, t *segment) [4]int {
+	return [4]int{s.part, t.part, s.index, t.index}
+}
+
+// ringInside returns true if ring, which does not cross other, is inside
+// other. The midpoint of a segment of ring that is not on other decides.
+func ringInside(ring, other []Coord) bool {
+	flatCoords, _ := deflate1(nil, other, len(other[0]))
+	for i := 1; i < len(ring); i++ {
+		mid := Coord{(ring[i-1][0] + ring[i][0]) / 2, (ring[i-1][1] + ring[i][1]) / 2}
+		if inside, boundary := pointInRing(mid, flatCoords, 0, len(flatCoords), len(other[0])); !boundary {
+			return inside
+		}
+	}
+	return false
+}
+
+// dedupe returns ring without repeated consecutive points.
+func dedupe(ring []Coord) []Coord {
+	if len(ring) == 0 {
+		return nil
+	}
+	deduped := []Coord{ring[0]}
+	for _, c := range ring[1:] {
+		if prev := deduped[len(deduped)-1]; c[0] != prev[0] || c[1] != prev[1] {
+			deduped = append(deduped, c)
+		}
+	}
+	return deduped
+}
+
+// ringDoubleArea returns twice the signed area of the closed ring, positive
+// for counter-clockwise rings.
+func ringDoubleArea(ring []Coord) float64 {
+	var area float64
+	for i := 1; i < len(ring); i++ {
+		area += ring[i-1][0]*ring[i][1] - ring[i][0]*ring[i-1][1]
+	}
+	return area
+}
+
+// A segmentRelation is the way in which two segments intersect.
+type segmentRelation int
+
+const (
+	disjointSegments segmentRelation = iota
+	// touchingSegments meet at an end of one of them.
+	touchingSegments
+	// crossingSegments cross at a point inside both of them.
+	crossingSegments
+	// overlappingSegments are collinear and share more than a point.
+	overlappingSegments
+)
+
+// intersectSegments returns how the segments ab and cd intersect, and a
+// point where they do.
+func intersectSegments(a, b, c, d Coord) (segmentRelation, Coord) {
+	switch x := segmentIntersection(a, b, c, d); {
+	case len(x) == 0:
+		return disjointSegments, nil
+	case len(x) == 2:
+		return overlappingSegments, x[0]
+	case equals(x[0], a) || equals(x[0], b) || equals(x[0], c) || equals(x[0], d):
+		return touchingSegments, x[0]
+	default:
+		return crossingSegments, x[0]
+	}
+}
@@ -0,0 +1,211 @@
+package goodgeo
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestValidate(t *testing.T) {
+	square := []Coord{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	for i, tc := range []struct {
+		g    T
+		want []ValidationError
+	}{
+		{
+			g: NewPoint(XY).MustSetCoords(Coord{1, 2}),
+		},
+		{
+			g:    NewPoint(XY).MustSetCoords(Coord{1, math.Inf(1)}),
+			want: []ValidationError{{Reason: InvalidCoordinate, Location: Coord{1, math.Inf(1)}, Path: []int{}}},
+		},
+		{
+			g: NewMultiPoint(XY).MustSetCoords([]Coord{{1, 2}, nil}),
+		},
+		{
+			g:    NewLineString(XY).MustSetCoords([]Coord{{1, 2}, {1, 2}}),
+			want: []ValidationError{{Reason: TooFewPoints, Location: Coord{1, 2}, Path: []int{}}},
+		},
+		{
+			g: NewPolygon(XY).MustSetCoords([][]Coord{square}),
+		},
+		{
+			g: NewPolygon(XY).MustSetCoords([][]Coord{square, {{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}}}),
+		},
+		{
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{{{0, 0}, {10, 0}, {10, 10}, {0, 10}}}),
+			want: []ValidationError{{Reason: RingNotClosed, Location: Coord{0, 10}, Path: []int{0}}},
+		},
+		{
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{{{0, 0}, {10, 0}, {10, 0}, {0, 0}}}),
+			want: []ValidationError{{Reason: RepeatedPoint, Location: Coord{10, 0}, Path: []int{0}}, {Reason: TooFewPoints, Location: Coord{0, 0}, Path: []int{0}}},
+		},
+		{
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{{{0, 0}, {0, 10}, {10, 10}, {10, 0}, {0, 0}}}),
+			want: []ValidationError{{Reason: WrongOrientation, Location: Coord{0, 0}, Path: []int{0}}},
+		},
+		{
+			// A bow-tie.
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{{{0, 0}, {10, 10}, {10, 0}, {0, 10}, {0, 0}}}),
+			want: []ValidationError{{Reason: SelfIntersection, Location: Coord{5, 5}, Path: []int{0}}},
+		},
+		{
+			// A ring that touches itself.
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{{{0, 0}, {10, 0}, {10, 10}, {5, 0}, {0, 10}, {0, 0}}}),
+			want: []ValidationError{{Reason: SelfIntersection, Location: Coord{5, 0}, Path: []int{0}}},
+		},
+		{
+			// A hole that touches the shell at a point is valid.
+			g: NewPolygon(XY).MustSetCoords([][]Coord{square, {{0, 5}, {5, 8}, {5, 2}, {0, 5}}}),
+		},
+		{
+			// A hole that touches the shell at two points.
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{square, {{0, 5}, {5, 10}, {5, 2}, {0, 5}}}),
+			want: []ValidationError{{Reason: DisconnectedInterior, Location: Coord{0, 5}, Path: []int{1}}},
+		},
+		{
+			// Holes that touch each other and the shell.
+			g: NewPolygon(XY).MustSetCoords([][]Coord{
+				square,
+				{{0, 5}, {3, 7}, {5, 5}, {0, 5}},
+				{{5, 5}, {7, 7}, {10, 5}, {5, 5}},
+			}),
+			want: []ValidationError{{Reason: DisconnectedInterior, Location: Coord{5, 5}, Path: []int{2}}},
+		},
+		{
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{square, {{5, 5}, {5, 15}, {15, 15}, {15, 5}, {5, 5}}}),
+			want: []ValidationError{{Reason: SelfIntersection, Location: Coord{10, 5}, Path: []int{1}}},
+		},
+		{
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{square, {{20, 20}, {20, 30}, {30, 30}, {30, 20}, {20, 20}}}),
+			want: []ValidationError{{Reason: HoleOutsideShell, Location: Coord{20, 20}, Path: []int{1}}},
+		},
+		{
+			g: NewPolygon(XY).MustSetCoords([][]Coord{
+				square,
+				{{1, 1}, {1, 9}, {9, 9}, {9, 1}, {1, 1}},
+				{{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}},
+			}),
+			want: []ValidationError{{Reason: NestedHoles, Location: Coord{2, 2}, Path: []int{2}}},
+		},
+		{
+			// Polygons that touch at a point are valid.
+			g: NewMultiPolygon(XY).MustSetCoords([][][]Coord{
+				{square},
+				{{{10, 10}, {20, 10}, {20, 20}, {10, 10}}},
+			}),
+		},
+		{
+			g: NewMultiPolygon(XY).MustSetCoords([][][]Coord{
+				{square},
+				{{{2, 2}, {4, 2}, {4, 4}, {2, 2}}},
+			}),
+			want: []ValidationError{{Reason: NestedShells, Location: Coord{2, 2}, Path: []int{1}}},
+		},
+		{
+			// A polygon in the hole of another is valid.
+			g: NewMultiPolygon(XY).MustSetCoords([][][]Coord{
+				{square, {{1, 1}, {1, 9}, {9, 9}, {9, 1}, {1, 1}}},
+				{{{2, 2}, {4, 2}, {4, 4}, {2, 2}}},
+			}),
+		},
+		{
+			g: NewMultiPolygon(XY).MustSetCoords([][][]Coord{
+				{square},
+				{{{10, 0}, {20, 0}, {20, 10}, {10, 10}, {10, 0}}},
+			}),
+			want: []ValidationError{{Reason: SelfIntersection, Location: Coord{10, 0}, Path: []int{1}}},
+		},
+		{
+			g: NewGeometryCollection().MustPush(
+				NewPoint(XY).MustSetCoords(Coord{1, 2}),
+				NewMultiPolygon(XY).MustSetCoords([][][]Coord{{square}, {{{0, 0}, {1, 1}, {1, 0}, {0, 1}, {0, 0}}}}),
+			),
+			want: []ValidationError{
+				{Reason: SelfIntersection, Location: Coord{0.5, 0.5}, Path: []int{1, 1, 0}},
+			},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tc.want, Validate(tc.g))
+		})
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	err := ValidationError{Reason: SelfIntersection, Location: Coord{5, 5}}
+	assert.Equal(t, "Self-intersection at or near point 5 5", err.Error())
+}
+
+func TestMakeValid(t *testing.T) {
+	square := []Coord{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	for i, tc := range []struct {
+		g    T
+		want T
+	}{
+		{
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{square}),
+			want: NewPolygon(XY).MustSetCoords([][]Coord{square}),
+		},
+		{
+			g:    NewLineString(XY).MustSetCoords([]Coord{{1, 2}, {1, 2}}),
+			want: NewPoint(XY).MustSetCoords(Coord{1, 2}),
+		},
+		{
+			g:    NewLineString(XY).MustSetCoords([]Coord{{1, 2}, {math.NaN(), 0}, {3, 4}, {3, 4}}),
+			want: NewLineString(XY).MustSetCoords([]Coord{{1, 2}, {3, 4}}),
+		},
+		{
+			// The ring is closed, the repeated point removed and the ring
+			// reversed.
+			g:    NewPolygon(XY).MustSetCoords([][]Coord{{{0, 0}, {0, 10}, {10, 10}, {10, 10}, {10, 0}}}),
+			want: NewPolygon(XY).MustSetCoords([][]Coord{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}}),
+		},
+		{
+			// A bow-tie is split in two.
+			g: NewPolygon(XY).MustSetCoords([][]Coord{{{0, 0}, {10, 10}, {10, 0}, {0, 10}, {0, 0}}}).SetSRID(4326),
+			want: NewMultiPolygon(XY).MustSetCoords([][][]Coord{
+				{{{0, 0}, {5, 5}, {0, 10}, {0, 0}}},
+				{{{5, 5}, {10, 0}, {10, 10}, {5, 5}}},
+			}).SetSRID(4326),
+		},
+		{
+			// Degenerate rings are dropped and the hole is reversed.
+			g: NewPolygon(XY).MustSetCoords([][]Coord{
+				square,
+				{{1, 1}, {2, 2}, {1, 1}},
+				{{2, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 2}},
+			}),
+			want: NewPolygon(XY).MustSetCoords([][]Coord{square, {{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}}}),
+		},
+		{
+			// A hole outside the shell becomes a polygon.
+			g: NewPolygon(XY).MustSetCoords([][]Coord{
+				square,
+				{{20, 20}, {20, 30}, {30, 30}, {30, 20}, {20, 20}},
+			}),
+			want: NewMultiPolygon(XY).MustSetCoords([][][]Coord{
+				{square},
+				{{{20, 20}, {30, 20}, {30, 30}, {20, 30}, {20, 20}}},
+			}),
+		},
+		{
+			// Overlapping polygons are merged.
+			g: NewMultiPolygon(XY).MustSetCoords([][][]Coord{
+				{square},
+				{{{5, 5}, {15, 5}, {15, 15}, {5, 15}, {5, 5}}},
+			}),
+			want: NewMultiPolygon(XY).MustSetCoords([][][]Coord{
+				{{{0, 0}, {10, 0}, {10, 5}, {15, 5}, {15, 15}, {5, 15}, {5, 10}, {0, 10}, {0, 0}}},
+			}),
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := MakeValid(tc.g)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, nil, Validate(got))
+		})
+	}
+}